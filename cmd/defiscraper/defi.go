@@ -39,6 +39,20 @@ func handleDefiState(c chan *dia.DefiProtocolState, wg *sync.WaitGroup, ds model
 	}
 }
 
+// handleDefiMarketState writes states of single lending markets to the database
+func handleDefiMarketState(c chan *dia.DefiMarketState, wg *sync.WaitGroup, ds models.Datastore) {
+	defer wg.Done()
+	// Pull from channel as long as not empty
+	for {
+		t, ok := <-c
+		if !ok {
+			log.Error("error")
+			return
+		}
+		ds.SetDefiMarketStateInflux(t)
+	}
+}
+
 func main() {
 	rateType := flag.String("type", "DYDX", "Type of Defi rate")
 	flag.Parse()
@@ -54,9 +68,10 @@ func main() {
 		defer sRate.Close()
 
 		// Send rates to the database while the scraper scrapes
-		wg.Add(2)
+		wg.Add(3)
 		go handleDefiInterestRate(sRate.RateChannel(), &wg, ds)
 		go handleDefiState(sRate.StateChannel(), &wg, ds)
+		go handleDefiMarketState(sRate.MarketChannel(), &wg, ds)
 
 		defer wg.Wait()
 	}
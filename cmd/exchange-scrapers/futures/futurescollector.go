@@ -0,0 +1,176 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	scrapers "github.com/diadata-org/diadata/internal/pkg/exchange-scrapers"
+	writers "github.com/diadata-org/diadata/internal/pkg/scraper-writers"
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/kafkaHelper"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+)
+
+var log *logrus.Logger
+
+func init() {
+	log = logrus.New()
+}
+
+const (
+	watchdogDelay = 60 * 60
+)
+
+// handleFutures writes trades to kafka and influx, and funding rates and open interest to influx
+// until a signal is received on @stop. If @archive is not nil, trades are archived as well.
+func handleFutures(datastore *models.DB, es scrapers.FuturesScraper, w *kafka.Writer, archive writers.RecordWriter, stop <-chan os.Signal) {
+	lastTradeTime := time.Now()
+	t := time.NewTicker(time.Duration(watchdogDelay) * time.Second)
+	for {
+		select {
+		case sig := <-stop:
+			log.Info("received ", sig, ", shutting down")
+			closeArchive(archive)
+			return
+		case <-t.C:
+			duration := time.Since(lastTradeTime)
+			if duration > time.Duration(watchdogDelay)*time.Second {
+				log.Error(duration)
+				closeArchive(archive)
+				panic("frozen? ")
+			}
+		case trade := <-es.TradesChannel():
+			lastTradeTime = time.Now()
+			if trade.Time.After(lastTradeTime) || trade.Price <= 0 {
+				continue
+			}
+			err := kafkaHelper.WriteMessage(w, trade)
+			if err != nil {
+				log.Error("write futures trade to kafka: ", err)
+			}
+			err = datastore.SaveFuturesTradeInflux(*trade)
+			if err != nil {
+				log.Error("save futures trade: ", err)
+			}
+			if archive != nil {
+				err = archive.Write(trade)
+				if err != nil {
+					log.Error("archive futures trade: ", err)
+				}
+			}
+		case rate := <-es.FundingRatesChannel():
+			err := datastore.SaveFundingRateInflux(*rate)
+			if err != nil {
+				log.Error("save funding rate: ", err)
+			}
+		case openInterest := <-es.OpenInterestChannel():
+			err := datastore.SaveOpenInterestInflux(*openInterest)
+			if err != nil {
+				log.Error("save open interest: ", err)
+			}
+		}
+	}
+}
+
+var (
+	exchange = flag.String("exchange", "", "which exchange")
+	markets  = flag.String("markets", "", "comma separated list of futures markets, e.g. XBTUSD,ETHUSD")
+	archive  = flag.String("archive", "", "archive trades as ndjson or parquet files")
+	// archiveDir is used unless ARCHIVE_S3_BUCKET is set, in which case files are uploaded to
+	// ARCHIVE_S3_ENDPOINT with ARCHIVE_S3_REGION, ARCHIVE_S3_ACCESS_KEY and ARCHIVE_S3_SECRET_KEY.
+	archiveDir = flag.String("archiveDir", "archive", "directory of the archived files")
+)
+
+// newArchive returns the writer for the archived trades of the futures scraper, or nil if disabled.
+func newArchive() (writers.RecordWriter, error) {
+	var format writers.Format
+	switch *archive {
+	case "":
+		return nil, nil
+	case "ndjson":
+		format = writers.NDJSON{Compression: writers.Gzip}
+	case "parquet":
+		format = writers.Parquet{Compression: writers.Gzip}
+	default:
+		return nil, errors.New("unknown archive format " + *archive)
+	}
+	var storage writers.Storage = writers.LocalStorage{Dir: *archiveDir}
+	if bucket := os.Getenv("ARCHIVE_S3_BUCKET"); bucket != "" {
+		s3, err := writers.NewS3Storage(
+			os.Getenv("ARCHIVE_S3_ENDPOINT"),
+			os.Getenv("ARCHIVE_S3_REGION"),
+			bucket,
+			os.Getenv("ARCHIVE_S3_ACCESS_KEY"),
+			os.Getenv("ARCHIVE_S3_SECRET_KEY"),
+		)
+		if err != nil {
+			return nil, err
+		}
+		storage = s3
+	}
+	return writers.NewBatchWriter(writers.BatchWriterConfig{
+		Format:  format,
+		Storage: storage,
+		Prefix:  "futures/" + strings.ToLower(*exchange),
+	})
+}
+
+// closeArchive writes the trades of the current batch of @archive, if any.
+func closeArchive(archive writers.RecordWriter) {
+	if archive == nil {
+		return
+	}
+	if err := archive.Close(); err != nil {
+		log.Error("close archive: ", err)
+	}
+}
+
+func init() {
+	flag.Parse()
+	if *exchange == "" || *markets == "" {
+		flag.Usage()
+		log.Println([]string{dia.BitmexExchange, dia.BitflyerExchange, dia.CoinflexExchange, dia.Deribit, dia.FTX, dia.HuobiExchange})
+		for true {
+			time.Sleep(24 * time.Hour)
+		}
+	}
+}
+
+// main scrapes the futures markets of an exchange and stores trades, funding rates and open interest
+func main() {
+
+	ds, err := models.NewDataStore()
+	if err != nil {
+		log.Fatal("NewDataStore: ", err)
+	}
+
+	configApi, err := dia.GetConfig(*exchange)
+	if err != nil {
+		log.Warning("no config for exchange's api ", err)
+	}
+	es := scrapers.NewFuturesScraper(*exchange, strings.Split(*markets, ","), configApi.ApiKey, configApi.SecretKey)
+	if es == nil {
+		log.Fatal("no futures scraper for exchange ", *exchange)
+	}
+
+	w := kafkaHelper.NewWriter(kafkaHelper.TopicFuturesTrades)
+	defer w.Close()
+
+	archiveWriter, err := newArchive()
+	if err != nil {
+		log.Fatal("archive: ", err)
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+
+	go es.ScrapeMarkets()
+	handleFutures(ds, es, w, archiveWriter, stop)
+}
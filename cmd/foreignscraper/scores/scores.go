@@ -1,5 +1,65 @@
 package main
 
+import (
+	"sync"
+
+	scrapers "github.com/diadata-org/diadata/internal/pkg/foreign-scrapers"
+	models "github.com/diadata-org/diadata/pkg/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// Computes risk scores of the DeFi protocols in config/defi/scores.json once a day. The scores
+// are stored with all their indices and as foreign quotations of source DefiScore.
 func main() {
-    // TO DO
-}
\ No newline at end of file
+
+	wg := sync.WaitGroup{}
+
+	ds, err := models.NewDataStore()
+	if err != nil {
+		log.Fatal("datastore error: ", err)
+	}
+
+	sc := scrapers.NewDefiScoreScraper(ds)
+
+	wg.Add(2)
+	go handleQuotations(sc.GetQuoteChannel(), &wg, ds)
+	go handleScores(sc.GetScoreChannel(), &wg, ds)
+	defer wg.Wait()
+
+}
+
+func handleQuotations(quotation chan *models.ForeignQuotation, wg *sync.WaitGroup, ds models.Datastore) {
+	defer wg.Done()
+
+	for {
+		fq, ok := <-quotation
+		if !ok {
+			log.Error("error")
+			return
+		}
+
+		err := ds.SaveForeignQuotationInflux(*fq)
+		if err != nil {
+			log.Errorf("error saving score quotation of %s: %v", fq.Symbol, err)
+		}
+	}
+
+}
+
+func handleScores(scores chan *models.DefiScore, wg *sync.WaitGroup, ds models.Datastore) {
+	defer wg.Done()
+
+	for {
+		score, ok := <-scores
+		if !ok {
+			log.Error("error")
+			return
+		}
+
+		err := ds.SaveDefiScoreInflux(*score)
+		if err != nil {
+			log.Errorf("error saving score of %s: %v", score.Protocol, err)
+		}
+	}
+
+}
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"os"
+	"strconv"
 	"time"
 
 	jwt "github.com/appleboy/gin-jwt/v2"
@@ -9,8 +11,11 @@ import (
 	_ "github.com/diadata-org/diadata/api/docs"
 	"github.com/diadata-org/diadata/pkg/dia"
 	"github.com/diadata-org/diadata/pkg/dia/helpers/kafkaHelper"
+	"github.com/diadata-org/diadata/pkg/http/restServer/apiKeyApi"
 	"github.com/diadata-org/diadata/pkg/http/restServer/diaApi"
+	"github.com/diadata-org/diadata/pkg/http/restServer/graphqlApi"
 	"github.com/diadata-org/diadata/pkg/http/restServer/kafkaApi"
+	"github.com/diadata-org/diadata/pkg/http/restServer/streamApi"
 	models "github.com/diadata-org/diadata/pkg/model"
 	"github.com/gin-contrib/cache"
 	"github.com/gin-contrib/cache/persistence"
@@ -83,6 +88,20 @@ func helloHandler(c *gin.Context) {
 	})
 }
 
+// anonymousRateLimit returns the requests per minute and client IP allowed without API key, as set
+// in the environment variable ANONYMOUS_RATE_LIMIT. It is 0, i.e. unlimited, if not set.
+func anonymousRateLimit() int64 {
+	limit := os.Getenv("ANONYMOUS_RATE_LIMIT")
+	if limit == "" {
+		return 0
+	}
+	requestsPerMinute, err := strconv.ParseInt(limit, 10, 64)
+	if err != nil || requestsPerMinute < 0 {
+		log.Fatalf("invalid ANONYMOUS_RATE_LIMIT %s", limit)
+	}
+	return requestsPerMinute
+}
+
 func main() {
 
 	r := gin.New()
@@ -202,8 +221,41 @@ func main() {
 		diaAuth.POST("/indexRebalance/:symbol", diaApiEnv.PostIndexRebalance)
 	}
 
+	// Administration of API keys
+	apiKeyEnv := &apiKeyApi.Env{
+		RelDB: relStore,
+	}
+	admin := r.Group("/admin")
+	admin.Use(authMiddleware.MiddlewareFunc())
+	{
+		admin.POST("/apikeys", apiKeyEnv.PostAPIKey)
+		admin.GET("/apikeys", apiKeyEnv.GetAPIKeys)
+		admin.DELETE("/apikeys/:id", apiKeyEnv.DeleteAPIKey)
+		admin.GET("/apikeys/:id/usage", apiKeyEnv.GetAPIKeyUsage)
+	}
+
+	// Streaming of live trades, filters, quotations and index levels
+	streamHub := streamApi.NewHub(0, 0)
+	streamHub.Run(context.Background(), store)
+	stream := r.Group("/v1/stream")
+	stream.Use(authMiddleware.MiddlewareFunc())
+	{
+		stream.GET("/ws", streamHub.ServeWebSocket)
+		stream.GET("/sse", streamHub.ServeSSE)
+	}
+
+	graphqlHandler, err := graphqlApi.NewHandler(store, relStore, graphqlApi.DefaultMaxCost)
+	if err != nil {
+		log.Fatal("graphql schema: ", err)
+	}
+
 	dia := r.Group("/v1")
+	dia.Use(apiKeyApi.NewRateLimiter(relStore, anonymousRateLimit()).Handle)
 	{
+		// GraphQL queries over all resources
+		dia.GET("/graphql", graphqlHandler.Serve)
+		dia.POST("/graphql", graphqlHandler.Serve)
+
 		// Endpoints for cryptocurrencies/exchanges
 		dia.GET("/quotation/:symbol", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetQuotation))
 		dia.GET("/lastTrades/:symbol", diaApiEnv.GetLastTrades)
@@ -221,11 +273,14 @@ func main() {
 		dia.GET("/defiLendingProtocols", cache.CachePage(memoryStore, cachingTimeLong, diaApiEnv.GetLendingProtocols))
 		dia.GET("/chartPoints/:filter/:exchange/:symbol", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetChartPoints))
 		dia.GET("/chartPointsAllExchanges/:filter/:symbol", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetChartPointsAllExchanges))
+		dia.GET("/candles/:symbol", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetCandles))
 		dia.GET("/cviIndex", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetCviIndex))
 		dia.GET("/defiLendingRate/:protocol/:asset", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetDefiRate))
 		dia.GET("/defiLendingRate/:protocol/:asset/:time", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetDefiRate))
 		dia.GET("/defiLendingState/:protocol", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetDefiState))
 		dia.GET("/defiLendingState/:protocol/:time", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetDefiState))
+		dia.GET("/defiLendingMarket/:protocol/:asset", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetDefiMarketState))
+		dia.GET("/defiLendingMarket/:protocol/:asset/:time", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetDefiMarketState))
 
 		dia.GET("/FarmingPools", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetFarmingPools))
 		dia.GET("/FarmingPoolData/:protocol/:poolID", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetFarmingPoolData))
@@ -233,16 +288,30 @@ func main() {
 
 		dia.GET("CryptoDerivatives/:type/:name", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetCryptoDerivative))
 
+		// Endpoints for futures
+		dia.GET("/futures/fundingRates/:exchange/:market", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetFundingRates))
+		dia.GET("/futures/basis/:exchange/:market", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetFuturesBasis))
+
+		// Endpoints for option analytics
+		dia.GET("/options/greeks/:underlying", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetOptionGreeks))
+		dia.GET("/options/greeks/:underlying/:expiry", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetOptionGreeks))
+		dia.GET("/options/expiries/:underlying", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetOptionExpirySummaries))
+		dia.GET("/options/expiries/:underlying/:expiry", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetOptionExpirySummaries))
+		dia.GET("/options/onchain", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetLiveOnchainOptions))
+		dia.GET("/options/onchain/exercises/:protocol/:instrument", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetOptionExercises))
+
 		// Endpoints for interestrates
 		dia.GET("/interestrates", cache.CachePage(memoryStore, cachingTimeLong, diaApiEnv.GetRates))
 		dia.GET("/interestrate/:symbol", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetInterestRate))
 		dia.GET("/interestrate/:symbol/:time", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetInterestRate))
+		dia.GET("/interestrateReport/:symbol", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetInterestRateReport))
 		dia.GET("/compoundedRate/:symbol/:dpy", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetCompoundedRate))
 		dia.GET("/compoundedRate/:symbol/:dpy/:time", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetCompoundedRate))
 		dia.GET("/compoundedAvg/:symbol/:days/:dpy", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetCompoundedAvg))
 		dia.GET("/compoundedAvg/:symbol/:days/:dpy/:time", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetCompoundedAvg))
 		dia.GET("/compoundedAvgDIA/:symbol/:days/:dpy", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetCompoundedAvgDIA))
 		dia.GET("/compoundedAvgDIA/:symbol/:days/:dpy/:time", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetCompoundedAvgDIA))
+		dia.GET("/rateCurve/:symbol/:date", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetRateCurve))
 
 		// Endpoints for fiat currencies
 		dia.GET("/fiatQuotations", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetFiatQuotations))
@@ -256,6 +325,7 @@ func main() {
 		dia.GET("/foreignQuotation/:source/:symbol", cache.CachePage(memoryStore, cachingTimeLong, diaApiEnv.GetForeignQuotation))
 		dia.GET("/foreignQuotation/:source/:symbol/:time", cache.CachePage(memoryStore, cachingTimeLong, diaApiEnv.GetForeignQuotation))
 		dia.GET("/foreignSymbols/:source", cache.CachePage(memoryStore, cachingTimeLong, diaApiEnv.GetForeignSymbols))
+		dia.GET("/defiScore/:protocol", cache.CachePage(memoryStore, cachingTimeLong, diaApiEnv.GetDefiScore))
 
 		// Gold asset
 		dia.GET("/goldPaxgOunces", cache.CachePage(memoryStore, cachingTimeLong, diaApiEnv.GetPaxgQuotationOunces))
@@ -276,6 +346,13 @@ func main() {
 		dia.GET("/NFT/:blockchain/:address/:id", cache.CachePage(memoryStore, cachingTimeLong, diaApiEnv.GetNFT))
 		dia.GET("/NFTTrades/:blockchain/:address/:id", cache.CachePage(memoryStore, cachingTimeLong, diaApiEnv.GetNFTTrades))
 		dia.GET("/NFTPrice30Days/:blockchain/:address", cache.CachePage(memoryStore, cachingTimeLong, diaApiEnv.GetNFTPrice30Days))
+		dia.GET("/NFTMetadata/:blockchain/:address/:id", cache.CachePage(memoryStore, cachingTimeLong, diaApiEnv.GetNFTMetadata))
+		dia.GET("/NFTTraits/:blockchain/:address", cache.CachePage(memoryStore, cachingTimeLong, diaApiEnv.GetNFTTraits))
+		dia.GET("/NFTOrderbook/:blockchain/:address", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetNFTOrderbook))
+		dia.GET("/NFTBidAsk/:blockchain/:address", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetNFTBidAsk))
+		dia.GET("/NFTBidAsk/:blockchain/:address/:id", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetNFTBidAsk))
+		dia.GET("/NFTSpread/:blockchain/:address", cache.CachePage(memoryStore, cachingTimeLong, diaApiEnv.GetNFTSpread))
+		dia.GET("/NFTValuation/:blockchain/:address/:id", cache.CachePage(memoryStore, cachingTimeLong, diaApiEnv.GetNFTValuation))
 	}
 
 	r.Use(static.Serve("/v1/chart", static.LocalFile("/charts", true)))
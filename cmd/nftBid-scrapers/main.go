@@ -37,8 +37,9 @@ func main() {
 		}
 	}
 
-	wg.Add(1)
+	wg.Add(2)
 	go handleBids(scraper.GetBidChannel(), &wg, rdb)
+	go handleCloses(scraper.GetCloseChannel(), &wg, rdb)
 	defer wg.Wait()
 
 }
@@ -69,3 +70,25 @@ func handleBids(bidChannel chan dia.NFTBid, wg *sync.WaitGroup, rdb *models.RelD
 		}
 	}
 }
+
+func handleCloses(closeChannel chan dia.NFTOrderClose, wg *sync.WaitGroup, rdb *models.RelDB) {
+	defer wg.Done()
+	for {
+		orderClose, ok := <-closeChannel
+		if !ok {
+			log.Error("error")
+			return
+		}
+		err := rdb.SetNFTOrderClose(orderClose)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				log.Infof("%s close with tx hash %s already in db. continue.", orderClose.Side, orderClose.TxHash)
+				continue
+			}
+			log.Errorf("Error saving %s close with tx hash %s: %v", orderClose.Side, orderClose.TxHash, err)
+		} else {
+			log.Infof("successfully set %s close with tx hash %s", orderClose.Side, orderClose.TxHash)
+		}
+	}
+}
@@ -24,6 +24,9 @@ func main() {
 	}
 
 	scraperType := flag.String("nftclass", "Sorare", "which NFT class")
+	address := flag.String("address", "", "contract address of the NFT class for -nftclass=ERC721")
+	blockchain := flag.String("blockchain", dia.ETHEREUM, "blockchain of the NFT class for -nftclass=ERC721")
+	startBlock := flag.Uint64("startBlock", 0, "first block searched for mints for -nftclass=ERC721, looked up as the deployment block if zero")
 	flag.Parse()
 	var scraper nftdatascrapers.NFTDataScraper
 
@@ -40,6 +43,13 @@ func main() {
 	case "CryptoKitties":
 		log.Println("NFT Data Scraper: Start scraping data from CryptoKitties")
 		scraper = nftdatascrapers.NewCryptoKittiesScraper(rdb)
+	case "ERC721":
+		log.Printf("NFT Data Scraper: Start scraping data from ERC721 contract %s on %s", *address, *blockchain)
+		erc721Scraper := nftdatascrapers.NewGenericERC721Scraper(rdb, *address, *blockchain, *startBlock)
+		if erc721Scraper == nil {
+			log.Fatal("unable to start ERC721 scraper")
+		}
+		scraper = erc721Scraper
 	default:
 		for {
 			time.Sleep(24 * time.Hour)
@@ -40,8 +40,9 @@ func main() {
 		}
 	}
 
-	wg.Add(1)
+	wg.Add(2)
 	go handleOffers(scraper.GetOfferChannel(), &wg, rdb)
+	go handleCloses(scraper.GetCloseChannel(), &wg, rdb)
 	defer wg.Wait()
 
 }
@@ -72,3 +73,25 @@ func handleOffers(offerChannel chan dia.NFTOffer, wg *sync.WaitGroup, rdb *model
 		}
 	}
 }
+
+func handleCloses(closeChannel chan dia.NFTOrderClose, wg *sync.WaitGroup, rdb *models.RelDB) {
+	defer wg.Done()
+	for {
+		orderClose, ok := <-closeChannel
+		if !ok {
+			log.Error("error")
+			return
+		}
+		err := rdb.SetNFTOrderClose(orderClose)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				log.Infof("%s close with tx hash %s already in db. continue.", orderClose.Side, orderClose.TxHash)
+				continue
+			}
+			log.Errorf("Error saving %s close with tx hash %s: %v", orderClose.Side, orderClose.TxHash, err)
+		} else {
+			log.Infof("successfully set %s close with tx hash %s", orderClose.Side, orderClose.TxHash)
+		}
+	}
+}
@@ -22,7 +22,7 @@ func main() {
 			panic(err)
 		}
 
-		scraper := nfttradescrapers.NewOpenSeaScraper(rdb)
+		scraper := nfttradescrapers.NewOpenSeaScraper(rdb, dia.ETHEREUM)
 		go func() { time.Sleep(3 * time.Minute); scraper.Close() }()
 
 		wg := sync.WaitGroup{}
@@ -41,6 +41,7 @@ func main() {
 	}
 
 	scraperType := flag.String("nftclass", "Cryptopunk", "which NFT class")
+	blockchain := flag.String("blockchain", dia.ETHEREUM, "blockchain of the marketplace, one of Ethereum, Polygon and BinanceSmartChain. OpenSea's exchange only runs on Ethereum, on the other chains a Wyvern compatible marketplace contract has to be set in the scraper config")
 	flag.Parse()
 	var scraper nfttradescrapers.NFTTradeScraper
 
@@ -55,8 +56,12 @@ func main() {
 		log.Println("NFT Data Scraper: Start scraping trades from NBA Topshot")
 		scraper = nfttradescrapers.NewNBATopshotScraper(rdb)
 	case "Opensea":
-		log.Println("NFT Data Scraper: Start scraping trades from Opensea")
-		scraper = nfttradescrapers.NewOpenSeaScraper(rdb)
+		log.Printf("NFT Data Scraper: Start scraping trades from Opensea on %s", *blockchain)
+		openSeaScraper := nfttradescrapers.NewOpenSeaScraper(rdb, *blockchain)
+		if openSeaScraper == nil {
+			log.Fatalf("NFT Data Scraper: Opensea scraper on %s could not be started", *blockchain)
+		}
+		scraper = openSeaScraper
 	default:
 		for {
 			time.Sleep(24 * time.Hour)
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+
+	candles "github.com/diadata-org/diadata/internal/pkg/candlesService"
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/kafkaHelper"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/segmentio/kafka-go"
+	log "github.com/sirupsen/logrus"
+)
+
+// The candles service aggregates the trades of all trades blocks into OHLCV candles.
+func main() {
+	s, err := models.NewInfluxDataStore()
+	if err != nil {
+		log.Fatal("NewInfluxDataStore: ", err)
+	}
+	c := candles.NewCandlesService(s)
+
+	r := kafkaHelper.NewGroupReader(kafkaHelper.TopicTradesBlock, "candlesService")
+	defer r.Close()
+
+	err = kafkaHelper.ConsumeMessages(context.Background(), r, func(m kafka.Message) error {
+		var tb dia.TradesBlock
+		err := tb.UnmarshalBinary(m.Value)
+		if err != nil {
+			return err
+		}
+		c.ProcessTradesBlock(&tb)
+		return nil
+	})
+	if err != nil {
+		log.Error(err)
+	}
+}
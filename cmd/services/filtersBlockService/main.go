@@ -139,24 +139,22 @@ func main() {
 
 		go handler(channel, &wg, w)
 
-		r := kafkaHelper.NewReaderNextMessage(kafkaHelper.TopicTradesBlock)
+		r := kafkaHelper.NewGroupReader(kafkaHelper.TopicTradesBlock, "filtersBlockService")
 		defer r.Close()
 
-		for {
-			m, err := r.ReadMessage(context.Background())
+		err = kafkaHelper.ConsumeMessages(context.Background(), r, func(m kafka.Message) error {
+			log.Info("get block from tradesBlock")
+			var tb dia.TradesBlock
+			err := tb.UnmarshalBinary(m.Value)
 			if err != nil {
-				log.Printf(err.Error())
-			} else {
-				log.Info("get block from tradesBlock")
-				var tb dia.TradesBlock
-				err := tb.UnmarshalBinary(m.Value)
-				if err != nil {
-					log.Error("error unmarshalling trades block")
-				}
-				if err == nil {
-					f.ProcessTradesBlock(&tb)
-				}
+				log.Error("error unmarshalling trades block")
+				return err
 			}
+			f.ProcessTradesBlock(&tb)
+			return nil
+		})
+		if err != nil {
+			log.Error(err)
 		}
 	}
 }
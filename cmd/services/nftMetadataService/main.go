@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"strings"
+	"time"
+
+	nftmetadata "github.com/diadata-org/diadata/internal/pkg/nftMetadataService"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/nfthelper"
+	models "github.com/diadata-org/diadata/pkg/model"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	ipfsGateways    = flag.String("ipfsGateways", "", "comma separated list of IPFS gateways, public gateways if empty")
+	arweaveGateways = flag.String("arweaveGateways", "", "comma separated list of Arweave gateways, public gateways if empty")
+	refreshPeriod   = flag.Duration("refresh", 24*time.Hour, "age after which the metadata of an NFT is fetched again")
+	batchSize       = flag.Int("batch", 500, "maximal number of NFTs refreshed per run")
+	frequency       = flag.Duration("frequency", 10*time.Minute, "pause between runs")
+)
+
+// main periodically refreshes the metadata of NFTs and the trait rarities of their classes.
+func main() {
+	flag.Parse()
+	rdb, err := models.NewRelDataStore()
+	if err != nil {
+		log.Fatal("NewRelDataStore: ", err)
+	}
+	resolver := nfthelper.NewURIResolver(splitList(*ipfsGateways), splitList(*arweaveGateways))
+	service := nftmetadata.NewService(rdb, resolver, nftmetadata.Config{
+		RefreshPeriod: *refreshPeriod,
+		BatchSize:     *batchSize,
+	})
+	for {
+		n, err := service.Refresh(context.Background())
+		if err != nil {
+			log.Error("refresh nft metadata: ", err)
+		} else {
+			log.Infof("refreshed metadata of %d nfts", n)
+		}
+		// keep going without pause while there is a backlog
+		if n < *batchSize {
+			time.Sleep(*frequency)
+		}
+	}
+}
+
+func splitList(list string) (items []string) {
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"strings"
+	"time"
+
+	nftvaluation "github.com/diadata-org/diadata/internal/pkg/nftValuationService"
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	blockchains   = flag.String("blockchains", dia.ETHEREUM, "comma separated list of blockchains whose nfts are appraised")
+	lookback      = flag.Duration("lookback", nftvaluation.DefaultConfig.Lookback, "age of the oldest sales taken into account")
+	halfLife      = flag.Duration("halfLife", nftvaluation.DefaultConfig.HalfLife, "age at which the weight of a sale is halved")
+	floorWindow   = flag.Duration("floorWindow", nftvaluation.DefaultConfig.FloorWindow, "period whose sales determine the floor price")
+	floorQuantile = flag.Float64("floorQuantile", nftvaluation.DefaultConfig.FloorQuantile, "quantile of the unit prices taken as floor price")
+	floorWeight   = flag.Float64("floorWeight", nftvaluation.DefaultConfig.FloorWeight, "weight of the floor price relative to a current sale of the same nft")
+	confidence    = flag.Float64("confidence", nftvaluation.DefaultConfig.Confidence, "confidence level of the valuation bounds")
+	frequency     = flag.Duration("frequency", 24*time.Hour, "pause between valuations")
+)
+
+// main periodically appraises all nfts and stores the valuations.
+func main() {
+	flag.Parse()
+	if *floorWeight <= 0 || *confidence <= 0 || *confidence >= 1 {
+		log.Fatal("floorWeight must be positive and confidence between 0 and 1")
+	}
+	rdb, err := models.NewRelDataStore()
+	if err != nil {
+		log.Fatal("NewRelDataStore: ", err)
+	}
+	service := nftvaluation.NewService(rdb, nftvaluation.Config{
+		Lookback:      *lookback,
+		HalfLife:      *halfLife,
+		FloorWindow:   *floorWindow,
+		FloorQuantile: *floorQuantile,
+		FloorWeight:   *floorWeight,
+		Confidence:    *confidence,
+	})
+	for {
+		for _, blockchain := range strings.Split(*blockchains, ",") {
+			blockchain = strings.TrimSpace(blockchain)
+			n, err := service.ValueAll(blockchain, time.Now())
+			if err != nil {
+				log.Errorf("value nfts on %s: %v", blockchain, err)
+				continue
+			}
+			log.Infof("stored %d nft valuations on %s", n, blockchain)
+		}
+		time.Sleep(*frequency)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"strings"
+	"time"
+
+	optionanalytics "github.com/diadata-org/diadata/internal/pkg/optionAnalyticsService"
+	models "github.com/diadata-org/diadata/pkg/model"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// Put/call ratios and max pain need open interest, which the ETH option scrapers provide.
+	underlyings = flag.String("underlyings", "ETH", "comma separated list of underlyings")
+	rate        = flag.Float64("rate", 0, "continuously compounded risk free rate")
+	frequency   = flag.Duration("frequency", 5*time.Minute, "update frequency")
+)
+
+// main periodically computes the greeks, put/call ratios and max pain of the options on all underlyings.
+func main() {
+	flag.Parse()
+	ds, err := models.NewDataStore()
+	if err != nil {
+		log.Fatal("NewDataStore: ", err)
+	}
+	config := optionanalytics.Config{
+		Rate: *rate,
+	}
+	for {
+		for _, underlying := range strings.Split(*underlyings, ",") {
+			err = optionanalytics.Update(ds, underlying, config)
+			if err != nil {
+				log.Error("update option analytics of ", underlying, ": ", err)
+			}
+		}
+		time.Sleep(*frequency)
+	}
+}
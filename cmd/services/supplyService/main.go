@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"strings"
 	"time"
 
@@ -16,6 +17,14 @@ const (
 	lockedWalletsFilename = "wallets"
 )
 
+var (
+	backfill = flag.Bool("backfill", false, "reconstruct historical daily supplies from Transfer events and exit")
+)
+
+func init() {
+	flag.Parse()
+}
+
 func main() {
 
 	ds, err := models.NewDataStore()
@@ -37,6 +46,12 @@ func main() {
 	if err != nil {
 		log.Error(err)
 	}
+
+	if *backfill {
+		backfillSupplies(tokenAddresses, lockedWalletsMap, ds, conn)
+		return
+	}
+
 	// Initial run
 	err = setSupplies(tokenAddresses, lockedWalletsMap, ds, conn)
 	if err != nil {
@@ -123,3 +138,22 @@ func setSupplies(tokenAddresses []string, lockedWalletsMap map[string][]string,
 	}
 	return nil
 }
+
+// backfillSupplies replays the Transfer events of all tokens in @tokenAddresses
+// and writes the reconstructed daily supplies to influx.
+func backfillSupplies(tokenAddresses []string, lockedWalletsMap map[string][]string, ds models.Datastore, conn *ethclient.Client) {
+	for _, address := range tokenAddresses {
+		supplies, err := supplyservice.GetHistoricalSupplies(address, lockedWalletsMap[address], conn)
+		if err != nil {
+			log.Errorf("error reconstructing supplies for %s: %v", address, err)
+			continue
+		}
+		for i := range supplies {
+			err = ds.SaveSupplyInflux(&supplies[i])
+			if err != nil {
+				log.Errorf("error saving historical supply for %s: %v", supplies[i].Symbol, err)
+			}
+		}
+		log.Infof("backfilled %d daily supplies for %s", len(supplies), address)
+	}
+}
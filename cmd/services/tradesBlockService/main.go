@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"github.com/diadata-org/diadata/internal/pkg/tradesBlockService"
 	"github.com/diadata-org/diadata/pkg/dia"
 	"github.com/diadata-org/diadata/pkg/dia/helpers/kafkaHelper"
@@ -27,11 +28,16 @@ func handleBlocks(blockMaker *tradesBlockService.TradesBlockService, wg *sync.Wa
 }
 
 func main() {
+	gracePeriod := flag.Duration("grace", tradesBlockService.DefaultGracePeriod, "time by which trades may lag behind the latest trade and still enter their block")
+	flag.Parse()
 
 	w := kafkaHelper.NewSyncWriter(kafkaHelper.TopicTradesBlock)
 	defer w.Close()
 
-	r := kafkaHelper.NewReaderNextMessage(kafkaHelper.TopicTrades)
+	// Blocks span the trades of all symbols, so a single instance has to consume all partitions of
+	// the trades topic. The consumer group only keeps its offsets across restarts. As partitions are
+	// read concurrently, blocks are kept open for a grace period, see TradesBlockService.GracePeriod.
+	r := kafkaHelper.NewGroupReader(kafkaHelper.TopicTrades, "tradesBlockService")
 	defer r.Close()
 
 	s, err := models.NewDataStore()
@@ -40,24 +46,24 @@ func main() {
 	}
 
 	tradesBlockService := tradesBlockService.NewTradesBlockService(s, dia.BlockSizeSeconds)
+	tradesBlockService.GracePeriod = *gracePeriod
 
 	wg := sync.WaitGroup{}
 	go handleBlocks(tradesBlockService, &wg, w)
 
 	log.Printf("starting...")
 
-	for {
-		m, err := r.ReadMessage(context.Background())
+	err = kafkaHelper.ConsumeMessages(context.Background(), r, func(m kafka.Message) error {
+		var t dia.Trade
+		err := t.UnmarshalBinary(m.Value)
 		if err != nil {
-			log.Printf(err.Error())
-		} else {
-			var t dia.Trade
-			err := t.UnmarshalBinary(m.Value)
-			if err == nil {
-				tradesBlockService.ProcessTrade(&t)
-			} else {
-				log.Printf("ignored message at offset %d: %s = %s\n", m.Offset, string(m.Key), string(m.Value))
-			}
+			log.Printf("ignored message at offset %d: %s = %s\n", m.Offset, string(m.Key), string(m.Value))
+			return err
 		}
+		tradesBlockService.ProcessTrade(&t)
+		return nil
+	})
+	if err != nil {
+		log.Error(err)
 	}
 }
@@ -91,10 +91,52 @@ CREATE TABLE nft (
     UNIQUE(nft_id)
 );
 
+-- nftmetadata keeps the refreshed on-chain and off-chain attributes of an nft apart
+CREATE TABLE nftmetadata (
+    nft_id uuid REFERENCES nft(nft_id),
+    token_uri text,
+    onchain_attributes jsonb,
+    onchain_fetch_time timestamp,
+    offchain_attributes jsonb,
+    offchain_fetch_time timestamp,
+    resolved_url text,
+    fetch_error text,
+    rarity_score numeric,
+    rarity_rank integer,
+    UNIQUE(nft_id)
+);
+
+-- nfttrait holds the rarity of each trait value within an nft class
+CREATE TABLE nfttrait (
+    nftclass_id uuid REFERENCES nftclass(nftclass_id),
+    trait_type text not null,
+    trait_value text not null,
+    token_count integer,
+    rarity_score numeric,
+    update_time timestamp,
+    UNIQUE(nftclass_id, trait_type, trait_value)
+);
+
+-- nftvaluation holds the history of estimated fair values of an nft in USD
+CREATE TABLE nftvaluation (
+    valuation_id UUID DEFAULT gen_random_uuid(),
+    nft_id uuid REFERENCES nft(nft_id),
+    value_usd numeric,
+    lower_bound numeric,
+    upper_bound numeric,
+    confidence numeric,
+    floor_price numeric,
+    comparables integer,
+    valuation_time timestamp,
+    UNIQUE(valuation_id),
+    UNIQUE(nft_id, valuation_time)
+);
+
 CREATE TABLE nfttrade (
     sale_id UUID DEFAULT gen_random_uuid(),
     nftclass_id uuid REFERENCES nftclass(nftclass_id),
     nft_id uuid REFERENCES nft(nft_id),
+    quantity numeric DEFAULT 1,
     price text,
     price_usd numeric,
     transfer_from text,
@@ -147,6 +189,21 @@ CREATE TABLE nftoffer (
     UNIQUE(nft_id, from_address, offer_time)
 );
 
+CREATE TABLE nftorderclose (
+    close_id UUID DEFAULT gen_random_uuid(),
+    nft_id uuid REFERENCES nft(nft_id),
+    side text,
+    reason text,
+    from_address text,
+    blocknumber numeric,
+    blockposition numeric,
+    close_time timestamp,
+    tx_hash text,
+    marketplace text,
+    UNIQUE(close_id),
+    UNIQUE(nft_id, side, blocknumber, blockposition)
+);
+
 CREATE TABLE IF NOT EXISTS scrapers (
     name character varying(255) NOT NULL,
 	conf json,
@@ -162,3 +219,15 @@ CREATE TABLE blockdata (
     UNIQUE(blockchain, block_number),
     UNIQUE(blockdata_id)
 );
+
+CREATE TABLE apikey (
+    apikey_id UUID DEFAULT gen_random_uuid(),
+    key_hash text not null,
+    key_prefix text not null,
+    owner text not null,
+    tier text not null,
+    created_at timestamp not null default now(),
+    revoked_at timestamp,
+    UNIQUE(key_hash),
+    UNIQUE(apikey_id)
+);
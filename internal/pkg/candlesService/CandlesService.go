@@ -0,0 +1,109 @@
+package candles
+
+import (
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+	log "github.com/sirupsen/logrus"
+)
+
+type candleKey struct {
+	symbol     string
+	exchange   string
+	resolution string
+	start      int64
+}
+
+// CandlesService aggregates the trades of trades blocks into candles of all resolutions in
+// models.CandleResolutions, per exchange and over all exchanges.
+type CandlesService struct {
+	datastore models.Datastore
+	candles   map[candleKey]*models.Candle
+	// watermarks hold per resolution the time before which candles may have been stored before,
+	// either by a previous run of the service or before they were evicted from memory. Such
+	// candles are loaded from the datastore before trades are added.
+	watermarks map[string]time.Time
+}
+
+// NewCandlesService returns a service storing candles in @datastore.
+func NewCandlesService(datastore models.Datastore) *CandlesService {
+	return &CandlesService{
+		datastore:  datastore,
+		candles:    make(map[candleKey]*models.Candle),
+		watermarks: make(map[string]time.Time),
+	}
+}
+
+// ProcessTradesBlock adds the trades of @tradesBlock to their candles and stores all updated candles.
+// Candles which ended before the end of the block are removed from memory afterwards.
+func (s *CandlesService) ProcessTradesBlock(tradesBlock *dia.TradesBlock) {
+	if len(s.watermarks) == 0 {
+		for resolution := range models.CandleResolutions {
+			s.watermarks[resolution] = tradesBlock.TradesBlockData.BeginTime
+		}
+	}
+
+	updated := make(map[candleKey]*models.Candle)
+	for _, trade := range tradesBlock.TradesBlockData.Trades {
+		if trade.EstimatedUSDPrice <= 0 {
+			continue
+		}
+		for resolution, length := range models.CandleResolutions {
+			start := trade.Time.Truncate(length)
+			for _, exchange := range []string{trade.Source, ""} {
+				key := candleKey{symbol: trade.Symbol, exchange: exchange, resolution: resolution, start: start.Unix()}
+				candle := s.getCandle(key, start)
+				candle.Add(trade.EstimatedUSDPrice, trade.Volume, trade.Time)
+				updated[key] = candle
+			}
+		}
+	}
+
+	for _, candle := range updated {
+		err := s.datastore.SaveCandleInflux(*candle)
+		if err != nil {
+			log.Errorln("CandlesService: error saving candle:", err)
+		}
+	}
+	s.datastore.Flush()
+	s.evict(tradesBlock.TradesBlockData.EndTime)
+	log.Infof("CandlesService: updated %d candles, %d in memory", len(updated), len(s.candles))
+}
+
+// getCandle returns the candle of @key from memory, the datastore or a new one.
+func (s *CandlesService) getCandle(key candleKey, start time.Time) *models.Candle {
+	if candle, ok := s.candles[key]; ok {
+		return candle
+	}
+	candle := &models.Candle{
+		Symbol:     key.symbol,
+		Exchange:   key.exchange,
+		Resolution: key.resolution,
+		Time:       start,
+	}
+	if start.Before(s.watermarks[key.resolution]) {
+		stored, err := s.datastore.GetCandles(key.symbol, key.exchange, key.resolution, start, start, 1, 0)
+		if err != nil {
+			log.Errorf("CandlesService: error loading candle %v: %v", key, err)
+		} else if len(stored) > 0 {
+			candle = &stored[0]
+		}
+	}
+	s.candles[key] = candle
+	return candle
+}
+
+// evict removes candles which ended before @endtime from memory.
+func (s *CandlesService) evict(endtime time.Time) {
+	for key, candle := range s.candles {
+		end := candle.Time.Add(models.CandleResolutions[key.resolution])
+		if end.After(endtime) {
+			continue
+		}
+		delete(s.candles, key)
+		if end.After(s.watermarks[key.resolution]) {
+			s.watermarks[key.resolution] = end
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package candles
+
+import (
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+)
+
+type candleStore struct {
+	models.Datastore
+	saved map[string]models.Candle
+}
+
+func (s *candleStore) SaveCandleInflux(candle models.Candle) error {
+	s.saved[candle.Exchange+candle.Resolution+candle.Time.String()] = candle
+	return nil
+}
+
+func (s *candleStore) GetCandles(symbol string, exchange string, resolution string, starttime time.Time, endtime time.Time, limit int, offset int) ([]models.Candle, error) {
+	if candle, ok := s.saved[exchange+resolution+starttime.String()]; ok {
+		return []models.Candle{candle}, nil
+	}
+	return nil, nil
+}
+
+func (s *candleStore) Flush() error {
+	return nil
+}
+
+func TestProcessTradesBlock(t *testing.T) {
+	start := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	trade := func(seconds int, price float64, volume float64, exchange string) dia.Trade {
+		return dia.Trade{Symbol: "BTC", Source: exchange, EstimatedUSDPrice: price, Volume: volume, Time: start.Add(time.Duration(seconds) * time.Second)}
+	}
+	block := func(begin int, trades ...dia.Trade) *dia.TradesBlock {
+		return &dia.TradesBlock{TradesBlockData: dia.TradesBlockData{
+			BeginTime: start.Add(time.Duration(begin) * time.Second),
+			EndTime:   start.Add(time.Duration(begin+dia.BlockSizeSeconds) * time.Second),
+			Trades:    trades,
+		}}
+	}
+
+	store := &candleStore{saved: make(map[string]models.Candle)}
+	s := NewCandlesService(store)
+	s.ProcessTradesBlock(block(0,
+		trade(10, 100, 1, "Binance"),
+		trade(5, 90, -2, "Kraken"),
+		trade(50, 120, 0.5, "Binance"),
+		trade(70, 110, 1, "Binance"),
+		trade(80, 0, 1, "Binance"),
+	))
+
+	// Trades are not sorted by time within blocks, and trades without USD price are ignored.
+	minute := store.saved["1m"+start.String()]
+	if minute.Open != 90 || minute.High != 120 || minute.Low != 90 || minute.Close != 120 ||
+		minute.Volume != 3.5 || minute.VolumeUSD != 180+100+60 || minute.Trades != 3 {
+		t.Errorf("unexpected candle %+v", minute)
+	}
+	if binance := store.saved["Binance1h"+start.String()]; binance.Trades != 3 || binance.Open != 100 || binance.Close != 110 {
+		t.Errorf("unexpected exchange candle %+v", binance)
+	}
+
+	// A restarted service continues stored candles.
+	s = NewCandlesService(store)
+	s.ProcessTradesBlock(block(120, trade(130, 130, 1, "Binance")))
+	if hour := store.saved["1h"+start.String()]; hour.Trades != 5 || hour.High != 130 || hour.Close != 130 || hour.Open != 90 {
+		t.Errorf("unexpected continued candle %+v", hour)
+	}
+	// The minute candles ended with the block.
+	if len(s.candles) != 10 {
+		t.Errorf("expected 10 candles in memory, got %d", len(s.candles))
+	}
+
+	// Closed candles are evicted and reloaded for late trades.
+	s.ProcessTradesBlock(block(240, trade(250, 140, 1, "Kraken"), trade(125, 80, 1, "Kraken")))
+	if minute := store.saved["1m"+start.Add(2*time.Minute).String()]; minute.Trades != 2 || minute.Low != 80 {
+		t.Errorf("unexpected late candle %+v", minute)
+	}
+}
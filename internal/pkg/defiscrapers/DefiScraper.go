@@ -6,6 +6,7 @@ import (
 
 	"github.com/diadata-org/diadata/pkg/dia"
 	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/diadata-org/diadata/pkg/utils"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -21,14 +22,22 @@ type nothing struct{}
 // The instance is asynchronously scraping as soon as it is created.
 func SpawnDefiScraper(datastore models.Datastore, rateType string) *DefiScraper {
 	s := &DefiScraper{
-		shutdown:      make(chan nothing),
-		shutdownDone:  make(chan nothing),
-		error:         nil,
-		tickerRate:    time.NewTicker(refreshRateDelay),
-		tickerState:   time.NewTicker(refreshStateDelay),
-		datastore:     datastore,
-		chanDefiRate:  make(chan *dia.DefiRate),
-		chanDefiState: make(chan *dia.DefiProtocolState),
+		shutdown:       make(chan nothing),
+		shutdownDone:   make(chan nothing),
+		error:          nil,
+		tickerRate:     time.NewTicker(refreshRateDelay),
+		tickerState:    time.NewTicker(refreshStateDelay),
+		datastore:      datastore,
+		chanDefiRate:   make(chan *dia.DefiRate),
+		chanDefiState:  make(chan *dia.DefiProtocolState),
+		chanDefiMarket: make(chan *dia.DefiMarketState),
+	}
+	// Lending protocols such as Compound and Aave forks are defined in the lending config.
+	if config, err := GetLendingProtocolConfig(rateType); err == nil {
+		s.lendingProtocol, err = NewLendingProtocol(s, config)
+		if err != nil {
+			log.Errorf("error building lending protocol %s: %v", rateType, err)
+		}
 	}
 
 	log.Info("Defi scraper is built and triggered")
@@ -61,6 +70,9 @@ func (s *DefiScraper) cleanup(err error) {
 
 	s.tickerRate.Stop()
 	s.tickerState.Stop()
+	if s.lendingProtocol != nil {
+		s.lendingProtocol.Close()
+	}
 
 	if err != nil {
 		s.error = err
@@ -91,6 +103,29 @@ func (s *DefiScraper) StateChannel() chan *dia.DefiProtocolState {
 	return s.chanDefiState
 }
 
+// MarketChannel returns a channel that can be used to receive states of single lending markets
+func (s *DefiScraper) MarketChannel() chan *dia.DefiMarketState {
+	return s.chanDefiMarket
+}
+
+// getPrice returns the USD price of @symbol from our own quotations.
+// If there is no quotation, the price is fetched from the DIA API.
+func (s *DefiScraper) getPrice(symbol string) (float64, error) {
+	switch symbol {
+	case "WETH":
+		symbol = "ETH"
+	case "WBNB":
+		symbol = "BNB"
+	case "WMATIC":
+		symbol = "MATIC"
+	}
+	quotation, err := s.datastore.GetQuotation(symbol)
+	if err == nil && quotation.Price > 0 {
+		return quotation.Price, nil
+	}
+	return utils.GetCoinPrice(symbol)
+}
+
 // UpdateRates calls the appropriate function corresponding to the rate type.
 func (s *DefiScraper) UpdateRates(defiType string) error {
 	var (
@@ -165,28 +200,6 @@ func (s *DefiScraper) UpdateRates(defiType string) error {
 	// 		}
 	// 		helper = NewDHARMA(s, protocol)
 	// 	}
-	case "COMPOUND":
-		{
-
-			protocol = dia.DefiProtocol{
-				Name:                 "COMPOUND",
-				Address:              "0x3d9819210a31b4961b30ef54be2aed79b9c9cd3b",
-				UnderlyingBlockchain: "Ethereum",
-				Token:                "",
-			}
-			helper = NewCompound(s, protocol)
-		}
-	case "CREAM":
-		{
-
-			protocol = dia.DefiProtocol{
-				Name:                 "CREAM",
-				Address:              "",
-				UnderlyingBlockchain: "Ethereum",
-				Token:                "",
-			}
-			helper = NewCreamFinance(s, protocol)
-		}
 	case "BZX":
 		{
 
@@ -244,7 +257,11 @@ func (s *DefiScraper) UpdateRates(defiType string) error {
 		}
 
 	default:
-		return errors.New("Error: " + defiType + " does not exist in database")
+		if s.lendingProtocol == nil || s.lendingProtocol.protocol.Name != defiType {
+			return errors.New("Error: " + defiType + " does not exist in database")
+		}
+		protocol = s.lendingProtocol.protocol
+		helper = s.lendingProtocol
 
 	}
 
@@ -280,10 +297,6 @@ func (s *DefiScraper) UpdateState(defiType string) error {
 		{
 			helper = NewDDEX(s, protocol)
 		}
-	case "COMPOUND":
-		{
-			helper = NewCompound(s, protocol)
-		}
 	case "BZX":
 		{
 			helper = NewBZX(s, protocol)
@@ -296,10 +309,6 @@ func (s *DefiScraper) UpdateState(defiType string) error {
 		{
 			helper = NewForTube(s, protocol)
 		}
-	case "CREAM":
-		{
-			helper = NewCompound(s, protocol)
-		}
 	case "BITFINEX":
 		{
 			helper = NewBitfinex(s, protocol)
@@ -309,7 +318,10 @@ func (s *DefiScraper) UpdateState(defiType string) error {
 			helper = NewMakerdao(s, protocol)
 		}
 	default:
-		return errors.New("Error: " + defiType + " does not exist in database")
+		if s.lendingProtocol == nil || s.lendingProtocol.protocol.Name != defiType {
+			return errors.New("Error: " + defiType + " does not exist in database")
+		}
+		helper = s.lendingProtocol
 	}
 	return helper.UpdateState()
 }
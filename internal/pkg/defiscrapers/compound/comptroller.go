@@ -0,0 +1,68 @@
+package compoundcontract
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ComptrollerABI is the subset of the Comptroller ABI needed for market discovery.
+const ComptrollerABI = "[{\"constant\":true,\"inputs\":[],\"name\":\"getAllMarkets\",\"outputs\":[{\"internalType\":\"contractCToken[]\",\"name\":\"\",\"type\":\"address[]\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"markets\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"isListed\",\"type\":\"bool\"},{\"internalType\":\"uint256\",\"name\":\"collateralFactorMantissa\",\"type\":\"uint256\"},{\"internalType\":\"bool\",\"name\":\"isComped\",\"type\":\"bool\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"}]"
+
+// ComptrollerCaller is a read-only Go binding around the Comptroller of a Compound fork.
+type ComptrollerCaller struct {
+	contract *bind.BoundContract
+}
+
+// NewComptrollerCaller creates a new read-only instance of Comptroller, bound to a specific deployed contract.
+func NewComptrollerCaller(address common.Address, caller bind.ContractCaller) (*ComptrollerCaller, error) {
+	parsed, err := abi.JSON(strings.NewReader(ComptrollerABI))
+	if err != nil {
+		return nil, err
+	}
+	contract := bind.NewBoundContract(address, parsed, caller, nil, nil)
+	return &ComptrollerCaller{contract: contract}, nil
+}
+
+// GetAllMarkets returns the addresses of all cTokens listed in the Comptroller.
+//
+// Solidity: function getAllMarkets() view returns(address[])
+func (_Comptroller *ComptrollerCaller) GetAllMarkets(opts *bind.CallOpts) ([]common.Address, error) {
+	var out []interface{}
+	err := _Comptroller.contract.Call(opts, &out, "getAllMarkets")
+	if err != nil {
+		return *new([]common.Address), err
+	}
+	out0 := *abi.ConvertType(out[0], new([]common.Address)).(*[]common.Address)
+	return out0, err
+}
+
+// Markets returns listing status and collateral factor of the market @cToken.
+//
+// Solidity: function markets(address ) view returns(bool isListed, uint256 collateralFactorMantissa, bool isComped)
+func (_Comptroller *ComptrollerCaller) Markets(opts *bind.CallOpts, cToken common.Address) (struct {
+	IsListed                 bool
+	CollateralFactorMantissa *big.Int
+	IsComped                 bool
+}, error) {
+	var out []interface{}
+	err := _Comptroller.contract.Call(opts, &out, "markets", cToken)
+
+	outstruct := new(struct {
+		IsListed                 bool
+		CollateralFactorMantissa *big.Int
+		IsComped                 bool
+	})
+	if err != nil {
+		return *outstruct, err
+	}
+
+	outstruct.IsListed = *abi.ConvertType(out[0], new(bool)).(*bool)
+	outstruct.CollateralFactorMantissa = *abi.ConvertType(out[1], new(*big.Int)).(**big.Int)
+	outstruct.IsComped = *abi.ConvertType(out[2], new(bool)).(*bool)
+
+	return *outstruct, err
+}
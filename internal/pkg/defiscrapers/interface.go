@@ -20,12 +20,16 @@ type DefiScraper struct {
 
 	// error handling; to read error or closed, first acquire read lock
 	// only cleanup method should hold write lock
-	errorLock     sync.RWMutex
-	error         error
-	closed        bool
-	tickerRate    *time.Ticker
-	tickerState   *time.Ticker
-	datastore     models.Datastore
-	chanDefiRate  chan *dia.DefiRate
-	chanDefiState chan *dia.DefiProtocolState
+	errorLock      sync.RWMutex
+	error          error
+	closed         bool
+	tickerRate     *time.Ticker
+	tickerState    *time.Ticker
+	datastore      models.Datastore
+	chanDefiRate   chan *dia.DefiRate
+	chanDefiState  chan *dia.DefiProtocolState
+	chanDefiMarket chan *dia.DefiMarketState
+	// lendingProtocol is set if the scraped protocol is defined in the lending config.
+	// It is built once such that connection and discovered markets are reused.
+	lendingProtocol *LendingProtocol
 }
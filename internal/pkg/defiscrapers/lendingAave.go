@@ -0,0 +1,150 @@
+package defiscrapers
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/diadata-org/diadata/internal/pkg/defiscrapers/aave/contract"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Aave rates are given in ray, i.e. with 27 decimals.
+	aaveRayDecimals = 27
+)
+
+// aaveForkAdapter reads reserves of Aave v2 and its forks through their lending pool.
+type aaveForkAdapter struct {
+	config      LendingProtocolConfig
+	connection  *ethclient.Client
+	markets     []LendingMarket
+	lendingPool *contract.LendingPool
+}
+
+func newAaveForkAdapter(config LendingProtocolConfig, connection *ethclient.Client) *aaveForkAdapter {
+	return &aaveForkAdapter{config: config, connection: connection, markets: config.Markets}
+}
+
+// getLendingPool returns the lending pool registered in the LendingPoolAddressesProvider from config.
+func (adapter *aaveForkAdapter) getLendingPool() (*contract.LendingPool, error) {
+	if adapter.lendingPool != nil {
+		return adapter.lendingPool, nil
+	}
+	if adapter.config.Address == "" {
+		return nil, errors.New("no addresses provider given for " + adapter.config.Name)
+	}
+	addrProvider, err := contract.NewILendingPoolAddressesProvider(common.HexToAddress(adapter.config.Address), adapter.connection)
+	if err != nil {
+		return nil, err
+	}
+	lendingPoolAddress, err := addrProvider.GetLendingPool(&bind.CallOpts{})
+	if err != nil {
+		return nil, err
+	}
+	adapter.lendingPool, err = contract.NewLendingPool(lendingPoolAddress, adapter.connection)
+	return adapter.lendingPool, err
+}
+
+// Markets returns the markets from config. If there are none, all reserves of the lending pool are discovered.
+func (adapter *aaveForkAdapter) Markets() ([]LendingMarket, error) {
+	if len(adapter.markets) > 0 {
+		return adapter.markets, nil
+	}
+	lendingPool, err := adapter.getLendingPool()
+	if err != nil {
+		return []LendingMarket{}, err
+	}
+	reserves, err := lendingPool.GetReservesList(&bind.CallOpts{})
+	if err != nil {
+		return []LendingMarket{}, err
+	}
+	for _, reserve := range reserves {
+		token, err := contract.NewERC20(reserve, adapter.connection)
+		if err != nil {
+			log.Errorf("error binding reserve %s on %s: %v", reserve.Hex(), adapter.config.Name, err)
+			continue
+		}
+		symbol, err := token.Symbol(&bind.CallOpts{})
+		if err != nil {
+			log.Errorf("error getting symbol of reserve %s on %s: %v", reserve.Hex(), adapter.config.Name, err)
+			continue
+		}
+		decimals, err := token.Decimals(&bind.CallOpts{})
+		if err != nil {
+			log.Errorf("error getting decimals of reserve %s on %s: %v", reserve.Hex(), adapter.config.Name, err)
+			continue
+		}
+		adapter.markets = append(adapter.markets, LendingMarket{
+			Asset:    symbol,
+			Address:  reserve.Hex(),
+			Decimals: int(decimals),
+		})
+	}
+	log.Infof("discovered %d markets on %s", len(adapter.markets), adapter.config.Name)
+	return adapter.markets, nil
+}
+
+func (adapter *aaveForkAdapter) FetchMarket(market LendingMarket) (state LendingMarketState, err error) {
+	lendingPool, err := adapter.getLendingPool()
+	if err != nil {
+		return
+	}
+	reserveData, err := lendingPool.GetReserveData(&bind.CallOpts{}, common.HexToAddress(market.Address))
+	if err != nil {
+		return
+	}
+	aToken, err := contract.NewERC20(reserveData.ATokenAddress, adapter.connection)
+	if err != nil {
+		return
+	}
+	aTokenSupply, err := aToken.TotalSupply(&bind.CallOpts{})
+	if err != nil {
+		return
+	}
+	borrowed := float64(0)
+	for _, debtTokenAddress := range []common.Address{reserveData.StableDebtTokenAddress, reserveData.VariableDebtTokenAddress} {
+		debtToken, err := contract.NewERC20(debtTokenAddress, adapter.connection)
+		if err != nil {
+			return state, err
+		}
+		debt, err := debtToken.TotalSupply(&bind.CallOpts{})
+		if err != nil {
+			return state, err
+		}
+		borrowed += normalizeBigInt(debt, market.Decimals)
+	}
+	ltv, liquidationThreshold := aaveReserveConfiguration(reserveData.Configuration.Data)
+
+	// Aave rates are APRs compounded per second.
+	// https://docs.aave.com/developers/v/2.0/guides/apy-and-apr
+	state = LendingMarketState{
+		Market:               market,
+		SupplyAPY:            aprToAPY(normalizeBigInt(reserveData.CurrentLiquidityRate, aaveRayDecimals), secondsPerYear),
+		BorrowAPY:            aprToAPY(normalizeBigInt(reserveData.CurrentVariableBorrowRate, aaveRayDecimals), secondsPerYear),
+		Supplied:             normalizeBigInt(aTokenSupply, market.Decimals),
+		Borrowed:             borrowed,
+		CollateralFactor:     ltv,
+		LiquidationThreshold: liquidationThreshold,
+		Timestamp:            time.Now(),
+	}
+	return
+}
+
+// aaveReserveConfiguration returns loan to value and liquidation threshold as fractions
+// from the reserve configuration bitmap. Both are stored in basis points in bits 0-15 and 16-31.
+// https://docs.aave.com/developers/v/2.0/the-core-protocol/lendingpool#getconfiguration
+func aaveReserveConfiguration(configuration *big.Int) (ltv float64, liquidationThreshold float64) {
+	if configuration == nil {
+		return
+	}
+	mask := big.NewInt(0xFFFF)
+	ltvBps := new(big.Int).And(configuration, mask)
+	thresholdBps := new(big.Int).And(new(big.Int).Rsh(configuration, 16), mask)
+	ltv = float64(ltvBps.Int64()) / 1e4
+	liquidationThreshold = float64(thresholdBps.Int64()) / 1e4
+	return
+}
@@ -0,0 +1,160 @@
+package defiscrapers
+
+import (
+	"errors"
+	"time"
+
+	compoundcontract "github.com/diadata-org/diadata/internal/pkg/defiscrapers/compound"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// 4 blocks per minute as assumed in https://compound.finance/docs#protocol-math
+	ethereumBlocksPerYear = 4 * 60 * 24 * 365
+	compoundMantissa      = 18
+)
+
+// compoundForkAdapter reads markets of Compound and its forks through their cToken contracts.
+type compoundForkAdapter struct {
+	config     LendingProtocolConfig
+	connection *ethclient.Client
+	markets    []LendingMarket
+}
+
+func newCompoundForkAdapter(config LendingProtocolConfig, connection *ethclient.Client) *compoundForkAdapter {
+	if config.BlocksPerYear == 0 {
+		config.BlocksPerYear = ethereumBlocksPerYear
+	}
+	if config.NativeAsset == "" {
+		config.NativeAsset = "ETH"
+	}
+	return &compoundForkAdapter{config: config, connection: connection, markets: config.Markets}
+}
+
+// Markets returns the markets from config. If there are none, all markets listed
+// in the Comptroller are discovered.
+func (adapter *compoundForkAdapter) Markets() ([]LendingMarket, error) {
+	if len(adapter.markets) > 0 {
+		return adapter.markets, nil
+	}
+	if adapter.config.Address == "" {
+		return []LendingMarket{}, errors.New("neither markets nor comptroller given for " + adapter.config.Name)
+	}
+	comptroller, err := compoundcontract.NewComptrollerCaller(common.HexToAddress(adapter.config.Address), adapter.connection)
+	if err != nil {
+		return []LendingMarket{}, err
+	}
+	cTokens, err := comptroller.GetAllMarkets(&bind.CallOpts{})
+	if err != nil {
+		return []LendingMarket{}, err
+	}
+	for _, cToken := range cTokens {
+		market, err := adapter.discoverMarket(cToken)
+		if err != nil {
+			log.Errorf("error discovering market %s on %s: %v", cToken.Hex(), adapter.config.Name, err)
+			continue
+		}
+		adapter.markets = append(adapter.markets, market)
+	}
+	log.Infof("discovered %d markets on %s", len(adapter.markets), adapter.config.Name)
+	return adapter.markets, nil
+}
+
+// discoverMarket returns the market of @cToken with symbol and decimals of its underlying asset.
+func (adapter *compoundForkAdapter) discoverMarket(cToken common.Address) (market LendingMarket, err error) {
+	cContract, err := compoundcontract.NewCErc20Caller(cToken, adapter.connection)
+	if err != nil {
+		return
+	}
+	market.Address = cToken.Hex()
+	underlying, err := cContract.Underlying(&bind.CallOpts{})
+	if err != nil {
+		// cEther-like markets have no underlying token.
+		market.Asset = adapter.config.NativeAsset
+		market.Decimals = 18
+		return market, nil
+	}
+	underlyingContract, err := compoundcontract.NewCErc20Caller(underlying, adapter.connection)
+	if err != nil {
+		return
+	}
+	market.Asset, err = underlyingContract.Symbol(&bind.CallOpts{})
+	if err != nil {
+		return
+	}
+	decimals, err := underlyingContract.Decimals(&bind.CallOpts{})
+	if err != nil {
+		return
+	}
+	market.Decimals = int(decimals.Int64())
+	return
+}
+
+func (adapter *compoundForkAdapter) FetchMarket(market LendingMarket) (state LendingMarketState, err error) {
+	contract, err := compoundcontract.NewCTokenCaller(common.HexToAddress(market.Address), adapter.connection)
+	if err != nil {
+		return
+	}
+	supplyRate, err := contract.SupplyRatePerBlock(&bind.CallOpts{})
+	if err != nil {
+		return
+	}
+	borrowRate, err := contract.BorrowRatePerBlock(&bind.CallOpts{})
+	if err != nil {
+		return
+	}
+	cash, err := contract.GetCash(&bind.CallOpts{})
+	if err != nil {
+		return
+	}
+	totalBorrows, err := contract.TotalBorrows(&bind.CallOpts{})
+	if err != nil {
+		return
+	}
+	totalReserves, err := contract.TotalReserves(&bind.CallOpts{})
+	if err != nil {
+		return
+	}
+	collateralFactor, err := adapter.collateralFactor(market)
+	if err != nil {
+		log.Errorf("error getting collateral factor of %s on %s: %v", market.Asset, adapter.config.Name, err)
+	}
+
+	blocksPerYear := adapter.config.BlocksPerYear
+	borrowed := normalizeBigInt(totalBorrows, market.Decimals)
+	reserves := normalizeBigInt(totalReserves, market.Decimals)
+	state = LendingMarketState{
+		Market:    market,
+		SupplyAPY: aprToAPY(normalizeBigInt(supplyRate, compoundMantissa)*blocksPerYear, blocksPerYear),
+		BorrowAPY: aprToAPY(normalizeBigInt(borrowRate, compoundMantissa)*blocksPerYear, blocksPerYear),
+		// Supplied assets are cash plus borrows minus reserves:
+		// https://compound.finance/docs/ctokens#exchange-rate
+		Supplied: normalizeBigInt(cash, market.Decimals) + borrowed - reserves,
+		Borrowed: borrowed,
+		Reserves: reserves,
+		// Compound liquidates as soon as the collateral factor is exceeded.
+		CollateralFactor:     collateralFactor,
+		LiquidationThreshold: collateralFactor,
+		Timestamp:            time.Now(),
+	}
+	return
+}
+
+// collateralFactor returns the collateral factor of @market as set in the Comptroller.
+func (adapter *compoundForkAdapter) collateralFactor(market LendingMarket) (float64, error) {
+	if adapter.config.Address == "" {
+		return 0, errors.New("no comptroller given")
+	}
+	comptroller, err := compoundcontract.NewComptrollerCaller(common.HexToAddress(adapter.config.Address), adapter.connection)
+	if err != nil {
+		return 0, err
+	}
+	marketInfo, err := comptroller.Markets(&bind.CallOpts{}, common.HexToAddress(market.Address))
+	if err != nil {
+		return 0, err
+	}
+	return normalizeBigInt(marketInfo.CollateralFactorMantissa, compoundMantissa), nil
+}
@@ -0,0 +1,91 @@
+package defiscrapers
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/configCollectors"
+)
+
+const (
+	// Adapter types of lending protocols which can be added by config.
+	LendingTypeCompound = "compound"
+	LendingTypeAave     = "aave"
+
+	lendingConfigFile = "defi/lending"
+)
+
+// LendingMarket is a single market of a lending protocol.
+// For Compound forks Address is the cToken address, for Aave forks it is the address of the reserve asset.
+type LendingMarket struct {
+	Asset    string `json:"Asset"`
+	Address  string `json:"Address"`
+	Decimals int    `json:"Decimals"`
+}
+
+// LendingProtocolConfig describes a lending protocol deployment on an EVM chain.
+// If Markets is empty, markets are discovered on-chain through the Comptroller (Compound forks)
+// or the lending pool's reserve list (Aave forks).
+type LendingProtocolConfig struct {
+	Name       string `json:"Name"`
+	Type       string `json:"Type"`
+	Blockchain string `json:"Blockchain"`
+	// RPC is the node endpoint of the chain. Defaults to the Ethereum client from ethhelper.
+	RPC string `json:"RPC"`
+	// Address of the Comptroller (Compound forks) or LendingPoolAddressesProvider (Aave forks).
+	Address string `json:"Address"`
+	// NativeAsset is the symbol of the chain's native currency, i.e. the underlying of cEther-like markets.
+	NativeAsset string `json:"NativeAsset"`
+	// BlocksPerYear is used for the conversion of per-block rates of Compound forks.
+	BlocksPerYear float64         `json:"BlocksPerYear"`
+	Markets       []LendingMarket `json:"Markets"`
+}
+
+// Protocol returns the dia.DefiProtocol corresponding to the config.
+func (lpc *LendingProtocolConfig) Protocol() dia.DefiProtocol {
+	return dia.DefiProtocol{
+		Name:                 lpc.Name,
+		Address:              lpc.Address,
+		UnderlyingBlockchain: lpc.Blockchain,
+	}
+}
+
+// GetLendingProtocolsFromConfig returns all lending protocols from the config file.
+func GetLendingProtocolsFromConfig() ([]LendingProtocolConfig, error) {
+	jsonFile, err := os.Open(configCollectors.ConfigFileConnectors(lendingConfigFile, ".json"))
+	if err != nil {
+		return []LendingProtocolConfig{}, err
+	}
+	defer jsonFile.Close()
+	byteData, err := ioutil.ReadAll(jsonFile)
+	if err != nil {
+		return []LendingProtocolConfig{}, err
+	}
+
+	type lendingProtocolList struct {
+		Protocols []LendingProtocolConfig `json:"Protocols"`
+	}
+	var protocols lendingProtocolList
+	err = json.Unmarshal(byteData, &protocols)
+	if err != nil {
+		return []LendingProtocolConfig{}, err
+	}
+	return protocols.Protocols, nil
+}
+
+// GetLendingProtocolConfig returns the config of the lending protocol with name @name.
+func GetLendingProtocolConfig(name string) (LendingProtocolConfig, error) {
+	protocols, err := GetLendingProtocolsFromConfig()
+	if err != nil {
+		return LendingProtocolConfig{}, err
+	}
+	for _, protocol := range protocols {
+		if protocol.Name == name {
+			return protocol, nil
+		}
+	}
+	return LendingProtocolConfig{}, errors.New("no lending protocol config for " + name)
+}
@@ -0,0 +1,195 @@
+package defiscrapers
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/ethhelper"
+	"github.com/ethereum/go-ethereum/ethclient"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	secondsPerYear = 365 * 24 * 60 * 60
+)
+
+// LendingAdapter is implemented by each family of lending protocols (Compound forks, Aave forks,...).
+type LendingAdapter interface {
+	// Markets returns all markets of the protocol, either from config or discovered on-chain.
+	Markets() ([]LendingMarket, error)
+	// FetchMarket returns the current state of @market.
+	FetchMarket(market LendingMarket) (LendingMarketState, error)
+}
+
+// LendingMarketState is the state of a lending market. Rates are APY in percent,
+// amounts are in units of the underlying asset.
+type LendingMarketState struct {
+	Market    LendingMarket
+	SupplyAPY float64
+	BorrowAPY float64
+	Supplied  float64
+	Borrowed  float64
+	Reserves  float64
+	// CollateralFactor is the fraction of the supplied value which can be borrowed against.
+	CollateralFactor float64
+	// LiquidationThreshold is the fraction of the supplied value at which a position becomes liquidatable.
+	LiquidationThreshold float64
+	Timestamp            time.Time
+}
+
+// Utilisation returns the fraction of supplied assets which is borrowed.
+func (state *LendingMarketState) Utilisation() float64 {
+	if state.Supplied == 0 {
+		return 0
+	}
+	return state.Borrowed / state.Supplied
+}
+
+// LendingProtocol implements DeFIHelper for any lending protocol with a LendingAdapter.
+type LendingProtocol struct {
+	scraper    *DefiScraper
+	protocol   dia.DefiProtocol
+	adapter    LendingAdapter
+	connection *ethclient.Client
+}
+
+// NewLendingProtocol returns a lending protocol scraper for the protocol given by @config.
+// The connection to its chain is kept open until Close is called.
+func NewLendingProtocol(scraper *DefiScraper, config LendingProtocolConfig) (*LendingProtocol, error) {
+	connection, err := dialLendingChain(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var adapter LendingAdapter
+	switch config.Type {
+	case LendingTypeCompound:
+		adapter = newCompoundForkAdapter(config, connection)
+	case LendingTypeAave:
+		adapter = newAaveForkAdapter(config, connection)
+	default:
+		connection.Close()
+		return nil, errors.New("unknown lending protocol type " + config.Type)
+	}
+	return &LendingProtocol{scraper: scraper, protocol: config.Protocol(), adapter: adapter, connection: connection}, nil
+}
+
+func dialLendingChain(config LendingProtocolConfig) (*ethclient.Client, error) {
+	if config.RPC == "" {
+		return ethhelper.NewETHClient()
+	}
+	return ethclient.Dial(config.RPC)
+}
+
+// Close closes the connection to the protocol's chain.
+func (proto *LendingProtocol) Close() {
+	proto.connection.Close()
+}
+
+func (proto *LendingProtocol) fetchAll() (states []LendingMarketState, err error) {
+	markets, err := proto.adapter.Markets()
+	if err != nil {
+		return
+	}
+	for _, market := range markets {
+		state, err := proto.adapter.FetchMarket(market)
+		if err != nil {
+			log.Errorf("error fetching market %s on %s: %v", market.Asset, proto.protocol.Name, err)
+			continue
+		}
+		states = append(states, state)
+	}
+	return
+}
+
+func (proto *LendingProtocol) UpdateRate() error {
+	log.Printf("Updating DEFI Rate for %+v\n ", proto.protocol.Name)
+	states, err := proto.fetchAll()
+	if err != nil {
+		return err
+	}
+	for _, state := range states {
+		asset := &dia.DefiRate{
+			Timestamp:     state.Timestamp,
+			Asset:         state.Market.Asset,
+			Protocol:      proto.protocol.Name,
+			LendingRate:   state.SupplyAPY,
+			BorrowingRate: state.BorrowAPY,
+		}
+		log.Printf("writing DEFI rate for  %#v in %v\n", asset, proto.scraper.RateChannel())
+		proto.scraper.RateChannel() <- asset
+	}
+	log.Info("Update complete")
+	return nil
+}
+
+// UpdateState sends the state of each market and the protocol's TVL.
+func (proto *LendingProtocol) UpdateState() error {
+	log.Printf("Updating DEFI state for %+v\n ", proto.protocol)
+	states, err := proto.fetchAll()
+	if err != nil {
+		return err
+	}
+	return sendLendingStates(proto.scraper, proto.protocol, states)
+}
+
+// sendLendingStates sends the state of each market in @states and the TVL of @protocol, i.e.
+// supplied minus borrowed assets valued at our own prices. Markets without a price are skipped.
+func sendLendingStates(scraper *DefiScraper, protocol dia.DefiProtocol, states []LendingMarketState) error {
+	totalValueLocked := float64(0)
+	for _, state := range states {
+		price, err := scraper.getPrice(state.Market.Asset)
+		if err != nil {
+			log.Errorf("error getting price of %s: %v", state.Market.Asset, err)
+			continue
+		}
+		totalValueLocked += (state.Supplied - state.Borrowed) * price
+		marketState := &dia.DefiMarketState{
+			Timestamp:            state.Timestamp,
+			Protocol:             protocol.Name,
+			Asset:                state.Market.Asset,
+			SuppliedUSD:          state.Supplied * price,
+			BorrowedUSD:          state.Borrowed * price,
+			ReservesUSD:          state.Reserves * price,
+			Utilisation:          state.Utilisation(),
+			CollateralFactor:     state.CollateralFactor,
+			LiquidationThreshold: state.LiquidationThreshold,
+		}
+		scraper.MarketChannel() <- marketState
+	}
+	ETHPrice, err := scraper.getPrice("ETH")
+	if err != nil {
+		return err
+	}
+	defistate := &dia.DefiProtocolState{
+		TotalUSD:  totalValueLocked,
+		TotalETH:  totalValueLocked / ETHPrice,
+		Protocol:  protocol,
+		Timestamp: time.Now(),
+	}
+	scraper.StateChannel() <- defistate
+	log.Printf("writing DEFI state for  %#v in %v\n", defistate, scraper.StateChannel())
+	log.Info("Update State complete")
+	return nil
+}
+
+// aprToAPY returns the APY in percent for a nominal annual rate @apr (as a fraction)
+// compounded @periodsPerYear times a year.
+func aprToAPY(apr float64, periodsPerYear float64) float64 {
+	if periodsPerYear <= 0 {
+		return apr * 100
+	}
+	return (math.Pow(1+apr/periodsPerYear, periodsPerYear) - 1) * 100
+}
+
+// normalizeBigInt returns @amount divided by 10^@decimals.
+func normalizeBigInt(amount *big.Int, decimals int) float64 {
+	if amount == nil {
+		return 0
+	}
+	value, _ := new(big.Float).Quo(new(big.Float).SetInt(amount), big.NewFloat(math.Pow10(decimals))).Float64()
+	return value
+}
@@ -1,15 +1,12 @@
 package defiscrapers
 
 import (
-	"fmt"
 	"math"
 	"math/big"
-	"strconv"
 	"time"
 
 	bzxcontract "github.com/diadata-org/diadata/internal/pkg/defiscrapers/bzx"
 	"github.com/diadata-org/diadata/pkg/dia"
-	"github.com/diadata-org/diadata/pkg/utils"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
@@ -146,49 +143,29 @@ func (proto *BZXProtocol) UpdateRate() error {
 	return nil
 }
 
-func (proto *BZXProtocol) getTotalSupply() (float64, error) {
+// fetchStates returns the supplied and borrowed amounts of all loan pools.
+func (proto *BZXProtocol) fetchStates() (states []LendingMarketState, err error) {
 	markets, err := proto.fetchALL()
 	if err != nil {
-		return 0, err
+		return
 	}
-	sum := float64(0)
-	for i := 0; i < len(markets); i++ {
-
-		TotalDiff := big.NewInt(0).Sub(markets[i].TotalSupplyAsset, markets[i].TotalBorrowAsset)
-		marketLiquidityUSD, err := strconv.ParseFloat(TotalDiff.String(), 64)
-		if err != nil {
-			return 0, err
-		}
-		marketLiquidityUSD /= math.Pow10(int(markets[i].Decimals))
-		sum += marketLiquidityUSD
-		// fmt.Printf("market %s holds %v worth in USD \n", markets[i].Symbol, marketLiquidityUSD)
+	for _, market := range markets {
+		decimals := int(market.Decimals)
+		states = append(states, LendingMarketState{
+			Market:    LendingMarket{Asset: market.Symbol, Address: proto.assets[market.Symbol], Decimals: decimals},
+			Supplied:  normalizeBigInt(market.TotalSupplyAsset, decimals),
+			Borrowed:  normalizeBigInt(market.TotalBorrowAsset, decimals),
+			Timestamp: time.Now(),
+		})
 	}
-	return sum, nil
+	return
 }
 
 func (proto *BZXProtocol) UpdateState() error {
 	log.Printf("Updating DEFI state for %+v\n ", proto.protocol)
-	totalSupplyUSD, err := proto.getTotalSupply()
-	if err != nil {
-		return err
-	}
-	priceETH, err := utils.GetCoinPrice("ETH")
+	states, err := proto.fetchStates()
 	if err != nil {
-		fmt.Println("error getting price: ", err)
 		return err
 	}
-	totalSupplyETH := totalSupplyUSD / priceETH
-	defistate := &dia.DefiProtocolState{
-		TotalUSD:  totalSupplyUSD,
-		TotalETH:  totalSupplyETH,
-		Protocol:  proto.protocol,
-		Timestamp: time.Now(),
-	}
-	proto.scraper.StateChannel() <- defistate
-	log.Printf("writing DEFI state for  %#v in %v\n", defistate, proto.scraper.StateChannel())
-
-	log.Info("Update State complete")
-
-	return nil
-
+	return sendLendingStates(proto.scraper, proto.protocol, states)
 }
@@ -100,49 +100,36 @@ func getDDEXAssetByAddress(address string) (reserve LendingPool, err error) {
 	return
 }
 
-func (proto *DDEXProtocol) getTotalSupply() (sum float64, err error) {
+// fetchStates returns the supplied and borrowed amounts of all lending pools.
+func (proto *DDEXProtocol) fetchStates() (states []LendingMarketState, err error) {
 	markets, err := fetchddexmarkets()
 	if err != nil {
-		return 0, err
+		return
 	}
-
-	for i := 0; i < len(markets.Data.LendingPoolStats); i++ {
-		coinPrice, err := utils.GetCoinPrice(markets.Data.LendingPoolStats[i].Symbol)
+	for _, market := range markets.Data.LendingPoolStats {
+		totalSupplyAsset, err := strconv.ParseFloat(market.TotalSupplyAmount, 64)
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
-		totalSupplyAsset, err := strconv.ParseFloat(markets.Data.LendingPoolStats[i].TotalSupplyAmount, 64)
+		totalBorrowAsset, err := strconv.ParseFloat(market.TotalBorrowAmount, 64)
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
-		sum += coinPrice * totalSupplyAsset
+		states = append(states, LendingMarketState{
+			Market:    LendingMarket{Asset: market.Symbol, Address: market.AssetAddress},
+			Supplied:  totalSupplyAsset,
+			Borrowed:  totalBorrowAsset,
+			Timestamp: time.Now(),
+		})
 	}
 	return
 }
 
 func (proto *DDEXProtocol) UpdateState() error {
 	log.Printf("Updating DEFI state for %+v\n ", proto.protocol)
-	totalSupplyUSD, err := proto.getTotalSupply()
-	if err != nil {
-		return err
-	}
-	priceETH, err := utils.GetCoinPrice("ETH")
+	states, err := proto.fetchStates()
 	if err != nil {
 		return err
 	}
-	totalSupplyETH := totalSupplyUSD / priceETH
-
-	defistate := &dia.DefiProtocolState{
-		TotalUSD:  totalSupplyUSD,
-		TotalETH:  totalSupplyETH,
-		Protocol:  proto.protocol,
-		Timestamp: time.Now(),
-	}
-	proto.scraper.StateChannel() <- defistate
-	log.Printf("writing DEFI state for  %#v in %v\n", defistate, proto.scraper.StateChannel())
-
-	log.Info("Update State complete")
-
-	return nil
-
+	return sendLendingStates(proto.scraper, proto.protocol, states)
 }
@@ -63,42 +63,38 @@ func fetchDYDXMarkets() (dydxrate []DYDXMarket, err error) {
 	return response["markets"], err
 }
 
-// getTotalSupply returns the total supply in ETH locked in the protocol
-func getDYDXTotalSupplyUSD() (float64, error) {
+// fetchDYDXStates returns the supplied and borrowed amounts and the liquidation threshold of all
+// markets. Positions are liquidated once their collateral falls below the borrowed value times the
+// collateral ratio, increased by the market's margin premium.
+func fetchDYDXStates() (states []LendingMarketState, err error) {
 	markets, err := fetchDYDXMarkets()
 	if err != nil {
-		return 0, err
+		return
 	}
-	sum := float64(0)
 	for _, market := range markets {
 		marketSupply, err := strconv.ParseFloat(market.TotalSupplyWei, 64)
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
 		marketBorrow, err := strconv.ParseFloat(market.TotalBorrowWei, 64)
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
-		marketLiquidity := (marketSupply - marketBorrow) / math.Pow10(market.Currency.Decimals)
-		priceCoin, err := utils.GetCoinPrice(market.Symbol)
-		if err != nil {
-			return 0, err
+		state := LendingMarketState{
+			Market:    LendingMarket{Asset: market.Symbol, Address: market.Currency.ContractAddress, Decimals: market.Currency.Decimals},
+			Supplied:  marketSupply / math.Pow10(market.Currency.Decimals),
+			Borrowed:  marketBorrow / math.Pow10(market.Currency.Decimals),
+			Timestamp: time.Now(),
 		}
-		// priceCoin := float64(0)
-		// if market.Symbol == "SAI" {
-		// 	// hotfix because coingecko is down. Impact currently low as total supply around 10. Change asap!
-		// 	priceCoin = float64(1.42)
-		// } else {
-		// 	priceCoin, err = utils.GetCoinPrice(market.Symbol)
-		// 	if err != nil {
-		// 		return 0, err
-		// 	}
-		// }
-
-		marketPrice := marketLiquidity * priceCoin
-		sum += marketPrice
+		collateralRatio, errRatio := strconv.ParseFloat(market.CollateralRatio, 64)
+		marginPremium, errPremium := strconv.ParseFloat(market.MarginPremium, 64)
+		if errRatio == nil && errPremium == nil && collateralRatio > 0 {
+			state.LiquidationThreshold = 1 / (collateralRatio * (1 + marginPremium))
+			state.CollateralFactor = state.LiquidationThreshold
+		}
+		states = append(states, state)
 	}
-	return sum, nil
+	return
 }
 
 func (proto *DYDXProtocol) UpdateRate() error {
@@ -147,26 +143,9 @@ func getMarketByID(marketID string) (dydxrate DYDXMarket, err error) {
 
 func (proto *DYDXProtocol) UpdateState() error {
 	log.Printf("Updating DEFI state for %+v\n ", proto.protocol)
-	totalSupplyUSD, err := getDYDXTotalSupplyUSD()
-	if err != nil {
-		return err
-	}
-	priceETH, err := utils.GetCoinPrice("ETH")
+	states, err := fetchDYDXStates()
 	if err != nil {
 		return err
 	}
-	totalSupplyETH := totalSupplyUSD / priceETH
-	defistate := &dia.DefiProtocolState{
-		TotalUSD:  totalSupplyUSD,
-		TotalETH:  totalSupplyETH,
-		Protocol:  proto.protocol,
-		Timestamp: time.Now(),
-	}
-	proto.scraper.StateChannel() <- defistate
-	log.Printf("writing DEFI state for  %#v in %v\n", defistate, proto.scraper.StateChannel())
-
-	log.Info("Update State complete")
-
-	return nil
-
+	return sendLendingStates(proto.scraper, proto.protocol, states)
 }
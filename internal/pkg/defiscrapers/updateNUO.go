@@ -3,13 +3,11 @@ package defiscrapers
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io/ioutil"
 	"net/http"
 	"time"
 
 	"github.com/diadata-org/diadata/pkg/dia"
-	"github.com/diadata-org/diadata/pkg/utils"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -125,24 +123,21 @@ func (proto *NuoProtocol) fetchALL() (rate NuoResponse, err error) {
 	return
 }
 
-func (proto *NuoProtocol) getTotalSupply() (float64, error) {
+// fetchStates returns the lent and borrowed amounts of all reserves.
+func (proto *NuoProtocol) fetchStates() (states []LendingMarketState, err error) {
 	markets, err := proto.fetchALL()
 	if err != nil {
-		return 0, err
+		return
 	}
-
-	sum := float64(0)
 	for _, market := range markets.Data.Reserves {
-		totalSupplyAsset := market.TotalBalance
-		priceCoin, err := utils.GetCoinPrice(market.Currency.ShortName)
-		if err != nil {
-			return 0, err
-		}
-		marketPrice := totalSupplyAsset * priceCoin
-		sum += marketPrice
-		fmt.Printf("market %s holds %v worth in USD \n", market.Currency.ShortName, marketPrice)
+		states = append(states, LendingMarketState{
+			Market:    LendingMarket{Asset: market.Currency.ShortName, Address: market.Currency.BaseAddress, Decimals: market.Currency.DecimalCount},
+			Supplied:  market.TotalBalance,
+			Borrowed:  market.ActiveLoanAmountSum,
+			Timestamp: time.Now(),
+		})
 	}
-	return sum, nil
+	return
 }
 
 func (proto *NuoProtocol) UpdateRate() error {
@@ -170,28 +165,10 @@ func (proto *NuoProtocol) UpdateRate() error {
 }
 
 func (proto *NuoProtocol) UpdateState() error {
-	log.Print("Updating DEFI state for %+v\n ", proto.protocol)
-	totalSupplyUSD, err := proto.getTotalSupply()
-	if err != nil {
-		return err
-	}
-	priceETH, err := utils.GetCoinPrice("ETH")
+	log.Printf("Updating DEFI state for %+v\n ", proto.protocol)
+	states, err := proto.fetchStates()
 	if err != nil {
 		return err
 	}
-	totalSupplyETH := totalSupplyUSD / priceETH
-
-	defistate := &dia.DefiProtocolState{
-		TotalUSD:  totalSupplyUSD,
-		TotalETH:  totalSupplyETH,
-		Protocol:  proto.protocol,
-		Timestamp: time.Now(),
-	}
-	proto.scraper.StateChannel() <- defistate
-	log.Printf("writing DEFI state for  %#v in %v\n", defistate, proto.scraper.StateChannel())
-
-	log.Info("Update State complete")
-
-	return nil
-
+	return sendLendingStates(proto.scraper, proto.protocol, states)
 }
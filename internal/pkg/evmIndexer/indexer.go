@@ -0,0 +1,443 @@
+// Package evmindexer follows an EVM chain and delivers decoded event logs to registered handlers.
+// Logs are delivered once their block has the configured number of confirmations. Chain
+// reorganisations are detected by parent hash mismatch, in which case the logs of orphaned
+// blocks are retracted from the handlers and the canonical blocks are replayed.
+//
+// Delivery is at least once: the cursor is persisted after each step, so the logs of a step
+// which failed, for instance because a handler returned an error, are delivered again.
+package evmindexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/jackc/pgx/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrReorgTooDeep is returned if a reorganisation reaches beyond the blocks kept in the state.
+	ErrReorgTooDeep = errors.New("chain reorganisation deeper than reorg window")
+)
+
+// ChainReader is the subset of ethclient.Client used by the indexer.
+type ChainReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
+}
+
+// StateStore persists the cursor of an indexer. It is implemented by models.RelDB.
+type StateStore interface {
+	GetScraperState(ctx context.Context, scraperName string, state models.ScraperState) error
+	SetScraperState(ctx context.Context, scraperName string, state models.ScraperState) error
+}
+
+// Event is a decoded log.
+type Event struct {
+	Name string
+	// Args contains indexed as well as non-indexed event arguments by name.
+	Args map[string]interface{}
+	Log  types.Log
+}
+
+// Handler receives the events of an indexer. As events can be delivered more than once, handlers
+// must be idempotent.
+type Handler interface {
+	// HandleEvent is called for each event of a confirmed block in chain order. If it returns an
+	// error, all events of the current step are delivered again in the next one.
+	HandleEvent(ctx context.Context, event Event) error
+	// RetractEvent is called in reverse order for each previously handled event
+	// whose block was removed from the canonical chain.
+	RetractEvent(ctx context.Context, event Event) error
+}
+
+// Config of an indexer.
+type Config struct {
+	// Name under which the state is stored in the scrapers table.
+	Name string
+	// StartBlock is the first block to index if there is no stored state.
+	StartBlock uint64
+	// Confirmations is the distance to the chain head a block must have before its logs are delivered.
+	Confirmations uint64
+	// ReorgWindow is the number of most recent blocks which are tracked for reorganisations.
+	// Blocks further behind the head are fetched in batches without tracking.
+	ReorgWindow uint64
+	// BatchSize is the maximal number of blocks per log query while catching up.
+	BatchSize uint64
+	// PollInterval is the waiting time once the indexer has reached the chain head.
+	PollInterval time.Duration
+}
+
+// BlockRecord is a block in the reorg window along with the logs delivered for it.
+type BlockRecord struct {
+	Number uint64      `json:"number"`
+	Hash   common.Hash `json:"hash"`
+	Logs   []types.Log `json:"logs"`
+}
+
+// State is the persisted cursor of an indexer.
+type State struct {
+	// LastBlock is the last block whose logs have been delivered.
+	LastBlock uint64      `json:"last_block"`
+	LastHash  common.Hash `json:"last_hash"`
+	// Recent holds the blocks in the reorg window in ascending order.
+	Recent []BlockRecord `json:"recent"`
+}
+
+type registration struct {
+	contractABI abi.ABI
+	event       abi.Event
+	handler     Handler
+}
+
+// Indexer follows a chain and delivers events to registered handlers.
+type Indexer struct {
+	conf  Config
+	chain ChainReader
+	store StateStore
+
+	mu            sync.Mutex
+	state         *State
+	registrations map[common.Address]map[common.Hash]registration
+}
+
+// New returns an indexer for the chain read through @chain whose state is persisted in @store.
+func New(conf Config, chain ChainReader, store StateStore) *Indexer {
+	if conf.BatchSize == 0 {
+		conf.BatchSize = 2000
+	}
+	if conf.ReorgWindow == 0 {
+		conf.ReorgWindow = 64
+	}
+	if conf.PollInterval == 0 {
+		conf.PollInterval = 15 * time.Second
+	}
+	return &Indexer{
+		conf:          conf,
+		chain:         chain,
+		store:         store,
+		registrations: make(map[common.Address]map[common.Hash]registration),
+	}
+}
+
+// Register delivers all events @eventName emitted by the contract at @address to @handler.
+func (idx *Indexer) Register(address common.Address, contractABI abi.ABI, eventName string, handler Handler) error {
+	event, ok := contractABI.Events[eventName]
+	if !ok {
+		return fmt.Errorf("event %s not found in abi", eventName)
+	}
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if _, ok := idx.registrations[address]; !ok {
+		idx.registrations[address] = make(map[common.Hash]registration)
+	}
+	idx.registrations[address][event.ID] = registration{contractABI: contractABI, event: event, handler: handler}
+	return nil
+}
+
+// State returns a copy of the current cursor.
+func (idx *Indexer) State() State {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if idx.state == nil {
+		return State{}
+	}
+	return *idx.state
+}
+
+// Sync processes all confirmed blocks which have not been processed yet. It is meant for
+// callers scheduling the indexer themselves, Run polls the chain continuously.
+func (idx *Indexer) Sync(ctx context.Context) error {
+	idx.mu.Lock()
+	loaded := idx.state != nil
+	idx.mu.Unlock()
+	if !loaded {
+		if err := idx.loadState(ctx); err != nil {
+			return err
+		}
+	}
+	for {
+		synced, err := idx.Step(ctx)
+		if err != nil || synced {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// Run indexes the chain until @ctx is cancelled.
+func (idx *Indexer) Run(ctx context.Context) error {
+	if err := idx.loadState(ctx); err != nil {
+		return err
+	}
+	log.Infof("indexer %s starts after block %d", idx.conf.Name, idx.state.LastBlock)
+	for {
+		synced, err := idx.Step(ctx)
+		if err != nil {
+			if errors.Is(err, ErrReorgTooDeep) {
+				return err
+			}
+			log.Errorf("indexer %s: %v", idx.conf.Name, err)
+		}
+		wait := time.Duration(0)
+		if synced || err != nil {
+			wait = idx.conf.PollInterval
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (idx *Indexer) loadState(ctx context.Context) error {
+	state := &State{}
+	err := idx.store.GetScraperState(ctx, idx.conf.Name, state)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+		state = &State{}
+		if idx.conf.StartBlock > 0 {
+			state.LastBlock = idx.conf.StartBlock - 1
+		}
+	}
+	idx.mu.Lock()
+	idx.state = state
+	idx.mu.Unlock()
+	return nil
+}
+
+// Step processes the next batch of blocks, or a single block if the indexer is
+// within the reorg window. It returns true if there are no confirmed blocks left to process.
+func (idx *Indexer) Step(ctx context.Context) (synced bool, err error) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if idx.state == nil {
+		return false, errors.New("indexer state not loaded")
+	}
+
+	head, err := idx.chain.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	if head.Number.Uint64() < idx.conf.Confirmations {
+		return true, nil
+	}
+	confirmed := head.Number.Uint64() - idx.conf.Confirmations
+	next := idx.state.LastBlock + 1
+	if next > confirmed {
+		return true, nil
+	}
+
+	if confirmed-next >= idx.conf.ReorgWindow {
+		to := next + idx.conf.BatchSize - 1
+		limit := confirmed - idx.conf.ReorgWindow
+		if to > limit {
+			to = limit
+		}
+		// The batch adjoining the reorg window records its blocks, such that a reorganisation
+		// reaching into it can be rolled back once blocks are processed one by one.
+		err = idx.processRange(ctx, next, to, to == limit)
+	} else {
+		err = idx.processBlock(ctx, next)
+	}
+	if err != nil {
+		return false, err
+	}
+	return false, idx.store.SetScraperState(ctx, idx.conf.Name, idx.state)
+}
+
+// processRange delivers all logs in the blocks @from to @to. The range is far enough from the
+// chain head that reorganisations are not expected, so only the hash of @to is recorded, unless
+// @track is set. Then the last blocks of the range are recorded as recent blocks.
+func (idx *Indexer) processRange(ctx context.Context, from, to uint64, track bool) error {
+	first := to
+	if track {
+		first = from
+		if to-from >= idx.conf.ReorgWindow {
+			first = to - idx.conf.ReorgWindow + 1
+		}
+	}
+	var records []BlockRecord
+	var parentHash common.Hash
+	for number := first; number <= to; number++ {
+		header, err := idx.chain.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+		if err != nil {
+			return err
+		}
+		if len(records) > 0 && header.ParentHash != parentHash {
+			return fmt.Errorf("chain changed while fetching block %d", number)
+		}
+		parentHash = header.Hash()
+		records = append(records, BlockRecord{Number: number, Hash: parentHash})
+	}
+
+	logs, err := idx.chain.FilterLogs(ctx, idx.filterQuery(ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+	}))
+	if err != nil {
+		return err
+	}
+	for _, l := range logs {
+		if l.Removed || l.BlockNumber < first {
+			continue
+		}
+		record := &records[l.BlockNumber-first]
+		if l.BlockHash != record.Hash {
+			return fmt.Errorf("chain changed while fetching logs of block %d", l.BlockNumber)
+		}
+		record.Logs = append(record.Logs, l)
+	}
+	for _, l := range logs {
+		if l.Removed {
+			continue
+		}
+		if err := idx.deliver(ctx, l, false); err != nil {
+			return err
+		}
+	}
+
+	idx.state.LastBlock = to
+	idx.state.LastHash = records[len(records)-1].Hash
+	if track {
+		idx.state.Recent = append(idx.state.Recent, records...)
+		if excess := len(idx.state.Recent) - int(idx.conf.ReorgWindow); excess > 0 {
+			idx.state.Recent = idx.state.Recent[excess:]
+		}
+	} else {
+		idx.state.Recent = nil
+	}
+	log.Debugf("indexer %s: processed blocks %d - %d with %d logs", idx.conf.Name, from, to, len(logs))
+	return nil
+}
+
+// processBlock delivers the logs of block @number after checking that it extends the last processed block.
+func (idx *Indexer) processBlock(ctx context.Context, number uint64) error {
+	header, err := idx.chain.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return err
+	}
+	if idx.state.LastHash != (common.Hash{}) && header.ParentHash != idx.state.LastHash {
+		log.Warnf("indexer %s: reorg detected at block %d", idx.conf.Name, number)
+		return idx.rollback(ctx)
+	}
+
+	hash := header.Hash()
+	logs, err := idx.chain.FilterLogs(ctx, idx.filterQuery(ethereum.FilterQuery{BlockHash: &hash}))
+	if err != nil {
+		return err
+	}
+	record := BlockRecord{Number: number, Hash: hash}
+	for _, l := range logs {
+		if l.Removed {
+			continue
+		}
+		if err := idx.deliver(ctx, l, false); err != nil {
+			return err
+		}
+		record.Logs = append(record.Logs, l)
+	}
+
+	idx.state.Recent = append(idx.state.Recent, record)
+	if uint64(len(idx.state.Recent)) > idx.conf.ReorgWindow {
+		idx.state.Recent = idx.state.Recent[1:]
+	}
+	idx.state.LastBlock = number
+	idx.state.LastHash = hash
+	return nil
+}
+
+// rollback retracts the logs of all recent blocks which are no longer canonical
+// and resets the cursor to the last common ancestor.
+func (idx *Indexer) rollback(ctx context.Context) error {
+	for len(idx.state.Recent) > 0 {
+		last := idx.state.Recent[len(idx.state.Recent)-1]
+		header, err := idx.chain.HeaderByNumber(ctx, new(big.Int).SetUint64(last.Number))
+		if err != nil {
+			return err
+		}
+		if header.Hash() == last.Hash {
+			idx.state.LastBlock = last.Number
+			idx.state.LastHash = last.Hash
+			return nil
+		}
+		for i := len(last.Logs) - 1; i >= 0; i-- {
+			if err := idx.deliver(ctx, last.Logs[i], true); err != nil {
+				return err
+			}
+		}
+		log.Infof("indexer %s: retracted block %d (%s)", idx.conf.Name, last.Number, last.Hash.Hex())
+		idx.state.Recent = idx.state.Recent[:len(idx.state.Recent)-1]
+	}
+	return ErrReorgTooDeep
+}
+
+// filterQuery adds addresses and topics of all registrations to @query.
+func (idx *Indexer) filterQuery(query ethereum.FilterQuery) ethereum.FilterQuery {
+	eventIDs := make(map[common.Hash]struct{})
+	for address, events := range idx.registrations {
+		query.Addresses = append(query.Addresses, address)
+		for id := range events {
+			eventIDs[id] = struct{}{}
+		}
+	}
+	topics := []common.Hash{}
+	for id := range eventIDs {
+		topics = append(topics, id)
+	}
+	query.Topics = [][]common.Hash{topics}
+	return query
+}
+
+// deliver decodes @l and passes it to the registered handler.
+func (idx *Indexer) deliver(ctx context.Context, l types.Log, retract bool) error {
+	if len(l.Topics) == 0 {
+		return nil
+	}
+	reg, ok := idx.registrations[l.Address][l.Topics[0]]
+	if !ok {
+		return nil
+	}
+	event, err := decode(reg, l)
+	if err != nil {
+		log.Errorf("indexer %s: decoding %s in tx %s: %v", idx.conf.Name, reg.event.Name, l.TxHash.Hex(), err)
+		return nil
+	}
+	if retract {
+		return reg.handler.RetractEvent(ctx, event)
+	}
+	return reg.handler.HandleEvent(ctx, event)
+}
+
+func decode(reg registration, l types.Log) (Event, error) {
+	args := make(map[string]interface{})
+	if len(l.Data) > 0 {
+		if err := reg.contractABI.UnpackIntoMap(args, reg.event.Name, l.Data); err != nil {
+			return Event{}, err
+		}
+	}
+	var indexed abi.Arguments
+	for _, arg := range reg.event.Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	if err := abi.ParseTopicsIntoMap(args, indexed, l.Topics[1:]); err != nil {
+		return Event{}, err
+	}
+	return Event{Name: reg.event.Name, Args: args, Log: l}, nil
+}
@@ -0,0 +1,203 @@
+package evmindexer
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/jackc/pgx/v4"
+)
+
+const transferABI = `[{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Transfer","type":"event"}]`
+
+var tokenAddress = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+
+// fakeChain is a chain whose blocks can be replaced to simulate reorganisations.
+type fakeChain struct {
+	headers []*types.Header
+	logs    map[common.Hash][]types.Log
+	event   abi.Event
+}
+
+func newFakeChain(event abi.Event) *fakeChain {
+	genesis := &types.Header{Number: big.NewInt(0)}
+	return &fakeChain{headers: []*types.Header{genesis}, logs: make(map[common.Hash][]types.Log), event: event}
+}
+
+// extend appends a block with one transfer of @value, branching off after block @parent.
+func (fc *fakeChain) extend(parent uint64, value int64) {
+	fc.headers = fc.headers[:parent+1]
+	header := &types.Header{
+		Number:     new(big.Int).SetUint64(parent + 1),
+		ParentHash: fc.headers[parent].Hash(),
+		Extra:      big.NewInt(value).Bytes(),
+	}
+	fc.headers = append(fc.headers, header)
+	data := common.LeftPadBytes(big.NewInt(value).Bytes(), 32)
+	fc.logs[header.Hash()] = []types.Log{{
+		Address:     tokenAddress,
+		Topics:      []common.Hash{fc.event.ID, common.Hash{}, common.Hash{}},
+		Data:        data,
+		BlockNumber: header.Number.Uint64(),
+		BlockHash:   header.Hash(),
+	}}
+}
+
+func (fc *fakeChain) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if number == nil {
+		return fc.headers[len(fc.headers)-1], nil
+	}
+	return fc.headers[number.Uint64()], nil
+}
+
+func (fc *fakeChain) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	if q.BlockHash != nil {
+		return fc.logs[*q.BlockHash], nil
+	}
+	var logs []types.Log
+	for n := q.FromBlock.Uint64(); n <= q.ToBlock.Uint64(); n++ {
+		logs = append(logs, fc.logs[fc.headers[n].Hash()]...)
+	}
+	return logs, nil
+}
+
+type memoryStore struct {
+	state *State
+}
+
+func (ms *memoryStore) GetScraperState(ctx context.Context, name string, state models.ScraperState) error {
+	if ms.state == nil {
+		return pgx.ErrNoRows
+	}
+	*state.(*State) = *ms.state
+	return nil
+}
+
+func (ms *memoryStore) SetScraperState(ctx context.Context, name string, state models.ScraperState) error {
+	s := *state.(*State)
+	ms.state = &s
+	return nil
+}
+
+type recordingHandler struct {
+	handled   []int64
+	retracted []int64
+}
+
+func (rh *recordingHandler) HandleEvent(ctx context.Context, event Event) error {
+	rh.handled = append(rh.handled, event.Args["value"].(*big.Int).Int64())
+	return nil
+}
+
+func (rh *recordingHandler) RetractEvent(ctx context.Context, event Event) error {
+	rh.retracted = append(rh.retracted, event.Args["value"].(*big.Int).Int64())
+	return nil
+}
+
+func runUntilSynced(t *testing.T, idx *Indexer) {
+	for i := 0; i < 100; i++ {
+		synced, err := idx.Step(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if synced {
+			return
+		}
+	}
+	t.Fatal("indexer did not sync")
+}
+
+func TestIndexerReorg(t *testing.T) {
+	contractABI, err := abi.JSON(strings.NewReader(transferABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := newFakeChain(contractABI.Events["Transfer"])
+	for n := uint64(0); n < 10; n++ {
+		chain.extend(n, int64(n+1))
+	}
+
+	handler := &recordingHandler{}
+	idx := New(Config{Name: "test", StartBlock: 1, ReorgWindow: 4, BatchSize: 3}, chain, &memoryStore{})
+	if err := idx.Register(tokenAddress, contractABI, "Transfer", handler); err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.loadState(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	runUntilSynced(t, idx)
+	if len(handler.handled) != 10 {
+		t.Fatalf("expected 10 handled events, got %v", handler.handled)
+	}
+
+	// Replace blocks 9 and 10 by a longer fork.
+	chain.extend(8, 90)
+	chain.extend(9, 100)
+	chain.extend(10, 110)
+	runUntilSynced(t, idx)
+
+	if len(handler.retracted) != 2 || handler.retracted[0] != 10 || handler.retracted[1] != 9 {
+		t.Errorf("expected retraction of 10 and 9, got %v", handler.retracted)
+	}
+	expected := []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 90, 100, 110}
+	if len(handler.handled) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, handler.handled)
+	}
+	for i := range expected {
+		if handler.handled[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected, handler.handled)
+			break
+		}
+	}
+	if state := idx.State(); state.LastBlock != 11 || state.LastHash != chain.headers[11].Hash() {
+		t.Errorf("unexpected cursor %d %s", state.LastBlock, state.LastHash.Hex())
+	}
+}
+
+func TestIndexerReorgAtBatchBoundary(t *testing.T) {
+	contractABI, err := abi.JSON(strings.NewReader(transferABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := newFakeChain(contractABI.Events["Transfer"])
+	for n := uint64(0); n < 10; n++ {
+		chain.extend(n, int64(n+1))
+	}
+
+	handler := &recordingHandler{}
+	idx := New(Config{Name: "test", StartBlock: 1, ReorgWindow: 4, BatchSize: 3}, chain, &memoryStore{})
+	if err := idx.Register(tokenAddress, contractABI, "Transfer", handler); err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.loadState(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// Batches of blocks 1 - 3 and 4 - 6, followed by block 7.
+	for i := 0; i < 3; i++ {
+		if _, err := idx.Step(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if state := idx.State(); state.LastBlock != 7 || len(state.Recent) != 4 {
+		t.Fatalf("unexpected state after block %d with %d recent blocks", state.LastBlock, len(state.Recent))
+	}
+
+	// Replace block 7, the first block processed on its own.
+	chain.extend(6, 70)
+	chain.extend(7, 80)
+	if err := idx.Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(handler.retracted) != 1 || handler.retracted[0] != 7 {
+		t.Errorf("expected retraction of 7, got %v", handler.retracted)
+	}
+	if state := idx.State(); state.LastBlock != 8 || state.LastHash != chain.headers[8].Hash() {
+		t.Errorf("unexpected cursor %d %s", state.LastBlock, state.LastHash.Hex())
+	}
+}
@@ -20,6 +20,7 @@ const (
 
 // DeribitScraper - used in conjunction with the DeribitScraperKind in a new struct to define futures and options scrapers
 type DeribitScraper struct {
+	futuresChannels
 	Markets					[]string
 	WaitGroup				*sync.WaitGroup
 	Logger					*zap.SugaredLogger
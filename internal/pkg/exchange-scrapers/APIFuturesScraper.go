@@ -1,11 +1,73 @@
 package scrapers
 
+import (
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
 // FuturesScraper is an interface for all of the Futures Contracts scrapers
 type FuturesScraper interface {
 	Scrape(market string) // a self-sustained goroutine that scrapes a single market
 	ScrapeMarkets()       // will scrape the futures markets defined during instantiation of the scraper
 	ScraperClose(market string, websocketConnection interface{}) error
 	//Authenticate(market string, websocketConnection interface{}) error
+
+	// TradesChannel returns a channel that can be used to receive trades
+	TradesChannel() chan *dia.FuturesTrade
+	// FundingRatesChannel returns a channel that can be used to receive funding rates of perpetual markets
+	FundingRatesChannel() chan *dia.FundingRate
+	// OpenInterestChannel returns a channel that can be used to receive the open interest of markets
+	OpenInterestChannel() chan *dia.OpenInterest
 }
 
 const retryIn uint8 = 5 // how long to wait in seconds before restarting a failed websocket
+
+// futuresChannels is embedded in all futures scrapers and implements the channel methods of FuturesScraper.
+type futuresChannels struct {
+	chanTrades       chan *dia.FuturesTrade
+	chanFundingRates chan *dia.FundingRate
+	chanOpenInterest chan *dia.OpenInterest
+}
+
+func newFuturesChannels() futuresChannels {
+	return futuresChannels{
+		chanTrades:       make(chan *dia.FuturesTrade),
+		chanFundingRates: make(chan *dia.FundingRate),
+		chanOpenInterest: make(chan *dia.OpenInterest),
+	}
+}
+
+// TradesChannel returns the channel on which the scraper emits trades
+func (c *futuresChannels) TradesChannel() chan *dia.FuturesTrade {
+	return c.chanTrades
+}
+
+// FundingRatesChannel returns the channel on which the scraper emits funding rates
+func (c *futuresChannels) FundingRatesChannel() chan *dia.FundingRate {
+	return c.chanFundingRates
+}
+
+// OpenInterestChannel returns the channel on which the scraper emits the open interest
+func (c *futuresChannels) OpenInterestChannel() chan *dia.OpenInterest {
+	return c.chanOpenInterest
+}
+
+// NewFuturesScraper returns a futures scraper for @markets on @exchange. @key and @secret are
+// only required by Deribit.
+func NewFuturesScraper(exchange string, markets []string, key string, secret string) FuturesScraper {
+	switch exchange {
+	case dia.BitmexExchange:
+		return NewBitmexFuturesScraper(markets)
+	case dia.BitflyerExchange:
+		return NewBitflyerFuturesScraper(markets)
+	case dia.CoinflexExchange:
+		return NewCoinflexFuturesScraper(markets)
+	case dia.Deribit:
+		return NewDeribitFuturesScraper(markets, key, secret)
+	case dia.FTX:
+		return NewFTXFuturesScraper(markets)
+	case dia.HuobiExchange:
+		return NewHuobiFuturesScraper(markets)
+	default:
+		return nil
+	}
+}
@@ -1,42 +1,56 @@
 package scrapers
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
 	zap "go.uber.org/zap"
 
-	writers "github.com/diadata-org/diadata/internal/pkg/scraper-writers"
+	"github.com/diadata-org/diadata/pkg/dia"
 	"github.com/gorilla/websocket"
 )
 
-const scrapeDataSaveLocationBitflyer = ""
-
 // BitflyerScraper - use the NewBitflyerFuturesScraper function to create an instance
 type BitflyerScraper struct {
+	futuresChannels
 	Markets   []string
 	WaitGroup *sync.WaitGroup
-	Writer    writers.Writer
 	Logger    *zap.SugaredLogger
 }
 
+type executionsMessageBitflyer struct {
+	Method string `json:"method"`
+	Params struct {
+		Channel string `json:"channel"`
+		Message []struct {
+			ID       int64     `json:"id"`
+			Side     string    `json:"side"`
+			Price    float64   `json:"price"`
+			Size     float64   `json:"size"`
+			ExecDate time.Time `json:"exec_date"`
+		} `json:"message"`
+	} `json:"params"`
+}
+
 // NewBitflyerFuturesScraper - returns an instance of an options scraper.
 func NewBitflyerFuturesScraper(markets []string) FuturesScraper {
 	wg := sync.WaitGroup{}
-	writer := writers.FileWriter{}
 	logger := zap.NewExample().Sugar() // or NewProduction, or NewDevelopment
 	defer logger.Sync()
 
 	var scraper FuturesScraper = &BitflyerScraper{
-		WaitGroup: &wg,
-		Markets:   markets,
-		Writer:    &writer,
-		Logger:    logger,
+		futuresChannels: newFuturesChannels(),
+		WaitGroup:       &wg,
+		Markets:         markets,
+		Logger:          logger,
 	}
 
 	return scraper
@@ -64,7 +78,7 @@ func (s *BitflyerScraper) ScraperClose(market string, connection interface{}) er
 	switch c := connection.(type) {
 	case *websocket.Conn:
 		// unsubscribe from the channel
-		err := s.send(&map[string]interface{}{"jsonrpc": "2.0", "method": "unsubscribe", "params": &map[string]interface{}{"channel": "lightning_ticker_" + market}}, market, c)
+		err := s.send(&map[string]interface{}{"jsonrpc": "2.0", "method": "unsubscribe", "params": &map[string]interface{}{"channel": "lightning_executions_" + market}}, market, c)
 		if err != nil {
 			s.Logger.Errorf("could not send a channel unsubscription message, err: %s", err)
 			return err
@@ -114,7 +128,7 @@ func (s *BitflyerScraper) Scrape(market string) {
 				s.Logger.Debugf("received a pong frame")
 				return nil
 			})
-			err = s.send(&map[string]interface{}{"jsonrpc": "2.0", "method": "subscribe", "params": &map[string]interface{}{"channel": "lightning_ticker_" + market}}, market, ws)
+			err = s.send(&map[string]interface{}{"jsonrpc": "2.0", "method": "subscribe", "params": &map[string]interface{}{"channel": "lightning_executions_" + market}}, market, ws)
 			if err != nil {
 				s.Logger.Errorf("could not send a channel subscription message. retrying, err: %s", err)
 				return
@@ -146,11 +160,10 @@ func (s *BitflyerScraper) Scrape(market string) {
 						s.Logger.Errorf("repeated read error, restarting")
 						return
 					}
-					s.Logger.Debugf("received new message: %s, saving new message", message)
-					_, err = s.Writer.Write(string(message)+"\n", scrapeDataSaveLocationBitflyer+s.Writer.GetWriteFileName("Bitflyer", market))
+					s.Logger.Debugf("received new message: %s", message)
+					err = s.handleExecutions(market, message)
 					if err != nil {
-						s.Logger.Errorf("could not write to file, err: %s", err)
-						return
+						s.Logger.Errorf("could not parse message on [%s], err: %s", market, err)
 					}
 				}
 			}
@@ -158,6 +171,51 @@ func (s *BitflyerScraper) Scrape(market string) {
 	}
 }
 
+// handleExecutions emits the executions in @message on the trades channel
+func (s *BitflyerScraper) handleExecutions(market string, message []byte) error {
+	msg := executionsMessageBitflyer{}
+	err := json.Unmarshal(message, &msg)
+	if err != nil {
+		return err
+	}
+	if msg.Method != "channelMessage" {
+		return nil
+	}
+	expiry := bitflyerExpiry(market)
+	for _, e := range msg.Params.Message {
+		volume := e.Size
+		if e.Side == "SELL" {
+			volume = -volume
+		}
+		s.chanTrades <- &dia.FuturesTrade{
+			Exchange:       dia.BitflyerExchange,
+			Market:         market,
+			Underlying:     strings.TrimPrefix(market, "FX_")[:3],
+			Price:          e.Price,
+			Volume:         volume,
+			Time:           e.ExecDate,
+			ForeignTradeID: strconv.FormatInt(e.ID, 10),
+			Perpetual:      expiry.IsZero(),
+			Expiry:         expiry,
+		}
+	}
+	return nil
+}
+
+// bitflyerExpiry returns the expiry date of dated futures such as BTCJPY27DEC2019, and
+// the zero time for other markets such as FX_BTC_JPY.
+func bitflyerExpiry(market string) time.Time {
+	if len(market) < 9 {
+		return time.Time{}
+	}
+	date := market[len(market)-9:]
+	expiry, err := time.Parse("02Jan2006", date[:3]+strings.ToLower(date[3:5])+date[5:])
+	if err != nil {
+		return time.Time{}
+	}
+	return expiry
+}
+
 // write's primary purpose is to write a ping frame op code to keep the websocket connection alive
 func (s *BitflyerScraper) write(mt int, payload []byte, ws *websocket.Conn) error {
 	ws.SetWriteDeadline(time.Now().Add(15 * time.Second))
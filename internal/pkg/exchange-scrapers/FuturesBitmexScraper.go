@@ -1,42 +1,77 @@
 package scrapers
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"os"
 	"os/signal"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
 	zap "go.uber.org/zap"
 
+	"github.com/diadata-org/diadata/pkg/dia"
 	"github.com/gorilla/websocket"
-	writers "github.com/diadata-org/diadata/internal/pkg/scraper-writers"
 )
 
-const scrapeDataSaveLocationBitmex = ""
+// tables of the Bitmex websocket we subscribe to for each market
+var tablesBitmex = []string{"trade", "funding", "instrument"}
 
 // BitmexScraper - use the NewBitmexFuturesScraper function to create an instance
 type BitmexScraper struct {
+	futuresChannels
 	Markets   []string
 	WaitGroup *sync.WaitGroup
-	Writer    writers.Writer
 	Logger    *zap.SugaredLogger
 }
 
+type bitmexMessage struct {
+	Table  string          `json:"table"`
+	Action string          `json:"action"`
+	Data   json.RawMessage `json:"data"`
+}
+
+type bitmexTrade struct {
+	Timestamp    time.Time `json:"timestamp"`
+	Symbol       string    `json:"symbol"`
+	Side         string    `json:"side"`
+	Price        float64   `json:"price"`
+	HomeNotional float64   `json:"homeNotional"` // traded amount of the underlying
+	TrdMatchID   string    `json:"trdMatchID"`
+}
+
+type bitmexFunding struct {
+	Timestamp       time.Time `json:"timestamp"`
+	Symbol          string    `json:"symbol"`
+	FundingInterval time.Time `json:"fundingInterval"` // given as offset to 2000-01-01
+	FundingRate     float64   `json:"fundingRate"`
+}
+
+// instrument updates only contain the fields that changed, hence the pointers
+type bitmexInstrument struct {
+	Timestamp        time.Time  `json:"timestamp"`
+	Symbol           string     `json:"symbol"`
+	Underlying       string     `json:"underlying"`
+	Expiry           *time.Time `json:"expiry"`
+	OpenInterest     *float64   `json:"openInterest"`
+	FundingRate      *float64   `json:"fundingRate"`
+	FundingTimestamp *time.Time `json:"fundingTimestamp"`
+}
+
 // NewBitmexFuturesScraper - returns an instance of an options scraper.
 func NewBitmexFuturesScraper(markets []string) FuturesScraper {
 	wg := sync.WaitGroup{}
-	writer := writers.FileWriter{}
 	logger := zap.NewExample().Sugar() // or NewProduction, or NewDevelopment
 	defer logger.Sync()
 
 	var scraper FuturesScraper = &BitmexScraper{
-		WaitGroup: &wg,
-		Markets:   markets,
-		Writer:    &writer,
-		Logger:    logger,
+		futuresChannels: newFuturesChannels(),
+		WaitGroup:       &wg,
+		Markets:         markets,
+		Logger:          logger,
 	}
 
 	return scraper
@@ -64,7 +99,7 @@ func (s *BitmexScraper) ScraperClose(market string, connection interface{}) erro
 	switch c := connection.(type) {
 	case *websocket.Conn:
 		// unsubscribe from the channel
-		err := s.send(&map[string]interface{}{"op": "unsubscribe", "args": []string{"trade:" + market}}, market, c)
+		err := s.send(&map[string]interface{}{"op": "unsubscribe", "args": bitmexTopics(market)}, market, c)
 		if err != nil {
 			s.Logger.Errorf("could not send a channel unsubscription message, err: %s", err)
 			return err
@@ -114,11 +149,13 @@ func (s *BitmexScraper) Scrape(market string) {
 				s.Logger.Debugf("received a pong frame")
 				return nil
 			})
-			err = s.send(&map[string]interface{}{"op": "subscribe", "args": []string{"trade:" + market}}, market, ws)
+			err = s.send(&map[string]interface{}{"op": "subscribe", "args": bitmexTopics(market)}, market, ws)
 			if err != nil {
 				s.Logger.Errorf("could not send a channel subscription message. retrying, err: %s", err)
 				return
 			}
+			// Trades of dated futures are completed with the expiry from the instrument table.
+			var expiry time.Time
 			tick := time.NewTicker(15 * time.Second)
 			defer tick.Stop()
 			go func() {
@@ -146,11 +183,10 @@ func (s *BitmexScraper) Scrape(market string) {
 						s.Logger.Errorf("repeated read error, restarting")
 						return
 					}
-					s.Logger.Debugf("received new message: %s, saving new message", message)
-					_, err = s.Writer.Write(string(message)+"\n", scrapeDataSaveLocationBitmex+s.Writer.GetWriteFileName("Bitmex", market))
+					s.Logger.Debugf("received new message: %s", message)
+					err = s.handleMessage(message, &expiry)
 					if err != nil {
-						s.Logger.Errorf("could not write to file, err: %s", err)
-						return
+						s.Logger.Errorf("could not parse message on [%s], err: %s", market, err)
 					}
 				}
 			}
@@ -158,6 +194,108 @@ func (s *BitmexScraper) Scrape(market string) {
 	}
 }
 
+// handleMessage emits the trades, funding rates and open interest in @message on the scraper's channels.
+// @expiry is updated from instrument messages and used for the trades.
+func (s *BitmexScraper) handleMessage(message []byte, expiry *time.Time) error {
+	msg := bitmexMessage{}
+	err := json.Unmarshal(message, &msg)
+	if err != nil {
+		return err
+	}
+	if len(msg.Data) == 0 || (msg.Action != "partial" && msg.Action != "insert" && msg.Action != "update") {
+		// subscription confirmations and other control messages
+		return nil
+	}
+	switch msg.Table {
+	case "trade":
+		if msg.Action == "partial" {
+			// the snapshot of recent trades has been emitted before a reconnect
+			return nil
+		}
+		trades := []bitmexTrade{}
+		err = json.Unmarshal(msg.Data, &trades)
+		if err != nil {
+			return err
+		}
+		for _, t := range trades {
+			volume := t.HomeNotional
+			if t.Side == "Sell" {
+				volume = -volume
+			}
+			s.chanTrades <- &dia.FuturesTrade{
+				Exchange:       dia.BitmexExchange,
+				Market:         t.Symbol,
+				Underlying:     bitmexUnderlying(t.Symbol),
+				Price:          t.Price,
+				Volume:         volume,
+				Time:           t.Timestamp,
+				ForeignTradeID: t.TrdMatchID,
+				Perpetual:      expiry.IsZero(),
+				Expiry:         *expiry,
+			}
+		}
+	case "funding":
+		fundings := []bitmexFunding{}
+		err = json.Unmarshal(msg.Data, &fundings)
+		if err != nil {
+			return err
+		}
+		for _, f := range fundings {
+			s.chanFundingRates <- &dia.FundingRate{
+				Exchange:    dia.BitmexExchange,
+				Market:      f.Symbol,
+				Underlying:  bitmexUnderlying(f.Symbol),
+				Rate:        f.FundingRate,
+				Interval:    f.FundingInterval.Sub(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)),
+				FundingTime: f.Timestamp,
+				Time:        time.Now(),
+			}
+		}
+	case "instrument":
+		instruments := []bitmexInstrument{}
+		err = json.Unmarshal(msg.Data, &instruments)
+		if err != nil {
+			return err
+		}
+		for _, i := range instruments {
+			if i.Expiry != nil {
+				*expiry = *i.Expiry
+			}
+			if i.OpenInterest != nil {
+				s.chanOpenInterest <- &dia.OpenInterest{
+					Exchange:   dia.BitmexExchange,
+					Market:     i.Symbol,
+					Underlying: bitmexUnderlying(i.Symbol),
+					Value:      *i.OpenInterest,
+					Time:       i.Timestamp,
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// bitmexTopics returns the subscription topics of @market
+func bitmexTopics(market string) []string {
+	topics := []string{}
+	for _, table := range tablesBitmex {
+		topics = append(topics, table+":"+market)
+	}
+	return topics
+}
+
+// bitmexUnderlying returns the symbol of the underlying of a Bitmex market such as XBTUSD or ETHH20
+func bitmexUnderlying(market string) string {
+	if len(market) < 3 {
+		return market
+	}
+	underlying := strings.ToUpper(market[:3])
+	if underlying == "XBT" {
+		return "BTC"
+	}
+	return underlying
+}
+
 // write's primary purpose is to write a ping frame op code to keep the websocket connection alive
 func (s *BitmexScraper) write(mt int, payload []byte, ws *websocket.Conn) error {
 	ws.SetWriteDeadline(time.Now().Add(15 * time.Second))
@@ -11,19 +11,17 @@ import (
 	"syscall"
 	"time"
 
-	writers "github.com/diadata-org/diadata/internal/pkg/scraper-writers"
+	"github.com/diadata-org/diadata/pkg/dia"
 	utils "github.com/diadata-org/diadata/pkg/utils"
 	"github.com/gorilla/websocket"
 	zap "go.uber.org/zap"
 )
 
-const scrapeDataSaveLocationCoinflex = ""
-
 // CoinflexFuturesScraper - scrapes the futures from the Coinflex exchange
 type CoinflexFuturesScraper struct {
+	futuresChannels
 	Markets   []string
 	WaitGroup *sync.WaitGroup
-	Writer    writers.Writer
 	Logger    *zap.SugaredLogger
 }
 
@@ -85,15 +83,14 @@ type ordersMatchedCoinflex struct {
 // NewCoinflexFuturesScraper - returns an instance of the coinflex scraper
 func NewCoinflexFuturesScraper(markets []string) FuturesScraper {
 	wg := sync.WaitGroup{}
-	writer := writers.FileWriter{}
 	logger := zap.NewExample().Sugar() // or NewProduction, or NewDevelopment
 	defer logger.Sync()
 
 	var scraper FuturesScraper = &CoinflexFuturesScraper{
-		WaitGroup: &wg,
-		Markets:   markets,
-		Writer:    &writer,
-		Logger:    logger,
+		futuresChannels: newFuturesChannels(),
+		WaitGroup:       &wg,
+		Markets:         markets,
+		Logger:          logger,
 	}
 
 	return scraper
@@ -136,21 +133,22 @@ func (s *CoinflexFuturesScraper) ScraperClose(market string, connection interfac
 
 // Scrape starts a websocket scraper for market
 func (s *CoinflexFuturesScraper) Scrape(market string) {
-	validated, err := s.validateMarket(market)
-	if !validated || err != nil {
+	marketInfo, err := s.validateMarket(market)
+	if marketInfo == nil || err != nil {
 		s.Logger.Errorf("could not validate %s market", market)
 		if err != nil {
 			s.Logger.Errorf("issue with validating, err: %s", err)
 		}
 		return
 	}
-	baseID, quoteID, err := s.getBaseAndCounterID(market)
+	base, quote, err := s.getBaseAndCounter(market)
 	// splits the string market into the base and the counter and then finds the int id of them.
 	// coinflex expects that we provide an int for the assets when we make the websocket requests.
 	if err != nil {
 		s.Logger.Errorf("issue with getting an id for base and quote: %s", err)
 		return
 	}
+	baseID, quoteID := base.ID, quote.ID
 
 	// this block is for listening to sigterms and interupts
 	sigs := make(chan os.Signal, 1)
@@ -228,11 +226,7 @@ func (s *CoinflexFuturesScraper) Scrape(market string) {
 					s.Logger.Debugf("received a message: %s", message)
 					if msg.Notice == "OrdersMatched" {
 						s.Logger.Debugf("received new match message on [%s]: %s", market, message)
-						_, err = s.Writer.Write(string(message)+"\n", scrapeDataSaveLocationCoinflex+s.Writer.GetWriteFileName("coinflex", market))
-						if err != nil {
-							s.Logger.Errorf("could not save to file: %s, on market: [%s], err: %s", scrapeDataSaveLocationCoinflex+s.Writer.GetWriteFileName("coinflex", market), market, err)
-							return
-						}
+						s.chanTrades <- s.normalizeTrade(market, msg, marketInfo, base, quote)
 					}
 				}
 			}
@@ -255,38 +249,68 @@ func (s *CoinflexFuturesScraper) ScrapeMarkets() {
 	s.WaitGroup.Wait()
 }
 
-func (s *CoinflexFuturesScraper) getBaseAndCounterID(market string) (int64, int64, error) {
+// normalizeTrade converts a match on @market into a futures trade. Coinflex sends integer amounts which
+// are scaled by the scale of the base asset (quantity) and of the counter asset (price).
+func (s *CoinflexFuturesScraper) normalizeTrade(market string, msg ordersMatchedCoinflex, marketInfo *marketCoinflex, base assetCoinflex, quote assetCoinflex) *dia.FuturesTrade {
+	volume := float64(msg.Quantity) / float64(base.Scale)
+	if msg.BidRem == 0 && msg.AskRem != 0 {
+		// the bid was a market sell order
+		volume = -volume
+	}
+	underlying := base.SpotName
+	if underlying == "XBT" {
+		underlying = "BTC"
+	}
+	trade := &dia.FuturesTrade{
+		Exchange:   dia.CoinflexExchange,
+		Market:     market,
+		Underlying: underlying,
+		Price:      float64(msg.Price) / float64(quote.Scale),
+		Volume:     volume,
+		// times are given in microseconds
+		Time:           time.Unix(0, msg.Time*1e3),
+		ForeignTradeID: fmt.Sprintf("%d-%d", msg.Bid, msg.Ask),
+		Perpetual:      marketInfo.Expires == 0,
+	}
+	if !trade.Perpetual {
+		trade.Expiry = time.Unix(0, marketInfo.Expires*1e3)
+	}
+	return trade
+}
+
+func (s *CoinflexFuturesScraper) getBaseAndCounter(market string) (assetCoinflex, assetCoinflex, error) {
 	assets := strings.Split(market, "/")
-	var baseID int64 = 0
-	var quoteID int64 = 0
-	base := assets[0]
-	quote := assets[1] // coinflex call this "counter"
-	baseID, err := s.assetID(base)
+	if len(assets) != 2 {
+		return assetCoinflex{}, assetCoinflex{}, fmt.Errorf("market %s should be of the form base/counter", market)
+	}
+	base, err := s.asset(assets[0])
 	if err != nil {
-		return baseID, quoteID, err
+		return assetCoinflex{}, assetCoinflex{}, err
 	}
-	quoteID, err = s.assetID(quote)
+	quote, err := s.asset(assets[1]) // coinflex call this "counter"
 	if err != nil {
-		return baseID, quoteID, err
+		return assetCoinflex{}, assetCoinflex{}, err
+	}
+	if base.Scale == 0 || quote.Scale == 0 {
+		return assetCoinflex{}, assetCoinflex{}, fmt.Errorf("unknown asset in market %s", market)
 	}
-	return baseID, quoteID, nil
+	return base, quote, nil
 }
 
-// ensures that market available to trade
-func (s *CoinflexFuturesScraper) validateMarket(market string) (bool, error) {
+// ensures that market available to trade and returns it, or nil if it is not available
+func (s *CoinflexFuturesScraper) validateMarket(market string) (*marketCoinflex, error) {
 	// should validate that there is an available market
-	marketAvailable := false
 	marketsCoinflex, err := s.availableMarketsCoinflex()
-	fmt.Printf("[DEBUG] all coinflex's available markets are: %v\n", marketsCoinflex)
+	s.Logger.Debugf("all coinflex's available markets are: %v", marketsCoinflex)
 	if err != nil {
-		return marketAvailable, err
+		return nil, err
 	}
 	for _, availableMarket := range marketsCoinflex {
 		if availableMarket.Name == market {
-			marketAvailable = true
+			return &availableMarket, nil
 		}
 	}
-	return marketAvailable, nil
+	return nil, nil
 }
 
 func (s *CoinflexFuturesScraper) availableMarketsCoinflex() ([]marketCoinflex, error) {
@@ -318,17 +342,16 @@ func (s *CoinflexFuturesScraper) getAllAssets() ([]assetCoinflex, error) {
 	return assets, nil
 }
 
-// gives you the id of the asset. Asset can be, not limited to, ETH, XBTJUL, BTCDEC, etc.
-func (s *CoinflexFuturesScraper) assetID(asset string) (int64, error) {
-	var assetsID int64 = 0
+// gives you the asset with its id and scale. Asset can be, not limited to, ETH, XBTJUL, BTCDEC, etc.
+func (s *CoinflexFuturesScraper) asset(asset string) (assetCoinflex, error) {
 	assets, err := s.getAllAssets()
 	if err != nil {
-		return assetsID, fmt.Errorf("could not retrieve all Coinflex's assets, err: %s", err)
+		return assetCoinflex{}, fmt.Errorf("could not retrieve all Coinflex's assets, err: %s", err)
 	}
 	for _, assetObj := range assets {
 		if assetObj.Name == asset {
-			assetsID = assetObj.ID
+			return assetObj, nil
 		}
 	}
-	return assetsID, nil
+	return assetCoinflex{}, nil
 }
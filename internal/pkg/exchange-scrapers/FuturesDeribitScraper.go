@@ -4,15 +4,20 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/diadata-org/diadata/pkg/dia"
 	utils "github.com/diadata-org/diadata/pkg/utils"
 	"github.com/gorilla/websocket"
 	zap "go.uber.org/zap"
 )
 
-const scrapeDataSaveLocationDeribit = ""
+// funding rates and open interest are taken from the ticker, which is published every 100ms,
+// but are only emitted once per fundingEveryDeribit
+const fundingEveryDeribit = time.Minute
 
 type deribitRefreshMessage struct {
 	Result struct {
@@ -38,6 +43,30 @@ type ParsedDeribitResponseParams struct {
 	Data    ParsedDeribitOptionOrderbookEntry `json:"data"`
 }
 
+type deribitFuturesMessage struct {
+	Method string `json:"method"`
+	Params struct {
+		Channel string          `json:"channel"`
+		Data    json.RawMessage `json:"data"`
+	} `json:"params"`
+}
+
+type deribitFuturesTrade struct {
+	TradeID        string  `json:"trade_id"`
+	Timestamp      int64   `json:"timestamp"`
+	InstrumentName string  `json:"instrument_name"`
+	Price          float64 `json:"price"`
+	Amount         float64 `json:"amount"` // in USD
+	Direction      string  `json:"direction"`
+}
+
+type deribitFuturesTicker struct {
+	Timestamp      int64    `json:"timestamp"`
+	InstrumentName string   `json:"instrument_name"`
+	OpenInterest   float64  `json:"open_interest"`
+	Funding8h      *float64 `json:"funding_8h"` // only set on perpetual markets
+}
+
 type ParsedDeribitOptionOrderbookEntry struct {
 	Timestamp      int64       `json:"timestamp"`
 	InstrumentName string      `json:"instrument_name"`
@@ -53,9 +82,10 @@ func NewDeribitFuturesScraper(markets []string, accessKey string, accessSecret s
 	defer logger.Sync()
 
 	var scraper DeribitScraper = DeribitScraper{
-		WaitGroup: &wg,
-		Markets:   markets, // e.g. []string{"BTC-PERPETUAL", "ETH-PERPETUAL"}
-		Logger:    logger,
+		futuresChannels: newFuturesChannels(),
+		WaitGroup:       &wg,
+		Markets:         markets, // e.g. []string{"BTC-PERPETUAL", "ETH-PERPETUAL"}
+		Logger:          logger,
 
 		AccessKey:    accessKey,
 		AccessSecret: accessSecret,
@@ -80,9 +110,21 @@ func (s *DeribitScraper) send(message *map[string]interface{}, websocketConn *we
 func (s *DeribitScraper) ScraperClose(market string, websocketConnection interface{}) error {
 	switch c := websocketConnection.(type) {
 	case *websocket.Conn:
-		err := c.WriteJSON(map[string]string{"op": "unsubscribe", "channel": "trades", "market": market})
-		if err != nil {
-			return err
+		if s.MarketKind == DeribitFuture {
+			// futures markets have their own connection
+			err := s.send(deribitFuturesRequest("public/unsubscribe", market), c)
+			if err != nil {
+				return err
+			}
+			err = c.Close()
+			if err != nil {
+				return err
+			}
+		} else {
+			err := c.WriteJSON(map[string]string{"op": "unsubscribe", "channel": "trades", "market": market})
+			if err != nil {
+				return err
+			}
 		}
 		log.Infof("gracefully shutdown deribit scraper on market: %s", market)
 		time.Sleep(time.Duration(retryIn) * time.Second)
@@ -108,18 +150,11 @@ func (s *DeribitScraper) Scrape(market string) {
 		"jsonrpc": "2.0",
 		"id":      0,
 	}
-	futureRequest := &map[string]interface{}{
-		"method": "private/subscribe",
-		"params": &map[string]interface{}{
-			"channels": []string{"trades." + market + ".raw"},
-		},
-		"jsonrpc": "2.0",
-		"id":      0,
-	}
 
 	switch s.MarketKind {
 	case DeribitFuture:
-		err = s.send(futureRequest, s.WsConnection)
+		s.scrapeFutures(market)
+		return
 	case DeribitOption:
 		err = s.send(optionRequest, s.WsConnection)
 	default:
@@ -132,6 +167,149 @@ func (s *DeribitScraper) Scrape(market string) {
 	}
 }
 
+// scrapeFutures subscribes to the trades and the ticker of the futures @market on a websocket
+// connection of its own and emits them on the scraper's channels.
+func (s *DeribitScraper) scrapeFutures(market string) {
+	expiry := deribitExpiry(market)
+	var lastFunding time.Time
+	for {
+		// immediately invoked function expression for easy clenup with defer
+		func() {
+			u := url.URL{Scheme: "wss", Host: "www.deribit.com", Path: "/ws/api/v2/"}
+			ws, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+			if err != nil {
+				log.Errorf("could not dial the websocket: %s", err)
+				time.Sleep(time.Duration(retryIn) * time.Second)
+				return
+			}
+			defer s.ScraperClose(market, ws)
+			err = s.send(deribitFuturesRequest("public/subscribe", market), ws)
+			if err != nil {
+				log.Errorf("could not send ws message. restarting the websocket, err: %s", err)
+				return
+			}
+			for {
+				_, message, err := ws.ReadMessage()
+				if err != nil {
+					log.Errorf("problem reading deribit on [%s], err: %s", market, err)
+					return
+				}
+				msg := deribitFuturesMessage{}
+				err = json.Unmarshal(message, &msg)
+				if err != nil {
+					log.Errorf("problem unmarshalling the message: %s, err: %s", message, err)
+					continue
+				}
+				if msg.Method != "subscription" {
+					continue
+				}
+				switch strings.Split(msg.Params.Channel, ".")[0] {
+				case "trades":
+					err = s.handleFuturesTrades(msg.Params.Data, expiry)
+				case "ticker":
+					if time.Since(lastFunding) < fundingEveryDeribit {
+						continue
+					}
+					lastFunding = time.Now()
+					err = s.handleFuturesTicker(msg.Params.Data)
+				}
+				if err != nil {
+					log.Errorf("problem parsing the message: %s, err: %s", message, err)
+				}
+			}
+		}()
+	}
+}
+
+func (s *DeribitScraper) handleFuturesTrades(data json.RawMessage, expiry time.Time) error {
+	trades := []deribitFuturesTrade{}
+	err := json.Unmarshal(data, &trades)
+	if err != nil {
+		return err
+	}
+	for _, t := range trades {
+		if t.Price == 0 {
+			continue
+		}
+		// amounts of futures are given in USD
+		volume := t.Amount / t.Price
+		if t.Direction == "sell" {
+			volume = -volume
+		}
+		s.chanTrades <- &dia.FuturesTrade{
+			Exchange:       dia.Deribit,
+			Market:         t.InstrumentName,
+			Underlying:     strings.Split(t.InstrumentName, "-")[0],
+			Price:          t.Price,
+			Volume:         volume,
+			Time:           time.Unix(0, t.Timestamp*1e6),
+			ForeignTradeID: t.TradeID,
+			Perpetual:      expiry.IsZero(),
+			Expiry:         expiry,
+		}
+	}
+	return nil
+}
+
+func (s *DeribitScraper) handleFuturesTicker(data json.RawMessage) error {
+	ticker := deribitFuturesTicker{}
+	err := json.Unmarshal(data, &ticker)
+	if err != nil {
+		return err
+	}
+	timestamp := time.Unix(0, ticker.Timestamp*1e6)
+	underlying := strings.Split(ticker.InstrumentName, "-")[0]
+	if ticker.Funding8h != nil {
+		s.chanFundingRates <- &dia.FundingRate{
+			Exchange:   dia.Deribit,
+			Market:     ticker.InstrumentName,
+			Underlying: underlying,
+			Rate:       *ticker.Funding8h,
+			Interval:   8 * time.Hour,
+			// funding is paid continuously on Deribit
+			FundingTime: timestamp,
+			Time:        timestamp,
+		}
+	}
+	s.chanOpenInterest <- &dia.OpenInterest{
+		Exchange:   dia.Deribit,
+		Market:     ticker.InstrumentName,
+		Underlying: underlying,
+		Value:      ticker.OpenInterest,
+		Time:       timestamp,
+	}
+	return nil
+}
+
+// deribitFuturesRequest returns a (un)subscription request with @method for the public trades and ticker of @market
+func deribitFuturesRequest(method string, market string) *map[string]interface{} {
+	return &map[string]interface{}{
+		"method": method,
+		"params": &map[string]interface{}{
+			"channels": []string{"trades." + market + ".100ms", "ticker." + market + ".100ms"},
+		},
+		"jsonrpc": "2.0",
+		"id":      0,
+	}
+}
+
+// deribitExpiry returns the expiry of dated futures such as BTC-25MAR22, and the zero time for perpetual markets.
+func deribitExpiry(market string) time.Time {
+	parts := strings.Split(market, "-")
+	if len(parts) != 2 || len(parts[1]) < 6 {
+		return time.Time{}
+	}
+	date := parts[1]
+	// the month is given in upper case and the day may have a single digit
+	n := len(date)
+	expiry, err := time.Parse("2Jan06", date[:n-5]+date[n-5:n-4]+strings.ToLower(date[n-4:n-2])+date[n-2:])
+	if err != nil {
+		return time.Time{}
+	}
+	// deribit futures expire at 08:00 UTC
+	return expiry.Add(8 * time.Hour)
+}
+
 // ScrapeMarkets - will scrape the markets specified during instantiation
 func (s *DeribitScraper) ScrapeMarkets() {
 	for _, market := range s.Markets {
@@ -6,11 +6,13 @@ import (
 	"net/url"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
-	writers "github.com/diadata-org/diadata/internal/pkg/scraper-writers"
+	"github.com/diadata-org/diadata/pkg/dia"
 	utils "github.com/diadata-org/diadata/pkg/utils"
 	"github.com/gorilla/websocket"
 	zap "go.uber.org/zap"
@@ -22,32 +24,54 @@ var allFuturesMarketsFTX = []string{"BTC-PERP", "ETH-PERP", "LINK-PERP",
 	"USDT-PERP", "EXCH-PERP", "BTMX-PERP", "ALT-PERP", "ADA-PERP", "MID-PERP",
 	"OKB-PERP", "MATIC-PERP", "ATOM-PERP", "ETC-PERP", "TOMO-PERP", "DOGE-PERP"}
 
-const scrapeDataSaveLocationFTX = ""
+const (
+	statsURLFTX = "https://ftx.com/api/futures/"
+	// FTX publishes neither funding rates nor open interest on the websocket, they are polled instead
+	statsEveryFTX = 5 * time.Minute
+)
 
 // FTXFuturesScraper - scrapes the futures from the FTX exchange
 type FTXFuturesScraper struct {
+	futuresChannels
 	Markets   []string
 	WaitGroup *sync.WaitGroup
-	Writer    writers.Writer
 	Logger    *zap.SugaredLogger
 }
 
 type tradeMessageFTX struct {
-	Type string `json:"type"`
+	Type   string     `json:"type"`
+	Market string     `json:"market"`
+	Data   []tradeFTX `json:"data"`
+}
+
+type tradeFTX struct {
+	ID    int64     `json:"id"`
+	Price float64   `json:"price"`
+	Size  float64   `json:"size"`
+	Side  string    `json:"side"`
+	Time  time.Time `json:"time"`
+}
+
+type statsResponseFTX struct {
+	Success bool `json:"success"`
+	Result  struct {
+		NextFundingRate float64   `json:"nextFundingRate"`
+		NextFundingTime time.Time `json:"nextFundingTime"`
+		OpenInterest    float64   `json:"openInterest"`
+	} `json:"result"`
 }
 
 // NewFTXFuturesScraper - returns an instance of the FTX scraper
 func NewFTXFuturesScraper(markets []string) FuturesScraper {
 	wg := sync.WaitGroup{}
-	writer := writers.FileWriter{}
 	logger := zap.NewExample().Sugar() // or NewProduction, or NewDevelopment
 	defer logger.Sync()
 
 	var scraper FuturesScraper = &FTXFuturesScraper{
-		WaitGroup: &wg,
-		Markets:   markets, // []string{"BNB-PERP", "ETH-PERP", "BTC-PERP", "EOS-PERP"}
-		Writer:    &writer,
-		Logger:    logger,
+		futuresChannels: newFuturesChannels(),
+		WaitGroup:       &wg,
+		Markets:         markets, // []string{"BNB-PERP", "ETH-PERP", "BTC-PERP", "EOS-PERP"}
+		Logger:          logger,
 	}
 
 	return scraper
@@ -101,6 +125,7 @@ func (s *FTXFuturesScraper) Scrape(market string) {
 		fmt.Println(sig)
 		userCancelled <- true
 	}()
+	go s.scrapeStats(market)
 
 	for {
 		// immediately invoked function expression for easy clenup with defer
@@ -156,13 +181,8 @@ func (s *FTXFuturesScraper) Scrape(market string) {
 						return
 					}
 					s.Logger.Debugf("received new message: %s", message)
-					if decodedMsg.Type != "subscribed" && decodedMsg.Type != "pong" && decodedMsg.Type != "unsubscribed" {
-						s.Logger.Debugf("saving new message on [%s]", market)
-						_, err = s.Writer.Write(string(message)+"\n", scrapeDataSaveLocationFTX+s.Writer.GetWriteFileName("ftx", market))
-						if err != nil {
-							s.Logger.Errorf("could not write to file, err: %s", err)
-							return
-						}
+					if decodedMsg.Type == "update" {
+						s.handleTrades(decodedMsg)
 					}
 				}
 			}
@@ -170,6 +190,75 @@ func (s *FTXFuturesScraper) Scrape(market string) {
 	}
 }
 
+// handleTrades emits the trades of an update message on the trades channel
+func (s *FTXFuturesScraper) handleTrades(msg tradeMessageFTX) {
+	for _, t := range msg.Data {
+		volume := t.Size
+		if t.Side == "sell" {
+			volume = -volume
+		}
+		s.chanTrades <- &dia.FuturesTrade{
+			Exchange:       dia.FTX,
+			Market:         msg.Market,
+			Underlying:     strings.Split(msg.Market, "-")[0],
+			Price:          t.Price,
+			Volume:         volume,
+			Time:           t.Time,
+			ForeignTradeID: strconv.FormatInt(t.ID, 10),
+			Perpetual:      strings.HasSuffix(msg.Market, "-PERP"),
+		}
+	}
+}
+
+// scrapeStats periodically emits the funding rate and the open interest of @market
+func (s *FTXFuturesScraper) scrapeStats(market string) {
+	tick := time.NewTicker(statsEveryFTX)
+	defer tick.Stop()
+	for {
+		err := s.emitStats(market)
+		if err != nil {
+			s.Logger.Errorf("could not get ftx stats on [%s], err: %s", market, err)
+		}
+		<-tick.C
+	}
+}
+
+func (s *FTXFuturesScraper) emitStats(market string) error {
+	body, err := utils.GetRequest(statsURLFTX + market + "/stats")
+	if err != nil {
+		return err
+	}
+	stats := statsResponseFTX{}
+	err = json.Unmarshal(body, &stats)
+	if err != nil {
+		return err
+	}
+	if !stats.Success {
+		return fmt.Errorf("unsuccessful response: %s", body)
+	}
+	now := time.Now()
+	underlying := strings.Split(market, "-")[0]
+	if strings.HasSuffix(market, "-PERP") {
+		s.chanFundingRates <- &dia.FundingRate{
+			Exchange:    dia.FTX,
+			Market:      market,
+			Underlying:  underlying,
+			Rate:        stats.Result.NextFundingRate,
+			Interval:    time.Hour,
+			FundingTime: stats.Result.NextFundingTime,
+			Time:        now,
+		}
+	}
+	s.chanOpenInterest <- &dia.OpenInterest{
+		Exchange:   dia.FTX,
+		Market:     market,
+		Underlying: underlying,
+		Value:      stats.Result.OpenInterest,
+		Time:       now,
+	}
+	return nil
+}
+
 // ScrapeMarkets - will scrape the markets specified during instantiation
 func (s *FTXFuturesScraper) ScrapeMarkets() {
 	for _, market := range s.Markets {
@@ -4,16 +4,18 @@ import (
 	"bytes"
 	"compress/gzip"
 	"encoding/binary"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
 	"time"
 
-	writers "github.com/diadata-org/diadata/internal/pkg/scraper-writers"
+	"github.com/diadata-org/diadata/pkg/dia"
 	utils "github.com/diadata-org/diadata/pkg/utils"
 	zap "go.uber.org/zap"
 	"golang.org/x/net/websocket"
@@ -22,18 +24,19 @@ import (
 // --------------------------------- Config --------------------------------------------------
 // Huobi API configuration
 const (
-	scrapeDataSaveLocationHuobi string = "" // location in which to save. ensure that the folder exists.
-	// files will be created automatically
-
 	// API Endpoints
 	marketURLHuobi     string = "https://api.hbdm.com"
 	wsURLHuobi         string = "wss://www.hbdm.com/ws"
 	pingMsgLengthHuobi int    = 22
+
+	// open interest is not published on the websocket, it is polled instead
+	openInterestEveryHuobi = 5 * time.Minute
 )
 
 var (
 	allowedMarketsHuobi     = []string{"BTC", "ETC", "ETH", "EOS", "LTC", "BCH", "XRP", "TRX", "BSV"}
 	allowedFrequenciesHuobi = []string{"CW", "NW", "CQ"}
+	contractTypesHuobi      = map[string]string{"CW": "this_week", "NW": "next_week", "CQ": "quarter"}
 	bufferHuobi             bytes.Buffer
 )
 
@@ -41,26 +44,56 @@ var (
 
 // HuobiFuturesScraper - scrapes huobi's futures markets
 type HuobiFuturesScraper struct {
+	futuresChannels
 	Markets   []string // markets to scrape. To scrape all, call AllFuturesMarketsHuobi()
 	WaitGroup *sync.WaitGroup
-	Writer    writers.Writer // an interface to write the messages
 	Logger    *zap.SugaredLogger
 }
 
+type tradeMessageHuobi struct {
+	Ch   string `json:"ch"`
+	Tick struct {
+		Data []struct {
+			ID        int64   `json:"id"`
+			Amount    float64 `json:"amount"` // number of contracts
+			Price     float64 `json:"price"`
+			Direction string  `json:"direction"`
+			Ts        int64   `json:"ts"`
+		} `json:"data"`
+	} `json:"tick"`
+}
+
+type contractInfoHuobi struct {
+	Status string `json:"status"`
+	Data   []struct {
+		Symbol       string  `json:"symbol"`
+		ContractType string  `json:"contract_type"`
+		ContractSize float64 `json:"contract_size"` // face value of a contract in USD
+		DeliveryDate string  `json:"delivery_date"`
+	} `json:"data"`
+}
+
+type openInterestHuobi struct {
+	Status string `json:"status"`
+	Data   []struct {
+		Amount float64 `json:"amount"` // open interest in the underlying
+	} `json:"data"`
+	Ts int64 `json:"ts"`
+}
+
 // --------------------------------------------------------------------------------------------
 
 // NewHuobiFuturesScraper - returns an instance of the Huobi scraper
 func NewHuobiFuturesScraper(markets []string) FuturesScraper {
 	wg := sync.WaitGroup{}
-	writer := writers.FileWriter{}
 	logger := zap.NewExample().Sugar() // or NewProduction, or NewDevelopment
 	defer logger.Sync()
 
 	var scraper FuturesScraper = &HuobiFuturesScraper{
-		WaitGroup: &wg,
-		Markets:   markets, // []string{"BNB-PERP", "ETH-PERP", "BTC-PERP", "EOS-PERP"}
-		Writer:    &writer,
-		Logger:    logger,
+		futuresChannels: newFuturesChannels(),
+		WaitGroup:       &wg,
+		Markets:         markets, // []string{"BTC_CQ", "ETH_CW"}
+		Logger:          logger,
 	}
 
 	return scraper
@@ -116,6 +149,12 @@ func (s *HuobiFuturesScraper) Scrape(market string) {
 		fmt.Println(sig)
 		userCancelled <- true
 	}()
+	contractSize, expiry, err := s.contractInfo(market)
+	if err != nil {
+		s.Logger.Errorf("[%s] could not get contract info, err: %s", market, err)
+		return
+	}
+	go s.scrapeOpenInterest(market)
 
 	for {
 		// IIFE for easy cleanup with defer
@@ -137,7 +176,7 @@ func (s *HuobiFuturesScraper) Scrape(market string) {
 				return
 			}
 			// create the conduit for the received messages
-			var msg = make([]byte, 512)
+			var msg []byte
 			for {
 				select {
 				case <-userCancelled:
@@ -145,20 +184,19 @@ func (s *HuobiFuturesScraper) Scrape(market string) {
 					s.ScraperClose(market, ws)
 					os.Exit(0)
 				default:
-					m, err := ws.Read(msg)
+					err := websocket.Message.Receive(ws, &msg)
 					if err != nil {
 						s.Logger.Errorf("[%s] %s", market, err)
 						// an error reading means we may have lost the connection
 						// return out and just try again
 						return
 					}
-					newmsg := msg[:m]
-					unzipmsg, err := parseGzip(newmsg)
+					unzipmsg, err := parseGzip(msg)
 					if err != nil {
-						s.Logger.Errorf("[%s] problem saving to %s, err: %s", market, s.Writer.GetWriteFileName("huobi", market), err)
+						s.Logger.Errorf("[%s] problem unzipping message, err: %s", market, err)
 						return
 					}
-					s.Logger.Debugf("[%s] byteLen:%d, unzipLen:%d %s", market, m, len(unzipmsg), unzipmsg)
+					s.Logger.Debugf("[%s] byteLen:%d, unzipLen:%d %s", market, len(msg), len(unzipmsg), unzipmsg)
 					if len(unzipmsg) == pingMsgLengthHuobi {
 						if "ping" == string(unzipmsg[2:6]) {
 							_, err := s.pong(string(unzipmsg[8:21]), market, ws)
@@ -168,11 +206,9 @@ func (s *HuobiFuturesScraper) Scrape(market string) {
 							}
 						}
 					} else {
-						// ensure that scrapeDataSaveLocation exists
-						_, err := s.Writer.Write(string(unzipmsg)+"\n", scrapeDataSaveLocationHuobi+s.Writer.GetWriteFileName("huobi", market))
+						err = s.handleTrades(market, unzipmsg, contractSize, expiry)
 						if err != nil {
-							s.Logger.Errorf("[%s] problem saving to %s, err: %s", market, s.Writer.GetWriteFileName("huobi", market), err)
-							return
+							s.Logger.Errorf("[%s] could not parse message, err: %s", market, err)
 						}
 					}
 				}
@@ -191,6 +227,91 @@ func (s *HuobiFuturesScraper) ScrapeMarkets() {
 	s.WaitGroup.Wait()
 }
 
+// handleTrades emits the trades in @message on the trades channel. Huobi trades a number of
+// contracts with a face value of @contractSize USD, hence the volume in the underlying depends on the price.
+func (s *HuobiFuturesScraper) handleTrades(market string, message []byte, contractSize float64, expiry time.Time) error {
+	msg := tradeMessageHuobi{}
+	err := json.Unmarshal(message, &msg)
+	if err != nil {
+		return err
+	}
+	if msg.Ch == "" {
+		// subscription confirmations
+		return nil
+	}
+	for _, t := range msg.Tick.Data {
+		if t.Price == 0 {
+			continue
+		}
+		volume := t.Amount * contractSize / t.Price
+		if t.Direction == "sell" {
+			volume = -volume
+		}
+		s.chanTrades <- &dia.FuturesTrade{
+			Exchange:       dia.HuobiExchange,
+			Market:         market,
+			Underlying:     strings.Split(market, "_")[0],
+			Price:          t.Price,
+			Volume:         volume,
+			Time:           time.Unix(0, t.Ts*1e6),
+			ForeignTradeID: strconv.FormatInt(t.ID, 10),
+			Expiry:         expiry,
+		}
+	}
+	return nil
+}
+
+// contractInfo returns the face value in USD and the delivery date of the contract traded on @market
+func (s *HuobiFuturesScraper) contractInfo(market string) (float64, time.Time, error) {
+	parts := strings.Split(market, "_")
+	body, err := utils.GetRequest(marketURLHuobi + "/api/v1/contract_contract_info?symbol=" + parts[0] + "&contract_type=" + contractTypesHuobi[parts[1]])
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	info := contractInfoHuobi{}
+	err = json.Unmarshal(body, &info)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	if info.Status != "ok" || len(info.Data) == 0 {
+		return 0, time.Time{}, fmt.Errorf("no contract info for %s", market)
+	}
+	// Huobi delivers at 08:00 UTC
+	expiry, err := time.Parse("20060102", info.Data[0].DeliveryDate)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return info.Data[0].ContractSize, expiry.Add(8 * time.Hour), nil
+}
+
+// scrapeOpenInterest periodically emits the open interest of @market
+func (s *HuobiFuturesScraper) scrapeOpenInterest(market string) {
+	parts := strings.Split(market, "_")
+	tick := time.NewTicker(openInterestEveryHuobi)
+	defer tick.Stop()
+	for {
+		body, err := utils.GetRequest(marketURLHuobi + "/api/v1/contract_open_interest?symbol=" + parts[0] + "&contract_type=" + contractTypesHuobi[parts[1]])
+		if err != nil {
+			s.Logger.Errorf("[%s] could not get open interest, err: %s", market, err)
+		} else {
+			oi := openInterestHuobi{}
+			err = json.Unmarshal(body, &oi)
+			if err != nil || oi.Status != "ok" || len(oi.Data) == 0 {
+				s.Logger.Errorf("[%s] could not parse open interest %s, err: %v", market, body, err)
+			} else {
+				s.chanOpenInterest <- &dia.OpenInterest{
+					Exchange:   dia.HuobiExchange,
+					Market:     market,
+					Underlying: parts[0],
+					Value:      oi.Data[0].Amount,
+					Time:       time.Unix(0, oi.Ts*1e6),
+				}
+			}
+		}
+		<-tick.C
+	}
+}
+
 // ------------- Huobi util functions -------------------
 
 // AllFuturesMarketsHuobi - returns all the futures markets tradable on Huobi.
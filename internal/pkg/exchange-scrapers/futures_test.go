@@ -0,0 +1,332 @@
+package scrapers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+// futuresOutput collects what a parser emitted on its channels, with times in UTC.
+type futuresOutput struct {
+	trades    []dia.FuturesTrade
+	fundings  []dia.FundingRate
+	interests []dia.OpenInterest
+}
+
+func bufferedFuturesChannels() futuresChannels {
+	return futuresChannels{
+		chanTrades:       make(chan *dia.FuturesTrade, 16),
+		chanFundingRates: make(chan *dia.FundingRate, 16),
+		chanOpenInterest: make(chan *dia.OpenInterest, 16),
+	}
+}
+
+func drainFutures(c futuresChannels) futuresOutput {
+	var out futuresOutput
+	for {
+		select {
+		case t := <-c.chanTrades:
+			t.Time, t.Expiry = t.Time.UTC(), t.Expiry.UTC()
+			out.trades = append(out.trades, *t)
+		case f := <-c.chanFundingRates:
+			f.FundingTime, f.Time = f.FundingTime.UTC(), f.Time.UTC()
+			out.fundings = append(out.fundings, *f)
+		case i := <-c.chanOpenInterest:
+			i.Time = i.Time.UTC()
+			out.interests = append(out.interests, *i)
+		default:
+			return out
+		}
+	}
+}
+
+func gzipped(t *testing.T, payload string) []byte {
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	if _, err := w.Write([]byte(payload)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func TestFuturesParsers(t *testing.T) {
+	date := func(year int, month time.Month, day, hour, min, sec, msec int) time.Time {
+		return time.Date(year, month, day, hour, min, sec, msec*1e6, time.UTC)
+	}
+	bitmexExpiry := date(2020, time.March, 27, 12, 0, 0, 0)
+
+	cases := []struct {
+		name string
+		run  func(t *testing.T, c futuresChannels) error
+		want futuresOutput
+		// the funding rates are stamped with the time of receipt
+		receiptTime bool
+	}{
+		{
+			name: "bitmex perpetual trades",
+			run: func(t *testing.T, c futuresChannels) error {
+				return (&BitmexScraper{futuresChannels: c}).handleMessage([]byte(`{"table":"trade","action":"insert","data":[
+					{"timestamp":"2020-01-08T10:15:30.123Z","symbol":"XBTUSD","side":"Buy","size":100,"price":8150.5,"tickDirection":"PlusTick","trdMatchID":"a1d7e9f2-7e3c-4f0b-9b0e-2a1c8c0f5b11","grossValue":1226900,"homeNotional":0.012269,"foreignNotional":100},
+					{"timestamp":"2020-01-08T10:15:30.456Z","symbol":"XBTUSD","side":"Sell","size":2000,"price":8150,"tickDirection":"MinusTick","trdMatchID":"c3f0e5a8-1b2d-4c6e-8f9a-0d1e2f3a4b5c","grossValue":24540000,"homeNotional":0.2454,"foreignNotional":2000}]}`), &time.Time{})
+			},
+			want: futuresOutput{trades: []dia.FuturesTrade{
+				{Exchange: dia.BitmexExchange, Market: "XBTUSD", Underlying: "BTC", Price: 8150.5, Volume: 0.012269, Time: date(2020, time.January, 8, 10, 15, 30, 123), ForeignTradeID: "a1d7e9f2-7e3c-4f0b-9b0e-2a1c8c0f5b11", Perpetual: true},
+				{Exchange: dia.BitmexExchange, Market: "XBTUSD", Underlying: "BTC", Price: 8150, Volume: -0.2454, Time: date(2020, time.January, 8, 10, 15, 30, 456), ForeignTradeID: "c3f0e5a8-1b2d-4c6e-8f9a-0d1e2f3a4b5c", Perpetual: true},
+			}},
+		},
+		{
+			name: "bitmex trade snapshot",
+			run: func(t *testing.T, c futuresChannels) error {
+				return (&BitmexScraper{futuresChannels: c}).handleMessage([]byte(`{"table":"trade","action":"partial","keys":[],"data":[
+					{"timestamp":"2020-01-08T10:15:29.000Z","symbol":"XBTUSD","side":"Buy","size":10,"price":8150,"trdMatchID":"e4b1","homeNotional":0.001227}]}`), &time.Time{})
+			},
+		},
+		{
+			name: "bitmex instrument sets the expiry of later trades",
+			run: func(t *testing.T, c futuresChannels) error {
+				s := &BitmexScraper{futuresChannels: c}
+				expiry := time.Time{}
+				err := s.handleMessage([]byte(`{"table":"instrument","action":"partial","data":[
+					{"symbol":"ETHH20","underlying":"ETH","expiry":"2020-03-27T12:00:00.000Z","openInterest":1520311,"timestamp":"2020-01-08T10:15:00.000Z"}]}`), &expiry)
+				if err != nil {
+					return err
+				}
+				return s.handleMessage([]byte(`{"table":"trade","action":"insert","data":[
+					{"timestamp":"2020-01-08T10:16:01.500Z","symbol":"ETHH20","side":"Sell","size":5,"price":0.01768,"trdMatchID":"9f2e","homeNotional":5}]}`), &expiry)
+			},
+			want: futuresOutput{
+				trades: []dia.FuturesTrade{
+					{Exchange: dia.BitmexExchange, Market: "ETHH20", Underlying: "ETH", Price: 0.01768, Volume: -5, Time: date(2020, time.January, 8, 10, 16, 1, 500), ForeignTradeID: "9f2e", Expiry: bitmexExpiry},
+				},
+				interests: []dia.OpenInterest{
+					{Exchange: dia.BitmexExchange, Market: "ETHH20", Underlying: "ETH", Value: 1520311, Time: date(2020, time.January, 8, 10, 15, 0, 0)},
+				},
+			},
+		},
+		{
+			name: "bitmex funding",
+			run: func(t *testing.T, c futuresChannels) error {
+				return (&BitmexScraper{futuresChannels: c}).handleMessage([]byte(`{"table":"funding","action":"insert","data":[
+					{"timestamp":"2020-01-08T12:00:00.000Z","symbol":"XBTUSD","fundingInterval":"2000-01-01T08:00:00.000Z","fundingRate":0.0001,"fundingRateDaily":0.0003}]}`), &time.Time{})
+			},
+			want: futuresOutput{fundings: []dia.FundingRate{
+				{Exchange: dia.BitmexExchange, Market: "XBTUSD", Underlying: "BTC", Rate: 0.0001, Interval: 8 * time.Hour, FundingTime: date(2020, time.January, 8, 12, 0, 0, 0)},
+			}},
+			receiptTime: true,
+		},
+		{
+			name: "bitflyer perpetual executions",
+			run: func(t *testing.T, c futuresChannels) error {
+				return (&BitflyerScraper{futuresChannels: c}).handleExecutions("FX_BTC_JPY", []byte(`{"jsonrpc":"2.0","method":"channelMessage","params":{"channel":"lightning_executions_FX_BTC_JPY","message":[
+					{"id":1575474536,"side":"BUY","price":855000,"size":0.25,"exec_date":"2020-01-08T10:15:30.5Z","buy_child_order_acceptance_id":"JRF20200108-101530-049322","sell_child_order_acceptance_id":"JRF20200108-101530-614592"},
+					{"id":1575474537,"side":"SELL","price":854990,"size":1.5,"exec_date":"2020-01-08T10:15:31Z","buy_child_order_acceptance_id":"JRF20200108-101531-322511","sell_child_order_acceptance_id":"JRF20200108-101530-712063"}]}}`))
+			},
+			want: futuresOutput{trades: []dia.FuturesTrade{
+				{Exchange: dia.BitflyerExchange, Market: "FX_BTC_JPY", Underlying: "BTC", Price: 855000, Volume: 0.25, Time: date(2020, time.January, 8, 10, 15, 30, 500), ForeignTradeID: "1575474536", Perpetual: true},
+				{Exchange: dia.BitflyerExchange, Market: "FX_BTC_JPY", Underlying: "BTC", Price: 854990, Volume: -1.5, Time: date(2020, time.January, 8, 10, 15, 31, 0), ForeignTradeID: "1575474537", Perpetual: true},
+			}},
+		},
+		{
+			name: "bitflyer dated executions",
+			run: func(t *testing.T, c futuresChannels) error {
+				return (&BitflyerScraper{futuresChannels: c}).handleExecutions("BTCJPY27DEC2019", []byte(`{"jsonrpc":"2.0","method":"channelMessage","params":{"channel":"lightning_executions_BTCJPY27DEC2019","message":[
+					{"id":1575480001,"side":"SELL","price":812000,"size":0.1,"exec_date":"2019-12-20T03:00:00Z"}]}}`))
+			},
+			want: futuresOutput{trades: []dia.FuturesTrade{
+				{Exchange: dia.BitflyerExchange, Market: "BTCJPY27DEC2019", Underlying: "BTC", Price: 812000, Volume: -0.1, Time: date(2019, time.December, 20, 3, 0, 0, 0), ForeignTradeID: "1575480001", Expiry: date(2019, time.December, 27, 0, 0, 0, 0)},
+			}},
+		},
+		{
+			name: "bitflyer subscription confirmation",
+			run: func(t *testing.T, c futuresChannels) error {
+				return (&BitflyerScraper{futuresChannels: c}).handleExecutions("FX_BTC_JPY", []byte(`{"jsonrpc":"2.0","id":1,"result":true}`))
+			},
+		},
+		{
+			name: "coinflex matches",
+			run: func(t *testing.T, c futuresChannels) error {
+				s := &CoinflexFuturesScraper{futuresChannels: c}
+				market := &marketCoinflex{Base: 63488, Counter: 65283, Name: "XBTDEC/USDTDEC", Expires: 1577433600000000}
+				base := assetCoinflex{ID: 63488, Name: "XBTDEC", SpotID: 63744, SpotName: "XBT", Scale: 10000}
+				quote := assetCoinflex{ID: 65283, Name: "USDTDEC", SpotID: 65283, SpotName: "USDT", Scale: 10000}
+				// the bid was filled completely by a market sell order
+				c.chanTrades <- s.normalizeTrade("XBTDEC/USDTDEC", ordersMatchedCoinflex{Notice: "OrdersMatched", Bid: 5594651410237575, BidTonce: 1578478530161004, Ask: 5594650844839393, AskTonce: 1578478412330118, Base: 63488, Counter: 65283, Quantity: 1500, Price: 81505000, Total: 12225750, BidRem: 0, AskRem: 8500, Time: 1578478530161004}, market, base, quote)
+				// the ask was filled completely by a market buy order
+				c.chanTrades <- s.normalizeTrade("XBTDEC/USDTDEC", ordersMatchedCoinflex{Notice: "OrdersMatched", Bid: 5594650844839402, Ask: 5594651410237588, Base: 63488, Counter: 65283, Quantity: 20000, Price: 81500000, BidRem: 10000, AskRem: 0, Time: 1578478531000000}, market, base, quote)
+				return nil
+			},
+			want: futuresOutput{trades: []dia.FuturesTrade{
+				{Exchange: dia.CoinflexExchange, Market: "XBTDEC/USDTDEC", Underlying: "BTC", Price: 8150.5, Volume: -0.15, Time: date(2020, time.January, 8, 10, 15, 30, 161).Add(4 * time.Microsecond), ForeignTradeID: "5594651410237575-5594650844839393", Expiry: date(2019, time.December, 27, 8, 0, 0, 0)},
+				{Exchange: dia.CoinflexExchange, Market: "XBTDEC/USDTDEC", Underlying: "BTC", Price: 8150, Volume: 2, Time: date(2020, time.January, 8, 10, 15, 31, 0), ForeignTradeID: "5594650844839402-5594651410237588", Expiry: date(2019, time.December, 27, 8, 0, 0, 0)},
+			}},
+		},
+		{
+			name: "coinflex perpetual",
+			run: func(t *testing.T, c futuresChannels) error {
+				s := &CoinflexFuturesScraper{futuresChannels: c}
+				market := &marketCoinflex{Base: 63488, Counter: 65283, Name: "XBTPERP/USDT"}
+				base := assetCoinflex{ID: 63488, Name: "XBTPERP", SpotName: "XBT", Scale: 10000}
+				quote := assetCoinflex{ID: 65283, Name: "USDT", SpotName: "USDT", Scale: 10000}
+				c.chanTrades <- s.normalizeTrade("XBTPERP/USDT", ordersMatchedCoinflex{Bid: 1, Ask: 2, Quantity: 5000, Price: 81500000, BidRem: 100, AskRem: 200, Time: 1578478531000000}, market, base, quote)
+				return nil
+			},
+			want: futuresOutput{trades: []dia.FuturesTrade{
+				{Exchange: dia.CoinflexExchange, Market: "XBTPERP/USDT", Underlying: "BTC", Price: 8150, Volume: 0.5, Time: date(2020, time.January, 8, 10, 15, 31, 0), ForeignTradeID: "1-2", Perpetual: true},
+			}},
+		},
+		{
+			name: "deribit trades",
+			run: func(t *testing.T, c futuresChannels) error {
+				s := &DeribitScraper{futuresChannels: c}
+				return s.handleFuturesTrades([]byte(`[
+					{"trade_seq":30289432,"trade_id":"48079254","timestamp":1578478530123,"tick_direction":0,"price":8150.5,"mark_price":8150.1,"instrument_name":"BTC-27MAR20","index_price":8149.32,"direction":"buy","amount":4075.25},
+					{"trade_seq":30289433,"trade_id":"48079255","timestamp":1578478530124,"tick_direction":1,"price":8000,"mark_price":8150.1,"instrument_name":"BTC-27MAR20","index_price":8149.32,"direction":"sell","amount":160}]`), deribitExpiry("BTC-27MAR20"))
+			},
+			want: futuresOutput{trades: []dia.FuturesTrade{
+				{Exchange: dia.Deribit, Market: "BTC-27MAR20", Underlying: "BTC", Price: 8150.5, Volume: 0.5, Time: date(2020, time.January, 8, 10, 15, 30, 123), ForeignTradeID: "48079254", Expiry: date(2020, time.March, 27, 8, 0, 0, 0)},
+				{Exchange: dia.Deribit, Market: "BTC-27MAR20", Underlying: "BTC", Price: 8000, Volume: -0.02, Time: date(2020, time.January, 8, 10, 15, 30, 124), ForeignTradeID: "48079255", Expiry: date(2020, time.March, 27, 8, 0, 0, 0)},
+			}},
+		},
+		{
+			name: "deribit perpetual ticker",
+			run: func(t *testing.T, c futuresChannels) error {
+				s := &DeribitScraper{futuresChannels: c}
+				return s.handleFuturesTicker([]byte(`{"timestamp":1578478530000,"stats":{"volume":22731.5,"low":7985,"high":8202.5},"state":"open","settlement_price":8149.19,"open_interest":112893140,"min_price":8028.22,"max_price":8273.54,"mark_price":8150.88,"last_price":8150.5,"interest_value":3.6,"instrument_name":"BTC-PERPETUAL","index_price":8149.32,"funding_8h":0.00012,"current_funding":0.00001,"best_bid_price":8150.5,"best_ask_price":8151}`))
+			},
+			want: futuresOutput{
+				fundings: []dia.FundingRate{
+					{Exchange: dia.Deribit, Market: "BTC-PERPETUAL", Underlying: "BTC", Rate: 0.00012, Interval: 8 * time.Hour, FundingTime: date(2020, time.January, 8, 10, 15, 30, 0), Time: date(2020, time.January, 8, 10, 15, 30, 0)},
+				},
+				interests: []dia.OpenInterest{
+					{Exchange: dia.Deribit, Market: "BTC-PERPETUAL", Underlying: "BTC", Value: 112893140, Time: date(2020, time.January, 8, 10, 15, 30, 0)},
+				},
+			},
+		},
+		{
+			name: "deribit dated ticker",
+			run: func(t *testing.T, c futuresChannels) error {
+				s := &DeribitScraper{futuresChannels: c}
+				return s.handleFuturesTicker([]byte(`{"timestamp":1578478530000,"state":"open","open_interest":31243560,"mark_price":8210.3,"instrument_name":"ETH-3APR20"}`))
+			},
+			want: futuresOutput{interests: []dia.OpenInterest{
+				{Exchange: dia.Deribit, Market: "ETH-3APR20", Underlying: "ETH", Value: 31243560, Time: date(2020, time.January, 8, 10, 15, 30, 0)},
+			}},
+		},
+		{
+			name: "ftx trades",
+			run: func(t *testing.T, c futuresChannels) error {
+				msg := tradeMessageFTX{}
+				err := json.Unmarshal([]byte(`{"channel":"trades","market":"BTC-PERP","type":"update","data":[
+					{"id":18452347,"price":8150.5,"size":0.25,"side":"buy","liquidation":false,"time":"2020-01-08T10:15:30.123456+00:00"},
+					{"id":18452348,"price":8150,"size":1.5,"side":"sell","liquidation":true,"time":"2020-01-08T10:15:31.000000+00:00"}]}`), &msg)
+				if err != nil {
+					return err
+				}
+				(&FTXFuturesScraper{futuresChannels: c}).handleTrades(msg)
+				return nil
+			},
+			want: futuresOutput{trades: []dia.FuturesTrade{
+				{Exchange: dia.FTX, Market: "BTC-PERP", Underlying: "BTC", Price: 8150.5, Volume: 0.25, Time: date(2020, time.January, 8, 10, 15, 30, 123).Add(456 * time.Microsecond), ForeignTradeID: "18452347", Perpetual: true},
+				{Exchange: dia.FTX, Market: "BTC-PERP", Underlying: "BTC", Price: 8150, Volume: -1.5, Time: date(2020, time.January, 8, 10, 15, 31, 0), ForeignTradeID: "18452348", Perpetual: true},
+			}},
+		},
+		{
+			name: "ftx dated trades",
+			run: func(t *testing.T, c futuresChannels) error {
+				msg := tradeMessageFTX{}
+				err := json.Unmarshal([]byte(`{"channel":"trades","market":"ETH-0327","type":"update","data":[
+					{"id":18452400,"price":143.25,"size":10,"side":"sell","liquidation":false,"time":"2020-01-08T10:16:00.000000+00:00"}]}`), &msg)
+				if err != nil {
+					return err
+				}
+				(&FTXFuturesScraper{futuresChannels: c}).handleTrades(msg)
+				return nil
+			},
+			want: futuresOutput{trades: []dia.FuturesTrade{
+				{Exchange: dia.FTX, Market: "ETH-0327", Underlying: "ETH", Price: 143.25, Volume: -10, Time: date(2020, time.January, 8, 10, 16, 0, 0), ForeignTradeID: "18452400"},
+			}},
+		},
+		{
+			name: "huobi trades",
+			run: func(t *testing.T, c futuresChannels) error {
+				message, err := parseGzip(gzipped(t, `{"ch":"market.BTC_CQ.trade.detail","ts":1578478530200,"tick":{"id":2078469218,"ts":1578478530187,"data":[
+					{"amount":10,"ts":1578478530187,"id":20784692180000,"price":8000,"direction":"buy"},
+					{"amount":2,"ts":1578478530190,"id":20784692180001,"price":8000,"direction":"sell"}]}}`))
+				if err != nil {
+					return err
+				}
+				return (&HuobiFuturesScraper{futuresChannels: c}).handleTrades("BTC_CQ", message, 100, date(2020, time.March, 27, 8, 0, 0, 0))
+			},
+			want: futuresOutput{trades: []dia.FuturesTrade{
+				{Exchange: dia.HuobiExchange, Market: "BTC_CQ", Underlying: "BTC", Price: 8000, Volume: 0.125, Time: date(2020, time.January, 8, 10, 15, 30, 187), ForeignTradeID: "20784692180000", Expiry: date(2020, time.March, 27, 8, 0, 0, 0)},
+				{Exchange: dia.HuobiExchange, Market: "BTC_CQ", Underlying: "BTC", Price: 8000, Volume: -0.025, Time: date(2020, time.January, 8, 10, 15, 30, 190), ForeignTradeID: "20784692180001", Expiry: date(2020, time.March, 27, 8, 0, 0, 0)},
+			}},
+		},
+		{
+			name: "huobi subscription confirmation",
+			run: func(t *testing.T, c futuresChannels) error {
+				message, err := parseGzip(gzipped(t, `{"id":"id1","status":"ok","subbed":"market.BTC_CQ.trade.detail","ts":1578478529000}`))
+				if err != nil {
+					return err
+				}
+				return (&HuobiFuturesScraper{futuresChannels: c}).handleTrades("BTC_CQ", message, 100, time.Time{})
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			channels := bufferedFuturesChannels()
+			err := c.run(t, channels)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := drainFutures(channels)
+			if c.receiptTime {
+				for i := range got.fundings {
+					if got.fundings[i].Time.IsZero() {
+						t.Error("funding rate without time of receipt")
+					}
+					got.fundings[i].Time = time.Time{}
+				}
+			}
+			if !reflect.DeepEqual(got.trades, c.want.trades) {
+				t.Errorf("trades\n%+v\nexpected\n%+v", got.trades, c.want.trades)
+			}
+			if !reflect.DeepEqual(got.fundings, c.want.fundings) {
+				t.Errorf("funding rates\n%+v\nexpected\n%+v", got.fundings, c.want.fundings)
+			}
+			if !reflect.DeepEqual(got.interests, c.want.interests) {
+				t.Errorf("open interest\n%+v\nexpected\n%+v", got.interests, c.want.interests)
+			}
+		})
+	}
+}
+
+func TestFuturesExpiries(t *testing.T) {
+	cases := []struct {
+		expiry   func(string) time.Time
+		market   string
+		expected time.Time
+	}{
+		{bitflyerExpiry, "BTCJPY27DEC2019", time.Date(2019, time.December, 27, 0, 0, 0, 0, time.UTC)},
+		{bitflyerExpiry, "FX_BTC_JPY", time.Time{}},
+		{bitflyerExpiry, "BTC_JPY", time.Time{}},
+		{deribitExpiry, "BTC-25MAR22", time.Date(2022, time.March, 25, 8, 0, 0, 0, time.UTC)},
+		{deribitExpiry, "ETH-3APR20", time.Date(2020, time.April, 3, 8, 0, 0, 0, time.UTC)},
+		{deribitExpiry, "BTC-PERPETUAL", time.Time{}},
+	}
+	for _, c := range cases {
+		if expiry := c.expiry(c.market); !expiry.Equal(c.expected) {
+			t.Errorf("%s: expiry %v, expected %v", c.market, expiry, c.expected)
+		}
+	}
+}
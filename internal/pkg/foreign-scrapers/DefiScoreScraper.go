@@ -1,5 +1,355 @@
 package foreignscrapers
 
-// Please implement the scraping of defi scores here
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"os"
+	"strings"
+	"time"
 
+	supplyservice "github.com/diadata-org/diadata/internal/pkg/supplyService"
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/configCollectors"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/ethhelper"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	log "github.com/sirupsen/logrus"
+)
 
+const (
+	defiScoreRefreshDelay = time.Hour * 24
+	defiScoreSource       = "DefiScore"
+	defiScoreConfigFile   = "defi/scores"
+
+	// Age of a contract from which on it is considered battle-tested.
+	defiScoreMaturity = 2 * 365 * 24 * time.Hour
+	// Timelock delay from which on users have sufficient time to react on admin actions.
+	defiScoreTimelockDelay = 48 * time.Hour
+
+	// Weights of the indices in the total score.
+	defiScoreWeightAge           = 0.25
+	defiScoreWeightConcentration = 0.25
+	defiScoreWeightAdmin         = 0.3
+	defiScoreWeightOracle        = 0.2
+
+	// adminABI contains the getters used to identify the admin of a protocol and the type of the admin contract.
+	adminABI = `[{"inputs":[],"name":"admin","outputs":[{"name":"","type":"address"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"owner","outputs":[{"name":"","type":"address"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"delay","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"getThreshold","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`
+)
+
+// oracleIndices maps the type of price oracle a protocol depends on to its oracle index.
+var oracleIndices = map[string]float64{
+	"none":      1,
+	"chainlink": 0.8,
+	"multiple":  0.8,
+	"twap":      0.5,
+	"single":    0.3,
+}
+
+// DefiScoreProtocol is the config of a protocol to be scored.
+type DefiScoreProtocol struct {
+	// Name of the protocol as used for defi rates and states, e.g. COMPOUND.
+	Name string `json:"Name"`
+	// RPC is the node endpoint of the protocol's chain. Defaults to the Ethereum client from ethhelper.
+	RPC string `json:"RPC"`
+	// Address of the protocol's core contract, e.g. the Comptroller or the lending pool.
+	Address string `json:"Address"`
+	// Admin overrides the admin address read from admin() or owner() of the core contract.
+	Admin string `json:"Admin"`
+	// Oracle is the type of price oracle the protocol depends on, see oracleIndices.
+	Oracle string `json:"Oracle"`
+	// Deployed is the deployment date in the format 2006-01-02. If empty, it is discovered on-chain.
+	Deployed string `json:"Deployed"`
+}
+
+type DefiScoreScraper struct {
+	ticker          *time.Ticker
+	foreignScrapper ForeignScraper
+	protocols       []DefiScoreProtocol
+	adminABI        abi.ABI
+	chanScore       chan *models.DefiScore
+}
+
+func NewDefiScoreScraper(datastore models.Datastore) *DefiScoreScraper {
+	protocols, err := getDefiScoreProtocolsFromConfig()
+	if err != nil {
+		log.Error("error loading defi score config: ", err)
+	}
+	parsedABI, err := abi.JSON(strings.NewReader(adminABI))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	s := &DefiScoreScraper{
+		ticker: time.NewTicker(defiScoreRefreshDelay),
+		foreignScrapper: ForeignScraper{
+			shutdown:      make(chan nothing),
+			shutdownDone:  make(chan nothing),
+			error:         nil,
+			datastore:     datastore,
+			chanQuotation: make(chan *models.ForeignQuotation),
+		},
+		protocols: protocols,
+		adminABI:  parsedABI,
+		chanScore: make(chan *models.DefiScore),
+	}
+	go s.mainLoop()
+
+	return s
+}
+
+// mainLoop runs in a goroutine until channel s is closed.
+func (scraper *DefiScoreScraper) mainLoop() {
+	scraper.UpdateQuotation()
+	for {
+		select {
+		case <-scraper.ticker.C:
+			scraper.UpdateQuotation()
+		case <-scraper.foreignScrapper.shutdown: // user requested shutdown
+			log.Printf("DefiScoreScraper shutting down")
+			scraper.cleanup(nil)
+			return
+		}
+	}
+}
+
+// UpdateQuotation computes the scores of all protocols from config and sends them as
+// foreign quotations with the score as price, followed by the score with all its indices.
+func (scraper *DefiScoreScraper) UpdateQuotation() error {
+	log.Printf("Executing DefiScoreScraper update")
+	for _, protocol := range scraper.protocols {
+		score, err := scraper.computeScore(protocol)
+		if err != nil {
+			log.Errorf("error computing defi score for %s: %v", protocol.Name, err)
+			continue
+		}
+		log.Infof("defi score for %s: %+v", protocol.Name, score)
+
+		priceYesterday, err := scraper.foreignScrapper.datastore.GetForeignPriceYesterday(protocol.Name, defiScoreSource)
+		if err != nil {
+			priceYesterday = 0
+		}
+		scraper.foreignScrapper.chanQuotation <- &models.ForeignQuotation{
+			Symbol:         protocol.Name,
+			Name:           protocol.Name,
+			Price:          score.Score,
+			PriceYesterday: priceYesterday,
+			Source:         defiScoreSource,
+			Time:           score.Time,
+		}
+		scraper.chanScore <- &score
+	}
+	return nil
+}
+
+func (scraper *DefiScoreScraper) GetQuoteChannel() chan *models.ForeignQuotation {
+	return scraper.foreignScrapper.chanQuotation
+}
+
+// GetScoreChannel returns the channel of the scores with their indices.
+func (scraper *DefiScoreScraper) GetScoreChannel() chan *models.DefiScore {
+	return scraper.chanScore
+}
+
+// computeScore returns the weighted score of @protocol from contract age, TVL concentration,
+// admin key setup and oracle dependency.
+func (scraper *DefiScoreScraper) computeScore(protocol DefiScoreProtocol) (score models.DefiScore, err error) {
+	var client *ethclient.Client
+	if protocol.RPC == "" {
+		client, err = ethhelper.NewETHClient()
+	} else {
+		client, err = ethclient.Dial(protocol.RPC)
+	}
+	if err != nil {
+		return
+	}
+	defer client.Close()
+
+	score = models.DefiScore{
+		Protocol: protocol.Name,
+		Symbol:   protocol.Name,
+		Time:     time.Now(),
+	}
+
+	deployed, err := getDeploymentTime(protocol, client)
+	if err != nil {
+		return
+	}
+	score.ContractAgeIndex = math.Min(time.Since(deployed).Hours()/defiScoreMaturity.Hours(), 1)
+
+	score.AdminIndex, err = scraper.getAdminIndex(protocol, client)
+	if err != nil {
+		return
+	}
+
+	oracleIndex, ok := oracleIndices[protocol.Oracle]
+	if !ok {
+		return score, errors.New("unknown oracle type " + protocol.Oracle)
+	}
+	score.OracleIndex = oracleIndex
+
+	marketStates, errMarkets := scraper.foreignScrapper.datastore.GetDefiMarketStatesLatest(protocol.Name)
+	if errMarkets != nil {
+		log.Warnf("no market states for %s, assume full concentration: %v", protocol.Name, errMarkets)
+	}
+	score.ConcentrationIndex, score.LiquidityIndex, score.CollateralIndex = marketIndices(marketStates)
+
+	score.Score = 100 * (defiScoreWeightAge*score.ContractAgeIndex +
+		defiScoreWeightConcentration*score.ConcentrationIndex +
+		defiScoreWeightAdmin*score.AdminIndex +
+		defiScoreWeightOracle*score.OracleIndex)
+	return
+}
+
+// marketIndices returns indices computed from the market states of a protocol:
+// concentration index is one minus the Herfindahl index of supplied value over markets,
+// liquidity index is one minus the supply weighted utilisation and collateral index is
+// one minus the supply weighted liquidation threshold.
+func marketIndices(states []dia.DefiMarketState) (concentration float64, liquidity float64, collateral float64) {
+	totalSupplied := float64(0)
+	for _, state := range states {
+		totalSupplied += state.SuppliedUSD
+	}
+	if totalSupplied == 0 {
+		return
+	}
+	hhi, utilisation, threshold := float64(0), float64(0), float64(0)
+	for _, state := range states {
+		share := state.SuppliedUSD / totalSupplied
+		hhi += share * share
+		utilisation += share * state.Utilisation
+		threshold += share * state.LiquidationThreshold
+	}
+	return 1 - hhi, 1 - utilisation, 1 - threshold
+}
+
+// getDeploymentTime returns the deployment time of the protocol's core contract from config,
+// or from the timestamp of its creation block.
+func getDeploymentTime(protocol DefiScoreProtocol, client *ethclient.Client) (time.Time, error) {
+	if protocol.Deployed != "" {
+		return time.Parse("2006-01-02", protocol.Deployed)
+	}
+	blockNumber, err := supplyservice.GetContractCreationBlock(protocol.Address, client)
+	if err != nil {
+		return time.Time{}, err
+	}
+	header, err := client.HeaderByNumber(context.Background(), new(big.Int).SetUint64(blockNumber))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(int64(header.Time), 0), nil
+}
+
+// getAdminIndex rates the admin setup of a protocol: A renounced admin gets 1, an admin key held by
+// an EOA gets 0. Timelocks are rated by their delay, multisigs and other contracts in between.
+func (scraper *DefiScoreScraper) getAdminIndex(protocol DefiScoreProtocol, client *ethclient.Client) (float64, error) {
+	var admin common.Address
+	if protocol.Admin != "" {
+		admin = common.HexToAddress(protocol.Admin)
+	} else {
+		core := bind.NewBoundContract(common.HexToAddress(protocol.Address), scraper.adminABI, client, nil, nil)
+		var err error
+		admin, err = callAddress(core, "admin")
+		if err != nil {
+			admin, err = callAddress(core, "owner")
+			if err != nil {
+				return 0, errors.New("neither admin() nor owner() available on " + protocol.Address)
+			}
+		}
+	}
+	if admin == (common.Address{}) {
+		return 1, nil
+	}
+
+	code, err := client.CodeAt(context.Background(), admin, nil)
+	if err != nil {
+		return 0, err
+	}
+	if len(code) == 0 {
+		return 0, nil
+	}
+
+	adminContract := bind.NewBoundContract(admin, scraper.adminABI, client, nil, nil)
+	if delay, err := callUint(adminContract, "delay"); err == nil {
+		delayDuration := time.Duration(delay.Int64()) * time.Second
+		return 0.5 + 0.5*math.Min(delayDuration.Hours()/defiScoreTimelockDelay.Hours(), 1), nil
+	}
+	if threshold, err := callUint(adminContract, "getThreshold"); err == nil && threshold.Int64() > 1 {
+		return 0.4, nil
+	}
+	return 0.25, nil
+}
+
+func callAddress(contract *bind.BoundContract, method string) (common.Address, error) {
+	var out []interface{}
+	err := contract.Call(&bind.CallOpts{}, &out, method)
+	if err != nil || len(out) == 0 {
+		return common.Address{}, errors.New("call of " + method + " failed")
+	}
+	return *abi.ConvertType(out[0], new(common.Address)).(*common.Address), nil
+}
+
+func callUint(contract *bind.BoundContract, method string) (*big.Int, error) {
+	var out []interface{}
+	err := contract.Call(&bind.CallOpts{}, &out, method)
+	if err != nil || len(out) == 0 {
+		return nil, errors.New("call of " + method + " failed")
+	}
+	return *abi.ConvertType(out[0], new(*big.Int)).(**big.Int), nil
+}
+
+// getDefiScoreProtocolsFromConfig returns all protocols to be scored from the config file.
+func getDefiScoreProtocolsFromConfig() ([]DefiScoreProtocol, error) {
+	jsonFile, err := os.Open(configCollectors.ConfigFileConnectors(defiScoreConfigFile, ".json"))
+	if err != nil {
+		return []DefiScoreProtocol{}, err
+	}
+	defer jsonFile.Close()
+	byteData, err := ioutil.ReadAll(jsonFile)
+	if err != nil {
+		return []DefiScoreProtocol{}, err
+	}
+	type defiScoreProtocolList struct {
+		Protocols []DefiScoreProtocol `json:"Protocols"`
+	}
+	var protocols defiScoreProtocolList
+	err = json.Unmarshal(byteData, &protocols)
+	if err != nil {
+		return []DefiScoreProtocol{}, err
+	}
+	return protocols.Protocols, nil
+}
+
+// closes all connected Scrapers. Must only be called from mainLoop
+func (scraper *DefiScoreScraper) cleanup(err error) {
+
+	scraper.foreignScrapper.errorLock.Lock()
+	defer scraper.foreignScrapper.errorLock.Unlock()
+
+	scraper.ticker.Stop()
+
+	if err != nil {
+		scraper.foreignScrapper.error = err
+	}
+	scraper.foreignScrapper.closed = true
+
+	close(scraper.foreignScrapper.shutdownDone) // signal that shutdown is complete
+}
+
+// Close closes any existing API connections
+func (scraper *DefiScoreScraper) Close() error {
+	if scraper.foreignScrapper.closed {
+		return errors.New("Scraper: Already closed")
+	}
+	close(scraper.foreignScrapper.shutdown)
+	<-scraper.foreignScrapper.shutdownDone
+	scraper.foreignScrapper.errorLock.RLock()
+	defer scraper.foreignScrapper.errorLock.RUnlock()
+	return scraper.foreignScrapper.error
+}
@@ -16,6 +16,7 @@ import (
 	models "github.com/diadata-org/diadata/pkg/model"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 )
 
 const (
@@ -43,6 +44,7 @@ func NewCryptoPunksScraper(rdb *models.RelDB) *CryptoPunksScraper {
 		ethConnection: connection,
 		datastore:     rdb,
 		chanBid:       make(chan dia.NFTBid),
+		chanClose:     make(chan dia.NFTOrderClose),
 	}
 	s := &CryptoPunksScraper{
 		contractAddress: common.HexToAddress("0xb47e3cd837dDF8e4c57F05d70Ab865de6e193BBB"),
@@ -155,16 +157,83 @@ func (scraper *CryptoPunksScraper) FetchBids() error {
 		break
 	}
 
+	err = scraper.fetchCloses(filterer, nftclass, endBlockNumber)
+	if err != nil {
+		return err
+	}
+
 	// Update the last lastBlockNumber value.
 	scraper.lastBlockNumber = endBlockNumber
 	return nil
 }
 
+// fetchCloses sends the withdrawals of bids and the sales of punks up to @endBlockNumber to the close channel.
+func (scraper *CryptoPunksScraper) fetchCloses(filterer *cryptopunk.CryptoPunksMarketFilterer, nftclass dia.NFTClass, endBlockNumber uint64) error {
+	err := ethhelper.FilterRange(scraper.lastBlockNumber, endBlockNumber, func(opts *bind.FilterOpts) error {
+		iter, err := filterer.FilterPunkBidWithdrawn(opts, nil, nil)
+		if err != nil {
+			return err
+		}
+		for iter.Next() {
+			err = scraper.sendClose(nftclass, iter.Event.PunkIndex, dia.NFTOrderCancelled, iter.Event.FromAddress, iter.Event.Raw)
+			if err != nil {
+				return err
+			}
+		}
+		return iter.Error()
+	})
+	if err != nil {
+		return err
+	}
+
+	return ethhelper.FilterRange(scraper.lastBlockNumber, endBlockNumber, func(opts *bind.FilterOpts) error {
+		iter, err := filterer.FilterPunkBought(opts, nil, nil, nil)
+		if err != nil {
+			return err
+		}
+		for iter.Next() {
+			// A sale removes the bid of the buyer, who is the bidder if a bid was accepted.
+			err = scraper.sendClose(nftclass, iter.Event.PunkIndex, dia.NFTOrderFilled, iter.Event.ToAddress, iter.Event.Raw)
+			if err != nil {
+				return err
+			}
+		}
+		return iter.Error()
+	})
+}
+
+func (scraper *CryptoPunksScraper) sendClose(nftclass dia.NFTClass, punkIndex *big.Int, reason string, bidder common.Address, raw types.Log) error {
+	header, err := scraper.bidScraper.ethConnection.HeaderByNumber(context.Background(), new(big.Int).SetUint64(raw.BlockNumber))
+	if err != nil {
+		return err
+	}
+	scraper.GetCloseChannel() <- dia.NFTOrderClose{
+		NFT: dia.NFT{
+			NFTClass: nftclass,
+			TokenID:  punkIndex.String(),
+		},
+		Side:          dia.NFTBidSide,
+		Reason:        reason,
+		FromAddress:   bidder.Hex(),
+		BlockNumber:   raw.BlockNumber,
+		BlockPosition: uint64(raw.Index),
+		Timestamp:     time.Unix(int64(header.Time), 0),
+		TxHash:        raw.TxHash.Hex(),
+		Exchange:      "CryptopunkMarket",
+	}
+	return nil
+}
+
 // GetDataChannel returns the scrapers data channel.
 func (scraper *CryptoPunksScraper) GetBidChannel() chan dia.NFTBid {
 	return scraper.bidScraper.chanBid
 }
 
+// GetCloseChannel returns the channel of withdrawn and filled bids.
+func (scraper *CryptoPunksScraper) GetCloseChannel() chan dia.NFTOrderClose {
+	return scraper.bidScraper.chanClose
+}
+
 // closes all connected Scrapers. Must only be called from mainLoop
 func (scraper *CryptoPunksScraper) cleanup(err error) {
 	scraper.bidScraper.errorLock.Lock()
@@ -13,6 +13,8 @@ type nothing struct{}
 type NFTBidScraper interface {
 	// NFT bids should be streamed through dia.NFTBid channel.
 	GetBidChannel() chan dia.NFTBid
+	// Withdrawals and fills of bids should be streamed through dia.NFTOrderClose channel.
+	GetCloseChannel() chan dia.NFTOrderClose
 	// Should fetch bids and send them to the channel.
 	FetchBids() error
 }
@@ -30,4 +32,5 @@ type BidScraper struct {
 	ethConnection *ethclient.Client
 	datastore     *models.RelDB
 	chanBid       chan dia.NFTBid
+	chanClose     chan dia.NFTOrderClose
 }
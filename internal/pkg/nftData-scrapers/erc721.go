@@ -0,0 +1,294 @@
+package nftdatascrapers
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/diadata-org/diadata/config/nftContracts/erc721"
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/ethhelper"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// erc721Mint is the first transfer of a token, from the zero address to its creator.
+type erc721Mint struct {
+	creator     common.Address
+	blockNumber uint64
+}
+
+// erc721Enumerable is implemented by contracts with the ERC721Enumerable extension.
+type erc721Enumerable interface {
+	TotalSupply(opts *bind.CallOpts) (*big.Int, error)
+	TokenByIndex(opts *bind.CallOpts, index *big.Int) (*big.Int, error)
+}
+
+// GenericERC721Scraper fills the nft table for an ERC721 collection in the nftclass table without any
+// collection specific code. Tokens are found by replaying the mints of the contract, i.e. its Transfer
+// events from the zero address, and, if the contract implements ERC721Enumerable, with totalSupply and
+// tokenByIndex for the indices above the number of stored tokens. The creation time and creator of a
+// token are the time and receiver of its mint.
+type GenericERC721Scraper struct {
+	nftscraper NFTScraper
+	address    common.Address
+	blockchain string
+	ticker     *time.Ticker
+	// mints holds the mints found in the blocks up to lastBlockNumber, starting at startBlockNumber.
+	mints            map[string]erc721Mint
+	startBlockNumber uint64
+	lastBlockNumber  uint64
+	blockDelay       uint64
+	// known holds the tokens which are stored or have been sent to the data channel.
+	known map[string]bool
+}
+
+// NewGenericERC721Scraper returns a scraper for the ERC721 contract at @address on the Ethereum
+// compatible @blockchain. Mints are searched from @startBlock on. If @startBlock is zero, the deployment
+// block of the contract is looked up, which requires a node with the state of past blocks.
+func NewGenericERC721Scraper(rdb *models.RelDB, address string, blockchain string, startBlock uint64) *GenericERC721Scraper {
+	connection, err := ethhelper.NewEVMClient(blockchain)
+	if err != nil {
+		log.Errorf("connecting to %s: %v", blockchain, err)
+		return nil
+	}
+	if startBlock == 0 {
+		header, err := connection.Client.HeaderByNumber(context.Background(), nil)
+		if err != nil {
+			log.Errorf("getting head of %s: %v", blockchain, err)
+			return nil
+		}
+		startBlock, err = ethhelper.DeploymentBlock(context.Background(), connection.Client, common.HexToAddress(address), header.Number.Uint64())
+		if err != nil {
+			log.Errorf("looking up deployment block of %s: %v", address, err)
+			return nil
+		}
+		log.Infof("%s was deployed in block %d", address, startBlock)
+	}
+
+	nftScraper := NFTScraper{
+		shutdown:      make(chan nothing),
+		shutdownDone:  make(chan nothing),
+		errorLock:     new(sync.RWMutex),
+		error:         nil,
+		ethConnection: connection.Client,
+		relDB:         rdb,
+		chanData:      make(chan dia.NFT),
+	}
+	s := &GenericERC721Scraper{
+		nftscraper:       nftScraper,
+		address:          common.HexToAddress(address),
+		blockchain:       blockchain,
+		ticker:           time.NewTicker(refreshDelay),
+		mints:            make(map[string]erc721Mint),
+		startBlockNumber: startBlock,
+		blockDelay:       uint64(connection.Chain().BlockDelay),
+		known:            make(map[string]bool),
+	}
+
+	go s.mainLoop()
+	return s
+}
+
+// mainLoop runs in a goroutine until channel s is closed.
+func (scraper *GenericERC721Scraper) mainLoop() {
+	err := scraper.FetchData()
+	if err != nil {
+		log.Error("error updating NFT: ", err)
+	}
+	for {
+		select {
+		case <-scraper.ticker.C:
+			err := scraper.FetchData()
+			if err != nil {
+				log.Error("error updating NFT: ", err)
+			}
+		case <-scraper.nftscraper.shutdown: // user requested shutdown
+			log.Printf("ERC721 scraper for %s shutting down", scraper.address.Hex())
+			scraper.cleanup(nil)
+			return
+		}
+	}
+}
+
+// FetchData sends all tokens of the collection which are not in the nft table yet to the data channel.
+func (scraper *GenericERC721Scraper) FetchData() error {
+	nftclass, err := scraper.nftscraper.relDB.GetNFTClass(scraper.address.Hex(), scraper.blockchain)
+	if err != nil {
+		return errors.New("nft class " + scraper.address.Hex() + " on " + scraper.blockchain + " not in nftclass table: " + err.Error())
+	}
+
+	err = scraper.updateMints()
+	if err != nil {
+		return err
+	}
+
+	stored, err := scraper.nftscraper.relDB.GetNumNFTs(nftclass)
+	if err != nil {
+		return err
+	}
+	tokenIDs := scraper.mintedTokens()
+	enumerable, err := erc721.NewERC721EnumerableCaller(scraper.address, scraper.nftscraper.ethConnection)
+	if err != nil {
+		return err
+	}
+	enumerated, err := enumerateTokens(enumerable, int64(stored))
+	if err != nil {
+		log.Infof("%s is not enumerable, use mints only: %v", nftclass.Name, err)
+	}
+	tokenIDs = append(tokenIDs, enumerated...)
+	log.Infof("found %d minted and %d enumerated tokens of %s", len(scraper.mints), len(enumerated), nftclass.Name)
+
+	metadata, err := erc721.NewERC721MetadataCaller(scraper.address, scraper.nftscraper.ethConnection)
+	if err != nil {
+		return err
+	}
+	for _, tokenID := range tokenIDs {
+		if scraper.known[tokenID.String()] {
+			continue
+		}
+		if _, err := scraper.nftscraper.relDB.GetNFTID(nftclass.Address, nftclass.Blockchain, tokenID.String()); err == nil {
+			scraper.known[tokenID.String()] = true
+			continue
+		}
+		nft := dia.NFT{
+			NFTClass:   nftclass,
+			TokenID:    tokenID.String(),
+			Attributes: dia.NFTAttributes{},
+		}
+		if mint, ok := scraper.mints[tokenID.String()]; ok {
+			nft.CreatorAddress = mint.creator.Hex()
+			nft.CreationTime, err = scraper.blockTime(mint.blockNumber)
+			if err != nil {
+				log.Errorf("getting time of block %d: %v", mint.blockNumber, err)
+			}
+		}
+		// The token uri is optional in ERC721.
+		nft.URI, err = metadata.TokenURI(&bind.CallOpts{}, tokenID)
+		if err != nil {
+			log.Warnf("getting uri of token %s: %v", tokenID.String(), err)
+		}
+		scraper.GetDataChannel() <- nft
+		scraper.known[tokenID.String()] = true
+	}
+	return nil
+}
+
+// enumerateTokens returns the ids of the tokens of @contract from index @from on. As the indices of
+// ERC721Enumerable are dense, the tokens below the number of stored tokens are usually stored already.
+func enumerateTokens(contract erc721Enumerable, from int64) ([]*big.Int, error) {
+	totalSupply, err := contract.TotalSupply(&bind.CallOpts{})
+	if err != nil {
+		return nil, err
+	}
+	if totalSupply.Sign() == 0 {
+		return nil, errors.New("total supply is zero")
+	}
+
+	var tokenIDs []*big.Int
+	for i := from; i < totalSupply.Int64(); i++ {
+		tokenID, err := contract.TokenByIndex(&bind.CallOpts{}, big.NewInt(i))
+		if err != nil {
+			return nil, err
+		}
+		tokenIDs = append(tokenIDs, tokenID)
+	}
+	return tokenIDs, nil
+}
+
+// updateMints adds the mints since the last update to the mints of the scraper.
+func (scraper *GenericERC721Scraper) updateMints() error {
+	filterer, err := erc721.NewERC721Filterer(scraper.address, scraper.nftscraper.ethConnection)
+	if err != nil {
+		return err
+	}
+	header, err := scraper.nftscraper.ethConnection.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	// It's a good practise to stay a little behind the head.
+	endBlockNumber := header.Number.Uint64() - scraper.blockDelay
+	startBlockNumber := scraper.startBlockNumber
+	if scraper.lastBlockNumber > 0 {
+		startBlockNumber = scraper.lastBlockNumber + 1
+	}
+	if startBlockNumber > endBlockNumber {
+		return nil
+	}
+
+	err = ethhelper.FilterRange(startBlockNumber, endBlockNumber, func(opts *bind.FilterOpts) error {
+		iter, err := filterer.FilterTransfer(opts, []common.Address{{}}, nil, nil)
+		if err != nil {
+			return err
+		}
+		for iter.Next() {
+			tokenID := iter.Event.TokenId.String()
+			if _, ok := scraper.mints[tokenID]; !ok {
+				scraper.mints[tokenID] = erc721Mint{
+					creator:     iter.Event.To,
+					blockNumber: iter.Event.Raw.BlockNumber,
+				}
+			}
+		}
+		return iter.Error()
+	})
+	if err != nil {
+		return err
+	}
+	scraper.lastBlockNumber = endBlockNumber
+	return nil
+}
+
+// mintedTokens returns the ids of all minted tokens in ascending order.
+func (scraper *GenericERC721Scraper) mintedTokens() []*big.Int {
+	var tokenIDs []*big.Int
+	for id := range scraper.mints {
+		tokenID, ok := new(big.Int).SetString(id, 10)
+		if ok {
+			tokenIDs = append(tokenIDs, tokenID)
+		}
+	}
+	sort.Slice(tokenIDs, func(i, j int) bool { return tokenIDs[i].Cmp(tokenIDs[j]) < 0 })
+	return tokenIDs
+}
+
+func (scraper *GenericERC721Scraper) blockTime(blockNumber uint64) (time.Time, error) {
+	header, err := scraper.nftscraper.ethConnection.HeaderByNumber(context.Background(), new(big.Int).SetUint64(blockNumber))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(int64(header.Time), 0), nil
+}
+
+// GetDataChannel returns the scrapers data channel.
+func (scraper *GenericERC721Scraper) GetDataChannel() chan dia.NFT {
+	return scraper.nftscraper.chanData
+}
+
+// closes all connected Scrapers. Must only be called from mainLoop
+func (scraper *GenericERC721Scraper) cleanup(err error) {
+	scraper.nftscraper.errorLock.Lock()
+	defer scraper.nftscraper.errorLock.Unlock()
+	scraper.ticker.Stop()
+	if err != nil {
+		scraper.nftscraper.error = err
+	}
+	scraper.nftscraper.closed = true
+	close(scraper.nftscraper.shutdownDone) // signal that shutdown is complete
+}
+
+// Close closes any existing API connections
+func (scraper *GenericERC721Scraper) Close() error {
+	if scraper.nftscraper.closed {
+		return errors.New("scraper already closed")
+	}
+	close(scraper.nftscraper.shutdown)
+	<-scraper.nftscraper.shutdownDone
+	scraper.nftscraper.errorLock.RLock()
+	defer scraper.nftscraper.errorLock.RUnlock()
+	return scraper.nftscraper.error
+}
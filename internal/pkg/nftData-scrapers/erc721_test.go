@@ -0,0 +1,66 @@
+package nftdatascrapers
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+// testEnumerable holds the token ids by index and counts the calls of tokenByIndex.
+type testEnumerable struct {
+	tokenIDs []int64
+	calls    int
+}
+
+func (e *testEnumerable) TotalSupply(opts *bind.CallOpts) (*big.Int, error) {
+	return big.NewInt(int64(len(e.tokenIDs))), nil
+}
+
+func (e *testEnumerable) TokenByIndex(opts *bind.CallOpts, index *big.Int) (*big.Int, error) {
+	e.calls++
+	if index.Int64() >= int64(len(e.tokenIDs)) {
+		return nil, errors.New("index out of bounds")
+	}
+	return big.NewInt(e.tokenIDs[index.Int64()]), nil
+}
+
+func TestEnumerateTokens(t *testing.T) {
+	contract := &testEnumerable{tokenIDs: []int64{7, 3, 12, 5}}
+	tokenIDs, err := enumerateTokens(contract, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokenIDs) != 2 || tokenIDs[0].Int64() != 12 || tokenIDs[1].Int64() != 5 {
+		t.Errorf("tokens %v", tokenIDs)
+	}
+	if contract.calls != 2 {
+		t.Errorf("%d calls of tokenByIndex, expected 2", contract.calls)
+	}
+
+	tokenIDs, err = enumerateTokens(contract, 4)
+	if err != nil || len(tokenIDs) != 0 {
+		t.Errorf("tokens %v, err %v", tokenIDs, err)
+	}
+	if _, err := enumerateTokens(&testEnumerable{}, 0); err == nil {
+		t.Error("expected an error for a total supply of zero")
+	}
+}
+
+func TestMintedTokens(t *testing.T) {
+	scraper := &GenericERC721Scraper{mints: map[string]erc721Mint{
+		"10": {}, "9": {}, "100": {}, "1": {},
+	}}
+	tokenIDs := scraper.mintedTokens()
+	expected := []int64{1, 9, 10, 100}
+	if len(tokenIDs) != len(expected) {
+		t.Fatalf("tokens %v", tokenIDs)
+	}
+	for i, tokenID := range tokenIDs {
+		if tokenID.Int64() != expected[i] {
+			t.Errorf("tokens %v, expected %v", tokenIDs, expected)
+			break
+		}
+	}
+}
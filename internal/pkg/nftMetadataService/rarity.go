@@ -0,0 +1,110 @@
+package nftmetadata
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+// Traits returns the traits of a metadata document following the OpenSea metadata standard,
+// i.e. the entries of its "attributes" list mapping trait_type to value. Numeric traits which
+// carry a display_type such as boost_number or date are no categories and are left out.
+func Traits(document dia.NFTAttributes) map[string]string {
+	traits := make(map[string]string)
+	attributes, ok := document["attributes"].([]interface{})
+	if !ok {
+		return traits
+	}
+	for _, attribute := range attributes {
+		entry, ok := attribute.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if _, ok := entry["display_type"]; ok {
+			continue
+		}
+		traitType, ok := entry["trait_type"].(string)
+		if !ok || traitType == "" || entry["value"] == nil {
+			continue
+		}
+		traits[traitType] = fmt.Sprint(entry["value"])
+	}
+	return traits
+}
+
+// MetadataTraits returns the traits of the off-chain document of @metadata, or of its on-chain
+// attributes if the off-chain document has none.
+func MetadataTraits(metadata dia.NFTMetadata) map[string]string {
+	traits := Traits(metadata.OffchainAttributes)
+	if len(traits) == 0 {
+		traits = Traits(metadata.OnchainAttributes)
+	}
+	return traits
+}
+
+// TokenRarity is the rarity score of an NFT and its rank within the class, starting at 1.
+type TokenRarity struct {
+	Score float64
+	Rank  int
+}
+
+// ComputeRarity returns the rarity of each trait value in @nftclass and the rarity of each token,
+// given the traits of all tokens in the class indexed by token id. The score of a trait value is the
+// number of tokens divided by the number of tokens with that value, the score of a token is the sum of
+// the scores of its traits. Tokens with equal scores share a rank.
+func ComputeRarity(nftclass dia.NFTClass, tokenTraits map[string]map[string]string, updateTime time.Time) ([]dia.NFTTraitRarity, map[string]TokenRarity) {
+	type traitValue struct {
+		traitType string
+		value     string
+	}
+	counts := make(map[traitValue]int)
+	for _, traits := range tokenTraits {
+		for traitType, value := range traits {
+			counts[traitValue{traitType, value}]++
+		}
+	}
+
+	total := float64(len(tokenTraits))
+	var rarities []dia.NFTTraitRarity
+	for tv, count := range counts {
+		rarities = append(rarities, dia.NFTTraitRarity{
+			NFTClass:   nftclass,
+			TraitType:  tv.traitType,
+			Value:      tv.value,
+			Count:      count,
+			Score:      total / float64(count),
+			UpdateTime: updateTime,
+		})
+	}
+	sort.Slice(rarities, func(i, j int) bool {
+		if rarities[i].TraitType != rarities[j].TraitType {
+			return rarities[i].TraitType < rarities[j].TraitType
+		}
+		return rarities[i].Value < rarities[j].Value
+	})
+
+	var tokenIDs []string
+	tokens := make(map[string]TokenRarity)
+	for tokenID, traits := range tokenTraits {
+		var score float64
+		for traitType, value := range traits {
+			score += total / float64(counts[traitValue{traitType, value}])
+		}
+		tokens[tokenID] = TokenRarity{Score: score}
+		tokenIDs = append(tokenIDs, tokenID)
+	}
+	sort.Slice(tokenIDs, func(i, j int) bool {
+		return tokens[tokenIDs[i]].Score > tokens[tokenIDs[j]].Score
+	})
+	for i, tokenID := range tokenIDs {
+		rarity := tokens[tokenID]
+		rarity.Rank = i + 1
+		if i > 0 && tokens[tokenIDs[i-1]].Score == rarity.Score {
+			rarity.Rank = tokens[tokenIDs[i-1]].Rank
+		}
+		tokens[tokenID] = rarity
+	}
+	return rarities, tokens
+}
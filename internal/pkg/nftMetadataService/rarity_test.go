@@ -0,0 +1,58 @@
+package nftmetadata
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+func TestTraits(t *testing.T) {
+	var document dia.NFTAttributes
+	err := json.Unmarshal([]byte(`{"name":"Bag #1","attributes":[
+		{"trait_type":"Background","value":"Blue"},
+		{"trait_type":"Eyes","value":3},
+		{"trait_type":"Level","value":5,"display_type":"number"},
+		{"value":"untyped"}]}`), &document)
+	if err != nil {
+		t.Fatal(err)
+	}
+	traits := Traits(document)
+	if len(traits) != 2 || traits["Background"] != "Blue" || traits["Eyes"] != "3" {
+		t.Errorf("traits %v", traits)
+	}
+
+	metadata := dia.NFTMetadata{OnchainAttributes: document, OffchainAttributes: dia.NFTAttributes{}}
+	if len(MetadataTraits(metadata)) != 2 {
+		t.Error("expected fallback to on-chain traits")
+	}
+}
+
+func TestComputeRarity(t *testing.T) {
+	tokenTraits := map[string]map[string]string{
+		"1": {"Background": "Blue", "Hat": "Crown"},
+		"2": {"Background": "Blue", "Hat": "Cap"},
+		"3": {"Background": "Blue", "Hat": "Cap"},
+		"4": {"Background": "Red", "Hat": "Cap"},
+	}
+	traits, tokens := ComputeRarity(dia.NFTClass{}, tokenTraits, time.Now())
+	if len(traits) != 4 {
+		t.Fatalf("traits %v", traits)
+	}
+	if traits[0].TraitType != "Background" || traits[0].Value != "Blue" || traits[0].Count != 3 || traits[0].Score != 4.0/3 {
+		t.Errorf("trait %+v", traits[0])
+	}
+
+	expected := map[string]TokenRarity{
+		"1": {Score: 4.0/3 + 4, Rank: 1},
+		"2": {Score: 4.0/3 + 4.0/3, Rank: 3},
+		"3": {Score: 4.0/3 + 4.0/3, Rank: 3},
+		"4": {Score: 4 + 4.0/3, Rank: 1},
+	}
+	for tokenID, rarity := range expected {
+		if tokens[tokenID] != rarity {
+			t.Errorf("token %s: %+v, expected %+v", tokenID, tokens[tokenID], rarity)
+		}
+	}
+}
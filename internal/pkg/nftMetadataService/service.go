@@ -0,0 +1,193 @@
+package nftmetadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/diadata-org/diadata/config/nftContracts/erc1155"
+	"github.com/diadata-org/diadata/config/nftContracts/erc721"
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/ethhelper"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/nfthelper"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	log "github.com/sirupsen/logrus"
+)
+
+// Config holds the parameters of the metadata refresh.
+type Config struct {
+	// RefreshPeriod is the age after which the metadata of an NFT is fetched again.
+	RefreshPeriod time.Duration
+	// BatchSize is the maximal number of NFTs refreshed in one run.
+	BatchSize int
+}
+
+// Service refreshes the metadata of NFTs and the trait rarities of their classes.
+type Service struct {
+	rdb      models.RelDatastore
+	resolver *nfthelper.URIResolver
+	config   Config
+	clients  map[string]*ethhelper.EVMClient
+}
+
+// NewService returns a metadata service resolving token URIs with @resolver.
+func NewService(rdb models.RelDatastore, resolver *nfthelper.URIResolver, config Config) *Service {
+	return &Service{
+		rdb:      rdb,
+		resolver: resolver,
+		config:   config,
+		clients:  make(map[string]*ethhelper.EVMClient),
+	}
+}
+
+// Refresh fetches the metadata of the NFTs which were not refreshed within the refresh period and
+// recomputes the rarities of their classes. It returns the number of refreshed NFTs.
+func (s *Service) Refresh(ctx context.Context) (int, error) {
+	nfts, err := s.rdb.GetNFTsForMetadataRefresh(time.Now().Add(-s.config.RefreshPeriod), s.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var refreshed int
+	classes := make(map[string]dia.NFTClass)
+	for _, nft := range nfts {
+		metadata := s.FetchMetadata(ctx, nft)
+		if metadata.FetchError != "" {
+			log.Warnf("metadata of %s %s: %s", nft.NFTClass.Address, nft.TokenID, metadata.FetchError)
+		}
+		err = s.rdb.SetNFTMetadata(metadata)
+		if err != nil {
+			log.Errorf("set metadata of %s %s: %v", nft.NFTClass.Address, nft.TokenID, err)
+			continue
+		}
+		classes[nft.NFTClass.Blockchain+"-"+nft.NFTClass.Address] = nft.NFTClass
+		refreshed++
+	}
+
+	for _, nftclass := range classes {
+		err = s.UpdateRarity(nftclass)
+		if err != nil {
+			log.Errorf("update rarity of %s: %v", nftclass.Address, err)
+		}
+	}
+	return refreshed, nil
+}
+
+// FetchMetadata reads the token URI of @nft from its contract and resolves the document it points to.
+// Documents embedded in data URIs are on-chain attributes. NFTs without token URI in their contract,
+// such as CryptoPunks, keep the attributes the data scrapers read from the contract as on-chain attributes.
+func (s *Service) FetchMetadata(ctx context.Context, nft dia.NFT) dia.NFTMetadata {
+	metadata := dia.NFTMetadata{
+		NFT:               nft,
+		OnchainAttributes: dia.NFTAttributes{},
+		OnchainFetchTime:  time.Now(),
+	}
+
+	tokenURI, err := s.tokenURI(ctx, nft)
+	if err != nil {
+		log.Debugf("token uri of %s %s: %v", nft.NFTClass.Address, nft.TokenID, err)
+		tokenURI = nft.URI
+	}
+	metadata.TokenURI = tokenURI
+	if tokenURI == "" {
+		if len(nft.Attributes) > 0 {
+			metadata.OnchainAttributes = nft.Attributes
+		} else {
+			metadata.FetchError = "no token uri"
+		}
+		return metadata
+	}
+
+	document, resolvedURL, err := s.resolver.Resolve(ctx, tokenURI)
+	if err != nil {
+		metadata.FetchError = err.Error()
+		return metadata
+	}
+	if nfthelper.IsDataURI(tokenURI) {
+		metadata.OnchainAttributes = document
+		return metadata
+	}
+	metadata.OffchainAttributes = document
+	metadata.OffchainFetchTime = time.Now()
+	metadata.ResolvedURL = resolvedURL
+	return metadata
+}
+
+// tokenURI reads the current token URI of @nft from its ERC721 or ERC1155 contract.
+func (s *Service) tokenURI(ctx context.Context, nft dia.NFT) (string, error) {
+	client, err := s.client(nft.NFTClass.Blockchain)
+	if err != nil {
+		return "", err
+	}
+	tokenID, ok := new(big.Int).SetString(nft.TokenID, 10)
+	if !ok {
+		return "", errors.New("invalid token id " + nft.TokenID)
+	}
+	address := common.HexToAddress(nft.NFTClass.Address)
+	callOpts := &bind.CallOpts{Context: ctx}
+
+	switch strings.ToUpper(nft.NFTClass.ContractType) {
+	case "ERC721":
+		contract, err := erc721.NewERC721MetadataCaller(address, client)
+		if err != nil {
+			return "", err
+		}
+		return contract.TokenURI(callOpts, tokenID)
+	case "ERC1155":
+		contract, err := erc1155.NewERC1155MetadataCaller(address, client)
+		if err != nil {
+			return "", err
+		}
+		uri, err := contract.Uri(callOpts, tokenID)
+		if err != nil {
+			return "", err
+		}
+		// the uri may contain the placeholder {id} for the hex token id padded to 64 characters
+		return strings.ReplaceAll(uri, "{id}", fmt.Sprintf("%064x", tokenID)), nil
+	default:
+		return "", errors.New("no token uri in contract type " + nft.NFTClass.ContractType)
+	}
+}
+
+// client returns a connection to @blockchain, dialing it on first use.
+func (s *Service) client(blockchain string) (*ethhelper.EVMClient, error) {
+	if client, ok := s.clients[blockchain]; ok {
+		return client, nil
+	}
+	client, err := ethhelper.NewEVMClient(blockchain)
+	if err != nil {
+		return nil, err
+	}
+	s.clients[blockchain] = client
+	return client, nil
+}
+
+// UpdateRarity recomputes the trait rarities of @nftclass and the rarity scores of its NFTs.
+func (s *Service) UpdateRarity(nftclass dia.NFTClass) error {
+	metadata, err := s.rdb.GetNFTClassMetadata(nftclass)
+	if err != nil {
+		return err
+	}
+	tokenTraits := make(map[string]map[string]string)
+	for _, m := range metadata {
+		tokenTraits[m.NFT.TokenID] = MetadataTraits(m)
+	}
+
+	traits, tokens := ComputeRarity(nftclass, tokenTraits, time.Now())
+	err = s.rdb.SetNFTTraitRarities(nftclass, traits)
+	if err != nil {
+		return err
+	}
+	for tokenID, rarity := range tokens {
+		err = s.rdb.SetNFTRarityScore(dia.NFT{NFTClass: nftclass, TokenID: tokenID}, rarity.Score, rarity.Rank)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -44,6 +44,7 @@ func NewCryptokittiesScraper(rdb *models.RelDB) *CryptokittiesScraper {
 		ethConnection: connection,
 		datastore:     rdb,
 		chanOffer:     make(chan dia.NFTOffer),
+		chanClose:     make(chan dia.NFTOrderClose),
 	}
 	s := &CryptokittiesScraper{
 		contractAddress: common.HexToAddress("0xb1690C08E213a35Ed9bAb7B318DE14420FB57d8C"),
@@ -174,6 +175,11 @@ func (scraper *CryptokittiesScraper) FetchOffers() error {
 		break
 	}
 
+	err = scraper.fetchCloses(filterer, nftclass, endBlockNumber)
+	if err != nil {
+		return err
+	}
+
 	// Update the last lastBlockNumber value.
 	scraper.lastBlockNumber = endBlockNumber
 	return nil
@@ -184,6 +190,45 @@ func (scraper *CryptokittiesScraper) GetOfferChannel() chan dia.NFTOffer {
 	return scraper.offerScraper.chanOffer
 }
 
+// fetchCloses sends the cancelled and successful auctions up to @endBlockNumber to the close channel.
+func (scraper *CryptokittiesScraper) fetchCloses(filterer *cryptokitties.SaleClockAuctionFilterer, nftclass dia.NFTClass, endBlockNumber uint64) error {
+	err := ethhelper.FilterRange(scraper.lastBlockNumber, endBlockNumber, func(opts *bind.FilterOpts) error {
+		iter, err := filterer.FilterAuctionCancelled(opts)
+		if err != nil {
+			return err
+		}
+		for iter.Next() {
+			err = scraper.offerScraper.sendClose(nftclass, iter.Event.TokenId, dia.NFTOrderCancelled, iter.Event.Raw, "CrypoKittiesMarket")
+			if err != nil {
+				return err
+			}
+		}
+		return iter.Error()
+	})
+	if err != nil {
+		return err
+	}
+
+	return ethhelper.FilterRange(scraper.lastBlockNumber, endBlockNumber, func(opts *bind.FilterOpts) error {
+		iter, err := filterer.FilterAuctionSuccessful(opts)
+		if err != nil {
+			return err
+		}
+		for iter.Next() {
+			err = scraper.offerScraper.sendClose(nftclass, iter.Event.TokenId, dia.NFTOrderFilled, iter.Event.Raw, "CrypoKittiesMarket")
+			if err != nil {
+				return err
+			}
+		}
+		return iter.Error()
+	})
+}
+
+// GetCloseChannel returns the channel of cancelled and filled offers.
+func (scraper *CryptokittiesScraper) GetCloseChannel() chan dia.NFTOrderClose {
+	return scraper.offerScraper.chanClose
+}
+
 // closes all connected Scrapers. Must only be called from mainLoop
 func (scraper *CryptokittiesScraper) cleanup(err error) {
 	scraper.offerScraper.errorLock.Lock()
@@ -41,6 +41,7 @@ func NewCryptoPunksScraper(rdb *models.RelDB) *CryptoPunksScraper {
 		ethConnection: connection,
 		datastore:     rdb,
 		chanOffer:     make(chan dia.NFTOffer),
+		chanClose:     make(chan dia.NFTOrderClose),
 	}
 	s := &CryptoPunksScraper{
 		contractAddress: common.HexToAddress("0xb47e3cd837dDF8e4c57F05d70Ab865de6e193BBB"),
@@ -168,6 +169,11 @@ func (scraper *CryptoPunksScraper) FetchOffers() error {
 		break
 	}
 
+	err = scraper.fetchCloses(filterer, nftclass, endBlockNumber)
+	if err != nil {
+		return err
+	}
+
 	// Update the last lastBlockNumber value.
 	scraper.lastBlockNumber = endBlockNumber
 	return nil
@@ -178,6 +184,46 @@ func (scraper *CryptoPunksScraper) GetOfferChannel() chan dia.NFTOffer {
 	return scraper.offerScraper.chanOffer
 }
 
+// fetchCloses sends the withdrawals and sales of punks up to @endBlockNumber to the close channel.
+func (scraper *CryptoPunksScraper) fetchCloses(filterer *cryptopunk.CryptoPunksMarketFilterer, nftclass dia.NFTClass, endBlockNumber uint64) error {
+	// Withdrawals, transfers and sales all emit PunkNoLongerForSale, the latter in addition to PunkBought.
+	err := ethhelper.FilterRange(scraper.lastBlockNumber, endBlockNumber, func(opts *bind.FilterOpts) error {
+		iter, err := filterer.FilterPunkNoLongerForSale(opts, nil)
+		if err != nil {
+			return err
+		}
+		for iter.Next() {
+			err = scraper.offerScraper.sendClose(nftclass, iter.Event.PunkIndex, dia.NFTOrderCancelled, iter.Event.Raw, "CrypoPunksMarket")
+			if err != nil {
+				return err
+			}
+		}
+		return iter.Error()
+	})
+	if err != nil {
+		return err
+	}
+
+	return ethhelper.FilterRange(scraper.lastBlockNumber, endBlockNumber, func(opts *bind.FilterOpts) error {
+		iter, err := filterer.FilterPunkBought(opts, nil, nil, nil)
+		if err != nil {
+			return err
+		}
+		for iter.Next() {
+			err = scraper.offerScraper.sendClose(nftclass, iter.Event.PunkIndex, dia.NFTOrderFilled, iter.Event.Raw, "CrypoPunksMarket")
+			if err != nil {
+				return err
+			}
+		}
+		return iter.Error()
+	})
+}
+
+// GetCloseChannel returns the channel of cancelled and filled offers.
+func (scraper *CryptoPunksScraper) GetCloseChannel() chan dia.NFTOrderClose {
+	return scraper.offerScraper.chanClose
+}
+
 // closes all connected Scrapers. Must only be called from mainLoop
 func (scraper *CryptoPunksScraper) cleanup(err error) {
 	scraper.offerScraper.errorLock.Lock()
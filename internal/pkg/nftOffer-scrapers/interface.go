@@ -1,10 +1,14 @@
 package nftofferscrapers
 
 import (
+	"context"
+	"math/big"
 	"sync"
+	"time"
 
 	"github.com/diadata-org/diadata/pkg/dia"
 	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
@@ -13,6 +17,8 @@ type nothing struct{}
 type NFTOfferScraper interface {
 	// NFT bids should be streamed through dia.NFTBid channel.
 	GetOfferChannel() chan dia.NFTOffer
+	// Cancellations and fills of offers should be streamed through dia.NFTOrderClose channel.
+	GetCloseChannel() chan dia.NFTOrderClose
 	// Should fetch bids and send them to the channel.
 	FetchOffers() error
 }
@@ -30,4 +36,27 @@ type OfferScraper struct {
 	ethConnection *ethclient.Client
 	datastore     *models.RelDB
 	chanOffer     chan dia.NFTOffer
+	chanClose     chan dia.NFTOrderClose
+}
+
+// sendClose sends the closing of the offers on @tokenID on @exchange by the event @raw to the close channel.
+func (scraper *OfferScraper) sendClose(nftclass dia.NFTClass, tokenID *big.Int, reason string, raw types.Log, exchange string) error {
+	header, err := scraper.ethConnection.HeaderByNumber(context.Background(), new(big.Int).SetUint64(raw.BlockNumber))
+	if err != nil {
+		return err
+	}
+	scraper.chanClose <- dia.NFTOrderClose{
+		NFT: dia.NFT{
+			NFTClass: nftclass,
+			TokenID:  tokenID.String(),
+		},
+		Side:          dia.NFTOfferSide,
+		Reason:        reason,
+		BlockNumber:   raw.BlockNumber,
+		BlockPosition: uint64(raw.Index),
+		Timestamp:     time.Unix(int64(header.Time), 0),
+		TxHash:        raw.TxHash.Hex(),
+		Exchange:      exchange,
+	}
+	return nil
 }
@@ -0,0 +1,29 @@
+package nfttradescrapers
+
+import (
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/onflow/flow-go-sdk/client"
+)
+
+// ChainClient is the connection of an NFT trade scraper to the blockchain its marketplace runs on,
+// such that the same marketplace logic can scrape several chains. Ethereum compatible chains are
+// connected through ethhelper.EVMClient.
+type ChainClient interface {
+	// Blockchain returns the name of the chain as used in dia.NFTClass.
+	Blockchain() string
+	// NativeCurrency returns the symbol and decimals of the chain's native currency.
+	NativeCurrency() (string, int)
+}
+
+// FlowClient is a ChainClient for Flow.
+type FlowClient struct {
+	*client.Client
+}
+
+func (c *FlowClient) Blockchain() string {
+	return dia.FLOW
+}
+
+func (c *FlowClient) NativeCurrency() (string, int) {
+	return "FLOW", 8
+}
@@ -36,11 +36,13 @@ func NewCryptoKittiesScraper(rdb *models.RelDB) *CryptoKittiesScraper {
 		log.Error("Error connecting Eth Client")
 	}
 
+	ethConnection := ethhelper.NewEthereumClient(connection)
 	tradeScraper := TradeScraper{
 		shutdown:      make(chan nothing),
 		shutdownDone:  make(chan nothing),
 		error:         nil,
-		ethConnection: connection,
+		chain:         ethConnection,
+		ethConnection: ethConnection,
 		datastore:     rdb,
 		chanTrade:     make(chan dia.NFTTrade),
 	}
@@ -8,29 +8,30 @@ import (
 	"fmt"
 	"math/big"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/diadata-org/diadata/config/nftContracts/cryptopunk"
+	evmindexer "github.com/diadata-org/diadata/internal/pkg/evmIndexer"
 	"github.com/diadata-org/diadata/pkg/dia"
 	"github.com/diadata-org/diadata/pkg/dia/helpers/ethhelper"
-
-	// "github.com/diadata-org/diadata/pkg/dia/helpers/ethhelper"
 	models "github.com/diadata-org/diadata/pkg/model"
 	"github.com/ethereum/go-ethereum/accounts/abi"
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 )
 
 const (
 	CryptoPunkRefreshDelay = time.Second * 60 * 10
 	cryptoPunksFirstBlock  = 3918000
+	cryptoPunksIndexerName = "cryptopunks_trades"
 )
 
 type CryptoPunkScraper struct {
 	tradescraper    TradeScraper
 	contractAddress common.Address
+	contractABI     abi.ABI
+	indexer         *evmindexer.Indexer
 	ticker          *time.Ticker
-	lastBlockNumber uint64
 }
 
 func NewCryptoPunkScraper(rdb *models.RelDB) *CryptoPunkScraper {
@@ -38,16 +39,15 @@ func NewCryptoPunkScraper(rdb *models.RelDB) *CryptoPunkScraper {
 	if err != nil {
 		log.Error("Error connecting Eth Client")
 	}
-	// connection, err := ethclient.Dial("node url")
-	// if err != nil {
-	// 	log.Error("Error connecting Eth Client")
-	// }
 
+	ethConnection := ethhelper.NewEthereumClient(connection)
 	tradeScraper := TradeScraper{
 		shutdown:      make(chan nothing),
 		shutdownDone:  make(chan nothing),
+		errorLock:     new(sync.RWMutex),
 		error:         nil,
-		ethConnection: connection,
+		chain:         ethConnection,
+		ethConnection: ethConnection,
 		datastore:     rdb,
 		chanTrade:     make(chan dia.NFTTrade),
 	}
@@ -56,6 +56,32 @@ func NewCryptoPunkScraper(rdb *models.RelDB) *CryptoPunkScraper {
 		tradescraper:    tradeScraper,
 		ticker:          time.NewTicker(CryptoPunkRefreshDelay),
 	}
+	// We need the cryptopunk abi to decode PunkBought events and to unpack the transfer event.
+	s.contractABI, err = abi.JSON(strings.NewReader(string(cryptopunk.CryptoPunksMarketABI)))
+	if err != nil {
+		log.Fatal("parse cryptopunks abi: ", err)
+	}
+
+	// The start block is only used if the indexer has no stored state yet. Continue after the
+	// last stored trade, or fall back to CryptoPunks first block number.
+	startBlock := uint64(cryptoPunksFirstBlock)
+	lastBlock, err := rdb.GetLastBlockNFTTradeScraper(dia.NFTClass{
+		Address:    s.contractAddress.Hex(),
+		Blockchain: dia.ETHEREUM,
+	})
+	if err == nil && lastBlock > startBlock {
+		startBlock = lastBlock + 1
+	}
+	s.indexer = evmindexer.New(evmindexer.Config{
+		Name:          cryptoPunksIndexerName,
+		StartBlock:    startBlock,
+		Confirmations: blockDelayEthereum,
+	}, ethConnection, rdb)
+	// We're interested in the PunkBought events when actual trades happened!
+	if err := s.indexer.Register(s.contractAddress, s.contractABI, "PunkBought", s); err != nil {
+		log.Fatal("register PunkBought: ", err)
+	}
+
 	fmt.Println("scraper built. Start main loop.")
 	go s.mainLoop()
 	return s
@@ -76,161 +102,122 @@ func (scraper *CryptoPunkScraper) mainLoop() {
 			}
 		case <-scraper.tradescraper.shutdown: // user requested shutdown
 			log.Printf("CryptoPunk scraper shutting down")
-			err := scraper.Close()
-			scraper.cleanup(err)
+			scraper.cleanup(nil)
 			return
 		}
 	}
 }
 
+// FetchTrades indexes all confirmed blocks since the last call and sends the trades in them
+// to the trade channel.
 func (scraper *CryptoPunkScraper) FetchTrades() error {
 	log.Info("fetch trades...")
-	var err error
-	if scraper.lastBlockNumber == 0 {
-		scraper.lastBlockNumber, err = scraper.tradescraper.datastore.GetLastBlockNFTTradeScraper(dia.NFTClass{
-			Address:    scraper.contractAddress.Hex(),
-			Blockchain: dia.ETHEREUM,
-		})
-		if err != nil {
-			// We couldn't find a last block number, fallback to CryptoPunks first block number!
-			scraper.lastBlockNumber = uint64(cryptoPunksFirstBlock)
-		}
-	}
+	return scraper.indexer.Sync(context.Background())
+}
 
-	scraper.lastBlockNumber = uint64(12453867)
-	filterer, err := cryptopunk.NewCryptoPunksMarketFilterer(scraper.contractAddress, scraper.tradescraper.ethConnection)
-	if err != nil {
+// HandleEvent sends the trade of a PunkBought @event to the trade channel. Trades which are
+// delivered again by the indexer are rejected by the unique constraint of the trades table.
+func (scraper *CryptoPunkScraper) HandleEvent(ctx context.Context, event evmindexer.Event) error {
+	trade, ok, err := scraper.punkBoughtTrade(ctx, event)
+	if err != nil || !ok {
 		return err
 	}
-
-	// Get latest block number.
-	header, err := scraper.tradescraper.ethConnection.HeaderByNumber(context.Background(), nil)
-	if err != nil {
-		return err
+	select {
+	case scraper.GetTradeChannel() <- trade:
+	case <-scraper.tradescraper.shutdown:
+		return errors.New("scraper shutting down")
 	}
 
-	// It's a good practise to stay a little behind the head.
-	endBlockNumber := header.Number.Uint64() - blockDelayEthereum
+	log.Infof("got trade: ")
+	log.Info("price: ", trade.Price)
+	log.Info("from address: ", trade.FromAddress)
+	log.Info("to address(from the tx Transfer event): ", trade.ToAddress)
+	log.Info("tx: ", trade.TxHash)
+	log.Info("blockNumber: ", trade.BlockNumber)
+	log.Info("id: ", trade.NFT.TokenID)
+	log.Info("-----------------------------------------------")
+	return nil
+}
 
-	// We need the cryptopunk abi to unpack the transfer event.
-	abi, err := abi.JSON(strings.NewReader(string(cryptopunk.CryptoPunksMarketABI)))
+// RetractEvent removes the trade of a PunkBought @event whose block is no longer canonical.
+func (scraper *CryptoPunkScraper) RetractEvent(ctx context.Context, event evmindexer.Event) error {
+	punkIndex, ok := event.Args["punkIndex"].(*big.Int)
+	if !ok {
+		return nil
+	}
+	nft, err := scraper.tradescraper.datastore.GetNFT(scraper.contractAddress.Hex(), dia.ETHEREUM, punkIndex.String())
 	if err != nil {
-		return err
+		return nil
 	}
-	var iter *cryptopunk.CryptoPunksMarketPunkBoughtIterator
-	// Reduce the window size while there is an query limit error.
-	for {
-		fmt.Println("lastBlockNumber, endBlockNumber: ", scraper.lastBlockNumber, endBlockNumber)
-		// We're interested in the FilterPunkBought events when actual trades happened!
-		iter, err = filterer.FilterPunkBought(&bind.FilterOpts{
-			Start: scraper.lastBlockNumber,
-			End:   &endBlockNumber,
-		}, nil, nil, nil)
-		if err != nil {
-			if err.Error() == "query returned more than 10000 results" {
-				fmt.Println("Got `query returned more than 10000 results` error, reduce the window size and try again...")
-				endBlockNumber = scraper.lastBlockNumber + (endBlockNumber-scraper.lastBlockNumber)/2
-				continue
-			}
-			fmt.Println("error filtering FilterPunkBought: ", err)
-			return err
-		}
-
-		// Iter over FilterPunkBought events.
-		for iter.Next() {
-			time.Sleep(1 * time.Second)
-			currHeader, err := scraper.tradescraper.ethConnection.HeaderByNumber(context.Background(), big.NewInt(int64(iter.Event.Raw.BlockNumber)))
-			if err != nil {
-				log.Error("could not fetch current block header: ", err)
-			}
-			nft, err := scraper.tradescraper.datastore.GetNFT(scraper.contractAddress.Hex(), dia.ETHEREUM, iter.Event.PunkIndex.String())
-			if err != nil {
-				// TODO: should we continue if we failed to get NFT from the db or should we fail!
-				continue
-				// 	return nil, err
-			}
-
-			// This is a workaround to a Cryptopunks contract bug that leads to an empty ToAddress.
-			tx, err := scraper.tradescraper.ethConnection.TransactionReceipt(context.TODO(), iter.Event.Raw.TxHash)
-			if err != nil {
-				// TODO: should we continue if we failed to get tx or should we fail!
-				// continue
-				return err
-			}
-			log.Info("tx: ", tx.TxHash.Hex())
+	log.Warnf("retract trade of punk %s in tx %s", punkIndex.String(), event.Log.TxHash.Hex())
+	return scraper.tradescraper.datastore.DeleteNFTTrade(dia.NFTTrade{NFT: nft, TxHash: event.Log.TxHash.Hex()})
+}
 
-			var transferEvent struct {
-				From  common.Address
-				To    common.Address
-				Value *big.Int
-			}
-			for _, vLog := range tx.Logs {
-				log.Info("log", vLog)
-				err := abi.UnpackIntoInterface(&transferEvent, "Transfer", vLog.Data)
-				if err == nil {
-					log.Info("found a Transfer event")
-					transferEvent.To = common.BytesToAddress(vLog.Topics[2].Bytes())
-					break
-				}
-			}
+// punkBoughtTrade returns the trade of a PunkBought @event. It returns false if the punk is unknown.
+func (scraper *CryptoPunkScraper) punkBoughtTrade(ctx context.Context, event evmindexer.Event) (dia.NFTTrade, bool, error) {
+	punkIndex, okIndex := event.Args["punkIndex"].(*big.Int)
+	value, okValue := event.Args["value"].(*big.Int)
+	fromAddress, okFrom := event.Args["fromAddress"].(common.Address)
+	if !okIndex || !okValue || !okFrom {
+		return dia.NFTTrade{}, false, fmt.Errorf("unexpected PunkBought arguments in tx %s", event.Log.TxHash.Hex())
+	}
 
-			price := iter.Event.Value
-			// If acceptBidForPunk is called, get the bid value from the bidding history.
-			// TO DO: Check that transaction input is acceptBidForPunk.
-			if price.Cmp(big.NewInt(0)) == 0 {
-				bid, err := scraper.tradescraper.datastore.GetLastNFTBid(scraper.contractAddress.Hex(), dia.ETHEREUM, iter.Event.PunkIndex.String(), uint64(iter.Event.Raw.BlockNumber), iter.Event.Raw.Index)
-				if err != nil {
-					log.Error("could not find last bid: ", err)
-				}
-				fmt.Println("value is zero. Fetch from bids..")
-				fmt.Println(".. value of bid: ", bid.Value)
-				fmt.Println("block of bid: ", bid.BlockNumber)
-				fmt.Println("position in block: ", bid.BlockPosition)
-				fmt.Println("from address of bid: ", bid.FromAddress)
-				fmt.Println("----------")
-				fmt.Println("block of trade: ", iter.Event.Raw.BlockNumber)
-				fmt.Println("to address of trade: ", transferEvent.To.Hex())
-				if transferEvent.To.Hex() == bid.FromAddress {
-					price = bid.Value
-				} else {
-					log.Warn("fromAddress of bid does not coincide with toAddress of trade: .")
-				}
-			}
+	currHeader, err := scraper.tradescraper.ethConnection.HeaderByNumber(ctx, new(big.Int).SetUint64(event.Log.BlockNumber))
+	if err != nil {
+		return dia.NFTTrade{}, false, err
+	}
+	nft, err := scraper.tradescraper.datastore.GetNFT(scraper.contractAddress.Hex(), dia.ETHEREUM, punkIndex.String())
+	if err != nil {
+		// TODO: should we continue if we failed to get NFT from the db or should we fail!
+		return dia.NFTTrade{}, false, nil
+	}
 
-			trade := dia.NFTTrade{
-				NFT:              nft,
-				BlockNumber:      iter.Event.Raw.BlockNumber,
-				FromAddress:      iter.Event.FromAddress.Hex(),
-				ToAddress:        transferEvent.To.Hex(),
-				Exchange:         "CryptopunkMarket",
-				TxHash:           iter.Event.Raw.TxHash.Hex(),
-				Price:            price,
-				CurrencySymbol:   "ETH",
-				CurrencyDecimals: int32(18),
-				CurrencyAddress:  common.HexToAddress("0x0000000000000000000000000000000000000000").Hex(),
-				Timestamp:        time.Unix(int64(currHeader.Time), 0),
-			}
-			scraper.GetTradeChannel() <- trade
+	// This is a workaround to a Cryptopunks contract bug that leads to an empty ToAddress.
+	tx, err := scraper.tradescraper.ethConnection.TransactionReceipt(ctx, event.Log.TxHash)
+	if err != nil {
+		return dia.NFTTrade{}, false, err
+	}
+	var transferEvent struct {
+		From  common.Address
+		To    common.Address
+		Value *big.Int
+	}
+	for _, vLog := range tx.Logs {
+		err := scraper.contractABI.UnpackIntoInterface(&transferEvent, "Transfer", vLog.Data)
+		if err == nil {
+			transferEvent.To = common.BytesToAddress(vLog.Topics[2].Bytes())
+			break
+		}
+	}
 
-			log.Infof("got trade: ")
-			log.Infof("iter: %v", iter)
-			log.Info("price: ", price)
-			log.Info("from address: ", iter.Event.FromAddress)
-			log.Info("to address: ", iter.Event.ToAddress)
-			log.Info("to address(from the tx Transfer event): ", transferEvent.To)
-			log.Info("tx: ", iter.Event.Raw.TxHash)
-			log.Info("blockNumber: ", big.NewInt(int64(iter.Event.Raw.BlockNumber)))
-			log.Info("id: ", iter.Event.PunkIndex.String())
-			log.Info("-----------------------------------------------")
-			log.Info(" ")
-			log.Info("-----------------------------------------------")
+	price := value
+	// If acceptBidForPunk is called, get the bid value from the bidding history.
+	// TO DO: Check that transaction input is acceptBidForPunk.
+	if price.Cmp(big.NewInt(0)) == 0 {
+		bid, err := scraper.tradescraper.datastore.GetLastNFTBid(scraper.contractAddress.Hex(), dia.ETHEREUM, punkIndex.String(), event.Log.BlockNumber, event.Log.Index)
+		if err != nil {
+			log.Error("could not find last bid: ", err)
+		}
+		if transferEvent.To.Hex() == bid.FromAddress {
+			price = bid.Value
+		} else {
+			log.Warn("fromAddress of bid does not coincide with toAddress of trade: .")
 		}
-		break
 	}
 
-	// Update the last lastBlockNumber value.
-	scraper.lastBlockNumber = endBlockNumber
-	return nil
+	return dia.NFTTrade{
+		NFT:              nft,
+		BlockNumber:      event.Log.BlockNumber,
+		FromAddress:      fromAddress.Hex(),
+		ToAddress:        transferEvent.To.Hex(),
+		Exchange:         "CryptopunkMarket",
+		TxHash:           event.Log.TxHash.Hex(),
+		Price:            price,
+		CurrencySymbol:   "ETH",
+		CurrencyDecimals: int32(18),
+		CurrencyAddress:  common.HexToAddress("0x0000000000000000000000000000000000000000").Hex(),
+		Timestamp:        time.Unix(int64(currHeader.Time), 0),
+	}, true, nil
 }
 
 // GetDataChannel returns the scrapers data channel.
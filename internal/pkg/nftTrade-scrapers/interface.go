@@ -4,8 +4,8 @@ import (
 	"sync"
 
 	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/ethhelper"
 	models "github.com/diadata-org/diadata/pkg/model"
-	"github.com/ethereum/go-ethereum/ethclient"
 )
 
 type nothing struct{}
@@ -25,7 +25,8 @@ type TradeScraper struct {
 	errorLock     *sync.RWMutex
 	error         error
 	closed        bool
-	ethConnection *ethclient.Client
+	chain         ChainClient
+	ethConnection *ethhelper.EVMClient
 	datastore     *models.RelDB
 	chanTrade     chan dia.NFTTrade
 	source        string
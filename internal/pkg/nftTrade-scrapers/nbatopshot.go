@@ -43,6 +43,7 @@ func NewNBATopshotScraper(rdb *models.RelDB) *NBATopshotScraper {
 		shutdown:     make(chan nothing),
 		shutdownDone: make(chan nothing),
 		error:        nil,
+		chain:        &FlowClient{flowClient},
 		datastore:    rdb,
 		chanTrade:    make(chan dia.NFTTrade),
 	}
@@ -117,7 +118,7 @@ func (scraper *NBATopshotScraper) FetchTrades() (err error) {
 	for i, moment := range allMomentsPurchased {
 		e := MomentPurchasedEvent(moment.Value)
 
-		nft, err := scraper.tradescraper.datastore.GetNFT(scraper.address, dia.FLOW, strconv.Itoa(int(e.Id())))
+		nft, err := scraper.tradescraper.datastore.GetNFT(scraper.address, scraper.tradescraper.chain.Blockchain(), strconv.Itoa(int(e.Id())))
 		if err != nil {
 			log.Error("fetch NFT: ", err)
 			return err
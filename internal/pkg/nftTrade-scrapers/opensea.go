@@ -3,34 +3,34 @@ package nfttradescrapers
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"math/big"
-	"net/http"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/diadata-org/diadata/config/nftContracts/erc1155"
 	"github.com/diadata-org/diadata/config/nftContracts/erc20"
 	"github.com/diadata-org/diadata/config/nftContracts/erc721"
 	"github.com/diadata-org/diadata/config/nftContracts/opensea"
 	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/ethhelper"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/nfthelper"
 	models "github.com/diadata-org/diadata/pkg/model"
 	"github.com/diadata-org/diadata/pkg/utils"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/jackc/pgx/v4"
 	"github.com/shopspring/decimal"
 )
 
 const (
-	// we assume all of the NFTs traded on OpenSea are ERC721(1155 is an extension of it)
-	openSeaNFTContractType = "ERC721"
+	// contract types of the NFTs traded on OpenSea
+	erc721ContractType  = "ERC721"
+	erc1155ContractType = "ERC1155"
 
 	OpenSea = "OpenSea"
 
@@ -68,6 +68,10 @@ type OpenSeaScraperConfig struct {
 
 	// it limits duration of read for NFT's metadata from external url
 	MetadataTimeout time.Duration `json:"metadata_timeout"`
+
+	// gateways to resolve ipfs:// and ar:// token uris, public gateways are used if empty
+	IPFSGateways    []string `json:"ipfs_gateways,omitempty"`
+	ArweaveGateways []string `json:"arweave_gateways,omitempty"`
 }
 
 type OpenSeaScraperState struct {
@@ -87,6 +91,10 @@ type OpenSeaScraperState struct {
 type OpenSeaScraper struct {
 	tradeScraper TradeScraper
 
+	// name is the key of the scraper's config and state, such that each chain has its own block cursor
+	name       string
+	quotations models.Datastore
+
 	mu    sync.Mutex
 	conf  *OpenSeaScraperConfig
 	state *OpenSeaScraperState
@@ -101,16 +109,21 @@ type erc20Transfer struct {
 	Decimals    int
 }
 
-type erc721Transfer struct {
-	NFTAddress  common.Address
-	Name        *string
-	Symbol      *string
-	TotalSupply *big.Int
-	From        common.Address
-	To          common.Address
-	TokenID     *big.Int
-	TokenURI    *string
-	TokenAttrs  map[string]interface{}
+// nftTransfer is the transfer of an ERC721 token or of Quantity units of an ERC1155 token.
+// Each token id of an ERC1155 batch transfer is a transfer of its own with the LogIndex of the batch.
+type nftTransfer struct {
+	NFTAddress   common.Address
+	ContractType string
+	Name         *string
+	Symbol       *string
+	TotalSupply  *big.Int
+	From         common.Address
+	To           common.Address
+	TokenID      *big.Int
+	Quantity     *big.Int
+	LogIndex     uint
+	TokenURI     *string
+	TokenAttrs   map[string]interface{}
 }
 
 var (
@@ -137,6 +150,7 @@ var (
 	openSeaABI abi.ABI
 	erc20ABI   abi.ABI
 	erc721ABI  abi.ABI
+	erc1155ABI abi.ABI
 )
 
 func init() {
@@ -156,26 +170,47 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+
+	erc1155ABI, err = abi.JSON(strings.NewReader(erc1155.ERC1155ABI))
+	if err != nil {
+		panic(err)
+	}
 }
 
-func NewOpenSeaScraper(rdb *models.RelDB) *OpenSeaScraper {
+// NewOpenSeaScraper returns a scraper for OpenSea style marketplace contracts on the Ethereum
+// compatible @blockchain.
+//
+// OpenSea's Wyvern exchange, whose OrdersMatched events are decoded, is only deployed on Ethereum.
+// OpenSea trades on Polygon and BSC through other exchange contracts, so on these chains the scraper
+// only runs for a Wyvern compatible marketplace set in its scraper config.
+func NewOpenSeaScraper(rdb *models.RelDB, blockchain string) *OpenSeaScraper {
 	ctx := context.Background()
 
-	eth, err := ethclient.Dial(alchemyapi)
+	eth, err := ethhelper.NewEVMClient(blockchain)
 	if err != nil {
-		log.Errorf("unable to get ethereum client: %s", err.Error())
+		log.Errorf("unable to get %s client: %s", blockchain, err.Error())
 		return nil
 	}
 
+	var quotations models.Datastore
+	if ds, err := models.NewDataStore(); err != nil {
+		log.Errorf("unable to get datastore for currency prices: %s", err.Error())
+	} else {
+		quotations = ds
+	}
+
 	s := &OpenSeaScraper{
-		conf:  &OpenSeaScraperConfig{},
-		state: &OpenSeaScraperState{},
+		name:       openSeaScraperName(blockchain),
+		quotations: quotations,
+		conf:       &OpenSeaScraperConfig{},
+		state:      &OpenSeaScraperState{},
 		tradeScraper: TradeScraper{
 			shutdown:      make(chan nothing),
 			shutdownDone:  make(chan nothing),
 			datastore:     rdb,
 			chanTrade:     make(chan dia.NFTTrade),
 			source:        OpenSea,
+			chain:         eth,
 			ethConnection: eth,
 		},
 	}
@@ -190,6 +225,15 @@ func NewOpenSeaScraper(rdb *models.RelDB) *OpenSeaScraper {
 	return s
 }
 
+// openSeaScraperName returns the name of the scraper on @blockchain. Ethereum keeps the plain name
+// of the existing config and state.
+func openSeaScraperName(blockchain string) string {
+	if blockchain == dia.ETHEREUM {
+		return OpenSea
+	}
+	return OpenSea + "-" + blockchain
+}
+
 // init scraper
 // if there are no values stored previously, use defaults and store them
 func (s *OpenSeaScraper) initScraper(ctx context.Context) error {
@@ -202,35 +246,44 @@ func (s *OpenSeaScraper) initScraper(ctx context.Context) error {
 		// use & store defaults if there is no record in the scraper table
 
 		defConf := *defOpenSeaConf // copy
+		defState := *defOpenSeaState
+		if chain := s.tradeScraper.ethConnection.Chain(); chain.Blockchain != dia.ETHEREUM {
+			// the marketplace contract and its deployment block have to be configured on other chains
+			defConf.ContractAddr = ""
+			defConf.FollowDist = chain.BlockDelay
+			defState = OpenSeaScraperState{}
+		}
 		s.conf = &defConf
-		if err := s.tradeScraper.datastore.SetScraperConfig(ctx, OpenSea, s.conf); err != nil {
+		if err := s.tradeScraper.datastore.SetScraperConfig(ctx, s.name, s.conf); err != nil {
 			log.Errorf("unable to store scraper config on rdb: %s", err.Error())
 			return err
 		}
 
-		defState := *defOpenSeaState // copy
 		s.state = &defState
-		if err := s.tradeScraper.datastore.SetScraperState(ctx, OpenSea, s.state); err != nil {
+		if err := s.tradeScraper.datastore.SetScraperState(ctx, s.name, s.state); err != nil {
 			log.Errorf("unable to store scraper state on rdb: %s", err.Error())
 			return err
 		}
-
-		return nil
+	} else if err := s.loadState(ctx); err != nil {
+		return err
 	}
 
-	return s.loadState(ctx)
+	if s.conf.ContractAddr == "" {
+		return fmt.Errorf("no marketplace contract on %s: set contract_addr in the config and last_block_num in the state of scraper %s", s.tradeScraper.chain.Blockchain(), s.name)
+	}
+	return nil
 }
 
 func (s *OpenSeaScraper) loadConfig(ctx context.Context) error {
-	return s.tradeScraper.datastore.GetScraperConfig(ctx, OpenSea, s.conf)
+	return s.tradeScraper.datastore.GetScraperConfig(ctx, s.name, s.conf)
 }
 
 func (s *OpenSeaScraper) loadState(ctx context.Context) error {
-	return s.tradeScraper.datastore.GetScraperState(ctx, OpenSea, s.state)
+	return s.tradeScraper.datastore.GetScraperState(ctx, s.name, s.state)
 }
 
 func (s *OpenSeaScraper) storeState(ctx context.Context) error {
-	return s.tradeScraper.datastore.SetScraperState(ctx, OpenSea, s.state)
+	return s.tradeScraper.datastore.SetScraperState(ctx, s.name, s.state)
 }
 
 func (s *OpenSeaScraper) mainLoop() {
@@ -284,10 +337,16 @@ func (s *OpenSeaScraper) FetchTrades() error {
 		return err
 	}
 
-	log.Infof("fetching opensea trade transactions from block %d(+%d)", s.state.LastBlockNum, s.conf.BatchSize)
+	if s.conf.ContractAddr == "" {
+		err = fmt.Errorf("no opensea contract configured for scraper %s", s.name)
+		log.Warn(err.Error())
+		return err
+	}
+
+	log.Infof("fetching opensea trade transactions on %s from block %d(+%d)", s.tradeScraper.chain.Blockchain(), s.state.LastBlockNum, s.conf.BatchSize)
 
 	// fetch trade transactions
-	res, err := utils.EthFilterTXs(ctx, s.tradeScraper.ethConnection, utils.EthTxFilterCriteria{
+	res, err := utils.EthFilterTXs(ctx, s.tradeScraper.ethConnection.Client, utils.EthTxFilterCriteria{
 		StartBlockNum:      s.state.LastBlockNum,
 		StartTxIndex:       s.state.LastTxIndex,
 		LimitBlocks:        s.conf.BatchSize,
@@ -390,9 +449,8 @@ func (s *OpenSeaScraper) processTx(ctx context.Context, tx *utils.EthFilteredTx)
 		return false, err
 	}
 
-	currSymbol := "ETH"
+	currSymbol, currDecimals := s.tradeScraper.chain.NativeCurrency()
 	currAddr := common.Address{}
-	currDecimals := 18
 
 	// if an ERC20 token used for the trade
 	if new(big.Int).Cmp(txData.Value()) == 0 {
@@ -419,19 +477,43 @@ func (s *OpenSeaScraper) processTx(ctx context.Context, tx *utils.EthFilteredTx)
 		return false, err
 	}
 
+	multiTransfers, err := s.findERC1155Transfers(ctx, receipt)
+	if err != nil {
+		log.Errorf("unable to find erc1155 transfers of the event(block: %d, tx index: %d, tx: %s): %s", ev.Raw.BlockNumber, ev.Raw.TxIndex, ev.Raw.TxHash.Hex(), err.Error())
+		return false, err
+	}
+
+	transfers = append(transfers, multiTransfers...)
+
 	// skip if the event has no transfer
 	if len(transfers) == 0 {
-		log.Tracef("event(block: %d, tx index: %d, tx: %s) skipped due to it has no erc721 transfer log", ev.Raw.BlockNumber, ev.Raw.TxIndex, ev.Raw.TxHash.Hex())
+		log.Tracef("event(block: %d, tx index: %d, tx: %s) skipped due to it has no erc721 or erc1155 transfer log", ev.Raw.BlockNumber, ev.Raw.TxIndex, ev.Raw.TxHash.Hex())
 		return true, nil
 	}
 
-	// skip if the event has multiple transfers due to we can't calculate the price of trade
-	if len(transfers) > 1 {
-		log.Tracef("event(block: %d, tx index: %d, tx: %s) skipped due to it has multiple erc721 transfer logs", ev.Raw.BlockNumber, ev.Raw.TxIndex, ev.Raw.TxHash.Hex())
+	// skip if the event transfers several tokens due to we can't calculate the price of each of them,
+	// whereas all units of a single token are traded at the same price
+	transfer, ok := singleToken(transfers)
+	if !ok {
+		log.Tracef("event(block: %d, tx index: %d, tx: %s) skipped due to it transfers a bundle of tokens", ev.Raw.BlockNumber, ev.Raw.TxIndex, ev.Raw.TxHash.Hex())
 		return true, nil
 	}
 
-	normPrice := decimal.NewFromBigInt(ev.Price, 0).Div(decimal.NewFromInt(10).Pow(decimal.NewFromInt(int64(currDecimals))))
+	quantity := transfer.Quantity
+	if quantity.Sign() == 0 {
+		log.Tracef("event(block: %d, tx index: %d, tx: %s) skipped due to it transfers no units", ev.Raw.BlockNumber, ev.Raw.TxIndex, ev.Raw.TxHash.Hex())
+		return true, nil
+	}
+
+	header, err := s.tradeScraper.ethConnection.HeaderByNumber(ctx, new(big.Int).SetUint64(ev.Raw.BlockNumber))
+	if err != nil {
+		log.Errorf("unable to read block(%d) header: %s", ev.Raw.BlockNumber, err.Error())
+		return false, err
+	}
+
+	timestamp := time.Unix(int64(header.Time), 0).UTC()
+
+	unitPrice, normPrice := unitPrices(ev.Price, quantity, currDecimals)
 
 	usdPrice, err := s.calcUSDPrice(ev.Raw.BlockNumber, currAddr, currSymbol, normPrice)
 	if err != nil {
@@ -439,7 +521,7 @@ func (s *OpenSeaScraper) processTx(ctx context.Context, tx *utils.EthFilteredTx)
 		return false, err
 	}
 
-	if err := s.notifyTrade(ev, transfers[0], ev.Price, normPrice, usdPrice, currSymbol, currAddr); err != nil {
+	if err := s.notifyTrade(ev, transfer, timestamp, unitPrice, normPrice, usdPrice, currSymbol, currAddr); err != nil {
 		if !errors.Is(err, errOpenSeaShutdownRequest) {
 			log.Warnf("event(block: %d, tx index: %d, tx: %s) couldn't processed: %s", ev.Raw.BlockNumber, ev.Raw.TxIndex, ev.Raw.TxHash.Hex(), err.Error())
 		}
@@ -450,7 +532,34 @@ func (s *OpenSeaScraper) processTx(ctx context.Context, tx *utils.EthFilteredTx)
 	return false, nil
 }
 
-func (s *OpenSeaScraper) notifyTrade(ev *opensea.ContractOrdersMatched, transfer *erc721Transfer, price *big.Int, priceDec decimal.Decimal, usdPrice float64, currSymbol string, currAddr common.Address) error {
+// singleToken returns the transfer of all units if @transfers stem from a single log and move units
+// of a single token, i.e. one erc721 transfer, one erc1155 single transfer or one erc1155 batch
+// transfer whose ids are all the same. Bundles of several tokens are rejected.
+func singleToken(transfers []*nftTransfer) (*nftTransfer, bool) {
+	merged := *transfers[0]
+	merged.Quantity = new(big.Int)
+	for _, transfer := range transfers {
+		if transfer.NFTAddress != merged.NFTAddress || transfer.LogIndex != merged.LogIndex || transfer.TokenID.Cmp(merged.TokenID) != 0 {
+			return nil, false
+		}
+		merged.Quantity.Add(merged.Quantity, transfer.Quantity)
+	}
+	if !merged.Quantity.IsUint64() {
+		return nil, false
+	}
+	return &merged, true
+}
+
+// unitPrices returns the raw price and the price normalized by the currency's @decimals of one of
+// @quantity units traded for @price. The raw unit price is rounded to the nearest integer, whereas
+// the normalized price keeps the fraction.
+func unitPrices(price *big.Int, quantity *big.Int, decimals int) (*big.Int, decimal.Decimal) {
+	unitPrice := new(big.Int).Quo(new(big.Int).Add(price, new(big.Int).Rsh(quantity, 1)), quantity)
+	normPrice := decimal.NewFromBigInt(price, 0).Div(decimal.NewFromBigInt(quantity, 0)).Div(decimal.NewFromInt(10).Pow(decimal.NewFromInt(int64(decimals))))
+	return unitPrice, normPrice
+}
+
+func (s *OpenSeaScraper) notifyTrade(ev *opensea.ContractOrdersMatched, transfer *nftTransfer, timestamp time.Time, price *big.Int, priceDec decimal.Decimal, usdPrice float64, currSymbol string, currAddr common.Address) error {
 	nftClass, err := s.createOrReadNFTClass(transfer)
 	if err != nil {
 		return err
@@ -463,6 +572,7 @@ func (s *OpenSeaScraper) notifyTrade(ev *opensea.ContractOrdersMatched, transfer
 
 	trade := dia.NFTTrade{
 		NFT:              *nft,
+		Quantity:         transfer.Quantity.Uint64(),
 		Price:            price,
 		PriceUSD:         usdPrice,
 		FromAddress:      transfer.From.Hex(),
@@ -471,6 +581,7 @@ func (s *OpenSeaScraper) notifyTrade(ev *opensea.ContractOrdersMatched, transfer
 		CurrencyAddress:  currAddr.Hex(),
 		CurrencyDecimals: priceDec.Exponent(),
 		BlockNumber:      ev.Raw.BlockNumber,
+		Timestamp:        timestamp,
 		TxHash:           ev.Raw.TxHash.Hex(),
 		Exchange:         OpenSea,
 	}
@@ -487,8 +598,8 @@ func (s *OpenSeaScraper) notifyTrade(ev *opensea.ContractOrdersMatched, transfer
 	return nil
 }
 
-func (s *OpenSeaScraper) createOrReadNFTClass(transfer *erc721Transfer) (*dia.NFTClass, error) {
-	nftClass, err := s.tradeScraper.datastore.GetNFTClass(transfer.NFTAddress.Hex(), dia.ETHEREUM)
+func (s *OpenSeaScraper) createOrReadNFTClass(transfer *nftTransfer) (*dia.NFTClass, error) {
+	nftClass, err := s.tradeScraper.datastore.GetNFTClass(transfer.NFTAddress.Hex(), s.tradeScraper.chain.Blockchain())
 	if err != nil {
 		if !errors.Is(err, pgx.ErrNoRows) {
 			log.Warnf("unable to read nftclass from reldb: %s", err.Error())
@@ -497,8 +608,8 @@ func (s *OpenSeaScraper) createOrReadNFTClass(transfer *erc721Transfer) (*dia.NF
 
 		nftClass = dia.NFTClass{
 			Address:      transfer.NFTAddress.Hex(),
-			Blockchain:   dia.ETHEREUM,
-			ContractType: openSeaNFTContractType,
+			Blockchain:   s.tradeScraper.chain.Blockchain(),
+			ContractType: transfer.ContractType,
 		}
 
 		if transfer.Name != nil {
@@ -518,8 +629,8 @@ func (s *OpenSeaScraper) createOrReadNFTClass(transfer *erc721Transfer) (*dia.NF
 	return &nftClass, nil
 }
 
-func (s *OpenSeaScraper) createOrReadNFT(nftClass *dia.NFTClass, transfer *erc721Transfer) (*dia.NFT, error) {
-	nft, err := s.tradeScraper.datastore.GetNFT(nftClass.Address, dia.ETHEREUM, transfer.TokenID.String())
+func (s *OpenSeaScraper) createOrReadNFT(nftClass *dia.NFTClass, transfer *nftTransfer) (*dia.NFT, error) {
+	nft, err := s.tradeScraper.datastore.GetNFT(nftClass.Address, nftClass.Blockchain, transfer.TokenID.String())
 	if err != nil {
 		if !errors.Is(err, pgx.ErrNoRows) {
 			log.Warnf("unable to read nft from reldb: %s", err.Error())
@@ -568,18 +679,22 @@ func (s *OpenSeaScraper) calcUSDPrice(blockNum uint64, tokenAddr common.Address,
 	return f, nil
 }
 
+// findPrice returns the usd price of the currency @symbol on the scraper's chain. The wrapped native
+// currency is priced as the native currency. Currencies without quotation are priced at zero.
 func (s *OpenSeaScraper) findPrice(blockNum uint64, tokenAddr common.Address, symbol string) (decimal.Decimal, error) {
 	// TODO: find the token price in usd for the given block number
-	switch symbol {
-	case "ETH", "WETH":
-		return decimal.NewFromString("2040.0910")
-
-	case "MANA":
-		return decimal.NewFromString("0.5")
-
-	default:
-		return decimal.NewFromString("1")
+	if chain := s.tradeScraper.ethConnection.Chain(); symbol == chain.WrappedNative {
+		symbol = chain.NativeSymbol
 	}
+	if s.quotations == nil {
+		return decimal.Zero, errors.New("no datastore for currency prices")
+	}
+	quotation, err := s.quotations.GetQuotation(symbol)
+	if err != nil {
+		log.Warnf("no usd price for %s on %s, using zero: %s", symbol, s.tradeScraper.chain.Blockchain(), err.Error())
+		return decimal.Zero, nil
+	}
+	return decimal.NewFromFloat(quotation.Price), nil
 }
 
 // GetDataChannel returns the scrapers data channel.
@@ -605,36 +720,20 @@ func (s *OpenSeaScraper) findContractCreationInfo(ctx context.Context, contractA
 	}
 
 	var (
-		lo, hi, blockNum uint64
-		code             []byte
-		receipt          *types.Receipt
-		chainID          *big.Int
-		block            *types.Block
+		head, blockNum uint64
+		receipt        *types.Receipt
+		chainID        *big.Int
+		block          *types.Block
 	)
 
-	hi, err = s.tradeScraper.ethConnection.BlockNumber(ctx)
+	head, err = s.tradeScraper.ethConnection.BlockNumber(ctx)
 	if err != nil {
 		return
 	}
 
-	for lo <= hi {
-		blockNum = (lo + hi) / 2
-
-		code, err = s.tradeScraper.ethConnection.CodeAt(ctx, contractAddr, new(big.Int).SetUint64(blockNum))
-		if err != nil {
-			return
-		}
-
-		if len(code) == 0 {
-			lo = blockNum
-		} else {
-			hi = blockNum
-		}
-
-		if hi == lo+1 {
-			blockNum = hi
-			break
-		}
+	blockNum, err = ethhelper.DeploymentBlock(ctx, s.tradeScraper.ethConnection, contractAddr, head)
+	if err != nil {
+		return
 	}
 
 	block, err = s.tradeScraper.ethConnection.BlockByNumber(ctx, new(big.Int).SetUint64(blockNum))
@@ -743,8 +842,8 @@ func (s *OpenSeaScraper) findERC20Transfers(ctx context.Context, receipt *types.
 }
 
 // it finds the transfer events of ERC721 in the given transaction
-func (s *OpenSeaScraper) findERC721Transfers(ctx context.Context, receipt *types.Receipt) ([]*erc721Transfer, error) {
-	transfers := make([]*erc721Transfer, 0, 1)
+func (s *OpenSeaScraper) findERC721Transfers(ctx context.Context, receipt *types.Receipt) ([]*nftTransfer, error) {
+	transfers := make([]*nftTransfer, 0, 1)
 
 	for _, txLog := range receipt.Logs {
 		if len(txLog.Topics) < 1 || txLog.Topics[0] != erc721ABI.Events["Transfer"].ID {
@@ -785,12 +884,15 @@ func (s *OpenSeaScraper) findERC721Transfers(ctx context.Context, receipt *types
 			}
 		}
 
-		transfer := &erc721Transfer{
-			NFTAddress: txLog.Address,
-			From:       transferLog.From,
-			To:         transferLog.To,
-			TokenID:    transferLog.TokenId,
-			TokenAttrs: make(map[string]interface{}),
+		transfer := &nftTransfer{
+			NFTAddress:   txLog.Address,
+			ContractType: erc721ContractType,
+			From:         transferLog.From,
+			To:           transferLog.To,
+			TokenID:      transferLog.TokenId,
+			Quantity:     big.NewInt(1),
+			LogIndex:     txLog.Index,
+			TokenAttrs:   make(map[string]interface{}),
 		}
 
 		callOpts := &bind.CallOpts{Context: ctx}
@@ -830,40 +932,134 @@ func (s *OpenSeaScraper) findERC721Transfers(ctx context.Context, receipt *types
 	return transfers, nil
 }
 
-func (s *OpenSeaScraper) readNFTAttr(ctx context.Context, uri string) (map[string]interface{}, error) {
-	if uri == "" {
-		return nil, nil
+// it finds the single and batch transfer events of ERC1155 in the given transaction
+func (s *OpenSeaScraper) findERC1155Transfers(ctx context.Context, receipt *types.Receipt) ([]*nftTransfer, error) {
+	transfers := make([]*nftTransfer, 0, 1)
+
+	for _, txLog := range receipt.Logs {
+		if len(txLog.Topics) < 1 {
+			continue
+		}
+
+		if txLog.Topics[0] != erc1155ABI.Events["TransferSingle"].ID && txLog.Topics[0] != erc1155ABI.Events["TransferBatch"].ID {
+			continue
+		}
+
+		token, err := erc1155.NewERC1155Filterer(txLog.Address, s.tradeScraper.ethConnection)
+		if err != nil {
+			log.Warnf("unable to bind erc1155 contract at address %s: %s", txLog.Address.Hex(), err.Error())
+			continue
+		}
+
+		var (
+			from, to    common.Address
+			ids, values []*big.Int
+		)
+
+		if txLog.Topics[0] == erc1155ABI.Events["TransferSingle"].ID {
+			ev, err := token.ParseTransferSingle(*txLog)
+			if err != nil {
+				log.Tracef("the event cannot comply to erc1155's single transfer: %s", err)
+				continue
+			}
+
+			from, to, ids, values = ev.From, ev.To, []*big.Int{ev.Id}, []*big.Int{ev.Value}
+		} else {
+			ev, err := token.ParseTransferBatch(*txLog)
+			if err != nil || len(ev.Ids) != len(ev.Values) {
+				log.Tracef("the event cannot comply to erc1155's batch transfer: %v", err)
+				continue
+			}
+
+			from, to, ids, values = ev.From, ev.To, ev.Ids, ev.Values
+		}
+
+		// a quantity out of range invalidates the whole log, otherwise the units of the remaining ids
+		// would be priced as if they made up the whole trade
+		inRange := true
+		for i := range ids {
+			if !values[i].IsUint64() {
+				log.Warnf("quantity %s of erc1155 token(addr: %s, id: %s) out of range", values[i].String(), txLog.Address.Hex(), ids[i].String())
+				inRange = false
+			}
+		}
+		if !inRange {
+			continue
+		}
+
+		for i := range ids {
+			transfer := &nftTransfer{
+				NFTAddress:   txLog.Address,
+				ContractType: erc1155ContractType,
+				From:         from,
+				To:           to,
+				TokenID:      ids[i],
+				Quantity:     values[i],
+				LogIndex:     txLog.Index,
+				TokenAttrs:   make(map[string]interface{}),
+			}
+
+			s.readERC1155Metadata(ctx, txLog, transfer)
+
+			transfers = append(transfers, transfer)
+		}
 	}
 
-	if strings.HasPrefix(uri, "ipfs://") {
-		// TODO: add IPFS support
-		return nil, nil
+	return transfers, nil
+}
+
+// it reads name, symbol, uri and attributes of the transferred ERC1155 token on a best effort basis
+func (s *OpenSeaScraper) readERC1155Metadata(ctx context.Context, txLog *types.Log, transfer *nftTransfer) {
+	callOpts := &bind.CallOpts{Context: ctx}
+
+	if s.conf.UseArchiveNode {
+		callOpts.BlockNumber = new(big.Int).SetUint64(txLog.BlockNumber)
 	}
 
-	attrs := make(map[string]interface{})
+	// name and symbol are not part of erc1155, but many contracts implement them as erc721 does
+	if md, err := erc721.NewERC721Metadata(txLog.Address, s.tradeScraper.ethConnection); err == nil {
+		if nftName, err := md.Name(callOpts); err == nil {
+			transfer.Name = &nftName
+		}
 
-	ctx, cancel := context.WithTimeout(ctx, s.conf.MetadataTimeout)
-	defer cancel()
+		if nftSymbol, err := md.Symbol(callOpts); err == nil {
+			transfer.Symbol = &nftSymbol
+		}
+	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	md, err := erc1155.NewERC1155MetadataCaller(txLog.Address, s.tradeScraper.ethConnection)
 	if err != nil {
-		return nil, err
+		log.Warnf("unable to bind erc1155 metadata contract at address %s: %s", txLog.Address.Hex(), err.Error())
+		return
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	tokenURI, err := md.Uri(callOpts, transfer.TokenID)
 	if err != nil {
-		return nil, err
+		log.Warnf("unable to find token(%s) uri: %s", transfer.TokenID.String(), err.Error())
+		return
 	}
 
-	defer resp.Body.Close()
+	// the uri may contain the placeholder {id} for the hex token id padded to 64 characters
+	tokenURI = strings.ReplaceAll(tokenURI, "{id}", fmt.Sprintf("%064x", transfer.TokenID))
 
-	if resp.StatusCode < 200 || resp.StatusCode > 299 {
-		return nil, errors.New("unable to read token attributes: " + resp.Status)
+	if attrs, err := s.readNFTAttr(ctx, tokenURI); err != nil {
+		log.Warnf("unable to read token(%s) attributes: %s", transfer.TokenID.String(), err.Error())
+	} else {
+		transfer.TokenURI = &tokenURI
+		transfer.TokenAttrs = attrs
 	}
+}
 
-	if err := json.NewDecoder(io.LimitReader(resp.Body, int64(s.conf.MaxMetadataSize))).Decode(&attrs); err != nil {
-		return nil, err
+func (s *OpenSeaScraper) readNFTAttr(ctx context.Context, uri string) (map[string]interface{}, error) {
+	if uri == "" {
+		return nil, nil
 	}
 
-	return attrs, nil
+	resolver := nfthelper.NewURIResolver(s.conf.IPFSGateways, s.conf.ArweaveGateways)
+	resolver.MaxSize = s.conf.MaxMetadataSize
+	resolver.Timeout = s.conf.MetadataTimeout
+
+	attrs, _, err := resolver.Resolve(ctx, uri)
+
+	return attrs, err
 }
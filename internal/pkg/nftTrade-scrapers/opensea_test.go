@@ -0,0 +1,294 @@
+package nfttradescrapers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/config/nftContracts/erc1155"
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/ethhelper"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/shopspring/decimal"
+)
+
+// testQuotations quotes the symbols in its map and has no quotation for all others.
+type testQuotations struct {
+	models.Datastore
+	prices map[string]float64
+}
+
+func (ds testQuotations) GetQuotation(symbol string) (*models.Quotation, error) {
+	price, ok := ds.prices[symbol]
+	if !ok {
+		return nil, errors.New("no quotation for " + symbol)
+	}
+	return &models.Quotation{Symbol: symbol, Price: price}, nil
+}
+
+// testOpenSeaScraper returns an OpenSea scraper on @blockchain without connection to a node.
+func testOpenSeaScraper(t *testing.T, blockchain string) *OpenSeaScraper {
+	client, err := ethhelper.WrapEVMClient(nil, blockchain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &OpenSeaScraper{
+		name:       openSeaScraperName(blockchain),
+		quotations: testQuotations{prices: map[string]float64{"ETH": 3000, "MATIC": 1.5, "BNB": 400, "USDC": 1}},
+		conf:       &OpenSeaScraperConfig{},
+		state:      &OpenSeaScraperState{},
+		tradeScraper: TradeScraper{
+			source:        OpenSea,
+			chain:         client,
+			ethConnection: client,
+		},
+	}
+}
+
+func TestOpenSeaPrices(t *testing.T) {
+	tests := []struct {
+		blockchain string
+		symbol     string
+		price      float64
+	}{
+		{dia.ETHEREUM, "ETH", 3000},
+		{dia.ETHEREUM, "WETH", 3000},
+		{dia.POLYGON, "MATIC", 1.5},
+		{dia.POLYGON, "WMATIC", 1.5},
+		{dia.BINANCESMARTCHAIN, "WBNB", 400},
+		{dia.POLYGON, "USDC", 1},
+		// only the chain's own wrapped native currency is priced as the native currency
+		{dia.POLYGON, "WETH", 0},
+		{dia.BINANCESMARTCHAIN, "WMATIC", 0},
+	}
+	for _, test := range tests {
+		s := testOpenSeaScraper(t, test.blockchain)
+		price, err := s.findPrice(0, common.Address{}, test.symbol)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !price.Equal(decimal.NewFromFloat(test.price)) {
+			t.Errorf("price of %s on %s: %s, expected %v", test.symbol, test.blockchain, price, test.price)
+		}
+	}
+
+	// a unit price of 2 WMATIC on Polygon
+	usdPrice, err := testOpenSeaScraper(t, dia.POLYGON).calcUSDPrice(0, common.Address{}, "WMATIC", decimal.NewFromInt(2))
+	if err != nil || usdPrice != 3 {
+		t.Errorf("usd price %v, err %v", usdPrice, err)
+	}
+}
+
+func TestOpenSeaScraperName(t *testing.T) {
+	if name := openSeaScraperName(dia.ETHEREUM); name != OpenSea {
+		t.Errorf("scraper on Ethereum is named %s", name)
+	}
+	if openSeaScraperName(dia.POLYGON) == openSeaScraperName(dia.BINANCESMARTCHAIN) {
+		t.Error("scrapers on different chains share their config and state")
+	}
+}
+
+// testERC1155Node serves a node on which all contracts implement erc1155's uri(id) with metadata
+// served by the node itself, and revert all other calls.
+func testERC1155Node(t *testing.T) (*httptest.Server, *ethclient.Client) {
+	metadataABI, err := abi.JSON(strings.NewReader(erc1155.ERC1155MetadataABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	uri := metadataABI.Methods["uri"]
+	var node *httptest.Server
+	node = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			fmt.Fprintf(w, `{"name":"token %s"}`, strings.TrimPrefix(r.URL.Path, "/metadata/"))
+			return
+		}
+		var request struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		var call struct {
+			Data hexutil.Bytes `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Method != "eth_call" || len(request.Params) == 0 {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		if err := json.Unmarshal(request.Params[0], &call); err != nil {
+			http.Error(w, "unexpected call", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if len(call.Data) < 4 || !bytes.Equal(call.Data[:4], uri.ID) {
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"error":{"code":-32000,"message":"execution reverted"}}`, request.ID)
+			return
+		}
+		result, err := uri.Outputs.Pack(node.URL + "/metadata/{id}.json")
+		if err != nil {
+			t.Error(err)
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":"%s"}`, request.ID, hexutil.Encode(result))
+	}))
+	client, err := ethclient.Dial(node.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return node, client
+}
+
+// erc1155Log returns the log @event of an erc1155 token at @token with the non-indexed @values.
+func erc1155Log(t *testing.T, token common.Address, index uint, event string, from, to common.Address, values ...interface{}) *types.Log {
+	e := erc1155ABI.Events[event]
+	data, err := e.Inputs.NonIndexed().Pack(values...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	operator := common.HexToAddress("0x0000000000000000000000000000000000000bad")
+	return &types.Log{
+		Address: token,
+		Topics:  []common.Hash{e.ID, common.BytesToHash(operator.Bytes()), common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+		Data:    data,
+		Index:   index,
+	}
+}
+
+func TestFindERC1155Transfers(t *testing.T) {
+	node, client := testERC1155Node(t)
+	defer node.Close()
+	defer client.Close()
+	chain, err := ethhelper.WrapEVMClient(client, dia.ETHEREUM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := testOpenSeaScraper(t, dia.ETHEREUM)
+	s.tradeScraper.ethConnection = chain
+	s.conf.MetadataTimeout = 10 * time.Second
+	s.conf.MaxMetadataSize = defOpenSeaConf.MaxMetadataSize
+
+	token := common.HexToAddress("0x495f947276749ce646f68ac8c248420045cb7b5e")
+	seller := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	buyer := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	id := func(x int64) *big.Int { return big.NewInt(x) }
+	// a single transfer and a batch transfer, preceded by an erc20 transfer and followed by logs
+	// which do not comply to erc1155
+	erc20 := &types.Log{
+		Address: common.HexToAddress("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"),
+		Topics:  []common.Hash{erc20ABI.Events["Transfer"].ID, common.BytesToHash(buyer.Bytes()), common.BytesToHash(seller.Bytes())},
+		Data:    common.BigToHash(big.NewInt(1e18)).Bytes(),
+	}
+	malformed := erc1155Log(t, token, 4, "TransferSingle", seller, buyer, id(5), id(1))
+	malformed.Data = malformed.Data[:32]
+	outOfRange := erc1155Log(t, token, 5, "TransferBatch", seller, buyer, []*big.Int{id(6), id(7)}, []*big.Int{id(1), new(big.Int).Lsh(id(1), 64)})
+	receipt := &types.Receipt{Logs: []*types.Log{
+		erc20,
+		erc1155Log(t, token, 2, "TransferSingle", seller, buyer, id(1), id(3)),
+		erc1155Log(t, token, 3, "TransferBatch", seller, buyer, []*big.Int{id(2), id(300)}, []*big.Int{id(5), id(1)}),
+		malformed,
+		outOfRange,
+	}}
+
+	transfers, err := s.findERC1155Transfers(context.Background(), receipt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		id, quantity int64
+		logIndex     uint
+	}{{1, 3, 2}, {2, 5, 3}, {300, 1, 3}}
+	if len(transfers) != len(want) {
+		t.Fatalf("%d transfers, want %d", len(transfers), len(want))
+	}
+	for i, w := range want {
+		transfer := transfers[i]
+		if transfer.NFTAddress != token || transfer.ContractType != erc1155ContractType || transfer.From != seller || transfer.To != buyer ||
+			transfer.TokenID.Int64() != w.id || transfer.Quantity.Int64() != w.quantity || transfer.LogIndex != w.logIndex {
+			t.Errorf("transfer %d: %+v, want %+v", i, transfer, w)
+		}
+		// the {id} placeholder of the uri is replaced with the padded hex id
+		hexID := fmt.Sprintf("%064x", w.id)
+		if transfer.TokenURI == nil || *transfer.TokenURI != node.URL+"/metadata/"+hexID+".json" {
+			t.Errorf("transfer %d: uri %v", i, transfer.TokenURI)
+		}
+		if transfer.TokenAttrs["name"] != "token "+hexID+".json" {
+			t.Errorf("transfer %d: attributes %v", i, transfer.TokenAttrs)
+		}
+		if transfer.Name != nil || transfer.Symbol != nil {
+			t.Errorf("transfer %d: name and symbol of a contract which does not implement them", i)
+		}
+	}
+}
+
+func TestSingleToken(t *testing.T) {
+	token := common.HexToAddress("0x495f947276749ce646f68ac8c248420045cb7b5e")
+	other := common.HexToAddress("0x06012c8cf97bead5deae237070f9587f8e7a266d")
+	transfer := func(address common.Address, logIndex uint, id int64, quantity *big.Int) *nftTransfer {
+		return &nftTransfer{NFTAddress: address, LogIndex: logIndex, TokenID: big.NewInt(id), Quantity: quantity}
+	}
+	tests := []struct {
+		name      string
+		transfers []*nftTransfer
+		quantity  int64 // 0 for bundles
+	}{
+		{"erc721 transfer", []*nftTransfer{transfer(other, 1, 7, big.NewInt(1))}, 1},
+		{"erc1155 single transfer", []*nftTransfer{transfer(token, 2, 1, big.NewInt(3))}, 3},
+		{"erc1155 batch of a single id", []*nftTransfer{transfer(token, 2, 1, big.NewInt(3)), transfer(token, 2, 1, big.NewInt(4))}, 7},
+		{"erc1155 batch of several ids", []*nftTransfer{transfer(token, 2, 1, big.NewInt(3)), transfer(token, 2, 2, big.NewInt(4))}, 0},
+		{"transfers of one id in several logs", []*nftTransfer{transfer(token, 2, 1, big.NewInt(3)), transfer(token, 3, 1, big.NewInt(4))}, 0},
+		{"same id on several contracts", []*nftTransfer{transfer(token, 2, 1, big.NewInt(1)), transfer(other, 2, 1, big.NewInt(1))}, 0},
+		{"quantity out of range", []*nftTransfer{transfer(token, 2, 1, new(big.Int).SetUint64(1<<63)), transfer(token, 2, 1, new(big.Int).SetUint64(1<<63))}, 0},
+	}
+	for _, test := range tests {
+		merged, ok := singleToken(test.transfers)
+		if ok != (test.quantity != 0) {
+			t.Errorf("%s: single token %v", test.name, ok)
+			continue
+		}
+		if ok && (merged.Quantity.Int64() != test.quantity || merged.TokenID.Cmp(test.transfers[0].TokenID) != 0) {
+			t.Errorf("%s: %d units of token %s", test.name, merged.Quantity, merged.TokenID)
+		}
+	}
+
+	// merging does not modify the transfers
+	transfers := []*nftTransfer{transfer(token, 2, 1, big.NewInt(3)), transfer(token, 2, 1, big.NewInt(4))}
+	singleToken(transfers)
+	if transfers[0].Quantity.Int64() != 3 {
+		t.Errorf("quantity of the first transfer changed to %s", transfers[0].Quantity)
+	}
+}
+
+func TestUnitPrices(t *testing.T) {
+	eth := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+	tests := []struct {
+		price     *big.Int
+		quantity  int64
+		decimals  int
+		unitPrice int64
+		normPrice string
+	}{
+		{big.NewInt(10), 1, 0, 10, "10"},
+		{big.NewInt(10), 4, 0, 3, "2.5"},
+		{big.NewInt(10), 3, 0, 3, "3.3333333333333333"},
+		{big.NewInt(11), 3, 0, 4, "3.6666666666666667"},
+		{new(big.Int).Mul(big.NewInt(3), eth), 2, 18, 1500000000000000000, "1.5"},
+		{big.NewInt(2500000), 5, 6, 500000, "0.5"},
+	}
+	for _, test := range tests {
+		unitPrice, normPrice := unitPrices(test.price, big.NewInt(test.quantity), test.decimals)
+		if unitPrice.Int64() != test.unitPrice || normPrice.String() != test.normPrice {
+			t.Errorf("%s for %d units: %s (%s), want %d (%s)", test.price, test.quantity, unitPrice, normPrice, test.unitPrice, test.normPrice)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package nftvaluation
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+var (
+	// ErrNoSales is returned if an NFT class had no sales within the lookback period.
+	ErrNoSales = errors.New("no sales in lookback period")
+	// ErrNoComparables is returned if neither the floor nor any sale carries weight.
+	ErrNoComparables = errors.New("no comparable sales")
+)
+
+// Config holds the parameters of the appraisal model.
+type Config struct {
+	// Lookback is the age of the oldest sales taken into account.
+	Lookback time.Duration
+	// HalfLife is the age at which the weight of a sale is halved.
+	HalfLife time.Duration
+	// FloorWindow is the period before the valuation whose sales determine the floor price.
+	// If there were none, all sales within the lookback are used.
+	FloorWindow time.Duration
+	// FloorQuantile is the quantile of the unit prices taken as floor price.
+	FloorQuantile float64
+	// FloorWeight is the weight of the floor price relative to a sale of the same token at
+	// valuation time.
+	FloorWeight float64
+	// Confidence is the probability of the fair value lying within the bounds of a valuation.
+	Confidence float64
+}
+
+// DefaultConfig is used by the valuation service unless flags say otherwise.
+var DefaultConfig = Config{
+	Lookback:      90 * 24 * time.Hour,
+	HalfLife:      14 * 24 * time.Hour,
+	FloorWindow:   7 * 24 * time.Hour,
+	FloorQuantile: 0.1,
+	FloorWeight:   1,
+	Confidence:    0.9,
+}
+
+// Sale is a sale of a token in the class of the appraised NFT at a unit price in USD.
+type Sale struct {
+	TokenID string
+	Price   float64
+	Time    time.Time
+	Traits  map[string]string
+}
+
+// Similarity returns the share of trait values two tokens have in common among all trait values
+// either of them has. It is 0 if neither of them has traits.
+func Similarity(a map[string]string, b map[string]string) float64 {
+	var common int
+	for traitType, value := range a {
+		if v, ok := b[traitType]; ok && v == value {
+			common++
+		}
+	}
+	union := len(a) + len(b) - common
+	if union == 0 {
+		return 0
+	}
+	return float64(common) / float64(union)
+}
+
+// FloorPrice returns the @quantile of the prices of @sales, interpolating linearly between sales.
+func FloorPrice(sales []Sale, quantile float64) float64 {
+	if len(sales) == 0 {
+		return 0
+	}
+	prices := make([]float64, len(sales))
+	for i, sale := range sales {
+		prices[i] = sale.Price
+	}
+	sort.Float64s(prices)
+	pos := quantile * float64(len(prices)-1)
+	i := int(math.Floor(pos))
+	if i >= len(prices)-1 {
+		return prices[len(prices)-1]
+	}
+	return prices[i] + (prices[i+1]-prices[i])*(pos-float64(i))
+}
+
+// Appraise estimates the fair value of @nft with @traits at time @t from the @sales of its class.
+//
+// The estimate is a weighted mean of log prices. Each sale within the lookback is weighted by the
+// similarity of the token sold to @nft, which is 1 for sales of @nft itself, and halves its weight
+// every half-life. The floor price of the class enters with the configured floor weight, such that
+// NFTs without comparable sales are valued at the floor. The bounds are those of a log-normal
+// distribution whose variance is the weighted variance of the log prices, where the floor carries
+// the variance of all sales in the class, widened by the uncertainty of the mean.
+func Appraise(nft dia.NFT, traits map[string]string, sales []Sale, t time.Time, config Config) (dia.NFTValuation, error) {
+	valuation := dia.NFTValuation{NFT: nft, Confidence: config.Confidence, Time: t}
+
+	var recent, floorSales []Sale
+	for _, sale := range sales {
+		if sale.Price <= 0 || sale.Time.After(t) || t.Sub(sale.Time) > config.Lookback {
+			continue
+		}
+		recent = append(recent, sale)
+		if t.Sub(sale.Time) <= config.FloorWindow {
+			floorSales = append(floorSales, sale)
+		}
+	}
+	if len(recent) == 0 {
+		return valuation, ErrNoSales
+	}
+	if len(floorSales) == 0 {
+		floorSales = recent
+	}
+	valuation.FloorPrice = FloorPrice(floorSales, config.FloorQuantile)
+
+	// variance of all log prices in the class
+	var classMean, classVar float64
+	for _, sale := range recent {
+		classMean += math.Log(sale.Price)
+	}
+	classMean /= float64(len(recent))
+	for _, sale := range recent {
+		classVar += math.Pow(math.Log(sale.Price)-classMean, 2)
+	}
+	classVar /= float64(len(recent))
+
+	logPrices := []float64{math.Log(valuation.FloorPrice)}
+	weights := []float64{config.FloorWeight}
+	for _, sale := range recent {
+		similarity := 1.0
+		if sale.TokenID != nft.TokenID {
+			similarity = Similarity(traits, sale.Traits)
+		}
+		weight := similarity * math.Pow(0.5, float64(t.Sub(sale.Time))/float64(config.HalfLife))
+		if weight <= 0 {
+			continue
+		}
+		logPrices = append(logPrices, math.Log(sale.Price))
+		weights = append(weights, weight)
+		valuation.Comparables++
+	}
+
+	var sumWeights, sumSquaredWeights, mean float64
+	for i, weight := range weights {
+		sumWeights += weight
+		sumSquaredWeights += weight * weight
+		mean += weight * logPrices[i]
+	}
+	if sumWeights == 0 {
+		return valuation, ErrNoComparables
+	}
+	mean /= sumWeights
+	variance := config.FloorWeight * classVar
+	for i, weight := range weights {
+		variance += weight * math.Pow(logPrices[i]-mean, 2)
+	}
+	variance /= sumWeights
+	effectiveSales := sumWeights * sumWeights / sumSquaredWeights
+	deviation := math.Sqrt(variance * (1 + 1/effectiveSales))
+	z := math.Sqrt2 * math.Erfinv(config.Confidence)
+
+	valuation.Value = math.Exp(mean)
+	valuation.LowerBound = math.Exp(mean - z*deviation)
+	valuation.UpperBound = math.Exp(mean + z*deviation)
+	return valuation, nil
+}
@@ -0,0 +1,80 @@
+package nftvaluation
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+func TestSimilarity(t *testing.T) {
+	a := map[string]string{"Background": "Blue", "Hat": "Crown"}
+	b := map[string]string{"Background": "Blue", "Hat": "Cap", "Eyes": "Laser"}
+	if s := Similarity(a, b); s != 0.25 {
+		t.Errorf("similarity %v", s)
+	}
+	if s := Similarity(map[string]string{}, map[string]string{}); s != 0 {
+		t.Errorf("similarity without traits %v", s)
+	}
+}
+
+func TestFloorPrice(t *testing.T) {
+	sales := []Sale{{Price: 30}, {Price: 10}, {Price: 20}}
+	if f := FloorPrice(sales, 0.25); f != 15 {
+		t.Errorf("floor %v", f)
+	}
+	if f := FloorPrice(sales, 1); f != 30 {
+		t.Errorf("max %v", f)
+	}
+}
+
+func TestAppraise(t *testing.T) {
+	now := time.Unix(1640995200, 0)
+	crown := map[string]string{"Hat": "Crown"}
+	capHat := map[string]string{"Hat": "Cap"}
+	var sales []Sale
+	for i := 0; i < 5; i++ {
+		sales = append(sales,
+			Sale{TokenID: "1", Price: 10, Time: now.Add(-time.Duration(i) * time.Hour), Traits: capHat},
+			Sale{TokenID: "2", Price: 100, Time: now.Add(-time.Duration(i) * time.Hour), Traits: crown},
+		)
+	}
+	// sales after the valuation time are ignored
+	sales = append(sales, Sale{TokenID: "3", Price: 1000, Time: now.Add(time.Hour), Traits: crown})
+
+	config := DefaultConfig
+	crowned, err := Appraise(dia.NFT{TokenID: "3"}, crown, sales, now, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	capped, err := Appraise(dia.NFT{TokenID: "4"}, capHat, sales, now, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if crowned.Comparables != 5 || capped.Comparables != 5 {
+		t.Errorf("comparables %d %d", crowned.Comparables, capped.Comparables)
+	}
+	if crowned.FloorPrice != 10 {
+		t.Errorf("floor %v", crowned.FloorPrice)
+	}
+	if crowned.Value <= capped.Value || crowned.Value >= 100 || crowned.Value <= 10 {
+		t.Errorf("values %v %v", crowned.Value, capped.Value)
+	}
+	if !(crowned.LowerBound < crowned.Value && crowned.Value < crowned.UpperBound) {
+		t.Errorf("bounds %v %v %v", crowned.LowerBound, crowned.Value, crowned.UpperBound)
+	}
+
+	// without comparable sales, the floor is the value
+	plain, err := Appraise(dia.NFT{TokenID: "5"}, map[string]string{}, sales, now, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(plain.Value-10) > 1e-9 || plain.Comparables != 0 {
+		t.Errorf("value without comparables %v", plain.Value)
+	}
+
+	if _, err = Appraise(dia.NFT{TokenID: "1"}, capHat, sales, now.Add(-config.Lookback-2*time.Hour), config); err != ErrNoSales {
+		t.Errorf("expected ErrNoSales, got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package nftvaluation
+
+import (
+	"time"
+
+	nftmetadata "github.com/diadata-org/diadata/internal/pkg/nftMetadataService"
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// Service appraises NFTs and stores the valuations in their history.
+type Service struct {
+	rdb    models.RelDatastore
+	config Config
+}
+
+// NewService returns a valuation service using the model parameters in @config.
+func NewService(rdb models.RelDatastore, config Config) *Service {
+	return &Service{
+		rdb:    rdb,
+		config: config,
+	}
+}
+
+// ValueClass appraises all NFTs of @nftclass at time @t which have metadata or were sold within the
+// lookback period. It returns the number of stored valuations.
+func (s *Service) ValueClass(nftclass dia.NFTClass, t time.Time) (int, error) {
+	trades, err := s.rdb.GetNFTClassTrades(nftclass, t.Add(-s.config.Lookback), t)
+	if err != nil {
+		return 0, err
+	}
+	if len(trades) == 0 {
+		return 0, nil
+	}
+	metadata, err := s.rdb.GetNFTClassMetadata(nftclass)
+	if err != nil {
+		return 0, err
+	}
+
+	tokenTraits := make(map[string]map[string]string)
+	for _, m := range metadata {
+		tokenTraits[m.NFT.TokenID] = nftmetadata.MetadataTraits(m)
+	}
+	sales := make([]Sale, len(trades))
+	for i, trade := range trades {
+		if _, ok := tokenTraits[trade.NFT.TokenID]; !ok {
+			tokenTraits[trade.NFT.TokenID] = map[string]string{}
+		}
+		sales[i] = Sale{
+			TokenID: trade.NFT.TokenID,
+			Price:   trade.PriceUSD,
+			Time:    trade.Timestamp,
+			Traits:  tokenTraits[trade.NFT.TokenID],
+		}
+	}
+
+	var stored int
+	for tokenID, traits := range tokenTraits {
+		valuation, err := Appraise(dia.NFT{NFTClass: nftclass, TokenID: tokenID}, traits, sales, t, s.config)
+		if err == ErrNoSales {
+			// none of the trades has a USD price
+			return stored, nil
+		}
+		if err != nil {
+			return stored, err
+		}
+		err = s.rdb.SetNFTValuation(valuation)
+		if err != nil {
+			log.Errorf("set valuation of %s %s: %v", nftclass.Address, tokenID, err)
+			continue
+		}
+		stored++
+	}
+	return stored, nil
+}
+
+// ValueAll appraises the NFTs of all classes on @blockchain at time @t. It returns the number of
+// stored valuations.
+func (s *Service) ValueAll(blockchain string, t time.Time) (int, error) {
+	nftclasses, err := s.rdb.GetAllNFTClasses(blockchain)
+	if err != nil {
+		return 0, err
+	}
+	var stored int
+	for _, nftclass := range nftclasses {
+		n, err := s.ValueClass(nftclass, t)
+		if err != nil {
+			log.Errorf("value nfts of %s: %v", nftclass.Address, err)
+		}
+		stored += n
+	}
+	return stored, nil
+}
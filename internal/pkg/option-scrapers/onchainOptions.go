@@ -0,0 +1,112 @@
+package optionscrapers
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	Otoken "github.com/diadata-org/diadata/internal/pkg/option-scrapers/opyncontracts/OpynToken"
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+const (
+	// refreshOnchainOptions is how often the state of on-chain option series is read from the contracts.
+	refreshOnchainOptions = 10 * time.Minute
+	// resubscribeDelay is the waiting time before a failed event subscription is renewed.
+	resubscribeDelay = 10 * time.Second
+)
+
+// onchainAssets reads and caches the metadata of the tokens referenced by on-chain options.
+type onchainAssets struct {
+	client *ethclient.Client
+	mu     sync.Mutex
+	cache  map[common.Address]dia.OptionAsset
+}
+
+func newOnchainAssets(client *ethclient.Client) *onchainAssets {
+	return &onchainAssets{
+		client: client,
+		cache:  make(map[common.Address]dia.OptionAsset),
+	}
+}
+
+// get returns the symbol and decimals of the ERC-20 token at @address.
+func (a *onchainAssets) get(address common.Address) dia.OptionAsset {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if asset, ok := a.cache[address]; ok {
+		return asset
+	}
+	asset := dia.OptionAsset{Address: address.Hex()}
+	// oTokens are ERC-20 tokens, so their binding can read any token's symbol and decimals.
+	token, err := Otoken.NewOtokenCaller(address, a.client)
+	if err != nil {
+		log.Errorln("Error binding token", address.Hex(), err)
+		return asset
+	}
+	asset.Symbol, err = token.Symbol(&bind.CallOpts{})
+	if err != nil {
+		log.Errorln("Error getting symbol of", address.Hex(), err)
+		return asset
+	}
+	asset.Decimals, err = token.Decimals(&bind.CallOpts{})
+	if err != nil {
+		log.Errorln("Error getting decimals of", address.Hex(), err)
+		return asset
+	}
+	a.cache[address] = asset
+	return asset
+}
+
+// scaleAmount returns the token @amount in units of a token with @decimals.
+func scaleAmount(amount *big.Int, decimals uint8) float64 {
+	if amount == nil {
+		return 0
+	}
+	unit := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	value, _ := new(big.Float).Quo(new(big.Float).SetInt(amount), unit).Float64()
+	return value
+}
+
+// blockTime returns the time of the block @number.
+func blockTime(client *ethclient.Client, number uint64) (time.Time, error) {
+	header, err := client.HeaderByNumber(context.Background(), new(big.Int).SetUint64(number))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(int64(header.Time), 0), nil
+}
+
+// headBlock returns the number of the latest block.
+func headBlock(client *ethclient.Client) (uint64, error) {
+	header, err := client.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		return 0, err
+	}
+	return header.Number.Uint64(), nil
+}
+
+// storeFailed sets @lastBlock before the block of the @exercise which could not be stored, so that
+// the block is filtered again after resubscribing.
+func storeFailed(lastBlock *uint64, exercise dia.OptionExercise) {
+	if exercise.BlockNumber > 0 && *lastBlock >= exercise.BlockNumber {
+		*lastBlock = exercise.BlockNumber - 1
+	}
+}
+
+// settlementStatus returns the status of a series expiring at @expiry, where @settled tells whether
+// its settlement price is known.
+func settlementStatus(expiry time.Time, settled bool, now time.Time) dia.OptionSettlementStatus {
+	switch {
+	case now.Before(expiry):
+		return dia.OptionActive
+	case settled:
+		return dia.OptionSettled
+	default:
+		return dia.OptionExpired
+	}
+}
@@ -0,0 +1,377 @@
+package optionscrapers
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/internal/pkg/option-scrapers/opyncontracts/OtokenController"
+	"github.com/diadata-org/diadata/internal/pkg/option-scrapers/premiacontracts/PremiaOption"
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	testWETH = dia.OptionAsset{Symbol: "WETH", Address: "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2", Decimals: 18}
+	testUSDC = dia.OptionAsset{Symbol: "USDC", Address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", Decimals: 6}
+	testDAI  = dia.OptionAsset{Symbol: "DAI", Address: "0x6B175474E89094C44Da98b954EedeAC495271d0F", Decimals: 18}
+
+	testOtoken  = common.HexToAddress("0x5f1A1Bd0dfc79ABC0Ac2B5cC9db3C86Ea3f6e80D")
+	testAccount = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	testTxHash  = common.HexToHash("0xaaaa")
+)
+
+// amount returns @units times 10^@decimals.
+func amount(units int64, decimals int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(units), new(big.Int).Exp(big.NewInt(10), big.NewInt(decimals), nil))
+}
+
+func TestScaleAmount(t *testing.T) {
+	tests := []struct {
+		amount   *big.Int
+		decimals uint8
+		want     float64
+	}{
+		{nil, 18, 0},
+		{big.NewInt(0), 8, 0},
+		{big.NewInt(42), 0, 42},
+		{big.NewInt(150000000), otokenDecimals, 1.5},
+		{amount(2500, 6), 6, 2500},
+		{amount(-3, 18), 18, -3},
+		// amounts beyond 64 bits
+		{amount(123456789, 18), 18, 123456789},
+		{big.NewInt(1), 18, 1e-18},
+	}
+	for _, test := range tests {
+		if got := scaleAmount(test.amount, test.decimals); got != test.want {
+			t.Errorf("scaleAmount(%v, %d) = %v, want %v", test.amount, test.decimals, got, test.want)
+		}
+	}
+}
+
+func TestSettlementStatus(t *testing.T) {
+	expiry := time.Date(2021, 4, 30, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		now     time.Time
+		settled bool
+		want    dia.OptionSettlementStatus
+	}{
+		{expiry.Add(-time.Second), false, dia.OptionActive},
+		// a known settlement price before expiry does not end trading
+		{expiry.Add(-time.Second), true, dia.OptionActive},
+		{expiry, false, dia.OptionExpired},
+		{expiry.Add(time.Hour), false, dia.OptionExpired},
+		{expiry, true, dia.OptionSettled},
+	}
+	for _, test := range tests {
+		if got := settlementStatus(expiry, test.settled, test.now); got != test.want {
+			t.Errorf("settlementStatus at %v, settled %v: %s, want %s", test.now, test.settled, got, test.want)
+		}
+	}
+}
+
+func TestOtokenOption(t *testing.T) {
+	expiry := time.Date(2021, 4, 30, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		isPut   bool
+		now     time.Time
+		settled bool
+		payout  *big.Int
+		want    dia.OnchainOption
+	}{
+		{
+			name: "active call",
+			now:  expiry.Add(-time.Hour),
+			want: dia.OnchainOption{OptionType: dia.CallOption, Collateral: testWETH, Status: dia.OptionActive},
+		},
+		{
+			name:  "expired put",
+			isPut: true,
+			now:   expiry.Add(time.Hour),
+			want:  dia.OnchainOption{OptionType: dia.PutOption, Collateral: testUSDC, Status: dia.OptionExpired},
+		},
+		{
+			name:    "settled put",
+			isPut:   true,
+			now:     expiry.Add(time.Hour),
+			settled: true,
+			payout:  big.NewInt(215500000), // 215.5 USDC per oToken
+			want:    dia.OnchainOption{OptionType: dia.PutOption, Collateral: testUSDC, Status: dia.OptionSettled, Payout: 215.5},
+		},
+	}
+	for _, test := range tests {
+		collateral := testWETH
+		if test.isPut {
+			collateral = testUSDC
+		}
+		option := otokenOption(testOtoken, testWETH, testUSDC, collateral, amount(1800, otokenDecimals), big.NewInt(expiry.Unix()), test.isPut)
+		setOtokenState(&option, big.NewInt(1234500000000), test.settled, test.payout, test.now)
+
+		want := test.want
+		want.Protocol = dia.Opyn
+		want.InstrumentName = testOtoken.String()
+		want.Underlying = testWETH
+		want.StrikeAsset = testUSDC
+		want.Style = dia.EuropeanOption
+		want.StrikePrice = 1800
+		want.ExpirationTime = expiry
+		want.OpenInterest = 12345
+		want.LastUpdate = test.now
+		if !option.ExpirationTime.Equal(want.ExpirationTime) {
+			t.Errorf("%s: expiry %v, want %v", test.name, option.ExpirationTime, want.ExpirationTime)
+		}
+		option.ExpirationTime = want.ExpirationTime
+		if option != want {
+			t.Errorf("%s:\n%+v\nwant\n%+v", test.name, option, want)
+		}
+	}
+}
+
+func TestPremiaOptionData(t *testing.T) {
+	expiry := time.Date(2021, 5, 7, 23, 59, 59, 0, time.UTC)
+	tests := []struct {
+		name   string
+		isCall bool
+		now    time.Time
+		want   dia.OnchainOption
+	}{
+		{
+			name:   "active call",
+			isCall: true,
+			now:    expiry.Add(-time.Hour),
+			want:   dia.OnchainOption{OptionType: dia.CallOption, Collateral: testWETH, Status: dia.OptionActive},
+		},
+		{
+			// physically settled options need no settlement price
+			name: "expired put",
+			now:  expiry.Add(time.Hour),
+			want: dia.OnchainOption{OptionType: dia.PutOption, Collateral: testDAI, Status: dia.OptionSettled},
+		},
+	}
+	for _, test := range tests {
+		market := &premiaMarket{
+			optionID: big.NewInt(7),
+			option: dia.OnchainOption{
+				Protocol:       dia.Premia,
+				InstrumentName: premiaInstrumentName(big.NewInt(7)),
+				Underlying:     testWETH,
+				StrikeAsset:    testDAI,
+				Style:          dia.AmericanOption,
+			},
+		}
+		setPremiaOptionData(market, premiaOptionData{
+			Token:       common.HexToAddress(testWETH.Address),
+			StrikePrice: amount(2000, 18),
+			Expiration:  big.NewInt(expiry.Unix()),
+			IsCall:      test.isCall,
+			Exercised:   amount(3, 17),
+			Supply:      amount(25, 18),
+			Decimals:    18,
+		}, test.now)
+
+		want := test.want
+		want.Protocol = dia.Premia
+		want.InstrumentName = PremiaOptionAddress.String() + "-7"
+		want.Underlying = testWETH
+		want.StrikeAsset = testDAI
+		want.Style = dia.AmericanOption
+		want.StrikePrice = 2000
+		want.ExpirationTime = expiry
+		want.OpenInterest = 25
+		want.Exercised = 0.3
+		want.LastUpdate = test.now
+		option := market.option
+		if !option.ExpirationTime.Equal(want.ExpirationTime) {
+			t.Errorf("%s: expiry %v, want %v", test.name, option.ExpirationTime, want.ExpirationTime)
+		}
+		option.ExpirationTime = want.ExpirationTime
+		if option != want || market.decimals != 18 {
+			t.Errorf("%s:\n%+v\nwant\n%+v", test.name, option, want)
+		}
+	}
+}
+
+// testLog returns the log of @event of the contract with ABI @abiJSON with the arguments @args,
+// in the order of the event's inputs.
+func testLog(t *testing.T, abiJSON string, event string, args ...interface{}) types.Log {
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, ok := parsed.Events[event]
+	if !ok {
+		t.Fatalf("no event %s", event)
+	}
+	l := types.Log{Topics: []common.Hash{e.ID}, TxHash: testTxHash, BlockNumber: 12345678, Index: 42}
+	var data []interface{}
+	for i, input := range e.Inputs {
+		if !input.Indexed {
+			data = append(data, args[i])
+			continue
+		}
+		switch arg := args[i].(type) {
+		case common.Address:
+			l.Topics = append(l.Topics, common.BytesToHash(arg.Bytes()))
+		case *big.Int:
+			l.Topics = append(l.Topics, common.BigToHash(arg))
+		default:
+			t.Fatalf("unsupported topic %T", arg)
+		}
+	}
+	l.Data, err = e.Inputs.NonIndexed().Pack(data...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func TestExerciseEvents(t *testing.T) {
+	opyn := &OpynOptionScraper{markets: []dia.OnchainOption{{InstrumentName: testOtoken.String(), Collateral: testUSDC}}}
+	controller, err := OtokenController.NewOtokenControllerFilterer(OtokenControllerAddress, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	premia := &PremiaScraper{markets: map[string]*premiaMarket{"3": {optionID: big.NewInt(3), decimals: 18}}}
+	premiaOption, err := PremiaOption.NewPremiaOptionFilterer(PremiaOptionAddress, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	weth := common.HexToAddress(testWETH.Address)
+	usdc := common.HexToAddress(testUSDC.Address)
+
+	tests := []struct {
+		name   string
+		log    types.Log
+		decode func(types.Log) (dia.OptionExercise, bool, error)
+		want   dia.OptionExercise
+		ok     bool
+	}{
+		{
+			name: "opyn redeem",
+			log:  testLog(t, OtokenController.OtokenControllerABI, "Redeem", testOtoken, testAccount, testAccount, usdc, big.NewInt(250000000), big.NewInt(537500000)),
+			decode: func(l types.Log) (dia.OptionExercise, bool, error) {
+				event, err := controller.ParseRedeem(l)
+				if err != nil {
+					return dia.OptionExercise{}, false, err
+				}
+				exercise, ok := opyn.redeemExercise(event)
+				return exercise, ok, nil
+			},
+			want: dia.OptionExercise{InstrumentName: testOtoken.String(), Kind: dia.OptionRedeemKind, Amount: 2.5, Payout: 537.5},
+			ok:   true,
+		},
+		{
+			name: "opyn redeem of another oToken",
+			log:  testLog(t, OtokenController.OtokenControllerABI, "Redeem", testAccount, testAccount, testAccount, usdc, big.NewInt(1), big.NewInt(1)),
+			decode: func(l types.Log) (dia.OptionExercise, bool, error) {
+				event, err := controller.ParseRedeem(l)
+				if err != nil {
+					return dia.OptionExercise{}, false, err
+				}
+				exercise, ok := opyn.redeemExercise(event)
+				return exercise, ok, nil
+			},
+		},
+		{
+			name: "opyn vault settlement",
+			log:  testLog(t, OtokenController.OtokenControllerABI, "VaultSettled", testAccount, testAccount, testOtoken, big.NewInt(1), big.NewInt(1000000000)),
+			decode: func(l types.Log) (dia.OptionExercise, bool, error) {
+				event, err := controller.ParseVaultSettled(l)
+				if err != nil {
+					return dia.OptionExercise{}, false, err
+				}
+				exercise, ok := opyn.settlementExercise(event)
+				return exercise, ok, nil
+			},
+			want: dia.OptionExercise{InstrumentName: testOtoken.String(), Kind: dia.OptionSettleKind, Payout: 1000},
+			ok:   true,
+		},
+		{
+			name: "premia exercise",
+			log:  testLog(t, PremiaOption.PremiaOptionABI, "OptionExercised", testAccount, big.NewInt(3), weth, amount(15, 17)),
+			decode: func(l types.Log) (dia.OptionExercise, bool, error) {
+				event, err := premiaOption.ParseOptionExercised(l)
+				if err != nil {
+					return dia.OptionExercise{}, false, err
+				}
+				exercise, ok := premia.exercise(event)
+				return exercise, ok, nil
+			},
+			want: dia.OptionExercise{InstrumentName: PremiaOptionAddress.String() + "-3", Kind: dia.OptionExerciseKind, Amount: 1.5},
+			ok:   true,
+		},
+		{
+			name: "premia withdrawal",
+			log:  testLog(t, PremiaOption.PremiaOptionABI, "Withdraw", testAccount, big.NewInt(3), weth, amount(4, 18)),
+			decode: func(l types.Log) (dia.OptionExercise, bool, error) {
+				event, err := premiaOption.ParseWithdraw(l)
+				if err != nil {
+					return dia.OptionExercise{}, false, err
+				}
+				exercise, ok := premia.withdrawal(event)
+				return exercise, ok, nil
+			},
+			want: dia.OptionExercise{InstrumentName: PremiaOptionAddress.String() + "-3", Kind: dia.OptionWithdrawalKind, Amount: 4},
+			ok:   true,
+		},
+		{
+			name: "premia exercise of another series",
+			log:  testLog(t, PremiaOption.PremiaOptionABI, "OptionExercised", testAccount, big.NewInt(4), weth, amount(1, 18)),
+			decode: func(l types.Log) (dia.OptionExercise, bool, error) {
+				event, err := premiaOption.ParseOptionExercised(l)
+				if err != nil {
+					return dia.OptionExercise{}, false, err
+				}
+				exercise, ok := premia.exercise(event)
+				return exercise, ok, nil
+			},
+		},
+	}
+	for _, test := range tests {
+		got, ok, err := test.decode(test.log)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if ok != test.ok {
+			t.Errorf("%s: scraped %v, want %v", test.name, ok, test.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		want := test.want
+		want.Account = testAccount.Hex()
+		want.TxHash = testTxHash.Hex()
+		want.BlockNumber = 12345678
+		want.LogIndex = 42
+		if got != want {
+			t.Errorf("%s:\n%+v\nwant\n%+v", test.name, got, want)
+		}
+	}
+}
+
+func TestStoreFailed(t *testing.T) {
+	tests := []struct {
+		lastBlock uint64
+		block     uint64
+		want      uint64
+	}{
+		// the block is filtered again after resubscribing
+		{100, 120, 100},
+		// other events of the block were stored before
+		{120, 120, 119},
+		{130, 120, 119},
+	}
+	for _, test := range tests {
+		lastBlock := test.lastBlock
+		storeFailed(&lastBlock, dia.OptionExercise{BlockNumber: test.block})
+		if lastBlock != test.want {
+			t.Errorf("last block %d after failing block %d: %d, want %d", test.lastBlock, test.block, lastBlock, test.want)
+		}
+	}
+}
@@ -55,6 +55,8 @@ type DeribitETHOptionScraper struct {
 	chanOrderBook      chan *dia.OptionOrderbookDatum
 	Ratelimiter        *rate.Limiter
 	refreshToken       string
+	openInterest       map[string]float64
+	openInterestMu     sync.Mutex
 }
 
 type DeribitRequest struct {
@@ -79,6 +81,7 @@ func NewDeribitETHOptionScraper() *DeribitETHOptionScraper {
 	s := &DeribitETHOptionScraper{
 		chanOrderBook: make(chan *dia.OptionOrderbookDatum),
 		DataStore:     ds,
+		openInterest:  make(map[string]float64),
 	}
 	s.GetAndStoreOptionsMeta()
 
@@ -183,6 +186,9 @@ func (scraper *DeribitETHOptionScraper) handleWSMessage() {
 		BidPrice:        resolvedBidPX,
 		BidSize:         resolvedBidSize,
 	}
+	scraper.openInterestMu.Lock()
+	o.OpenInterest = scraper.openInterest[o.InstrumentName]
+	scraper.openInterestMu.Unlock()
 	log.Infoln("Got trade", o)
 
 	scraper.chanOrderBook <- &o
@@ -201,6 +207,7 @@ func (scraper *DeribitETHOptionScraper) handleWSMessage() {
 func (scraper *DeribitETHOptionScraper) Scrape() {
 
 	go scraper.heartBeat()
+	go scraper.scrapeOpenInterest()
 	scraper.subscribe()
 	go func() {
 		for {
@@ -212,6 +219,38 @@ func (scraper *DeribitETHOptionScraper) Scrape() {
 
 }
 
+type DeribitBookSummaryResponse struct {
+	Result []struct {
+		InstrumentName string  `json:"instrument_name"`
+		OpenInterest   float64 `json:"open_interest"`
+	} `json:"result"`
+}
+
+// scrapeOpenInterest periodically fetches the open interest of all ETH options, which is not part
+// of the order book channel.
+func (scraper *DeribitETHOptionScraper) scrapeOpenInterest() {
+	t := time.NewTicker(5 * time.Minute)
+	for {
+		rawResponse, err := utils.GetRequest("https://www.deribit.com/api/v2/public/get_book_summary_by_currency?currency=ETH&kind=option")
+		if err != nil {
+			log.Errorln("Error getting open interest", err)
+		} else {
+			var response DeribitBookSummaryResponse
+			err = json.Unmarshal(rawResponse, &response)
+			if err != nil {
+				log.Errorln("Error parsing open interest", err)
+			} else {
+				scraper.openInterestMu.Lock()
+				for _, summary := range response.Result {
+					scraper.openInterest[summary.InstrumentName] = summary.OpenInterest
+				}
+				scraper.openInterestMu.Unlock()
+			}
+		}
+		<-t.C
+	}
+}
+
 func (scraper *DeribitETHOptionScraper) heartBeat() {
 
 	t := time.NewTicker(3 * time.Second)
@@ -2,11 +2,14 @@ package optionscrapers
 
 import (
 	"encoding/json"
-	Otoken "github.com/diadata-org/diadata/internal/pkg/option-scrapers/opyncontracts/OpynToken"
+	"fmt"
+	"math/big"
 	"strconv"
 	"sync"
 	"time"
 
+	Otoken "github.com/diadata-org/diadata/internal/pkg/option-scrapers/opyncontracts/OpynToken"
+	"github.com/diadata-org/diadata/internal/pkg/option-scrapers/opyncontracts/OtokenController"
 	"github.com/diadata-org/diadata/internal/pkg/option-scrapers/opyncontracts/OtokenFactory"
 	"github.com/diadata-org/diadata/pkg/dia"
 	models "github.com/diadata-org/diadata/pkg/model"
@@ -25,6 +28,9 @@ const (
 var OtokenFactoryAddress = common.HexToAddress("0x7C06792Af1632E77cb27a558Dc0885338F4Bdf8E")
 var OtokenControllerAddress = common.HexToAddress("0x4ccc2339F87F6c59c6893E1A678c2266cA58dC72")
 
+// oTokens and their strike prices have 8 decimals.
+const otokenDecimals = 8
+
 type OpynInstrumentsResponse struct {
 	Bids struct {
 		Total   int `json:"total"`
@@ -97,7 +103,8 @@ type OpynInstrumentsResponse struct {
 }
 
 type OpynOptionScraper struct {
-	markets    []OptionAttrs
+	markets    []dia.OnchainOption
+	marketsMu  sync.Mutex
 	WsClient   *ethclient.Client
 	RestClient *ethclient.Client
 
@@ -108,6 +115,8 @@ type OpynOptionScraper struct {
 	DataStore          *models.DB
 	chanOrderBook      chan *dia.OptionOrderbookDatum
 	Ratelimiter        *rate.Limiter
+	assets             *onchainAssets
+	controller         *OtokenController.OtokenController
 }
 
 func NewOpynETHOptionScraper() *OpynOptionScraper {
@@ -130,11 +139,18 @@ func NewOpynETHOptionScraper() *OpynOptionScraper {
 		log.Errorln("error getting modelstore")
 	}
 
+	controller, err := OtokenController.NewOtokenController(OtokenControllerAddress, wsClient)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	s := &OpynOptionScraper{
 		WsClient:      wsClient,
 		RestClient:    restClient,
 		chanOrderBook: make(chan *dia.OptionOrderbookDatum),
 		DataStore:     ds,
+		assets:        newOnchainAssets(restClient),
+		controller:    controller,
 	}
 	s.GetAndStoreOptionsMeta()
 
@@ -144,17 +160,18 @@ func NewOpynETHOptionScraper() *OpynOptionScraper {
 
 }
 
-
-
 func (scraper *OpynOptionScraper) Scrape() {
 
+	go scraper.refreshOptions()
+	go scraper.watchSettlements()
+
 	go func() {
-		for _, market := range scraper.markets {
-			log.Infoln("Token Address", market.id)
+		for _, market := range scraper.getMarkets() {
+			log.Infoln("Token Address", market.InstrumentName)
 
 			var response OpynInstrumentsResponse
 
-			b, err := utils.GetRequest("https://api.0x.org/sra/v4/orderbook?baseToken=" + market.id + "&quoteToken=0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48&perPage=100")
+			b, err := utils.GetRequest("https://api.0x.org/sra/v4/orderbook?baseToken=" + market.InstrumentName + "&quoteToken=0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48&perPage=100")
 			if err != nil {
 				log.Errorln("Error", err)
 			}
@@ -174,12 +191,15 @@ func (scraper *OpynOptionScraper) Scrape() {
 				resolvedAskPX = (takerAmount / 1e6) / (makeAmount / 1e8)
 
 				var o = dia.OptionOrderbookDatum{
-					InstrumentName:  market.id,
-					ObservationTime: market.expiryDate,
+					InstrumentName:  market.InstrumentName,
+					ObservationTime: time.Now(),
 					AskSize:         resolvedAskSize,
 					AskPrice:        resolvedAskPX,
 					BidPrice:        resolvedBidPX,
 					BidSize:         resolvedBidSize,
+					StrikePrice:     market.StrikePrice,
+					ExpirationTime:  market.ExpirationTime,
+					OpenInterest:    market.OpenInterest,
 				}
 				log.Infoln("Got trade", o)
 				scraper.chanOrderBook <- &o
@@ -193,10 +213,13 @@ func (scraper *OpynOptionScraper) Scrape() {
 				resolvedBidPX = (makeAmount / 1e6) / (takerAmount / 1e8)
 
 				var o = dia.OptionOrderbookDatum{
-					InstrumentName:  market.id,
-					ObservationTime: market.expiryDate,
+					InstrumentName:  market.InstrumentName,
+					ObservationTime: time.Now(),
 					BidPrice:        resolvedBidPX,
 					BidSize:         resolvedBidSize,
+					StrikePrice:     market.StrikePrice,
+					ExpirationTime:  market.ExpirationTime,
+					OpenInterest:    market.OpenInterest,
 				}
 				log.Infoln("Got trade", o)
 				scraper.chanOrderBook <- &o
@@ -209,7 +232,7 @@ func (scraper *OpynOptionScraper) Scrape() {
 
 }
 
-func (scraper *OpynOptionScraper) getOPYNInstruments() (options []OptionAttrs) {
+func (scraper *OpynOptionScraper) getOPYNInstruments() (options []dia.OnchainOption) {
 
 	optionFilterer, err := OtokenFactory.NewOtokenFactoryFilterer(OtokenFactoryAddress, scraper.WsClient)
 	if err != nil {
@@ -221,21 +244,19 @@ func (scraper *OpynOptionScraper) getOPYNInstruments() (options []OptionAttrs) {
 	tokesn, err := optionFilterer.FilterOtokenCreated(&bind.FilterOpts{Start: block}, []common.Address{}, []common.Address{}, []common.Address{})
 	if err != nil {
 		log.Error("error in get watching channel: ", err)
+		return
 	}
 	for tokesn.Next() {
 		// Get Opyn Token Details
 		// 0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2 get only ETH options
 		if common.HexToAddress("0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2") == tokesn.Event.Underlying {
-			optionAttrs := scraper.getOtokenDetail(tokesn.Event.TokenAddress)
- 			op := OptionAttrs{
-				underLyingToken: tokesn.Event.Underlying.String(),
-				id:              tokesn.Event.TokenAddress.String(),
-				strikePrice:     optionAttrs.strikePrice,
-				optionType:      optionAttrs.optionType,
-				expiryDate:      optionAttrs.expiryDate,
- 			}
-			options = append(options, op)
- 		}
+			option, err := scraper.getOtokenDetail(tokesn.Event.TokenAddress)
+			if err != nil {
+				log.Errorln("Error getting oToken detail", tokesn.Event.TokenAddress.String(), err)
+				continue
+			}
+			options = append(options, option)
+		}
 
 	}
 	return
@@ -243,59 +264,304 @@ func (scraper *OpynOptionScraper) getOPYNInstruments() (options []OptionAttrs) {
 }
 
 func (scraper *OpynOptionScraper) FetchMarkets() {
-	scraper.markets = scraper.getOPYNInstruments()
+	markets := scraper.getOPYNInstruments()
+	scraper.marketsMu.Lock()
+	scraper.markets = markets
+	scraper.marketsMu.Unlock()
 }
 
-func (scraper *OpynOptionScraper) getOtokenDetail(otokenAddress common.Address) (optionAttrs OptionAttrs) {
-	oTokenData, err := Otoken.NewOtoken(otokenAddress, scraper.WsClient)
+// getMarkets returns a copy of the scraped option series.
+func (scraper *OpynOptionScraper) getMarkets() []dia.OnchainOption {
+	scraper.marketsMu.Lock()
+	defer scraper.marketsMu.Unlock()
+	return append([]dia.OnchainOption{}, scraper.markets...)
+}
+
+// getOtokenDetail returns the option series of the oToken at @otokenAddress.
+func (scraper *OpynOptionScraper) getOtokenDetail(otokenAddress common.Address) (option dia.OnchainOption, err error) {
+	oTokenData, err := Otoken.NewOtokenCaller(otokenAddress, scraper.RestClient)
 	if err != nil {
-		log.Errorln("Error getting oTokenData  ", err)
+		return
+	}
+	collateral, underlying, strike, strikePrice, expiryTime, isPut, err := oTokenData.GetOtokenDetails(&bind.CallOpts{})
+	if err != nil {
+		return
+	}
+
+	option = otokenOption(otokenAddress, scraper.assets.get(underlying), scraper.assets.get(strike), scraper.assets.get(collateral), strikePrice, expiryTime, isPut)
+	err = scraper.updateOtokenState(&option)
+	return
+
+}
+
+// otokenOption returns the option series of the oToken at @otokenAddress with the details read from the oToken.
+func otokenOption(otokenAddress common.Address, underlying, strike, collateral dia.OptionAsset, strikePrice *big.Int, expiryTime *big.Int, isPut bool) dia.OnchainOption {
+	optionType := dia.CallOption
+	if isPut {
+		optionType = dia.PutOption
 	}
-	strikePrice, err := oTokenData.StrikePrice(&bind.CallOpts{})
+	return dia.OnchainOption{
+		Protocol:       dia.Opyn,
+		InstrumentName: otokenAddress.String(),
+		Underlying:     underlying,
+		StrikeAsset:    strike,
+		Collateral:     collateral,
+		OptionType:     optionType,
+		Style:          dia.EuropeanOption,
+		StrikePrice:    scaleAmount(strikePrice, otokenDecimals),
+		ExpirationTime: time.Unix(expiryTime.Int64(), 0),
+	}
+}
+
+// updateOtokenState reads the open interest and settlement status of the oToken @option.
+func (scraper *OpynOptionScraper) updateOtokenState(option *dia.OnchainOption) error {
+	otokenAddress := common.HexToAddress(option.InstrumentName)
+	oTokenData, err := Otoken.NewOtokenCaller(otokenAddress, scraper.RestClient)
 	if err != nil {
-		log.Errorln("Error getting strikePrice Detail ", err)
+		return err
 	}
-	isPut, err := oTokenData.IsPut(&bind.CallOpts{})
+	supply, err := oTokenData.TotalSupply(&bind.CallOpts{})
 	if err != nil {
-		log.Errorln("Error getting isPut Detail ", err)
+		return err
+	}
+	now := time.Now()
+	settled := false
+	var payout *big.Int
+	if !now.Before(option.ExpirationTime) {
+		// The settlement price is known once the controller allows to settle.
+		settled, err = scraper.controller.IsSettlementAllowed(&bind.CallOpts{}, otokenAddress)
+		if err != nil {
+			return err
+		}
+		if settled {
+			payout, err = scraper.controller.GetPayout(&bind.CallOpts{}, otokenAddress, big.NewInt(1e8))
+			if err != nil {
+				return err
+			}
+		}
 	}
+	setOtokenState(option, supply, settled, payout, now)
+	return nil
+}
 
-	expiryTime, err := oTokenData.ExpiryTimestamp(&bind.CallOpts{})
+// setOtokenState sets the state of the oToken @option at @now from its total @supply and, once
+// @settled, the @payout of one oToken.
+func setOtokenState(option *dia.OnchainOption, supply *big.Int, settled bool, payout *big.Int, now time.Time) {
+	if settled {
+		option.Payout = scaleAmount(payout, option.Collateral.Decimals)
+	}
+	option.OpenInterest = scaleAmount(supply, otokenDecimals)
+	option.Status = settlementStatus(option.ExpirationTime, settled, now)
+	option.LastUpdate = now
+}
+
+// refreshOptions periodically updates the state of all unsettled series. The contracts are read
+// on a copy of the series, so that the event handlers are not blocked meanwhile.
+func (scraper *OpynOptionScraper) refreshOptions() {
+	t := time.NewTicker(refreshOnchainOptions)
+	for range t.C {
+		updated := make(map[string]dia.OnchainOption)
+		for _, option := range scraper.getMarkets() {
+			if option.Status == dia.OptionSettled {
+				continue
+			}
+			err := scraper.updateOtokenState(&option)
+			if err != nil {
+				log.Errorln("Error updating oToken", option.InstrumentName, err)
+				continue
+			}
+			err = scraper.DataStore.SetOnchainOption(&option)
+			if err != nil {
+				log.Errorln("Error storing oToken", option.InstrumentName, err)
+			}
+			updated[option.InstrumentName] = option
+		}
+
+		scraper.marketsMu.Lock()
+		for i := range scraper.markets {
+			if option, ok := updated[scraper.markets[i].InstrumentName]; ok {
+				scraper.markets[i] = option
+			}
+		}
+		scraper.marketsMu.Unlock()
+	}
+}
+
+// market returns the scraped series of the oToken at @otokenAddress.
+func (scraper *OpynOptionScraper) market(otokenAddress common.Address) (dia.OnchainOption, bool) {
+	scraper.marketsMu.Lock()
+	defer scraper.marketsMu.Unlock()
+	for _, option := range scraper.markets {
+		if option.InstrumentName == otokenAddress.String() {
+			return option, true
+		}
+	}
+	return dia.OnchainOption{}, false
+}
+
+// watchSettlements stores the redemptions of oTokens and the settlements of vaults. Failed
+// subscriptions are renewed, and the events of the blocks missed in between are filtered.
+func (scraper *OpynOptionScraper) watchSettlements() {
+	var lastBlock uint64
+	for {
+		err := scraper.subscribeSettlements(&lastBlock)
+		log.Errorf("settlement subscription after block %d: %v", lastBlock, err)
+		time.Sleep(resubscribeDelay)
+	}
+}
+
+// subscribeSettlements stores the settlement events after @lastBlock, or from now on if it is 0,
+// and keeps @lastBlock up to date. It returns once a subscription fails.
+func (scraper *OpynOptionScraper) subscribeSettlements(lastBlock *uint64) error {
+	redeems := make(chan *OtokenController.OtokenControllerRedeem)
+	redeemSub, err := scraper.controller.WatchRedeem(&bind.WatchOpts{}, redeems, []common.Address{}, []common.Address{}, []common.Address{})
+	if err != nil {
+		return err
+	}
+	defer redeemSub.Unsubscribe()
+	settlements := make(chan *OtokenController.OtokenControllerVaultSettled)
+	settleSub, err := scraper.controller.WatchVaultSettled(&bind.WatchOpts{}, settlements, []common.Address{}, []common.Address{}, []common.Address{})
 	if err != nil {
-		log.Errorln("Error getting expiryTime Detail ", err)
+		return err
 	}
+	defer settleSub.Unsubscribe()
 
-	optionType := dia.CallOption
+	// Events in the head block can be received twice, which overwrites the stored event.
+	head, err := headBlock(scraper.RestClient)
+	if err != nil {
+		return err
+	}
+	if *lastBlock > 0 && *lastBlock < head {
+		err = scraper.backfillSettlements(*lastBlock+1, head)
+		if err != nil {
+			return err
+		}
+	}
+	if head > *lastBlock {
+		*lastBlock = head
+	}
 
-	if isPut {
-		optionType = dia.PutOption
+	for {
+		var (
+			exercise dia.OptionExercise
+			ok       bool
+		)
+		select {
+		case err := <-redeemSub.Err():
+			return err
+		case err := <-settleSub.Err():
+			return err
+		case redeem := <-redeems:
+			exercise, ok = scraper.redeemExercise(redeem)
+		case settlement := <-settlements:
+			exercise, ok = scraper.settlementExercise(settlement)
+		}
+		if !ok {
+			continue
+		}
+		if err := scraper.storeExercise(exercise); err != nil {
+			storeFailed(lastBlock, exercise)
+			return err
+		}
+		if exercise.BlockNumber > *lastBlock {
+			*lastBlock = exercise.BlockNumber
+		}
 	}
+}
 
-	sp := float64(strikePrice.Int64())/ 1e8
+// backfillSettlements stores the settlement events in the blocks @from to @to.
+func (scraper *OpynOptionScraper) backfillSettlements(from uint64, to uint64) error {
+	log.Infof("backfill settlements in blocks %d - %d", from, to)
+	redeems, err := scraper.controller.FilterRedeem(&bind.FilterOpts{Start: from, End: &to}, []common.Address{}, []common.Address{}, []common.Address{})
+	if err != nil {
+		return err
+	}
+	for redeems.Next() {
+		if exercise, ok := scraper.redeemExercise(redeems.Event); ok {
+			if err := scraper.storeExercise(exercise); err != nil {
+				return err
+			}
+		}
+	}
+	if err := redeems.Error(); err != nil {
+		return err
+	}
 
-	optionAttrs.strikePrice = sp
-	optionAttrs.optionType = optionType
-	optionAttrs.expiryDate = time.Unix(expiryTime.Int64(), 0)
-	return
+	settlements, err := scraper.controller.FilterVaultSettled(&bind.FilterOpts{Start: from, End: &to}, []common.Address{}, []common.Address{}, []common.Address{})
+	if err != nil {
+		return err
+	}
+	for settlements.Next() {
+		if exercise, ok := scraper.settlementExercise(settlements.Event); ok {
+			if err := scraper.storeExercise(exercise); err != nil {
+				return err
+			}
+		}
+	}
+	return settlements.Error()
+}
+
+// redeemExercise returns the exercise of a @redeem event. It returns false if the oToken is not scraped.
+func (scraper *OpynOptionScraper) redeemExercise(redeem *OtokenController.OtokenControllerRedeem) (dia.OptionExercise, bool) {
+	option, ok := scraper.market(redeem.Otoken)
+	if !ok {
+		return dia.OptionExercise{}, false
+	}
+	return dia.OptionExercise{
+		InstrumentName: option.InstrumentName,
+		Kind:           dia.OptionRedeemKind,
+		Account:        redeem.Redeemer.Hex(),
+		Amount:         scaleAmount(redeem.OtokenBurned, otokenDecimals),
+		Payout:         scaleAmount(redeem.Payout, option.Collateral.Decimals),
+		TxHash:         redeem.Raw.TxHash.Hex(),
+		BlockNumber:    redeem.Raw.BlockNumber,
+		LogIndex:       redeem.Raw.Index,
+	}, true
+}
 
+// settlementExercise returns the exercise of a vault @settlement. It returns false if the oToken is not scraped.
+func (scraper *OpynOptionScraper) settlementExercise(settlement *OtokenController.OtokenControllerVaultSettled) (dia.OptionExercise, bool) {
+	option, ok := scraper.market(settlement.Otoken)
+	if !ok {
+		return dia.OptionExercise{}, false
+	}
+	return dia.OptionExercise{
+		InstrumentName: option.InstrumentName,
+		Kind:           dia.OptionSettleKind,
+		Account:        settlement.AccountOwner.Hex(),
+		Payout:         scaleAmount(settlement.Payout, option.Collateral.Decimals),
+		TxHash:         settlement.Raw.TxHash.Hex(),
+		BlockNumber:    settlement.Raw.BlockNumber,
+		LogIndex:       settlement.Raw.Index,
+	}, true
+}
+
+// storeExercise stores @exercise at the time of its block.
+func (scraper *OpynOptionScraper) storeExercise(exercise dia.OptionExercise) (err error) {
+	exercise.Protocol = dia.Opyn
+	exercise.Time, err = blockTime(scraper.RestClient, exercise.BlockNumber)
+	if err != nil {
+		return fmt.Errorf("time of block %d: %v", exercise.BlockNumber, err)
+	}
+	return scraper.DataStore.SaveOptionExerciseInflux(exercise)
 }
 
 func (scraper *OpynOptionScraper) FetchInstruments() {
 	scraper.FetchMarkets()
 }
 
-func (scraper *OpynOptionScraper) MetaOnOptionIsAvailable(option OptionAttrs) (available bool, err error) {
+func (scraper *OpynOptionScraper) MetaOnOptionIsAvailable(option dia.OnchainOption) (available bool, err error) {
 	available = false
 	err = nil
 
 	// TODO: can make this faster by specifying BaseCurrency/QuoteCurrency instead
-	optionMetas, err := scraper.DataStore.GetOptionMeta(option.underLyingTokenName)
+	optionMetas, err := scraper.DataStore.GetOptionMeta("ETH")
 	if err != nil {
 		return
 	}
 
 	for _, optionMeta := range optionMetas {
-		if optionMeta.InstrumentName == option.id {
+		if optionMeta.InstrumentName == option.InstrumentName {
 			return true, nil
 		}
 	}
@@ -306,9 +572,15 @@ func (scraper *OpynOptionScraper) MetaOnOptionIsAvailable(option OptionAttrs) (a
 func (scraper *OpynOptionScraper) GetAndStoreOptionsMeta() (err error) {
 	instruments := scraper.getOPYNInstruments()
 
-	for _, instrument := range instruments {
+	for i := range instruments {
+		instrument := &instruments[i]
+		err = scraper.DataStore.SetOnchainOption(instrument)
+		if err != nil {
+			return
+		}
+
 		var available bool
-		available, err = scraper.MetaOnOptionIsAvailable(instrument)
+		available, err = scraper.MetaOnOptionIsAvailable(*instrument)
 
 		if err != nil {
 			return
@@ -316,12 +588,12 @@ func (scraper *OpynOptionScraper) GetAndStoreOptionsMeta() (err error) {
 
 		if !available {
 			optionMeta := dia.OptionMeta{
-				InstrumentName: instrument.id,
+				InstrumentName: instrument.InstrumentName,
 				BaseCurrency:   "ETH",
-				ExpirationTime: instrument.expiryDate,
-				StrikePrice:     instrument.strikePrice,
-				OptionType:     instrument.optionType,
-
+				QuoteCurrency:  opynQuoteCurrency(instrument),
+				ExpirationTime: instrument.ExpirationTime,
+				StrikePrice:    instrument.StrikePrice,
+				OptionType:     instrument.OptionType,
 			}
 
 			scraper.DataStore.SetOptionMeta(&optionMeta)
@@ -332,6 +604,15 @@ func (scraper *OpynOptionScraper) GetAndStoreOptionsMeta() (err error) {
 	return nil
 }
 
+// opynQuoteCurrency returns the currency the premium of @option is quoted in. oTokens are traded
+// against their strike asset, usually USDC.
+func opynQuoteCurrency(option *dia.OnchainOption) string {
+	if option.StrikeAsset.Symbol != "" {
+		return option.StrikeAsset.Symbol
+	}
+	return "USDC"
+}
+
 func (scraper *OpynOptionScraper) Channel() chan *dia.OptionOrderbookDatum {
 	return scraper.chanOrderBook
 }
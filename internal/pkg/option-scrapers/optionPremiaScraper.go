@@ -1,7 +1,7 @@
 package optionscrapers
 
 import (
-	"math"
+	"fmt"
 	"math/big"
 	"sync"
 	"time"
@@ -20,17 +20,29 @@ import (
 var PremiaMarketAddress = common.HexToAddress("0x45eBD0FC72E2056adb5c864Ea6F151ad943d94af")
 var PremiaOptionAddress = common.HexToAddress("0x5920cb60B1c62dC69467bf7c6EDFcFb3f98548c0")
 
-type OptionAttrs struct {
-	underLyingToken     string
-	underLyingTokenName string
-	id                  string
-	strikePrice         float64
-	expiryDate          time.Time
-	optionType          dia.OptionType
+// premiaOptionData are the terms and the state of a series read from the Premia option contract.
+type premiaOptionData struct {
+	Token         common.Address
+	StrikePrice   *big.Int
+	Expiration    *big.Int
+	IsCall        bool
+	ClaimsPreExp  *big.Int
+	ClaimsPostExp *big.Int
+	Exercised     *big.Int
+	Supply        *big.Int
+	Decimals      uint8
+}
+
+// premiaMarket is an option series of the Premia option contract.
+type premiaMarket struct {
+	optionID *big.Int
+	decimals uint8
+	option   dia.OnchainOption
 }
 
 type PremiaScraper struct {
-	markets    []OptionAttrs
+	markets    map[string]*premiaMarket
+	marketsMu  sync.Mutex
 	WsClient   *ethclient.Client
 	RestClient *ethclient.Client
 
@@ -41,6 +53,8 @@ type PremiaScraper struct {
 	DataStore          *models.DB
 	chanOrderBook      chan *dia.OptionOrderbookDatum
 	Ratelimiter        *rate.Limiter
+	assets             *onchainAssets
+	premiaOption       *PremiaOption.PremiaOption
 }
 
 func NewPremiaETHOptionScraper() *PremiaScraper {
@@ -62,11 +76,19 @@ func NewPremiaETHOptionScraper() *PremiaScraper {
 		log.Errorln("error getting modelstore")
 	}
 
+	premiaOption, err := PremiaOption.NewPremiaOption(PremiaOptionAddress, wsClient)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	s := &PremiaScraper{
+		markets:       make(map[string]*premiaMarket),
 		WsClient:      wsClient,
 		RestClient:    restClient,
 		chanOrderBook: make(chan *dia.OptionOrderbookDatum),
 		DataStore:     ds,
+		assets:        newOnchainAssets(restClient),
+		premiaOption:  premiaOption,
 	}
 	//s.GetAndStoreOptionsMeta()
 
@@ -76,12 +98,15 @@ func NewPremiaETHOptionScraper() *PremiaScraper {
 
 }
 
+// premiaInstrumentName returns the name of the Premia option series @optionID.
+func premiaInstrumentName(optionID *big.Int) string {
+	return PremiaOptionAddress.String() + "-" + optionID.String()
+}
+
 func (scrapper *PremiaScraper) subscribe() (chan *PremiaMarket.PremiaMarketOrderCreated, error) {
 
 	sink := make(chan *PremiaMarket.PremiaMarketOrderCreated)
 
-	PremiaMarket.NewPremiaMarketFilterer(PremiaMarketAddress, scrapper.WsClient)
-
 	optionFilterer, err := PremiaMarket.NewPremiaMarketFilterer(PremiaMarketAddress, scrapper.WsClient)
 	if err != nil {
 		log.Fatal(err)
@@ -102,6 +127,9 @@ func (scrapper *PremiaScraper) Scrape() {
 
 	log.Infoln("Scrape")
 
+	go scrapper.refreshOptions()
+	go scrapper.watchExercises()
+
 	sink, _ := scrapper.subscribe()
 
 	go func() {
@@ -109,41 +137,31 @@ func (scrapper *PremiaScraper) Scrape() {
 		for {
 			orderCreated, ok := <-sink
 			if ok {
+				market, known := scrapper.market(orderCreated.OptionId)
+				if !known || orderCreated.OptionContract != PremiaOptionAddress {
+					continue
+				}
 
-				var (
-					resolvedAskPX, resolvedAskSize, resolvedBidSize, resolvedBidPX float64
-				)
+				// Prices are in the payment token, amounts in options with the decimals of the series.
+				price := scaleAmount(orderCreated.PricePerUnit, scrapper.assets.get(orderCreated.PaymentToken).Decimals)
+				size := scaleAmount(orderCreated.Amount, orderCreated.Decimals)
 
+				var o = dia.OptionOrderbookDatum{
+					InstrumentName:  market.option.InstrumentName,
+					ObservationTime: time.Now(),
+					StrikePrice:     market.option.StrikePrice,
+					ExpirationTime:  market.option.ExpirationTime,
+					OpenInterest:    market.option.OpenInterest,
+				}
 				if orderCreated.Side == 0 {
-
-					resolvedAskSize = float64(orderCreated.PricePerUnit.Int64())
-					resolvedAskPX = float64(orderCreated.Amount.Int64()) / math.Exp(float64(orderCreated.Decimals))
-
-					var o = dia.OptionOrderbookDatum{
-						InstrumentName: orderCreated.OptionContract.String(),
-						ExpirationTime: time.Unix(orderCreated.ExpirationTime.Int64(), 0),
-						AskSize:        resolvedAskSize,
-						AskPrice:       resolvedAskPX,
-						BidPrice:       resolvedBidPX,
-						BidSize:        resolvedBidSize,
-					}
-					log.Println("Got trade", o)
-					scrapper.chanOrderBook <- &o
-
+					o.AskPrice = price
+					o.AskSize = size
 				} else {
-					resolvedBidSize = float64(orderCreated.PricePerUnit.Int64())
-					resolvedBidPX = float64(orderCreated.Amount.Int64()) / math.Exp(float64(orderCreated.Decimals))
-
-					var o = dia.OptionOrderbookDatum{
-						InstrumentName: orderCreated.OptionContract.String(),
-						ExpirationTime: time.Unix(orderCreated.ExpirationTime.Int64(), 0),
-						BidPrice:       resolvedBidPX,
-						BidSize:        resolvedBidSize,
-					}
-					log.Println("Got trade", o)
-					scrapper.chanOrderBook <- &o
-
+					o.BidPrice = price
+					o.BidSize = size
 				}
+				log.Println("Got trade", o)
+				scrapper.chanOrderBook <- &o
 
 			}
 
@@ -161,68 +179,273 @@ func (scrapper *PremiaScraper) FetchMarkets() {
 		log.Fatal(err)
 	}
 
-	premiaOption, err := PremiaOption.NewPremiaOption(PremiaOptionAddress, scrapper.RestClient)
+	denominator, err := scrapper.premiaOption.Denominator(&bind.CallOpts{})
 	if err != nil {
-		log.Fatal(err)
+		log.Error("error getting denominator: ", err)
+		return
 	}
+	strikeAsset := scrapper.assets.get(denominator)
+
 	var block uint64
 
 	block = uint64(11345363)
 	tokesn, err := optionFilterer.FilterOptionIdCreated(&bind.FilterOpts{Start: block}, []*big.Int{}, []common.Address{})
 	if err != nil {
 		log.Error("error in get watching channel: ", err)
+		return
 	}
 	for tokesn.Next() {
-
-		response, err := premiaOption.OptionData(&bind.CallOpts{}, tokesn.Event.OptionId)
+		market := &premiaMarket{
+			optionID: tokesn.Event.OptionId,
+			option: dia.OnchainOption{
+				Protocol:       dia.Premia,
+				InstrumentName: premiaInstrumentName(tokesn.Event.OptionId),
+				Underlying:     scrapper.assets.get(tokesn.Event.Token),
+				StrikeAsset:    strikeAsset,
+				Style:          dia.AmericanOption,
+			},
+		}
+		err = scrapper.updateOptionState(market)
 		if err != nil {
 			log.Error("error in get getting option data: ", err)
+			continue
 		}
-		optionType := dia.CallOption
 
-		if !response.IsCall {
-			optionType = dia.PutOption
+		scrapper.marketsMu.Lock()
+		scrapper.markets[tokesn.Event.OptionId.String()] = market
+		scrapper.marketsMu.Unlock()
+
+		err = scrapper.DataStore.SetOnchainOption(&market.option)
+		if err != nil {
+			log.Error("error storing option: ", err)
 		}
 
-		market := OptionAttrs{
-			underLyingToken: tokesn.Event.Token.String(),
-			id:              tokesn.Event.Token.String(),
-			optionType:      optionType,
+	}
+
+}
+
+// updateOptionState reads the terms, open interest and exercised amount of the series @market.
+// Calls are collateralized by the underlying, puts by the strike asset.
+func (scrapper *PremiaScraper) updateOptionState(market *premiaMarket) error {
+	response, err := scrapper.premiaOption.OptionData(&bind.CallOpts{}, market.optionID)
+	if err != nil {
+		return err
+	}
+	setPremiaOptionData(market, response, time.Now())
+	return nil
+}
+
+// setPremiaOptionData sets the terms and the state of the series @market at @now from @data.
+func setPremiaOptionData(market *premiaMarket, data premiaOptionData, now time.Time) {
+	option := &market.option
+	option.OptionType = dia.CallOption
+	option.Collateral = option.Underlying
+	if !data.IsCall {
+		option.OptionType = dia.PutOption
+		option.Collateral = option.StrikeAsset
+	}
+	market.decimals = data.Decimals
+	option.StrikePrice = scaleAmount(data.StrikePrice, option.StrikeAsset.Decimals)
+	option.ExpirationTime = time.Unix(data.Expiration.Int64(), 0)
+	option.OpenInterest = scaleAmount(data.Supply, data.Decimals)
+	option.Exercised = scaleAmount(data.Exercised, data.Decimals)
+	// Options are physically settled, so there is no settlement price to wait for.
+	option.Status = settlementStatus(option.ExpirationTime, true, now)
+	option.LastUpdate = now
+}
+
+// refreshOptions periodically updates the state of all unsettled series. The contract is read
+// on copies of the series, so that the event handlers are not blocked meanwhile.
+func (scrapper *PremiaScraper) refreshOptions() {
+	t := time.NewTicker(refreshOnchainOptions)
+	for range t.C {
+		var markets []premiaMarket
+		scrapper.marketsMu.Lock()
+		for _, market := range scrapper.markets {
+			if market.option.Status != dia.OptionSettled {
+				markets = append(markets, *market)
+			}
+		}
+		scrapper.marketsMu.Unlock()
+
+		for i := range markets {
+			market := &markets[i]
+			err := scrapper.updateOptionState(market)
+			if err != nil {
+				log.Errorln("Error updating option", market.option.InstrumentName, err)
+				continue
+			}
+			err = scrapper.DataStore.SetOnchainOption(&market.option)
+			if err != nil {
+				log.Errorln("Error storing option", market.option.InstrumentName, err)
+			}
+			scrapper.marketsMu.Lock()
+			scrapper.markets[market.optionID.String()] = market
+			scrapper.marketsMu.Unlock()
 		}
-		scrapper.markets = append(scrapper.markets, market)
+	}
+}
 
-		// check only for eth options
-		//if common.HexToAddress("0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2") == tokesn.Event.Token {
-		//	scrapper.markets = append(scrapper.markets, tokesn.Event.TokenAddress.String())
-		//
-		//}
+// market returns the series @optionID.
+func (scrapper *PremiaScraper) market(optionID *big.Int) (premiaMarket, bool) {
+	scrapper.marketsMu.Lock()
+	defer scrapper.marketsMu.Unlock()
+	market, ok := scrapper.markets[optionID.String()]
+	if !ok {
+		return premiaMarket{}, false
+	}
+	return *market, true
+}
 
+// watchExercises stores the exercises of options by their holders and the withdrawals of writers.
+// Failed subscriptions are renewed, and the events of the blocks missed in between are filtered.
+func (scrapper *PremiaScraper) watchExercises() {
+	var lastBlock uint64
+	for {
+		err := scrapper.subscribeExercises(&lastBlock)
+		log.Errorf("exercise subscription after block %d: %v", lastBlock, err)
+		time.Sleep(resubscribeDelay)
 	}
+}
 
+// subscribeExercises stores the exercises and withdrawals after @lastBlock, or from now on if it
+// is 0, and keeps @lastBlock up to date. It returns once a subscription fails.
+func (scrapper *PremiaScraper) subscribeExercises(lastBlock *uint64) error {
+	exercises := make(chan *PremiaOption.PremiaOptionOptionExercised)
+	exerciseSub, err := scrapper.premiaOption.WatchOptionExercised(&bind.WatchOpts{}, exercises, []common.Address{}, []*big.Int{}, []common.Address{})
+	if err != nil {
+		return err
+	}
+	defer exerciseSub.Unsubscribe()
+	withdrawals := make(chan *PremiaOption.PremiaOptionWithdraw)
+	withdrawSub, err := scrapper.premiaOption.WatchWithdraw(&bind.WatchOpts{}, withdrawals, []common.Address{}, []*big.Int{}, []common.Address{})
+	if err != nil {
+		return err
+	}
+	defer withdrawSub.Unsubscribe()
+
+	// Events in the head block can be received twice, which overwrites the stored event.
+	head, err := headBlock(scrapper.RestClient)
+	if err != nil {
+		return err
+	}
+	if *lastBlock > 0 && *lastBlock < head {
+		err = scrapper.backfillExercises(*lastBlock+1, head)
+		if err != nil {
+			return err
+		}
+	}
+	if head > *lastBlock {
+		*lastBlock = head
+	}
+
+	for {
+		var (
+			exercise dia.OptionExercise
+			ok       bool
+		)
+		select {
+		case err := <-exerciseSub.Err():
+			return err
+		case err := <-withdrawSub.Err():
+			return err
+		case e := <-exercises:
+			exercise, ok = scrapper.exercise(e)
+		case w := <-withdrawals:
+			exercise, ok = scrapper.withdrawal(w)
+		}
+		if !ok {
+			continue
+		}
+		if err := scrapper.storeExercise(exercise); err != nil {
+			storeFailed(lastBlock, exercise)
+			return err
+		}
+		if exercise.BlockNumber > *lastBlock {
+			*lastBlock = exercise.BlockNumber
+		}
+	}
 }
 
-func (scrapper *PremiaScraper) FetchInstruments() {
-	scrapper.FetchMarkets()
+// backfillExercises stores the exercises and withdrawals in the blocks @from to @to.
+func (scrapper *PremiaScraper) backfillExercises(from uint64, to uint64) error {
+	log.Infof("backfill exercises in blocks %d - %d", from, to)
+	exercises, err := scrapper.premiaOption.FilterOptionExercised(&bind.FilterOpts{Start: from, End: &to}, []common.Address{}, []*big.Int{}, []common.Address{})
+	if err != nil {
+		return err
+	}
+	for exercises.Next() {
+		if exercise, ok := scrapper.exercise(exercises.Event); ok {
+			if err := scrapper.storeExercise(exercise); err != nil {
+				return err
+			}
+		}
+	}
+	if err := exercises.Error(); err != nil {
+		return err
+	}
+
+	withdrawals, err := scrapper.premiaOption.FilterWithdraw(&bind.FilterOpts{Start: from, End: &to}, []common.Address{}, []*big.Int{}, []common.Address{})
+	if err != nil {
+		return err
+	}
+	for withdrawals.Next() {
+		if exercise, ok := scrapper.withdrawal(withdrawals.Event); ok {
+			if err := scrapper.storeExercise(exercise); err != nil {
+				return err
+			}
+		}
+	}
+	return withdrawals.Error()
 }
 
-// func (s *PremiaScraper) MetaOnOptionIsAvailable(option BinanceInstrument) (available bool, err error) {
-// 	available = false
-// 	err = nil
+// exercise returns the exercise of an option by its holder. It returns false if the series is not scraped.
+func (scrapper *PremiaScraper) exercise(e *PremiaOption.PremiaOptionOptionExercised) (dia.OptionExercise, bool) {
+	market, ok := scrapper.market(e.OptionId)
+	if !ok {
+		return dia.OptionExercise{}, false
+	}
+	return dia.OptionExercise{
+		InstrumentName: premiaInstrumentName(e.OptionId),
+		Kind:           dia.OptionExerciseKind,
+		Account:        e.User.Hex(),
+		Amount:         scaleAmount(e.Amount, market.decimals),
+		TxHash:         e.Raw.TxHash.Hex(),
+		BlockNumber:    e.Raw.BlockNumber,
+		LogIndex:       e.Raw.Index,
+	}, true
+}
 
-// 	// TODO: can make this faster by specifying BaseCurrency/QuoteCurrency instead
-// 	optionMetas, err := s.DataStore.GetOptionMeta(option.Underlying)
-// 	if err != nil {
-// 		return
-// 	}
+// withdrawal returns the withdrawal of a writer. It returns false if the series is not scraped.
+func (scrapper *PremiaScraper) withdrawal(w *PremiaOption.PremiaOptionWithdraw) (dia.OptionExercise, bool) {
+	market, ok := scrapper.market(w.OptionId)
+	if !ok {
+		return dia.OptionExercise{}, false
+	}
+	return dia.OptionExercise{
+		InstrumentName: premiaInstrumentName(w.OptionId),
+		Kind:           dia.OptionWithdrawalKind,
+		Account:        w.User.Hex(),
+		Amount:         scaleAmount(w.Amount, market.decimals),
+		TxHash:         w.Raw.TxHash.Hex(),
+		BlockNumber:    w.Raw.BlockNumber,
+		LogIndex:       w.Raw.Index,
+	}, true
+}
 
-// 	for _, optionMeta := range optionMetas {
-// 		if optionMeta.InstrumentName == option.Symbol {
-// 			return true, nil
-// 		}
-// 	}
+// storeExercise stores @exercise at the time of its block.
+func (scrapper *PremiaScraper) storeExercise(exercise dia.OptionExercise) (err error) {
+	exercise.Protocol = dia.Premia
+	exercise.Time, err = blockTime(scrapper.RestClient, exercise.BlockNumber)
+	if err != nil {
+		return fmt.Errorf("time of block %d: %v", exercise.BlockNumber, err)
+	}
+	return scrapper.DataStore.SaveOptionExerciseInflux(exercise)
+}
 
-// 	return
-// }
+func (scrapper *PremiaScraper) FetchInstruments() {
+	scrapper.FetchMarkets()
+}
 
 func (scrapper *PremiaScraper) Channel() chan *dia.OptionOrderbookDatum {
 	return scrapper.chanOrderBook
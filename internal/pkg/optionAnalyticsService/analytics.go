@@ -0,0 +1,208 @@
+package optionanalytics
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+	log "github.com/sirupsen/logrus"
+)
+
+const secondsPerYear = 365 * 24 * 60 * 60
+
+// Option is an option together with the latest datum of its order book.
+type Option struct {
+	Meta      dia.OptionMeta
+	Orderbook dia.OptionOrderbookDatum
+}
+
+// Config holds the parameters of the option analytics.
+type Config struct {
+	// Rate is the continuously compounded risk free rate used in the Black-Scholes model.
+	Rate float64
+}
+
+// midPrice returns the mid price of the order book @datum.
+func midPrice(datum dia.OptionOrderbookDatum) (float64, error) {
+	if datum.AskPrice <= 0 || datum.BidPrice <= 0 {
+		return 0, errors.New("no two-sided quote for " + datum.InstrumentName)
+	}
+	return (datum.AskPrice + datum.BidPrice) / 2, nil
+}
+
+// InstrumentGreeks returns the implied volatility and greeks of @option at time @now, using the mid
+// price of its order book and the price @spot of its underlying in USD. @quotePrice is the price in
+// USD of the currency the option is quoted in.
+func InstrumentGreeks(option Option, spot float64, quotePrice float64, config Config, now time.Time) (dia.OptionGreeks, error) {
+	meta := option.Meta
+	greeks := dia.OptionGreeks{
+		InstrumentName:  meta.InstrumentName,
+		Underlying:      meta.BaseCurrency,
+		OptionType:      meta.OptionType,
+		StrikePrice:     meta.StrikePrice,
+		ExpirationTime:  meta.ExpirationTime,
+		UnderlyingPrice: spot,
+		OpenInterest:    option.Orderbook.OpenInterest,
+		Time:            now,
+	}
+	t := meta.ExpirationTime.Sub(now).Seconds() / secondsPerYear
+	if t <= 0 {
+		return greeks, errors.New(meta.InstrumentName + " is expired")
+	}
+	price, err := midPrice(option.Orderbook)
+	if err != nil {
+		return greeks, err
+	}
+	price *= quotePrice
+	greeks.MarkPrice = price
+
+	sigma, err := ImpliedVolatility(meta.OptionType, price, spot, meta.StrikePrice, t, config.Rate)
+	if err != nil {
+		return greeks, err
+	}
+	g := ComputeGreeks(meta.OptionType, spot, meta.StrikePrice, t, config.Rate, sigma)
+	greeks.ImpliedVolatility = sigma
+	greeks.Delta = g.Delta
+	greeks.Gamma = g.Gamma
+	greeks.Vega = g.Vega
+	greeks.Theta = g.Theta
+	return greeks, nil
+}
+
+// MaxPain returns the strike among the strikes of @options at which the total payoff to the holders
+// of @options, weighted by open interest, is minimal. All options should have the same expiry.
+func MaxPain(options []Option) float64 {
+	maxPain, minPayoff := 0.0, math.Inf(1)
+	for _, candidate := range options {
+		settlement := candidate.Meta.StrikePrice
+		payoff := 0.0
+		for _, option := range options {
+			if option.Meta.OptionType == dia.PutOption {
+				payoff += option.Orderbook.OpenInterest * math.Max(option.Meta.StrikePrice-settlement, 0)
+			} else {
+				payoff += option.Orderbook.OpenInterest * math.Max(settlement-option.Meta.StrikePrice, 0)
+			}
+		}
+		if payoff < minPayoff || (payoff == minPayoff && settlement < maxPain) {
+			maxPain, minPayoff = settlement, payoff
+		}
+	}
+	return maxPain
+}
+
+// ExpirySummaries groups @options on @underlying by expiry and returns the open interest weighted
+// put/call ratio and max pain of each expiry, sorted by expiry. Expiries without open interest are omitted.
+func ExpirySummaries(underlying string, options []Option, now time.Time) []dia.OptionExpirySummary {
+	byExpiry := make(map[int64][]Option)
+	for _, option := range options {
+		if !option.Meta.ExpirationTime.After(now) {
+			continue
+		}
+		expiry := option.Meta.ExpirationTime.Unix()
+		byExpiry[expiry] = append(byExpiry[expiry], option)
+	}
+
+	summaries := []dia.OptionExpirySummary{}
+	for expiry, expiryOptions := range byExpiry {
+		summary := dia.OptionExpirySummary{
+			Underlying:     underlying,
+			ExpirationTime: time.Unix(expiry, 0).UTC(),
+			Time:           now,
+		}
+		for _, option := range expiryOptions {
+			if option.Meta.OptionType == dia.PutOption {
+				summary.PutOpenInterest += option.Orderbook.OpenInterest
+			} else {
+				summary.CallOpenInterest += option.Orderbook.OpenInterest
+			}
+		}
+		if summary.CallOpenInterest+summary.PutOpenInterest == 0 {
+			continue
+		}
+		if summary.CallOpenInterest > 0 {
+			summary.PutCallRatio = summary.PutOpenInterest / summary.CallOpenInterest
+		}
+		summary.MaxPain = MaxPain(expiryOptions)
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].ExpirationTime.Before(summaries[j].ExpirationTime)
+	})
+	return summaries
+}
+
+// Update computes the greeks and expiry summaries of all options on @underlying from their latest
+// order books in @ds and stores them.
+func Update(ds models.Datastore, underlying string, config Config) error {
+	metas, err := ds.GetOptionMeta(underlying)
+	if err != nil {
+		return err
+	}
+	quotation, err := ds.GetQuotation(underlying)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+
+	// quotePrices caches the USD prices of the quote currencies of the options
+	quotePrices := map[string]float64{underlying: quotation.Price}
+	quotePrice := func(meta dia.OptionMeta) (float64, error) {
+		currency := meta.QuoteCurrency
+		if currency == "" {
+			currency = meta.BaseCurrency
+		}
+		if price, ok := quotePrices[currency]; ok {
+			return price, nil
+		}
+		q, err := ds.GetQuotation(currency)
+		if err != nil {
+			return 0, err
+		}
+		quotePrices[currency] = q.Price
+		return q.Price, nil
+	}
+
+	options := []Option{}
+	greeks := []dia.OptionGreeks{}
+	for _, meta := range metas {
+		if !meta.ExpirationTime.After(now) {
+			continue
+		}
+		datum, err := ds.GetOptionOrderbookDataInflux(meta)
+		if err != nil {
+			log.Errorln("GetOptionOrderbookDataInflux", meta.InstrumentName, ":", err)
+			continue
+		}
+		option := Option{Meta: meta, Orderbook: datum}
+		options = append(options, option)
+		price, err := quotePrice(meta)
+		if err != nil {
+			log.Errorln("GetQuotation", meta.QuoteCurrency, ":", err)
+			continue
+		}
+		g, err := InstrumentGreeks(option, quotation.Price, price, config, now)
+		if err != nil {
+			log.Debugln("InstrumentGreeks:", err)
+			continue
+		}
+		greeks = append(greeks, g)
+	}
+	sort.Slice(greeks, func(i, j int) bool {
+		if !greeks[i].ExpirationTime.Equal(greeks[j].ExpirationTime) {
+			return greeks[i].ExpirationTime.Before(greeks[j].ExpirationTime)
+		}
+		if greeks[i].StrikePrice != greeks[j].StrikePrice {
+			return greeks[i].StrikePrice < greeks[j].StrikePrice
+		}
+		return greeks[i].OptionType < greeks[j].OptionType
+	})
+
+	err = ds.SetOptionGreeks(underlying, greeks)
+	if err != nil {
+		return err
+	}
+	return ds.SetOptionExpirySummaries(underlying, ExpirySummaries(underlying, options, now))
+}
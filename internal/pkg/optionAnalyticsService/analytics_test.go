@@ -0,0 +1,76 @@
+package optionanalytics
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+func almostEqual(a float64, b float64, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance
+}
+
+func TestBlackScholes(t *testing.T) {
+	call := BlackScholesPrice(dia.CallOption, 100, 100, 1, 0.05, 0.2)
+	put := BlackScholesPrice(dia.PutOption, 100, 100, 1, 0.05, 0.2)
+	if !almostEqual(call, 10.4506, 1e-4) || !almostEqual(put, 5.5735, 1e-4) {
+		t.Errorf("call %v, put %v", call, put)
+	}
+
+	g := ComputeGreeks(dia.CallOption, 100, 100, 1, 0.05, 0.2)
+	if !almostEqual(g.Delta, 0.6368, 1e-4) || !almostEqual(g.Gamma, 0.018762, 1e-6) ||
+		!almostEqual(g.Vega, 0.37524, 1e-5) || !almostEqual(g.Theta, -6.4140/365, 1e-5) {
+		t.Errorf("call greeks %+v", g)
+	}
+	p := ComputeGreeks(dia.PutOption, 100, 100, 1, 0.05, 0.2)
+	if !almostEqual(p.Delta, g.Delta-1, 1e-12) || !almostEqual(p.Theta, -1.6579/365, 1e-5) {
+		t.Errorf("put greeks %+v", p)
+	}
+
+	for _, optionType := range []dia.OptionType{dia.CallOption, dia.PutOption} {
+		price := BlackScholesPrice(optionType, 2000, 2400, 0.25, 0, 0.85)
+		sigma, err := ImpliedVolatility(optionType, price, 2000, 2400, 0.25, 0)
+		if err != nil || !almostEqual(sigma, 0.85, 1e-6) {
+			t.Errorf("implied volatility %v, %v", sigma, err)
+		}
+	}
+	if _, err := ImpliedVolatility(dia.CallOption, 150, 100, 100, 1, 0); err == nil {
+		t.Error("expected an error for a call above the spot price")
+	}
+}
+
+func TestExpirySummaries(t *testing.T) {
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	expiry := time.Date(2021, 6, 25, 8, 0, 0, 0, time.UTC)
+	option := func(optionType dia.OptionType, strike float64, openInterest float64, expiration time.Time) Option {
+		return Option{
+			Meta:      dia.OptionMeta{BaseCurrency: "ETH", OptionType: optionType, StrikePrice: strike, ExpirationTime: expiration},
+			Orderbook: dia.OptionOrderbookDatum{OpenInterest: openInterest},
+		}
+	}
+	options := []Option{
+		option(dia.CallOption, 2000, 100, expiry),
+		option(dia.CallOption, 2500, 300, expiry),
+		option(dia.PutOption, 2000, 200, expiry),
+		option(dia.PutOption, 1500, 50, expiry),
+		option(dia.CallOption, 3000, 0, expiry.AddDate(0, 1, 0)),
+		option(dia.PutOption, 2000, 10, now.AddDate(0, 0, -1)),
+	}
+
+	// payoffs: 1500: 200*500 = 100000, 2000: 50*0 = 0, 2500: 100*500 + 0 = 50000
+	if maxPain := MaxPain(options[:4]); maxPain != 2000 {
+		t.Errorf("max pain %v", maxPain)
+	}
+
+	summaries := ExpirySummaries("ETH", options, now)
+	if len(summaries) != 1 {
+		t.Fatalf("summaries %+v", summaries)
+	}
+	s := summaries[0]
+	if !s.ExpirationTime.Equal(expiry) || s.CallOpenInterest != 400 || s.PutOpenInterest != 250 ||
+		s.PutCallRatio != 0.625 || s.MaxPain != 2000 {
+		t.Errorf("summary %+v", s)
+	}
+}
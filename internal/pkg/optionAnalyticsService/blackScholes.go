@@ -0,0 +1,90 @@
+package optionanalytics
+
+import (
+	"errors"
+	"math"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+const (
+	minVolatility = 1e-4
+	maxVolatility = 10.0
+	volTolerance  = 1e-8
+	maxIterations = 200
+)
+
+// Greeks are the sensitivities of an option's price. Vega is the change of the price for a change of
+// the volatility by one percentage point, Theta the change of the price over one calendar day.
+type Greeks struct {
+	Delta float64
+	Gamma float64
+	Vega  float64
+	Theta float64
+}
+
+func normCDF(x float64) float64 {
+	return 0.5 * math.Erfc(-x/math.Sqrt2)
+}
+
+func normPDF(x float64) float64 {
+	return math.Exp(-x*x/2) / math.Sqrt(2*math.Pi)
+}
+
+func d1d2(spot float64, strike float64, t float64, rate float64, sigma float64) (float64, float64) {
+	d1 := (math.Log(spot/strike) + (rate+sigma*sigma/2)*t) / (sigma * math.Sqrt(t))
+	return d1, d1 - sigma*math.Sqrt(t)
+}
+
+// BlackScholesPrice returns the Black-Scholes price of a european option of @optionType with @strike
+// on an underlying priced at @spot, @t years before expiry, with the continuous risk free @rate and
+// the volatility @sigma.
+func BlackScholesPrice(optionType dia.OptionType, spot float64, strike float64, t float64, rate float64, sigma float64) float64 {
+	discount := math.Exp(-rate * t)
+	d1, d2 := d1d2(spot, strike, t, rate, sigma)
+	if optionType == dia.PutOption {
+		return strike*discount*normCDF(-d2) - spot*normCDF(-d1)
+	}
+	return spot*normCDF(d1) - strike*discount*normCDF(d2)
+}
+
+// ImpliedVolatility returns the volatility at which the Black-Scholes price of the option equals @price.
+// It fails if @price is outside of the bounds of the option's prices.
+func ImpliedVolatility(optionType dia.OptionType, price float64, spot float64, strike float64, t float64, rate float64) (float64, error) {
+	if price <= 0 || spot <= 0 || strike <= 0 || t <= 0 {
+		return 0, errors.New("price, spot, strike and time to expiry must be positive")
+	}
+	low, high := minVolatility, maxVolatility
+	if price < BlackScholesPrice(optionType, spot, strike, t, rate, low) || price > BlackScholesPrice(optionType, spot, strike, t, rate, high) {
+		return 0, errors.New("price out of bounds")
+	}
+	// The price is increasing in the volatility, so bisection always converges.
+	for i := 0; i < maxIterations && high-low > volTolerance; i++ {
+		mid := (low + high) / 2
+		if BlackScholesPrice(optionType, spot, strike, t, rate, mid) < price {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return (low + high) / 2, nil
+}
+
+// ComputeGreeks returns the Black-Scholes greeks of an option with the parameters of BlackScholesPrice.
+func ComputeGreeks(optionType dia.OptionType, spot float64, strike float64, t float64, rate float64, sigma float64) Greeks {
+	discount := math.Exp(-rate * t)
+	d1, d2 := d1d2(spot, strike, t, rate, sigma)
+	g := Greeks{
+		Gamma: normPDF(d1) / (spot * sigma * math.Sqrt(t)),
+		Vega:  spot * normPDF(d1) * math.Sqrt(t) / 100,
+	}
+	decay := -spot * normPDF(d1) * sigma / (2 * math.Sqrt(t))
+	if optionType == dia.PutOption {
+		g.Delta = normCDF(d1) - 1
+		g.Theta = (decay + rate*strike*discount*normCDF(-d2)) / 365
+	} else {
+		g.Delta = normCDF(d1)
+		g.Theta = (decay - rate*strike*discount*normCDF(d2)) / 365
+	}
+	return g
+}
@@ -0,0 +1,210 @@
+package ratederivatives
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/utils"
+)
+
+// Observance determines the day a holiday is observed on if it falls on a weekend.
+type Observance int
+
+const (
+	// NotObserved holidays falling on a weekend are not moved.
+	NotObserved Observance = iota
+	// NearestWeekday holidays are observed on Friday if on Saturday and on Monday if on Sunday.
+	NearestWeekday
+	// SundayToMonday holidays are observed on Monday if on Sunday and not moved if on Saturday.
+	SundayToMonday
+	// NextWeekday holidays are observed on the next weekday that is not a holiday already,
+	// as done with UK substitute days.
+	NextWeekday
+)
+
+// HolidayRule generates a holiday in each year from FromYear to ToYear (zero for no bound).
+// The date is given either by Month and Day, by the Nth Weekday of Month (Nth -1 for the last),
+// or by an offset of EasterOffset days to Easter Sunday if IsEaster is set.
+type HolidayRule struct {
+	Name         string
+	Month        time.Month
+	Day          int
+	Weekday      time.Weekday
+	Nth          int
+	IsEaster     bool
+	EasterOffset int
+	Observance   Observance
+	FromYear     int
+	ToYear       int
+}
+
+// Calendar is a business day calendar. Holidays are generated from Rules, complemented by
+// the dates in AddedHolidays and reduced by the dates in RemovedHolidays, both given as yyyy-mm-dd.
+type Calendar struct {
+	Name            string
+	Rules           []HolidayRule
+	AddedHolidays   []string
+	RemovedHolidays []string
+
+	mu    sync.Mutex
+	years map[int]map[string]bool
+}
+
+// date returns the day of @rule in @year, before applying the observance.
+func (rule HolidayRule) date(year int) time.Time {
+	switch {
+	case rule.IsEaster:
+		return easterSunday(year).AddDate(0, 0, rule.EasterOffset)
+	case rule.Nth > 0:
+		day := time.Date(year, rule.Month, 1, 0, 0, 0, 0, time.UTC)
+		for day.Weekday() != rule.Weekday {
+			day = day.AddDate(0, 0, 1)
+		}
+		return day.AddDate(0, 0, 7*(rule.Nth-1))
+	case rule.Nth < 0:
+		day := time.Date(year, rule.Month+1, 0, 0, 0, 0, 0, time.UTC)
+		for day.Weekday() != rule.Weekday {
+			day = day.AddDate(0, 0, -1)
+		}
+		return day
+	default:
+		return time.Date(year, rule.Month, rule.Day, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// easterSunday returns Easter Sunday of the gregorian calendar in @year (anonymous gregorian algorithm).
+func easterSunday(year int) time.Time {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	g := (b - (b+8)/25 + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	l := (32 + 2*e + 2*(c/4) - h - c%4) % 7
+	m := (a + 11*h + 22*l) / 451
+	n := h + l - 7*m + 114
+	return time.Date(year, time.Month(n/31), n%31+1, 0, 0, 0, 0, time.UTC)
+}
+
+// holidays returns the holidays of @year as set of dates formatted as yyyy-mm-dd.
+func (cal *Calendar) holidays(year int) map[string]bool {
+	cal.mu.Lock()
+	defer cal.mu.Unlock()
+	if h, ok := cal.years[year]; ok {
+		return h
+	}
+
+	h := make(map[string]bool)
+	for _, rule := range cal.Rules {
+		if (rule.FromYear != 0 && year < rule.FromYear) || (rule.ToYear != 0 && year > rule.ToYear) {
+			continue
+		}
+		day := rule.date(year)
+		switch rule.Observance {
+		case NearestWeekday:
+			if day.Weekday() == time.Saturday {
+				day = day.AddDate(0, 0, -1)
+			} else if day.Weekday() == time.Sunday {
+				day = day.AddDate(0, 0, 1)
+			}
+		case SundayToMonday:
+			if day.Weekday() == time.Sunday {
+				day = day.AddDate(0, 0, 1)
+			}
+		case NextWeekday:
+			for !utils.CheckWeekDay(day) || h[day.Format("2006-01-02")] {
+				day = day.AddDate(0, 0, 1)
+			}
+		}
+		h[day.Format("2006-01-02")] = true
+	}
+	prefix := strconv.Itoa(year) + "-"
+	for _, day := range cal.AddedHolidays {
+		if strings.HasPrefix(day, prefix) {
+			h[day] = true
+		}
+	}
+	for _, day := range cal.RemovedHolidays {
+		delete(h, day)
+	}
+
+	if cal.years == nil {
+		cal.years = make(map[int]map[string]bool)
+	}
+	cal.years[year] = h
+	return h
+}
+
+// IsHoliday returns true if @date is a holiday of the calendar, irrespective of the daytime.
+// Weekends are not holidays.
+func (cal *Calendar) IsHoliday(date time.Time) bool {
+	return utils.CheckWeekDay(date) && cal.holidays(date.Year())[date.Format("2006-01-02")]
+}
+
+// IsBusinessDay returns true if @date is neither a weekend nor a holiday.
+func (cal *Calendar) IsBusinessDay(date time.Time) bool {
+	return utils.CheckWeekDay(date) && !cal.IsHoliday(date)
+}
+
+// Holidays returns the holidays in the period from @dateInit to @dateFinal, both included,
+// sorted in increasing order. The result can be passed to RateFactor and CompoundedRate.
+func (cal *Calendar) Holidays(dateInit, dateFinal time.Time) []time.Time {
+	holidays := []time.Time{}
+	for day := dateInit; !utils.AfterDay(day, dateFinal); day = day.AddDate(0, 0, 1) {
+		if cal.IsHoliday(day) {
+			holidays = append(holidays, day)
+		}
+	}
+	return holidays
+}
+
+// BusinessDays returns the business days in the period from @dateInit to @dateFinal, both included.
+func (cal *Calendar) BusinessDays(dateInit, dateFinal time.Time) []time.Time {
+	days := []time.Time{}
+	for day := dateInit; !utils.AfterDay(day, dateFinal); day = day.AddDate(0, 0, 1) {
+		if cal.IsBusinessDay(day) {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+// PreviousBusinessDay returns the latest business day before or at @date.
+func (cal *Calendar) PreviousBusinessDay(date time.Time) time.Time {
+	for !cal.IsBusinessDay(date) {
+		date = date.AddDate(0, 0, -1)
+	}
+	return date
+}
+
+// NextBusinessDay returns the earliest business day after or at @date.
+func (cal *Calendar) NextBusinessDay(date time.Time) time.Time {
+	for !cal.IsBusinessDay(date) {
+		date = date.AddDate(0, 0, 1)
+	}
+	return date
+}
+
+// RateFactor returns the number of calendar days the fixing on @date accrues for, i.e. the
+// number of days until the next business day.
+func (cal *Calendar) RateFactor(date time.Time) int {
+	n := 1
+	for !cal.IsBusinessDay(date.AddDate(0, 0, n)) {
+		n++
+	}
+	return n
+}
+
+// GetCalendar returns the business day calendar of the rate benchmark @symbol. Term rates and
+// averages (such as SOFR30) use the calendar of their overnight rate.
+func GetCalendar(symbol string) (*Calendar, error) {
+	symbol = strings.ToUpper(symbol)
+	for name, cal := range rateCalendars {
+		if strings.HasPrefix(symbol, name) {
+			return cal, nil
+		}
+	}
+	return nil, errors.New("no calendar for rate " + symbol)
+}
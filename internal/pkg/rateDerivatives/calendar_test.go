@@ -0,0 +1,87 @@
+package ratederivatives
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEasterSunday(t *testing.T) {
+	tables := map[int]string{
+		2000: "2000-04-23",
+		2021: "2021-04-04",
+		2022: "2022-04-17",
+		2023: "2023-04-09",
+		2024: "2024-03-31",
+	}
+	for year, expected := range tables {
+		if date := easterSunday(year).Format("2006-01-02"); date != expected {
+			t.Errorf("Easter Sunday %d is %s but should be %s", year, date, expected)
+		}
+	}
+}
+
+func TestCalendarHolidays(t *testing.T) {
+	tables := []struct {
+		cal      *Calendar
+		year     int
+		holidays []string
+	}{
+		{USSIFMA, 2021, []string{"2021-01-01", "2021-01-18", "2021-02-15", "2021-05-31", "2021-07-05", "2021-09-06", "2021-10-11", "2021-11-11", "2021-11-25", "2021-12-24"}},
+		{USSIFMA, 2022, []string{"2022-01-17", "2022-02-21", "2022-04-15", "2022-05-30", "2022-06-20", "2022-07-04", "2022-09-05", "2022-10-10", "2022-11-11", "2022-11-24", "2022-12-26"}},
+		{TARGET2, 2022, []string{"2022-04-15", "2022-04-18", "2022-12-26"}},
+		{UK, 2021, []string{"2021-01-01", "2021-04-02", "2021-04-05", "2021-05-03", "2021-05-31", "2021-08-30", "2021-12-27", "2021-12-28"}},
+		{UK, 2022, []string{"2022-01-03", "2022-04-15", "2022-04-18", "2022-05-02", "2022-06-02", "2022-06-03", "2022-08-29", "2022-09-19", "2022-12-26", "2022-12-27"}},
+	}
+	for _, table := range tables {
+		dateInit := time.Date(table.year, 1, 1, 0, 0, 0, 0, time.UTC)
+		dateFinal := time.Date(table.year, 12, 31, 0, 0, 0, 0, time.UTC)
+		holidays := table.cal.Holidays(dateInit, dateFinal)
+		if len(holidays) != len(table.holidays) {
+			t.Errorf("%s %d: holidays are %v but should be %v", table.cal.Name, table.year, holidays, table.holidays)
+			continue
+		}
+		for i := range holidays {
+			if holidays[i].Format("2006-01-02") != table.holidays[i] {
+				t.Errorf("%s %d: holiday is %v but should be %s", table.cal.Name, table.year, holidays[i], table.holidays[i])
+			}
+		}
+	}
+}
+
+func TestCalendarRateFactor(t *testing.T) {
+	tables := []struct {
+		cal        *Calendar
+		date       string
+		ratefactor int
+	}{
+		// Thursday before Good Friday and Easter Monday
+		{UK, "2022-04-14", 5},
+		{TARGET2, "2022-04-14", 5},
+		// Good Friday is a business day in 2021.
+		{USSIFMA, "2021-04-01", 1},
+		{USSIFMA, "2021-04-02", 3},
+		{USSIFMA, "2022-04-14", 4},
+	}
+	for _, table := range tables {
+		date, _ := time.Parse("2006-01-02", table.date)
+		if n := table.cal.RateFactor(date); n != table.ratefactor {
+			t.Errorf("%s %s: rate factor is %d but should be %d", table.cal.Name, table.date, n, table.ratefactor)
+		}
+		holidays := table.cal.Holidays(date, date.AddDate(0, 0, 10))
+		if n, _ := RateFactor(date, holidays); n != table.ratefactor {
+			t.Errorf("%s %s: rate factor from holidays is %d but should be %d", table.cal.Name, table.date, n, table.ratefactor)
+		}
+	}
+}
+
+func TestGetCalendar(t *testing.T) {
+	tables := map[string]*Calendar{"SOFR": USSIFMA, "SOFR90": USSIFMA, "ESTER": TARGET2, "SONIA": UK}
+	for symbol, expected := range tables {
+		if cal, err := GetCalendar(symbol); err != nil || cal != expected {
+			t.Errorf("calendar of %s is %v but should be %s", symbol, cal, expected.Name)
+		}
+	}
+	if _, err := GetCalendar("LIBOR"); err == nil {
+		t.Error("expected error for unknown rate")
+	}
+}
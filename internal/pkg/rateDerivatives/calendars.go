@@ -0,0 +1,77 @@
+package ratederivatives
+
+import "time"
+
+// USSIFMA is the US bond market calendar following the full close recommendations of SIFMA.
+// SOFR is published on its business days.
+var USSIFMA = &Calendar{
+	Name: "US SIFMA",
+	Rules: []HolidayRule{
+		{Name: "New Year's Day", Month: time.January, Day: 1, Observance: SundayToMonday},
+		{Name: "Martin Luther King Jr. Day", Month: time.January, Weekday: time.Monday, Nth: 3},
+		{Name: "Washington's Birthday", Month: time.February, Weekday: time.Monday, Nth: 3},
+		{Name: "Good Friday", IsEaster: true, EasterOffset: -2},
+		{Name: "Memorial Day", Month: time.May, Weekday: time.Monday, Nth: -1},
+		{Name: "Juneteenth", Month: time.June, Day: 19, Observance: NearestWeekday, FromYear: 2022},
+		{Name: "Independence Day", Month: time.July, Day: 4, Observance: NearestWeekday},
+		{Name: "Labor Day", Month: time.September, Weekday: time.Monday, Nth: 1},
+		{Name: "Columbus Day", Month: time.October, Weekday: time.Monday, Nth: 2},
+		{Name: "Veterans Day", Month: time.November, Day: 11, Observance: SundayToMonday},
+		{Name: "Thanksgiving Day", Month: time.November, Weekday: time.Thursday, Nth: 4},
+		{Name: "Christmas Day", Month: time.December, Day: 25, Observance: NearestWeekday},
+	},
+	// National Day of Mourning for President George H. W. Bush.
+	AddedHolidays: []string{"2018-12-05"},
+	// Early close instead of full close on Good Friday due to the release of employment data.
+	RemovedHolidays: []string{"2021-04-02", "2023-04-07"},
+}
+
+// TARGET2 is the calendar of the Eurosystem's payment system. ESTER is published on its business days.
+var TARGET2 = &Calendar{
+	Name: "TARGET2",
+	Rules: []HolidayRule{
+		{Name: "New Year's Day", Month: time.January, Day: 1},
+		{Name: "Good Friday", IsEaster: true, EasterOffset: -2},
+		{Name: "Easter Monday", IsEaster: true, EasterOffset: 1},
+		{Name: "Labour Day", Month: time.May, Day: 1},
+		{Name: "Christmas Day", Month: time.December, Day: 25},
+		{Name: "Boxing Day", Month: time.December, Day: 26},
+	},
+}
+
+// UK is the calendar of bank holidays in England and Wales. SONIA is published on its business days.
+var UK = &Calendar{
+	Name: "UK",
+	Rules: []HolidayRule{
+		{Name: "New Year's Day", Month: time.January, Day: 1, Observance: NextWeekday},
+		{Name: "Good Friday", IsEaster: true, EasterOffset: -2},
+		{Name: "Easter Monday", IsEaster: true, EasterOffset: 1},
+		{Name: "Early May Bank Holiday", Month: time.May, Weekday: time.Monday, Nth: 1},
+		{Name: "Spring Bank Holiday", Month: time.May, Weekday: time.Monday, Nth: -1},
+		{Name: "Summer Bank Holiday", Month: time.August, Weekday: time.Monday, Nth: -1},
+		{Name: "Christmas Day", Month: time.December, Day: 25, Observance: NextWeekday},
+		{Name: "Boxing Day", Month: time.December, Day: 26, Observance: NextWeekday},
+	},
+	AddedHolidays: []string{
+		"1999-12-31", // Millennium
+		"2002-06-03", // Golden Jubilee
+		"2002-06-04", // Spring Bank Holiday moved
+		"2011-04-29", // Royal Wedding
+		"2012-06-04", // Spring Bank Holiday moved
+		"2012-06-05", // Diamond Jubilee
+		"2020-05-08", // Early May Bank Holiday moved to VE Day
+		"2022-06-02", // Spring Bank Holiday moved
+		"2022-06-03", // Platinum Jubilee
+		"2022-09-19", // State Funeral of Queen Elizabeth II
+		"2023-05-08", // Coronation of King Charles III
+	},
+	RemovedHolidays: []string{"2002-05-27", "2012-05-28", "2020-05-04", "2022-05-30"},
+}
+
+// rateCalendars maps rate benchmarks onto their business day calendar.
+var rateCalendars = map[string]*Calendar{
+	"SOFR":  USSIFMA,
+	"SAFR":  USSIFMA,
+	"ESTER": TARGET2,
+	"SONIA": UK,
+}
@@ -0,0 +1,129 @@
+package ratederivatives
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/utils"
+)
+
+// Conventions for compounding RFRs in arrears as defined by ISDA and ARRC.
+const (
+	// ConventionPlain compounds the fixing of each business day of the interest period.
+	ConventionPlain = "plain"
+	// ConventionLookback compounds the fixing Lag business days before each business day
+	// of the interest period, weighted by the days of the interest period.
+	ConventionLookback = "lookback"
+	// ConventionObsShift compounds the fixings of the observation period, i.e. the interest period
+	// shifted back by Lag business days, weighted by the days of the observation period.
+	ConventionObsShift = "obsShift"
+	// ConventionLockout compounds as ConventionPlain but uses the fixing of the rate cut-off date, Lag
+	// business days before the end of the interest period, for the cut-off date and all later days.
+	ConventionLockout = "lockout"
+)
+
+// Convention determines how fixings are compounded over an interest period. If Daily is set, interest
+// is compounded on every calendar day with the fixing of the preceding business day, as done by DIA's
+// methodology, instead of on business days only.
+type Convention struct {
+	Name  string
+	Lag   int
+	Daily bool
+}
+
+// ParseConvention returns the convention with @name and a lag of @lag business days.
+// An empty @name is ConventionPlain.
+func ParseConvention(name string, lag int) (Convention, error) {
+	if name == "" {
+		name = ConventionPlain
+	}
+	switch name {
+	case ConventionPlain, ConventionLookback, ConventionObsShift, ConventionLockout:
+	default:
+		return Convention{}, errors.New("unknown convention " + name)
+	}
+	if lag < 0 {
+		return Convention{}, errors.New("lag must not be negative")
+	}
+	return Convention{Name: name, Lag: lag}, nil
+}
+
+// AddBusinessDays returns the date @n business days after @date, or before if @n is negative.
+// For non-zero @n, a @date that is not a business day is first rolled to the following business day.
+func (cal *Calendar) AddBusinessDays(date time.Time, n int) time.Time {
+	if n == 0 {
+		return date
+	}
+	date = cal.NextBusinessDay(date)
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+	for ; n > 0; n-- {
+		date = date.AddDate(0, 0, step)
+		for !cal.IsBusinessDay(date) {
+			date = date.AddDate(0, 0, step)
+		}
+	}
+	return date
+}
+
+// CompoundedAverage returns the compounded average in percent of a rate over the interest period from
+// @dateInit to @dateFinal (excluded) following @convention. @fixing returns the fixing in percent on a
+// business day of @cal. @daysPerYear determines the total number of days per year.
+// Days of the interest period before the first business day accrue the fixing of the preceding business day.
+func CompoundedAverage(fixing func(time.Time) (float64, error), cal *Calendar, dateInit, dateFinal time.Time, daysPerYear int, convention Convention) (float64, error) {
+	if !utils.AfterDay(dateFinal, dateInit) {
+		return 0, errors.New("the final date must be after the initial date")
+	}
+	if daysPerYear <= 0 {
+		return 0, errors.New("days per year must be a positive integer")
+	}
+	if convention.Name == ConventionObsShift {
+		dateInit = cal.AddBusinessDays(dateInit, -convention.Lag)
+		dateFinal = cal.AddBusinessDays(dateFinal, -convention.Lag)
+	}
+
+	// Interest accrues in periods starting at @dateInit and at each compounding day.
+	starts := []time.Time{dateInit}
+	for day := dateInit.AddDate(0, 0, 1); utils.AfterDay(dateFinal, day); day = day.AddDate(0, 0, 1) {
+		if convention.Daily || cal.IsBusinessDay(day) {
+			starts = append(starts, day)
+		}
+	}
+	// Rate cut-off date in case of a lockout.
+	var cutoff time.Time
+	if convention.Name == ConventionLockout && convention.Lag > 0 {
+		cutoff = cal.AddBusinessDays(dateFinal, -convention.Lag)
+	}
+
+	prod := float64(1)
+	for i, start := range starts {
+		end := dateFinal
+		if i < len(starts)-1 {
+			end = starts[i+1]
+		}
+		observation := start
+		if !cutoff.IsZero() && !start.Before(cutoff) {
+			observation = cutoff
+		}
+		observation = cal.PreviousBusinessDay(observation)
+		if convention.Name == ConventionLookback {
+			observation = cal.AddBusinessDays(observation, -convention.Lag)
+		}
+		rate, err := fixing(observation)
+		if err != nil {
+			return 0, err
+		}
+		prod *= 1 + rate/100*float64(daysBetween(start, end))/float64(daysPerYear)
+	}
+	return 100 * (prod - 1) * float64(daysPerYear) / float64(daysBetween(dateInit, dateFinal)), nil
+}
+
+// daysBetween returns the number of calendar days from @dateInit to @dateFinal.
+func daysBetween(dateInit, dateFinal time.Time) int {
+	dateInit = time.Date(dateInit.Year(), dateInit.Month(), dateInit.Day(), 0, 0, 0, 0, time.UTC)
+	dateFinal = time.Date(dateFinal.Year(), dateFinal.Month(), dateFinal.Day(), 0, 0, 0, 0, time.UTC)
+	return int(math.Round(dateFinal.Sub(dateInit).Hours() / 24))
+}
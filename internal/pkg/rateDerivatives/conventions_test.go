@@ -0,0 +1,220 @@
+package ratederivatives
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// testFixing returns 0.1*month + 0.01*day percent on business days of @cal.
+func testFixing(cal *Calendar) func(time.Time) (float64, error) {
+	return func(date time.Time) (float64, error) {
+		if !cal.IsBusinessDay(date) {
+			return 0, errors.New("no fixing on " + date.Format("2006-01-02"))
+		}
+		return 0.1*float64(date.Month()) + 0.01*float64(date.Day()), nil
+	}
+}
+
+// expectedAverage compounds pairs of fixing in percent and accrual days and annualizes
+// over @days days.
+func expectedAverage(accruals [][2]float64, days int, daysPerYear int) float64 {
+	prod := float64(1)
+	for _, a := range accruals {
+		prod *= 1 + a[0]/100*a[1]/float64(daysPerYear)
+	}
+	return 100 * (prod - 1) * float64(daysPerYear) / float64(days)
+}
+
+func TestCompoundedAverage(t *testing.T) {
+	tol := 1e-12
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+	// Fixings of the test rate on the days of February and March 2021.
+	f := func(month, day int) float64 { return 0.1*float64(month) + 0.01*float64(day) }
+
+	tables := []struct {
+		cal        *Calendar
+		dateInit   time.Time
+		dateFinal  time.Time
+		convention Convention
+		expected   float64
+	}{
+		// Interest period of two weeks without holidays.
+		{USSIFMA, date("2021-03-01"), date("2021-03-15"), Convention{Name: ConventionPlain}, expectedAverage([][2]float64{
+			{f(3, 1), 1}, {f(3, 2), 1}, {f(3, 3), 1}, {f(3, 4), 1}, {f(3, 5), 3},
+			{f(3, 8), 1}, {f(3, 9), 1}, {f(3, 10), 1}, {f(3, 11), 1}, {f(3, 12), 3}}, 14, 360)},
+		// Lookback: fixings two business days earlier, weights of the interest period.
+		{USSIFMA, date("2021-03-01"), date("2021-03-15"), Convention{Name: ConventionLookback, Lag: 2}, expectedAverage([][2]float64{
+			{f(2, 25), 1}, {f(2, 26), 1}, {f(3, 1), 1}, {f(3, 2), 1}, {f(3, 3), 3},
+			{f(3, 4), 1}, {f(3, 5), 1}, {f(3, 8), 1}, {f(3, 9), 1}, {f(3, 10), 3}}, 14, 360)},
+		// Observation shift: fixings and weights of the period shifted back by two business days.
+		{USSIFMA, date("2021-03-01"), date("2021-03-15"), Convention{Name: ConventionObsShift, Lag: 2}, expectedAverage([][2]float64{
+			{f(2, 25), 1}, {f(2, 26), 3}, {f(3, 1), 1}, {f(3, 2), 1}, {f(3, 3), 1},
+			{f(3, 4), 1}, {f(3, 5), 3}, {f(3, 8), 1}, {f(3, 9), 1}, {f(3, 10), 1}}, 14, 360)},
+		// Lockout: the fixing of the cut-off date two business days before the end applies from then on.
+		{USSIFMA, date("2021-03-01"), date("2021-03-15"), Convention{Name: ConventionLockout, Lag: 2}, expectedAverage([][2]float64{
+			{f(3, 1), 1}, {f(3, 2), 1}, {f(3, 3), 1}, {f(3, 4), 1}, {f(3, 5), 3},
+			{f(3, 8), 1}, {f(3, 9), 1}, {f(3, 10), 1}, {f(3, 11), 1}, {f(3, 11), 3}}, 14, 360)},
+		// Zero lags do not change the plain convention.
+		{USSIFMA, date("2021-03-01"), date("2021-03-15"), Convention{Name: ConventionObsShift}, expectedAverage([][2]float64{
+			{f(3, 1), 1}, {f(3, 2), 1}, {f(3, 3), 1}, {f(3, 4), 1}, {f(3, 5), 3},
+			{f(3, 8), 1}, {f(3, 9), 1}, {f(3, 10), 1}, {f(3, 11), 1}, {f(3, 12), 3}}, 14, 360)},
+		// Period starting on a Saturday accrues the preceding fixing until Monday.
+		{USSIFMA, date("2021-03-06"), date("2021-03-10"), Convention{Name: ConventionPlain}, expectedAverage([][2]float64{
+			{f(3, 5), 2}, {f(3, 8), 1}, {f(3, 9), 1}}, 4, 360)},
+		// Easter holidays of TARGET2.
+		{TARGET2, date("2021-04-01"), date("2021-04-07"), Convention{Name: ConventionPlain}, expectedAverage([][2]float64{
+			{f(4, 1), 5}, {f(4, 6), 1}}, 6, 360)},
+		{TARGET2, date("2021-04-01"), date("2021-04-07"), Convention{Name: ConventionPlain, Daily: true}, expectedAverage([][2]float64{
+			{f(4, 1), 1}, {f(4, 1), 1}, {f(4, 1), 1}, {f(4, 1), 1}, {f(4, 1), 1}, {f(4, 6), 1}}, 6, 360)},
+		{TARGET2, date("2021-04-06"), date("2021-04-09"), Convention{Name: ConventionLookback, Lag: 1}, expectedAverage([][2]float64{
+			{f(4, 1), 1}, {f(4, 6), 1}, {f(4, 7), 1}}, 3, 360)},
+	}
+	for i, table := range tables {
+		value, err := CompoundedAverage(testFixing(table.cal), table.cal, table.dateInit, table.dateFinal, 360, table.convention)
+		if err != nil {
+			t.Errorf("%d: unexpected error %v", i, err)
+			continue
+		}
+		if math.Abs(value-table.expected) > tol {
+			t.Errorf("%d: compounded average is %v but should be %v", i, value, table.expected)
+		}
+	}
+}
+
+// TestCompoundedAverageIndex checks the plain and observation shift conventions against the ratio
+// of a compounded index as published by the New York Fed for SOFR. The SOFR Index compounds each
+// fixing over the calendar days until the next business day, such that the ratio of two index values
+// yields the average over the observation period, as ARRC recommends for the observation shift.
+func TestCompoundedAverageIndex(t *testing.T) {
+	tol := 1e-12
+	cal := USSIFMA
+	fixing := testFixing(cal)
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+
+	index := map[time.Time]float64{}
+	value := float64(1)
+	for _, day := range cal.BusinessDays(date("2021-05-03"), date("2021-07-30")) {
+		index[day] = value
+		rate, err := fixing(day)
+		if err != nil {
+			t.Fatal(err)
+		}
+		value *= 1 + rate/100*float64(daysBetween(day, cal.NextBusinessDay(day.AddDate(0, 0, 1))))/360
+	}
+	indexAverage := func(dateInit, dateFinal time.Time) float64 {
+		return 100 * (index[dateFinal]/index[dateInit] - 1) * 360 / float64(daysBetween(dateInit, dateFinal))
+	}
+
+	// Interest periods containing Memorial Day and Independence Day.
+	periods := [][2]time.Time{
+		{date("2021-05-17"), date("2021-06-16")},
+		{date("2021-06-21"), date("2021-07-21")},
+	}
+	for _, period := range periods {
+		value, err := CompoundedAverage(fixing, cal, period[0], period[1], 360, Convention{Name: ConventionPlain})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := indexAverage(period[0], period[1]); math.Abs(value-expected) > tol {
+			t.Errorf("plain average from %s is %v but the index yields %v", period[0].Format("2006-01-02"), value, expected)
+		}
+
+		for _, lag := range []int{2, 5} {
+			value, err := CompoundedAverage(fixing, cal, period[0], period[1], 360, Convention{Name: ConventionObsShift, Lag: lag})
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := indexAverage(cal.AddBusinessDays(period[0], -lag), cal.AddBusinessDays(period[1], -lag))
+			if math.Abs(value-expected) > tol {
+				t.Errorf("shifted average from %s with lag %d is %v but the index yields %v", period[0].Format("2006-01-02"), lag, value, expected)
+			}
+		}
+	}
+}
+
+// TestCompoundedAverageARRC works through the conventions of the ARRC for SOFR in arrears with its
+// recommended lag of five business days, listing the fixing date and accrued calendar days of each
+// business day as in the ARRC's worked examples. The interest period from 2020-11-16 to 2020-12-01
+// contains Thanksgiving, and the shifted observation period contains Veterans Day, when SIFMA
+// recommends a full close. The fixings are the synthetic ones of testFixing.
+func TestCompoundedAverageARRC(t *testing.T) {
+	tol := 1e-12
+	cal := USSIFMA
+	fixing := testFixing(cal)
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+	type accrual struct {
+		fixing string
+		days   int
+	}
+	dateInit, dateFinal := date("2020-11-16"), date("2020-12-01")
+
+	tables := []struct {
+		convention Convention
+		schedule   []accrual
+		days       int
+	}{
+		{Convention{Name: ConventionPlain}, []accrual{
+			{"2020-11-16", 1}, {"2020-11-17", 1}, {"2020-11-18", 1}, {"2020-11-19", 1}, {"2020-11-20", 3},
+			{"2020-11-23", 1}, {"2020-11-24", 1}, {"2020-11-25", 2}, {"2020-11-27", 3}, {"2020-11-30", 1}}, 15},
+		// each day of the interest period accrues the fixing five business days earlier
+		{Convention{Name: ConventionLookback, Lag: 5}, []accrual{
+			{"2020-11-06", 1}, {"2020-11-09", 1}, {"2020-11-10", 1}, {"2020-11-12", 1}, {"2020-11-13", 3},
+			{"2020-11-16", 1}, {"2020-11-17", 1}, {"2020-11-18", 2}, {"2020-11-19", 3}, {"2020-11-20", 1}}, 15},
+		// the observation period from 2020-11-06 to 2020-11-23 is weighted and annualized by its own days
+		{Convention{Name: ConventionObsShift, Lag: 5}, []accrual{
+			{"2020-11-06", 3}, {"2020-11-09", 1}, {"2020-11-10", 2}, {"2020-11-12", 1}, {"2020-11-13", 3},
+			{"2020-11-16", 1}, {"2020-11-17", 1}, {"2020-11-18", 1}, {"2020-11-19", 1}, {"2020-11-20", 3}}, 17},
+		// the fixing of the rate cut-off date 2020-11-23 applies until the end of the interest period
+		{Convention{Name: ConventionLockout, Lag: 5}, []accrual{
+			{"2020-11-16", 1}, {"2020-11-17", 1}, {"2020-11-18", 1}, {"2020-11-19", 1}, {"2020-11-20", 3},
+			{"2020-11-23", 1}, {"2020-11-23", 1}, {"2020-11-23", 2}, {"2020-11-23", 3}, {"2020-11-23", 1}}, 15},
+	}
+	for _, table := range tables {
+		var accruals [][2]float64
+		days := 0
+		for _, a := range table.schedule {
+			rate, err := fixing(date(a.fixing))
+			if err != nil {
+				t.Fatal(err)
+			}
+			accruals = append(accruals, [2]float64{rate, float64(a.days)})
+			days += a.days
+		}
+		if days != table.days {
+			t.Fatalf("%s: schedule of %d days, want %d", table.convention.Name, days, table.days)
+		}
+		value, err := CompoundedAverage(fixing, cal, dateInit, dateFinal, 360, table.convention)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := expectedAverage(accruals, table.days, 360); math.Abs(value-expected) > tol {
+			t.Errorf("%s: compounded average is %v but should be %v", table.convention.Name, value, expected)
+		}
+	}
+}
+
+func TestParseConvention(t *testing.T) {
+	if c, err := ParseConvention("", 0); err != nil || c.Name != ConventionPlain {
+		t.Errorf("unexpected convention %v, %v", c, err)
+	}
+	if _, err := ParseConvention("obsShift", 5); err != nil {
+		t.Error(err)
+	}
+	if _, err := ParseConvention("shift", 5); err == nil {
+		t.Error("expected error for unknown convention")
+	}
+	if _, err := ParseConvention("lookback", -1); err == nil {
+		t.Error("expected error for negative lag")
+	}
+}
@@ -0,0 +1,111 @@
+package ratederivatives
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+// Tenor is a standard maturity of a term structure, given in calendar months and days
+// from the curve date.
+type Tenor struct {
+	Name   string
+	Months int
+	Days   int
+}
+
+// StandardTenors are the tenors of the curves returned by BuildTermStructure.
+var StandardTenors = []Tenor{
+	{Name: "1D", Days: 1},
+	{Name: "1W", Days: 7},
+	{Name: "1M", Months: 1},
+	{Name: "2M", Months: 2},
+	{Name: "3M", Months: 3},
+	{Name: "6M", Months: 6},
+	{Name: "9M", Months: 9},
+	{Name: "1Y", Months: 12},
+}
+
+// Maturity returns the maturity of the tenor for a curve at @date.
+func (t Tenor) Maturity(date time.Time) time.Time {
+	return date.AddDate(0, t.Months, t.Days)
+}
+
+// BuildTermStructure bootstraps discount factors from @pillars with @daysPerYear days per year and
+// evaluates the curve at @tenors. Discount factors are interpolated log-linearly between pillars,
+// i.e. with piecewise flat instantaneous forward rates, and the last forward rate is extended
+// beyond the longest pillar.
+func BuildTermStructure(symbol string, date time.Time, pillars []dia.Pillar, daysPerYear int, tenors []Tenor) (*dia.TermStructure, error) {
+	if len(pillars) == 0 {
+		return nil, errors.New("no pillars for term structure")
+	}
+	if daysPerYear <= 0 {
+		return nil, errors.New("days per year must be a positive integer")
+	}
+	sorted := make([]dia.Pillar, len(pillars))
+	copy(sorted, pillars)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Days < sorted[j].Days })
+
+	// Bootstrap log discount factors. Each pillar is a single period instrument, hence its
+	// discount factor follows directly from its rate.
+	days := []float64{0}
+	logDF := []float64{0}
+	for _, p := range sorted {
+		if p.Days <= 0 || float64(p.Days) == days[len(days)-1] {
+			return nil, errors.New("pillars must have distinct positive terms")
+		}
+		df := 1 / (1 + p.Rate/100*float64(p.Days)/float64(daysPerYear))
+		if df <= 0 {
+			return nil, errors.New("pillar rate implies non-positive discount factor")
+		}
+		days = append(days, float64(p.Days))
+		logDF = append(logDF, math.Log(df))
+	}
+
+	ts := &dia.TermStructure{
+		Symbol:      symbol,
+		Date:        date,
+		DaysPerYear: daysPerYear,
+		Pillars:     sorted,
+	}
+	previousDays := 0
+	previousDF := 1.0
+	for _, tenor := range tenors {
+		maturity := tenor.Maturity(date)
+		t := int(math.Round(maturity.Sub(date).Hours() / 24))
+		if t <= 0 {
+			continue
+		}
+		df := math.Exp(interpolateLogDF(days, logDF, float64(t)))
+		ts.Points = append(ts.Points, dia.CurvePoint{
+			Tenor:          tenor.Name,
+			Maturity:       maturity,
+			Days:           t,
+			DiscountFactor: df,
+			ZeroRate:       -100 * math.Log(df) * 365 / float64(t),
+			ForwardRate:    100 * (previousDF/df - 1) * float64(daysPerYear) / float64(t-previousDays),
+			Extrapolated:   float64(t) > days[len(days)-1],
+		})
+		previousDays = t
+		previousDF = df
+	}
+	return ts, nil
+}
+
+// interpolateLogDF returns the log discount factor at @t, linearly interpolated in the nodes
+// (@days, @logDF) and extrapolated with the slope of the last segment.
+func interpolateLogDF(days []float64, logDF []float64, t float64) float64 {
+	n := len(days)
+	i := sort.SearchFloat64s(days, t)
+	if i < n && days[i] == t {
+		return logDF[i]
+	}
+	if i >= n {
+		i = n - 1
+	}
+	slope := (logDF[i] - logDF[i-1]) / (days[i] - days[i-1])
+	return logDF[i-1] + slope*(t-days[i-1])
+}
@@ -0,0 +1,95 @@
+package ratederivatives
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+func TestBuildTermStructure(t *testing.T) {
+	tol := 1e-10
+	date, _ := time.Parse("2006-01-02", "2021-03-01")
+	pillars := []dia.Pillar{
+		{Days: 180, Rate: 2.3},
+		{Days: 1, Rate: 2},
+		{Days: 90, Rate: 2.2},
+		{Days: 30, Rate: 2.1},
+	}
+	ts, err := BuildTermStructure("SOFR", date, pillars, 360, StandardTenors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ts.Points) != len(StandardTenors) {
+		t.Fatalf("expected %d points, got %d", len(StandardTenors), len(ts.Points))
+	}
+	if ts.Pillars[0].Days != 1 || ts.Pillars[3].Days != 180 {
+		t.Errorf("pillars are not sorted: %v", ts.Pillars)
+	}
+
+	df := func(rate float64, days int) float64 { return 1 / (1 + rate/100*float64(days)/360) }
+	logDF1, logDF30 := math.Log(df(2, 1)), math.Log(df(2.1, 30))
+	expectedDays := []int{1, 7, 31, 61, 92, 184, 275, 365}
+	for i, p := range ts.Points {
+		if p.Days != expectedDays[i] {
+			t.Errorf("%s: days are %d but should be %d", p.Tenor, p.Days, expectedDays[i])
+		}
+		if p.Extrapolated != (p.Days > 180) {
+			t.Errorf("%s: extrapolated is %v", p.Tenor, p.Extrapolated)
+		}
+		if math.Abs(p.ZeroRate+100*math.Log(p.DiscountFactor)*365/float64(p.Days)) > tol {
+			t.Errorf("%s: zero rate %v inconsistent with discount factor %v", p.Tenor, p.ZeroRate, p.DiscountFactor)
+		}
+	}
+	if math.Abs(ts.Points[0].DiscountFactor-df(2, 1)) > tol {
+		t.Errorf("1D discount factor is %v but should be %v", ts.Points[0].DiscountFactor, df(2, 1))
+	}
+	if expected := math.Exp(logDF1 + (logDF30-logDF1)*6/29); math.Abs(ts.Points[1].DiscountFactor-expected) > tol {
+		t.Errorf("1W discount factor is %v but should be %v", ts.Points[1].DiscountFactor, expected)
+	}
+	if math.Abs(ts.Points[0].ForwardRate-2) > tol {
+		t.Errorf("1D forward rate is %v but should be 2", ts.Points[0].ForwardRate)
+	}
+
+	// Compounding the period forward rates recovers the discount factor of the last tenor.
+	growth, previousDays := 1.0, 0
+	for _, p := range ts.Points {
+		growth *= 1 + p.ForwardRate/100*float64(p.Days-previousDays)/360
+		previousDays = p.Days
+	}
+	if last := ts.Points[len(ts.Points)-1]; math.Abs(growth*last.DiscountFactor-1) > tol {
+		t.Errorf("compounded forward rates %v inconsistent with discount factor %v", growth, last.DiscountFactor)
+	}
+}
+
+func TestBuildTermStructureFlat(t *testing.T) {
+	tol := 1e-10
+	date, _ := time.Parse("2006-01-02", "2021-03-01")
+	ts, err := BuildTermStructure("SONIA", date, []dia.Pillar{{Days: 1, Rate: 0.05}}, 365, StandardTenors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A single overnight pillar is extended with a flat instantaneous forward rate.
+	zeroRate := 100 * math.Log(1+0.05/100/365) * 365
+	for _, p := range ts.Points {
+		if math.Abs(p.ZeroRate-zeroRate) > tol {
+			t.Errorf("%s: zero rate is %v but should be %v", p.Tenor, p.ZeroRate, zeroRate)
+		}
+	}
+}
+
+func TestBuildTermStructureErrors(t *testing.T) {
+	date, _ := time.Parse("2006-01-02", "2021-03-01")
+	tables := [][]dia.Pillar{
+		{},
+		{{Days: 30, Rate: 1}, {Days: 30, Rate: 1.1}},
+		{{Days: 0, Rate: 1}},
+		{{Days: 30, Rate: -1300}},
+	}
+	for _, pillars := range tables {
+		if _, err := BuildTermStructure("SOFR", date, pillars, 360, StandardTenors); err == nil {
+			t.Errorf("expected error for pillars %v", pillars)
+		}
+	}
+}
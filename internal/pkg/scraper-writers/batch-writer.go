@@ -0,0 +1,213 @@
+package writers
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultMaxBytes = 64 << 20
+	defaultMaxAge   = time.Hour
+	// files of up to defaultPendingFiles times MaxBytes are kept while the storage is unavailable
+	defaultPendingFiles = 4
+)
+
+// BatchWriterConfig configures a BatchWriter.
+type BatchWriterConfig struct {
+	Format  Format
+	Storage Storage
+	// Prefix is prepended to the keys of all files, such as futures/trades.
+	Prefix string
+	// MaxBytes is the size of the encoded records, before compression, at which a file is written.
+	// Defaults to 64 MiB.
+	MaxBytes int
+	// MaxRecords is the number of records at which a file is written. Zero means no limit.
+	MaxRecords int
+	// MaxAge is the time after the first record of a file at which it is written. Defaults to an hour.
+	MaxAge time.Duration
+	// MaxPendingBytes is the size of the files which are kept for a retry while the storage is
+	// unavailable. Beyond it, the oldest files are dropped. Defaults to 4 times MaxBytes.
+	MaxPendingBytes int
+}
+
+// BatchWriter is a RecordWriter that encodes records with a Format and puts the files into a Storage.
+// Files are named Prefix/yyyy-mm-dd/yyyymmddThhmmssZ-sequence.extension after the time of their first
+// record. Files that could not be stored are kept and retried on the next flush, up to MaxPendingBytes.
+// If a file cannot be encoded, its records are kept and encoding is retried on the next flush. Records
+// are rejected as long as such a file is full.
+type BatchWriter struct {
+	config BatchWriterConfig
+	now    func() time.Time
+
+	mu      sync.Mutex
+	encoder Encoder
+	started time.Time
+	seq     int
+	pending []pendingFile
+	// pendingBytes is the size of the pending files
+	pendingBytes int
+	closed       bool
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+type pendingFile struct {
+	key  string
+	data []byte
+}
+
+// NewBatchWriter returns a BatchWriter with @config and starts its age based rotation.
+func NewBatchWriter(config BatchWriterConfig) (*BatchWriter, error) {
+	if config.Format == nil || config.Storage == nil {
+		return nil, errors.New("format and storage are required")
+	}
+	if config.MaxBytes <= 0 {
+		config.MaxBytes = defaultMaxBytes
+	}
+	if config.MaxAge <= 0 {
+		config.MaxAge = defaultMaxAge
+	}
+	if config.MaxPendingBytes <= 0 {
+		config.MaxPendingBytes = defaultPendingFiles * config.MaxBytes
+	}
+	w := &BatchWriter{
+		config: config,
+		now:    time.Now,
+		done:   make(chan struct{}),
+	}
+	w.wg.Add(1)
+	go w.rotate()
+	return w, nil
+}
+
+// rotate flushes files once they reach the maximum age.
+func (w *BatchWriter) rotate() {
+	defer w.wg.Done()
+	interval := w.config.MaxAge / 10
+	if interval < time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			w.mu.Lock()
+			if w.encoder != nil && w.now().Sub(w.started) >= w.config.MaxAge {
+				err := w.flush()
+				if err != nil {
+					log.Errorln("BatchWriter: flush", w.config.Prefix, ":", err)
+				}
+			}
+			w.mu.Unlock()
+		}
+	}
+}
+
+// Write adds @record to the current file, and writes the file if it reached its maximum size.
+func (w *BatchWriter) Write(record interface{}) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return errors.New("writer is closed")
+	}
+	if w.full() {
+		// the current file could not be encoded before
+		err := w.flush()
+		if w.full() {
+			return err
+		}
+	}
+	if w.encoder == nil {
+		w.encoder = w.config.Format.NewEncoder()
+		w.started = w.now()
+	}
+	err := w.encoder.Append(record)
+	if err != nil {
+		return err
+	}
+	if w.full() {
+		return w.flush()
+	}
+	return nil
+}
+
+// full returns true if the current file reached its maximum size. It must be called with w.mu held.
+func (w *BatchWriter) full() bool {
+	if w.encoder == nil {
+		return false
+	}
+	return w.encoder.Size() >= w.config.MaxBytes || (w.config.MaxRecords > 0 && w.encoder.Len() >= w.config.MaxRecords)
+}
+
+// Flush writes the current file and retries files that could not be stored before.
+func (w *BatchWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.flush()
+}
+
+// Close stops the rotation and flushes the writer.
+func (w *BatchWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.done)
+	w.mu.Unlock()
+	w.wg.Wait()
+	return w.Flush()
+}
+
+// flush must be called with w.mu held.
+func (w *BatchWriter) flush() error {
+	if w.encoder != nil && w.encoder.Len() > 0 {
+		data, err := w.encoder.Finish()
+		if err != nil {
+			// the records are kept for the next flush
+			return err
+		}
+		w.pending = append(w.pending, pendingFile{key: w.key(), data: data})
+		w.pendingBytes += len(data)
+		w.seq++
+	}
+	w.encoder = nil
+
+	var firstErr error
+	remaining := w.pending[:0]
+	for _, file := range w.pending {
+		err := w.config.Storage.Put(file.key, file.data)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("put %s: %v", file.key, err)
+			}
+			remaining = append(remaining, file)
+			continue
+		}
+		w.pendingBytes -= len(file.data)
+	}
+	w.pending = remaining
+	for w.pendingBytes > w.config.MaxPendingBytes {
+		log.Errorln("BatchWriter: storage unavailable, dropping", w.pending[0].key)
+		w.pendingBytes -= len(w.pending[0].data)
+		w.pending = w.pending[1:]
+	}
+	return firstErr
+}
+
+// key returns the key of the current file.
+func (w *BatchWriter) key() string {
+	started := w.started.UTC()
+	name := fmt.Sprintf("%s-%06d%s", started.Format("20060102T150405Z"), w.seq, w.config.Format.Extension())
+	return path.Join(w.config.Prefix, started.Format("2006-01-02"), name)
+}
@@ -0,0 +1,155 @@
+package writers
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memoryStorage struct {
+	mu    sync.Mutex
+	files map[string][]byte
+	fail  bool
+}
+
+func (s *memoryStorage) Put(key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail {
+		return errors.New("unavailable")
+	}
+	s.files[key] = data
+	return nil
+}
+
+func (s *memoryStorage) keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := []string{}
+	for key := range s.files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type batchTestRecord struct {
+	Price float64 `json:"price"`
+}
+
+func TestBatchWriter(t *testing.T) {
+	storage := &memoryStorage{files: map[string][]byte{}}
+	w, err := NewBatchWriter(BatchWriterConfig{
+		Format:     NDJSON{},
+		Storage:    storage,
+		Prefix:     "futures/trades",
+		MaxRecords: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.now = func() time.Time { return time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC) }
+
+	for i := 0; i < 3; i++ {
+		err = w.Write(batchTestRecord{Price: float64(i)})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if keys := storage.keys(); len(keys) != 1 || keys[0] != "futures/trades/2021-03-04/20210304T050607Z-000000.ndjson" {
+		t.Fatalf("files %v", keys)
+	}
+	if got := string(storage.files[storage.keys()[0]]); got != "{\"price\":0}\n{\"price\":1}\n" {
+		t.Errorf("content %q", got)
+	}
+
+	// failed files are retried on the next flush
+	storage.fail = true
+	if err := w.Flush(); err == nil {
+		t.Error("expected an error")
+	}
+	storage.fail = false
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if keys := storage.keys(); len(keys) != 2 || keys[1] != "futures/trades/2021-03-04/20210304T050607Z-000001.ndjson" {
+		t.Fatalf("files %v", keys)
+	}
+	if err := w.Write(batchTestRecord{}); err == nil {
+		t.Error("expected an error on a closed writer")
+	}
+}
+
+// failingFormat is NDJSON whose files cannot be encoded while fail is set.
+type failingFormat struct {
+	NDJSON
+	fail *bool
+}
+
+func (f failingFormat) NewEncoder() Encoder {
+	return failingEncoder{Encoder: f.NDJSON.NewEncoder(), fail: f.fail}
+}
+
+type failingEncoder struct {
+	Encoder
+	fail *bool
+}
+
+func (e failingEncoder) Finish() ([]byte, error) {
+	if *e.fail {
+		return nil, errors.New("cannot encode")
+	}
+	return e.Encoder.Finish()
+}
+
+func TestBatchWriterFailures(t *testing.T) {
+	storage := &memoryStorage{files: map[string][]byte{}}
+	fail := true
+	w, err := NewBatchWriter(BatchWriterConfig{
+		Format:          failingFormat{fail: &fail},
+		Storage:         storage,
+		MaxRecords:      2,
+		MaxPendingBytes: 25,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	// the records of a file which cannot be encoded are kept, and further records rejected
+	for i := 0; i < 2; i++ {
+		w.Write(batchTestRecord{Price: float64(i)})
+	}
+	if err := w.Write(batchTestRecord{Price: 2}); err == nil {
+		t.Error("expected an error for a full file")
+	}
+	fail = false
+	if err := w.Write(batchTestRecord{Price: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if keys := storage.keys(); len(keys) != 1 || string(storage.files[keys[0]]) != "{\"price\":0}\n{\"price\":1}\n" {
+		t.Fatalf("files %v", storage.files)
+	}
+
+	// files which cannot be stored are kept up to MaxPendingBytes, dropping the oldest
+	storage.fail = true
+	for i := 4; i < 10; i++ {
+		w.Write(batchTestRecord{Price: float64(i)})
+	}
+	if len(w.pending) != 1 || w.pendingBytes != 24 {
+		t.Fatalf("%d pending files of %d bytes", len(w.pending), w.pendingBytes)
+	}
+	storage.fail = false
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	keys := storage.keys()
+	if len(keys) != 3 || string(storage.files[keys[1]]) != "{\"price\":7}\n{\"price\":8}\n" || string(storage.files[keys[2]]) != "{\"price\":9}\n" {
+		t.Fatalf("files %v", storage.files)
+	}
+	if w.pendingBytes != 0 {
+		t.Errorf("%d pending bytes", w.pendingBytes)
+	}
+}
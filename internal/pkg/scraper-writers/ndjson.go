@@ -0,0 +1,87 @@
+package writers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+)
+
+// Compression is the compression applied by a Format.
+type Compression int
+
+const (
+	// Uncompressed files are written as they are encoded.
+	Uncompressed Compression = iota
+	// Gzip compresses files (NDJSON) or pages (Parquet) with gzip.
+	Gzip
+)
+
+// gzipBytes returns @data compressed with gzip.
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write(data)
+	if err != nil {
+		return nil, err
+	}
+	err = zw.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// NDJSON writes one JSON object per line.
+type NDJSON struct {
+	Compression Compression
+}
+
+// Extension returns .ndjson, or .ndjson.gz for compressed files.
+func (f NDJSON) Extension() string {
+	if f.Compression == Gzip {
+		return ".ndjson.gz"
+	}
+	return ".ndjson"
+}
+
+// NewEncoder returns an encoder for a new file.
+func (f NDJSON) NewEncoder() Encoder {
+	return &ndjsonEncoder{compression: f.Compression}
+}
+
+type ndjsonEncoder struct {
+	compression Compression
+	buf         bytes.Buffer
+	n           int
+}
+
+func (e *ndjsonEncoder) Append(record interface{}) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	e.buf.Write(line)
+	e.buf.WriteByte('\n')
+	e.n++
+	return nil
+}
+
+func (e *ndjsonEncoder) Len() int {
+	return e.n
+}
+
+func (e *ndjsonEncoder) Size() int {
+	return e.buf.Len()
+}
+
+func (e *ndjsonEncoder) Finish() ([]byte, error) {
+	switch e.compression {
+	case Uncompressed:
+		return e.buf.Bytes(), nil
+	case Gzip:
+		return gzipBytes(e.buf.Bytes())
+	default:
+		return nil, fmt.Errorf("unsupported compression %d", e.compression)
+	}
+}
@@ -0,0 +1,344 @@
+package writers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Parquet writes records into columnar Apache Parquet files with a single row group. Records
+// must be structs, or pointers to structs, of the same type. Each exported field is a required
+// column named after its json tag, or the field name otherwise:
+// bools are BOOLEAN, integers INT64, annotated as UINT_64 if unsigned, floats DOUBLE, strings UTF8
+// and time.Time TIMESTAMP_MICROS.
+// Fields of other types are stored as JSON strings.
+type Parquet struct {
+	Compression Compression
+}
+
+// Physical types, converted types, encodings and codecs of the parquet format.
+const (
+	parquetBoolean   = 0
+	parquetInt64     = 2
+	parquetDouble    = 5
+	parquetByteArray = 6
+
+	parquetUTF8            = 0
+	parquetTimestampMicros = 10
+	parquetUint64          = 14
+
+	parquetPlain = 0
+	parquetRLE   = 3
+
+	parquetUncompressed = 0
+	parquetGzip         = 2
+
+	parquetRequired = 0
+	parquetDataPage = 0
+)
+
+const parquetMagic = "PAR1"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Extension returns .parquet.
+func (f Parquet) Extension() string {
+	return ".parquet"
+}
+
+// NewEncoder returns an encoder for a new file.
+func (f Parquet) NewEncoder() Encoder {
+	return &parquetEncoder{compression: f.Compression}
+}
+
+type parquetColumn struct {
+	name          string
+	field         int
+	physicalType  int32
+	convertedType int32 // -1 if none
+	asJSON        bool
+	values        bytes.Buffer
+	bools         []bool
+}
+
+type parquetEncoder struct {
+	compression Compression
+	recordType  reflect.Type
+	columns     []*parquetColumn
+	n           int
+	size        int
+}
+
+// schema derives the columns from the struct type @t.
+func (e *parquetEncoder) schema(t reflect.Type) error {
+	if t.Kind() != reflect.Struct {
+		return fmt.Errorf("parquet records must be structs, got %s", t)
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := field.Name
+		if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+		column := &parquetColumn{name: name, field: i, convertedType: -1}
+		switch field.Type.Kind() {
+		case reflect.Bool:
+			column.physicalType = parquetBoolean
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			column.physicalType = parquetInt64
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			// values above math.MaxInt64 would read as negative without the annotation
+			column.physicalType = parquetInt64
+			column.convertedType = parquetUint64
+		case reflect.Float32, reflect.Float64:
+			column.physicalType = parquetDouble
+		case reflect.String:
+			column.physicalType = parquetByteArray
+			column.convertedType = parquetUTF8
+		default:
+			if field.Type == timeType {
+				column.physicalType = parquetInt64
+				column.convertedType = parquetTimestampMicros
+			} else {
+				column.physicalType = parquetByteArray
+				column.convertedType = parquetUTF8
+				column.asJSON = true
+			}
+		}
+		e.columns = append(e.columns, column)
+	}
+	if len(e.columns) == 0 {
+		return fmt.Errorf("%s has no exported fields", t)
+	}
+	e.recordType = t
+	return nil
+}
+
+func (e *parquetEncoder) Append(record interface{}) error {
+	v := reflect.ValueOf(record)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return errors.New("nil record")
+		}
+		v = v.Elem()
+	}
+	if e.recordType == nil {
+		err := e.schema(v.Type())
+		if err != nil {
+			return err
+		}
+	} else if v.Type() != e.recordType {
+		return fmt.Errorf("record of type %s in file of %s", v.Type(), e.recordType)
+	}
+
+	// Encode all fields before appending any, so that a failing record leaves no partial row.
+	values := make([][]byte, len(e.columns))
+	for i, column := range e.columns {
+		field := v.Field(column.field)
+		var buf [8]byte
+		switch {
+		case column.physicalType == parquetBoolean:
+			continue
+		case column.convertedType == parquetTimestampMicros:
+			t := field.Interface().(time.Time)
+			binary.LittleEndian.PutUint64(buf[:], uint64(t.UnixNano()/1e3))
+			values[i] = buf[:]
+		case column.convertedType == parquetUint64:
+			binary.LittleEndian.PutUint64(buf[:], field.Uint())
+			values[i] = buf[:]
+		case column.physicalType == parquetInt64:
+			binary.LittleEndian.PutUint64(buf[:], uint64(field.Int()))
+			values[i] = buf[:]
+		case column.physicalType == parquetDouble:
+			binary.LittleEndian.PutUint64(buf[:], math.Float64bits(field.Float()))
+			values[i] = buf[:]
+		default:
+			var data []byte
+			if column.asJSON {
+				var err error
+				data, err = json.Marshal(field.Interface())
+				if err != nil {
+					return err
+				}
+			} else {
+				data = []byte(field.String())
+			}
+			binary.LittleEndian.PutUint32(buf[:4], uint32(len(data)))
+			values[i] = append(buf[:4:4], data...)
+		}
+	}
+	for i, column := range e.columns {
+		if column.physicalType == parquetBoolean {
+			column.bools = append(column.bools, v.Field(column.field).Bool())
+			continue
+		}
+		column.values.Write(values[i])
+		e.size += len(values[i])
+	}
+	e.n++
+	return nil
+}
+
+func (e *parquetEncoder) Len() int {
+	return e.n
+}
+
+func (e *parquetEncoder) Size() int {
+	return e.size + e.n*countBools(e.columns)/8
+}
+
+func countBools(columns []*parquetColumn) int {
+	n := 0
+	for _, column := range columns {
+		if column.physicalType == parquetBoolean {
+			n++
+		}
+	}
+	return n
+}
+
+// columnChunk describes a column written to the file.
+type columnChunk struct {
+	offset           int64
+	uncompressedSize int64
+	compressedSize   int64
+}
+
+func (e *parquetEncoder) Finish() ([]byte, error) {
+	if e.n == 0 {
+		return nil, errors.New("no records")
+	}
+	codec := int32(parquetUncompressed)
+	switch e.compression {
+	case Uncompressed:
+	case Gzip:
+		codec = parquetGzip
+	default:
+		return nil, fmt.Errorf("unsupported compression %d", e.compression)
+	}
+
+	var file bytes.Buffer
+	file.WriteString(parquetMagic)
+	chunks := make([]columnChunk, len(e.columns))
+	for i, column := range e.columns {
+		data := column.values.Bytes()
+		if column.physicalType == parquetBoolean {
+			// booleans are bit packed, least significant bit first
+			data = make([]byte, (len(column.bools)+7)/8)
+			for j, b := range column.bools {
+				if b {
+					data[j/8] |= 1 << uint(j%8)
+				}
+			}
+		}
+		page := data
+		if codec == parquetGzip {
+			var err error
+			page, err = gzipBytes(data)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		header := thriftWriter{}
+		header.BeginStruct()
+		header.I32Field(1, parquetDataPage)
+		header.I32Field(2, int32(len(data)))
+		header.I32Field(3, int32(len(page)))
+		header.StructField(5)
+		header.BeginStruct()
+		header.I32Field(1, int32(e.n))
+		header.I32Field(2, parquetPlain)
+		header.I32Field(3, parquetRLE)
+		header.I32Field(4, parquetRLE)
+		header.EndStruct()
+		header.EndStruct()
+
+		chunks[i] = columnChunk{
+			offset:           int64(file.Len()),
+			uncompressedSize: int64(len(header.Bytes()) + len(data)),
+			compressedSize:   int64(len(header.Bytes()) + len(page)),
+		}
+		file.Write(header.Bytes())
+		file.Write(page)
+	}
+
+	footer := e.fileMetaData(chunks, codec)
+	file.Write(footer)
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(footer)))
+	file.Write(length[:])
+	file.WriteString(parquetMagic)
+	return file.Bytes(), nil
+}
+
+// fileMetaData encodes the footer of a file with the column @chunks.
+func (e *parquetEncoder) fileMetaData(chunks []columnChunk, codec int32) []byte {
+	meta := thriftWriter{}
+	meta.BeginStruct()
+	meta.I32Field(1, 1)
+
+	// schema, a root element followed by the columns
+	meta.ListField(2, thriftStruct, len(e.columns)+1)
+	meta.BeginStruct()
+	meta.StringField(4, "schema")
+	meta.I32Field(5, int32(len(e.columns)))
+	meta.EndStruct()
+	for _, column := range e.columns {
+		meta.BeginStruct()
+		meta.I32Field(1, column.physicalType)
+		meta.I32Field(3, parquetRequired)
+		meta.StringField(4, column.name)
+		if column.convertedType >= 0 {
+			meta.I32Field(6, column.convertedType)
+		}
+		meta.EndStruct()
+	}
+
+	meta.I64Field(3, int64(e.n))
+
+	// a single row group
+	meta.ListField(4, thriftStruct, 1)
+	meta.BeginStruct()
+	meta.ListField(1, thriftStruct, len(e.columns))
+	var totalSize int64
+	for i, column := range e.columns {
+		chunk := chunks[i]
+		totalSize += chunk.uncompressedSize
+		meta.BeginStruct()
+		meta.I64Field(2, chunk.offset)
+		meta.StructField(3)
+		meta.BeginStruct()
+		meta.I32Field(1, column.physicalType)
+		meta.ListField(2, thriftI32, 2)
+		meta.I32Elem(parquetPlain)
+		meta.I32Elem(parquetRLE)
+		meta.ListField(3, thriftBinary, 1)
+		meta.StringElem(column.name)
+		meta.I32Field(4, codec)
+		meta.I64Field(5, int64(e.n))
+		meta.I64Field(6, chunk.uncompressedSize)
+		meta.I64Field(7, chunk.compressedSize)
+		meta.I64Field(9, chunk.offset)
+		meta.EndStruct()
+		meta.EndStruct()
+	}
+	meta.I64Field(2, totalSize)
+	meta.I64Field(3, int64(e.n))
+	meta.EndStruct()
+
+	meta.StringField(6, "diadata scraper-writers")
+	meta.EndStruct()
+	return meta.Bytes()
+}
@@ -0,0 +1,288 @@
+package writers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// thriftReader decodes thrift compact structs into maps from field id to value, which is enough
+// to inspect the metadata written by parquetEncoder.
+type thriftReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *thriftReader) varint() uint64 {
+	v, n := binary.Uvarint(r.data[r.pos:])
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) zigzag() int64 {
+	v := r.varint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *thriftReader) value(typ byte) interface{} {
+	switch typ {
+	case thriftI32, thriftI64:
+		return r.zigzag()
+	case thriftBinary:
+		n := int(r.varint())
+		s := string(r.data[r.pos : r.pos+n])
+		r.pos += n
+		return s
+	case thriftList:
+		header := r.data[r.pos]
+		r.pos++
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(r.varint())
+		}
+		list := make([]interface{}, size)
+		for i := range list {
+			list[i] = r.value(header & 0x0f)
+		}
+		return list
+	case thriftStruct:
+		return r.structure()
+	}
+	panic("unexpected thrift type")
+}
+
+func (r *thriftReader) structure() map[int16]interface{} {
+	fields := map[int16]interface{}{}
+	var id int16
+	for {
+		header := r.data[r.pos]
+		r.pos++
+		if header == 0 {
+			return fields
+		}
+		if delta := int16(header >> 4); delta != 0 {
+			id += delta
+		} else {
+			id = int16(r.zigzag())
+		}
+		fields[id] = r.value(header & 0x0f)
+	}
+}
+
+type parquetTestRecord struct {
+	Symbol    string `json:"symbol"`
+	Price     float64
+	Volume    int32
+	Sequence  uint64
+	Perpetual bool
+	Time      time.Time
+	Tags      []string
+	ignored   int
+}
+
+func TestParquet(t *testing.T) {
+	at := time.Date(2021, 3, 4, 5, 6, 7, 8000, time.UTC)
+	records := []parquetTestRecord{
+		{Symbol: "BTC-PERP", Price: 50000.5, Volume: 3, Perpetual: true, Time: at, Tags: []string{"a"}},
+		{Symbol: "ETH-0326", Price: 1500.25, Volume: -2, Sequence: math.MaxUint64, Perpetual: false, Time: at.Add(time.Second)},
+	}
+	for _, compression := range []Compression{Uncompressed, Gzip} {
+		encoder := Parquet{Compression: compression}.NewEncoder()
+		for i := range records {
+			err := encoder.Append(&records[i])
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := encoder.Append(struct{ A int }{1}); err == nil {
+			t.Error("expected an error for a record of another type")
+		}
+		data, err := encoder.Finish()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(data[:4]) != "PAR1" || string(data[len(data)-4:]) != "PAR1" {
+			t.Fatal("missing magic")
+		}
+		footerLength := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+		footer := &thriftReader{data: data[len(data)-8-footerLength : len(data)-8]}
+		meta := footer.structure()
+		if meta[3].(int64) != 2 {
+			t.Errorf("num_rows %v", meta[3])
+		}
+		schema := meta[2].([]interface{})
+		names := []string{}
+		for _, element := range schema[1:] {
+			names = append(names, element.(map[int16]interface{})[4].(string))
+		}
+		if want := []string{"symbol", "Price", "Volume", "Sequence", "Perpetual", "Time", "Tags"}; !equalStrings(names, want) {
+			t.Fatalf("columns %v, want %v", names, want)
+		}
+
+		if convertedType := schema[4].(map[int16]interface{})[6]; convertedType != int64(parquetUint64) {
+			t.Errorf("converted type of unsigned column %v", convertedType)
+		}
+		if _, ok := schema[3].(map[int16]interface{})[6]; ok {
+			t.Error("signed column with converted type")
+		}
+
+		rowGroup := meta[4].([]interface{})[0].(map[int16]interface{})
+		columns := rowGroup[1].([]interface{})
+		values := make([][]byte, len(columns))
+		for i, column := range columns {
+			columnMeta := column.(map[int16]interface{})[3].(map[int16]interface{})
+			offset := int(columnMeta[9].(int64))
+			r := &thriftReader{data: data, pos: offset}
+			page := r.structure()
+			if int(columnMeta[7].(int64)) != r.pos-offset+int(page[3].(int64)) {
+				t.Errorf("column %d: compressed size %v", i, columnMeta[7])
+			}
+			values[i] = data[r.pos : r.pos+int(page[3].(int64))]
+			if compression == Gzip {
+				zr, err := gzip.NewReader(bytes.NewReader(values[i]))
+				if err != nil {
+					t.Fatal(err)
+				}
+				values[i], err = ioutil.ReadAll(zr)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+
+		if got := string(values[0]); got != "\x08\x00\x00\x00BTC-PERP\x08\x00\x00\x00ETH-0326" {
+			t.Errorf("symbols %q", got)
+		}
+		if got := math.Float64frombits(binary.LittleEndian.Uint64(values[1][8:])); got != 1500.25 {
+			t.Errorf("price %v", got)
+		}
+		if got := int64(binary.LittleEndian.Uint64(values[2][8:])); got != -2 {
+			t.Errorf("volume %v", got)
+		}
+		if got := binary.LittleEndian.Uint64(values[3][8:]); got != math.MaxUint64 {
+			t.Errorf("sequence %v", got)
+		}
+		if !bytes.Equal(values[4], []byte{1}) {
+			t.Errorf("perpetual %v", values[4])
+		}
+		if got := int64(binary.LittleEndian.Uint64(values[5])); got != at.UnixNano()/1e3 {
+			t.Errorf("time %v", got)
+		}
+		if got := string(values[6]); got != "\x05\x00\x00\x00[\"a\"]\x04\x00\x00\x00null" {
+			t.Errorf("tags %q", got)
+		}
+	}
+}
+
+// pyarrowRead prints the schema and the rows of a parquet file as JSON, with timestamps as
+// microseconds since the epoch.
+const pyarrowRead = `
+import datetime, json, sys
+import pyarrow.parquet as pq
+table = pq.read_table(sys.argv[1])
+epoch = datetime.datetime(1970, 1, 1)
+micros = lambda t: (t.replace(tzinfo=None) - epoch) // datetime.timedelta(microseconds=1)
+print(json.dumps({
+    "schema": {field.name: str(field.type) for field in table.schema},
+    "rows": table.to_pylist(),
+}, default=micros))
+`
+
+// TestParquetPyArrow reads the files back with the Apache Arrow implementation of parquet. It is
+// skipped if python3 with pyarrow is not installed.
+func TestParquetPyArrow(t *testing.T) {
+	if err := exec.Command("python3", "-c", "import pyarrow.parquet").Run(); err != nil {
+		t.Skip("python3 with pyarrow not installed")
+	}
+	at := time.Date(2021, 3, 4, 5, 6, 7, 8000, time.UTC)
+	records := []parquetTestRecord{
+		{Symbol: "BTC-PERP", Price: 50000.5, Volume: 3, Perpetual: true, Time: at, Tags: []string{"a"}},
+		{Symbol: "ETH-0326", Price: 1500.25, Volume: -2, Sequence: math.MaxUint64, Perpetual: false, Time: at.Add(time.Second)},
+		{Symbol: "", Price: -0.5, Volume: math.MaxInt32, Sequence: 7, Perpetual: true, Time: time.Unix(0, 0)},
+	}
+	for _, compression := range []Compression{Uncompressed, Gzip} {
+		encoder := Parquet{Compression: compression}.NewEncoder()
+		for i := range records {
+			if err := encoder.Append(records[i]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		data, err := encoder.Finish()
+		if err != nil {
+			t.Fatal(err)
+		}
+		file, err := ioutil.TempFile("", "records*"+Parquet{}.Extension())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(file.Name())
+		if _, err := file.Write(data); err != nil {
+			t.Fatal(err)
+		}
+		file.Close()
+		out, err := exec.Command("python3", "-c", pyarrowRead, file.Name()).CombinedOutput()
+		if err != nil {
+			t.Fatalf("pyarrow: %v: %s", err, out)
+		}
+
+		var table struct {
+			Schema map[string]string
+			Rows   []struct {
+				Symbol    string
+				Price     float64
+				Volume    int64
+				Sequence  uint64
+				Perpetual bool
+				Time      int64
+				Tags      string
+			}
+		}
+		if err := json.Unmarshal(out, &table); err != nil {
+			t.Fatalf("%v: %s", err, out)
+		}
+
+		types := map[string]string{"symbol": "string", "Price": "double", "Volume": "int64", "Sequence": "uint64", "Perpetual": "bool", "Tags": "string"}
+		for name, typ := range types {
+			if table.Schema[name] != typ {
+				t.Errorf("type of %s: %s, want %s", name, table.Schema[name], typ)
+			}
+		}
+		if !strings.HasPrefix(table.Schema["Time"], "timestamp[us") || len(table.Schema) != len(types)+1 {
+			t.Errorf("schema %v", table.Schema)
+		}
+
+		if len(table.Rows) != len(records) {
+			t.Fatalf("%d rows, want %d", len(table.Rows), len(records))
+		}
+		for i, row := range table.Rows {
+			record := records[i]
+			tags, _ := json.Marshal(record.Tags)
+			if row.Symbol != record.Symbol || row.Price != record.Price || row.Volume != int64(record.Volume) ||
+				row.Sequence != record.Sequence || row.Perpetual != record.Perpetual ||
+				row.Time != record.Time.UnixNano()/1e3 || row.Tags != string(tags) {
+				t.Errorf("row %d: %+v, want %+v", i, row, record)
+			}
+		}
+	}
+}
+
+func equalStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+package writers
+
+import (
+	"bytes"
+	"errors"
+	"net/url"
+	"strings"
+
+	minio "github.com/minio/minio-go"
+	"github.com/minio/minio-go/pkg/credentials"
+)
+
+// S3Storage stores files as objects in a bucket of an S3-compatible object store, such as AWS S3
+// or MinIO, through the MinIO client. Objects are addressed path-style, i.e. as
+// endpoint/bucket/key.
+type S3Storage struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewS3Storage returns a storage for the bucket @bucket at @endpoint, such as
+// https://s3.eu-central-1.amazonaws.com or http://localhost:9000. @region defaults to us-east-1.
+func NewS3Storage(endpoint, region, bucket, accessKey, secretKey string) (*S3Storage, error) {
+	if bucket == "" {
+		return nil, errors.New("no bucket")
+	}
+	u, err := url.Parse(strings.TrimSuffix(endpoint, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if u.Host == "" || (u.Path != "" && u.Path != "/") {
+		return nil, errors.New("endpoint must be a scheme and host, got " + endpoint)
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+	client, err := minio.NewWithOptions(u.Host, &minio.Options{
+		Creds:        credentials.NewStaticV4(accessKey, secretKey, ""),
+		Secure:       u.Scheme == "https",
+		Region:       region,
+		BucketLookup: minio.BucketLookupPath,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &S3Storage{client: client, bucket: bucket}, nil
+}
+
+// Put uploads @data as the object @key.
+func (s *S3Storage) Put(key string, data []byte) error {
+	_, err := s.client.PutObject(s.bucket, strings.TrimPrefix(key, "/"), bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{})
+	return err
+}
@@ -0,0 +1,92 @@
+package writers
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	minio "github.com/minio/minio-go"
+)
+
+func TestS3Storage(t *testing.T) {
+	var path, authorization, body string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		path, authorization, body = r.URL.EscapedPath(), r.Header.Get("Authorization"), string(data)
+		if strings.Contains(path, "denied") {
+			// as MinIO answers requests with a wrong signature
+			rw.Header().Set("Content-Type", "application/xml")
+			rw.WriteHeader(http.StatusForbidden)
+			rw.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<Error><Code>SignatureDoesNotMatch</Code><Message>The request signature we calculated does not match the signature you provided.</Message></Error>`))
+			return
+		}
+		rw.Header().Set("ETag", `"8d777f385d3dfec8815d20f7496026dc"`)
+	}))
+	defer server.Close()
+
+	if _, err := NewS3Storage(server.URL, "", "", "key", "secret"); err == nil {
+		t.Error("expected an error without bucket")
+	}
+	if _, err := NewS3Storage(server.URL+"/archive", "", "archive", "key", "secret"); err == nil {
+		t.Error("expected an error for an endpoint with a path")
+	}
+
+	storage, err := NewS3Storage(server.URL, "eu-central-1", "archive", "key", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = storage.Put("futures/2021-03-04/BTC PERP+1.ndjson", []byte("data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "/archive/futures/2021-03-04/BTC%20PERP%2B1.ndjson" {
+		t.Errorf("path %s", path)
+	}
+	// over http the client signs the payload in chunks
+	if !strings.Contains(body, ";chunk-signature=") || !strings.Contains(body, "\r\ndata\r\n") {
+		t.Errorf("body %s", body)
+	}
+	if !strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=key/") || !strings.Contains(authorization, "/eu-central-1/s3/aws4_request") {
+		t.Errorf("authorization %s", authorization)
+	}
+
+	err = storage.Put("denied.ndjson", []byte("data"))
+	if minio.ToErrorResponse(err).Code != "SignatureDoesNotMatch" {
+		t.Errorf("expected the error of the store, got %v", err)
+	}
+}
+
+// TestS3StorageMinIO uploads an object to the MinIO server given by MINIO_ENDPOINT, such as
+// http://localhost:9000, and reads it back. The bucket MINIO_BUCKET must exist and be writable
+// with MINIO_ACCESS_KEY and MINIO_SECRET_KEY.
+func TestS3StorageMinIO(t *testing.T) {
+	endpoint := os.Getenv("MINIO_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("MINIO_ENDPOINT not set")
+	}
+	storage, err := NewS3Storage(endpoint, "", os.Getenv("MINIO_BUCKET"), os.Getenv("MINIO_ACCESS_KEY"), os.Getenv("MINIO_SECRET_KEY"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := "scraper-writers-test/" + time.Now().UTC().Format("20060102T150405.000000000Z") + " trades.ndjson"
+	data := []byte("{\"price\":1}\n")
+	err = storage.Put(key, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	object, err := storage.client.GetObject(storage.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer object.Close()
+	got, err := ioutil.ReadAll(object)
+	if err != nil || string(got) != string(data) {
+		t.Errorf("get %s: %v %q", key, err, got)
+	}
+}
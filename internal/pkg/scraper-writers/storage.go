@@ -0,0 +1,44 @@
+package writers
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// LocalStorage stores files below the directory Dir.
+type LocalStorage struct {
+	Dir string
+}
+
+// Put writes @data to Dir/@key. The file is written to a temporary file first and renamed,
+// so that readers never see partially written files.
+func (s LocalStorage) Put(key string, data []byte) error {
+	path := filepath.Join(s.Dir, filepath.FromSlash(key))
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Close()
+	} else {
+		tmp.Close()
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	err = os.Chmod(tmp.Name(), 0644)
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
@@ -0,0 +1,115 @@
+package writers
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Type ids of the thrift compact protocol used for the parquet metadata.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes thrift structs in the compact protocol. Fields of a struct must be
+// written in increasing order of their ids.
+type thriftWriter struct {
+	buf     bytes.Buffer
+	lastIDs []int16
+	lastID  int16
+}
+
+func (t *thriftWriter) varint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], v)
+	t.buf.Write(b[:n])
+}
+
+func (t *thriftWriter) fieldHeader(id int16, typ byte) {
+	if delta := id - t.lastID; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		t.varint(uint64((int64(id) << 1) ^ (int64(id) >> 63)))
+	}
+	t.lastID = id
+}
+
+func (t *thriftWriter) i32(v int32) {
+	t.varint(uint64(uint32((v << 1) ^ (v >> 31))))
+}
+
+func (t *thriftWriter) i64(v int64) {
+	t.varint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (t *thriftWriter) binary(v []byte) {
+	t.varint(uint64(len(v)))
+	t.buf.Write(v)
+}
+
+// I32Field writes the field @id with value @v.
+func (t *thriftWriter) I32Field(id int16, v int32) {
+	t.fieldHeader(id, thriftI32)
+	t.i32(v)
+}
+
+// I64Field writes the field @id with value @v.
+func (t *thriftWriter) I64Field(id int16, v int64) {
+	t.fieldHeader(id, thriftI64)
+	t.i64(v)
+}
+
+// StringField writes the field @id with value @v.
+func (t *thriftWriter) StringField(id int16, v string) {
+	t.fieldHeader(id, thriftBinary)
+	t.binary([]byte(v))
+}
+
+// ListField writes the header of the list field @id with @size elements of type @elemType.
+// The elements must be written right after.
+func (t *thriftWriter) ListField(id int16, elemType byte, size int) {
+	t.fieldHeader(id, thriftList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | elemType)
+	} else {
+		t.buf.WriteByte(0xf0 | elemType)
+		t.varint(uint64(size))
+	}
+}
+
+// StructField writes the header of the struct field @id. The struct must be written right after.
+func (t *thriftWriter) StructField(id int16) {
+	t.fieldHeader(id, thriftStruct)
+}
+
+// BeginStruct starts a struct, either as field, list element or top level struct.
+func (t *thriftWriter) BeginStruct() {
+	t.lastIDs = append(t.lastIDs, t.lastID)
+	t.lastID = 0
+}
+
+// EndStruct writes the stop field of the current struct.
+func (t *thriftWriter) EndStruct() {
+	t.buf.WriteByte(0)
+	t.lastID = t.lastIDs[len(t.lastIDs)-1]
+	t.lastIDs = t.lastIDs[:len(t.lastIDs)-1]
+}
+
+// I32Elem writes the list element @v.
+func (t *thriftWriter) I32Elem(v int32) {
+	t.i32(v)
+}
+
+// StringElem writes the list element @v.
+func (t *thriftWriter) StringElem(v string) {
+	t.binary([]byte(v))
+}
+
+// Bytes returns the encoded data.
+func (t *thriftWriter) Bytes() []byte {
+	return t.buf.Bytes()
+}
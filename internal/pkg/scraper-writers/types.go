@@ -6,3 +6,30 @@ type Writer interface {
 	Write(line string, filename string) (int, error)        // returns number of bytes written or error
 	// rationale for making a line a pointer - is because it can be very large. filename will always be small.
 }
+
+// RecordWriter archives records, such as trades, in batches. Records of a batch are encoded into a
+// single file, which is rotated by size and age. Implementations must be safe for concurrent use.
+type RecordWriter interface {
+	Write(record interface{}) error // adds the record to the current batch
+	Flush() error                   // writes the current batch, even if it is not full
+	Close() error                   // flushes and releases the writer
+}
+
+// Format encodes batches of records into files.
+type Format interface {
+	Extension() string   // file extension including the dot, such as .parquet
+	NewEncoder() Encoder // returns an encoder for a new file
+}
+
+// Encoder encodes the records of a single file.
+type Encoder interface {
+	Append(record interface{}) error // adds the record to the file
+	Len() int                        // number of records
+	Size() int                       // size of the encoded records before compression
+	Finish() ([]byte, error)         // returns the content of the file
+}
+
+// Storage stores files under a key, i.e. a slash separated path.
+type Storage interface {
+	Put(key string, data []byte) error
+}
@@ -0,0 +1,225 @@
+package supplyservice
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/ethhelper"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Number of blocks requested per FilterTransfer call during backfilling.
+	backfillBlockRange = uint64(5000)
+	// Minimal block range the backfill falls back to when a node refuses a query.
+	backfillMinBlockRange = uint64(100)
+)
+
+var zeroAddress = common.HexToAddress("0x0000000000000000000000000000000000000000")
+
+// supplyReplay keeps track of the state of a token while replaying its Transfer events.
+type supplyReplay struct {
+	totalSupply   *big.Int
+	lockedWallets map[common.Address]*big.Int
+}
+
+func newSupplyReplay(lockedWallets []string) *supplyReplay {
+	sr := &supplyReplay{
+		totalSupply:   big.NewInt(0),
+		lockedWallets: make(map[common.Address]*big.Int),
+	}
+	for _, wallet := range lockedWallets {
+		sr.lockedWallets[common.HexToAddress(wallet)] = big.NewInt(0)
+	}
+	return sr
+}
+
+// apply updates total supply and locked balances with a single transfer.
+// Mints are transfers from the zero address, burns are transfers to the zero address.
+func (sr *supplyReplay) apply(from, to common.Address, value *big.Int) {
+	if from == zeroAddress {
+		sr.totalSupply.Add(sr.totalSupply, value)
+	}
+	if to == zeroAddress {
+		sr.totalSupply.Sub(sr.totalSupply, value)
+	}
+	if balance, ok := sr.lockedWallets[from]; ok {
+		balance.Sub(balance, value)
+	}
+	if balance, ok := sr.lockedWallets[to]; ok {
+		balance.Add(balance, value)
+	}
+}
+
+// supplies returns total and circulating supply normalized by @decimals.
+func (sr *supplyReplay) supplies(decimals uint8) (total float64, circulating float64) {
+	locked := big.NewInt(0)
+	for _, balance := range sr.lockedWallets {
+		locked.Add(locked, balance)
+	}
+	circulatingInt := new(big.Int).Sub(sr.totalSupply, locked)
+	total = normalizeAmount(sr.totalSupply, decimals)
+	circulating = normalizeAmount(circulatingInt, decimals)
+	return
+}
+
+func normalizeAmount(amount *big.Int, decimals uint8) float64 {
+	value, _ := new(big.Float).Quo(new(big.Float).SetInt(amount), big.NewFloat(math.Pow10(int(decimals)))).Float64()
+	return value
+}
+
+// GetContractCreationBlock returns the first block in which the contract at @address has code.
+// It performs a binary search over the chain and hence requires an archive node.
+func GetContractCreationBlock(address string, client *ethclient.Client) (uint64, error) {
+	head, err := client.BlockNumber(context.Background())
+	if err != nil {
+		return 0, err
+	}
+	return ethhelper.DeploymentBlock(context.Background(), client, common.HexToAddress(address), head)
+}
+
+// transfer is a Transfer event reduced to the fields needed by the replay.
+type transfer struct {
+	blockNumber uint64
+	from        common.Address
+	to          common.Address
+	value       *big.Int
+}
+
+// supplyHistory collects the supplies at the end of each day while replaying transfers in order.
+type supplyHistory struct {
+	replay     *supplyReplay
+	symbol     string
+	name       string
+	decimals   uint8
+	currentDay time.Time
+	supplies   []dia.Supply
+}
+
+// add applies @t which happened at @blockTime. All days before the day of @blockTime are closed first.
+func (h *supplyHistory) add(t transfer, blockTime time.Time) {
+	day := time.Date(blockTime.Year(), blockTime.Month(), blockTime.Day(), 0, 0, 0, 0, time.UTC)
+	if day.After(h.currentDay) {
+		h.closeDays(day)
+		h.currentDay = day
+	}
+	h.replay.apply(t.from, t.to, t.value)
+}
+
+// closeDays appends the supply at the end of each day from currentDay up to (excluding) @next.
+// Days without transfers carry the supply of the previous day.
+func (h *supplyHistory) closeDays(next time.Time) {
+	if h.currentDay.IsZero() {
+		return
+	}
+	total, circulating := h.replay.supplies(h.decimals)
+	for day := h.currentDay; day.Before(next); day = day.AddDate(0, 0, 1) {
+		h.supplies = append(h.supplies, dia.Supply{
+			Symbol:            h.symbol,
+			Name:              h.name,
+			Supply:            total,
+			CirculatingSupply: circulating,
+			Source:            dia.Diadata,
+			Time:              day.AddDate(0, 0, 1).Add(-time.Second),
+		})
+	}
+}
+
+// filterTransfers returns all Transfer events of @instance in the blocks @from to @to.
+// The range is only usable if it was read completely, so iteration errors are returned.
+func filterTransfers(instance *ERC20, from, to uint64) ([]transfer, error) {
+	iter, err := instance.FilterTransfer(&bind.FilterOpts{Start: from, End: &to}, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	var transfers []transfer
+	for iter.Next() {
+		transfers = append(transfers, transfer{
+			blockNumber: iter.Event.Raw.BlockNumber,
+			from:        iter.Event.From,
+			to:          iter.Event.To,
+			value:       iter.Event.Value,
+		})
+	}
+	return transfers, iter.Error()
+}
+
+// GetHistoricalSupplies reconstructs daily total and circulating supplies of the token at @tokenAddress
+// by replaying all its Transfer events from the block of contract creation up to the current block.
+// Circulating supply is total supply minus the balances of @lockedWallets.
+// For each day from the first transfer on the supply at the end of this day is returned.
+func GetHistoricalSupplies(tokenAddress string, lockedWallets []string, client *ethclient.Client) (supplies []dia.Supply, err error) {
+	instance, err := NewERC20(common.HexToAddress(tokenAddress), client)
+	if err != nil {
+		return
+	}
+	history := &supplyHistory{replay: newSupplyReplay(lockedWallets)}
+	history.symbol, err = instance.Symbol(&bind.CallOpts{})
+	if err != nil {
+		return
+	}
+	history.name, err = instance.Name(&bind.CallOpts{})
+	if err != nil {
+		return
+	}
+	history.decimals, err = instance.Decimals(&bind.CallOpts{})
+	if err != nil {
+		return
+	}
+	startBlock, err := GetContractCreationBlock(tokenAddress, client)
+	if err != nil {
+		return
+	}
+	header, err := client.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		return
+	}
+	endBlock := header.Number.Uint64()
+	log.Infof("replay transfers of %s from block %d to block %d", history.symbol, startBlock, endBlock)
+
+	blockRange := backfillBlockRange
+	for from := startBlock; from <= endBlock; {
+		to := from + blockRange - 1
+		if to > endBlock {
+			to = endBlock
+		}
+		transfers, errFilter := filterTransfers(instance, from, to)
+		if errFilter != nil {
+			// Nodes limit the number of logs per query and may drop long responses.
+			// Retry with a smaller range, a partially read range would corrupt the replay.
+			if blockRange > backfillMinBlockRange {
+				log.Warnf("transfers of %s in blocks %d - %d: %v. Retry with smaller range.", history.symbol, from, to, errFilter)
+				blockRange /= 2
+				continue
+			}
+			return nil, errFilter
+		}
+		// Block timestamps are only needed within the current range.
+		blockTimes := make(map[uint64]time.Time)
+		for _, t := range transfers {
+			blockTime, ok := blockTimes[t.blockNumber]
+			if !ok {
+				blockHeader, errHeader := client.HeaderByNumber(context.Background(), new(big.Int).SetUint64(t.blockNumber))
+				if errHeader != nil {
+					return nil, errHeader
+				}
+				blockTime = time.Unix(int64(blockHeader.Time), 0).UTC()
+				blockTimes[t.blockNumber] = blockTime
+			}
+			history.add(t, blockTime)
+		}
+		from = to + 1
+		if blockRange < backfillBlockRange {
+			blockRange *= 2
+		}
+	}
+	history.closeDays(history.currentDay.AddDate(0, 0, 1))
+	return history.supplies, nil
+}
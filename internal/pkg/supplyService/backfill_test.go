@@ -0,0 +1,52 @@
+package supplyservice
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestSupplyHistory(t *testing.T) {
+	alice := common.HexToAddress("0x1")
+	treasury := common.HexToAddress("0x2")
+	history := &supplyHistory{
+		replay:   newSupplyReplay([]string{treasury.Hex()}),
+		symbol:   "TKN",
+		decimals: 2,
+	}
+	day := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	transfers := []struct {
+		transfer
+		time time.Time
+	}{
+		// mint to alice and treasury on the first day
+		{transfer{from: zeroAddress, to: alice, value: big.NewInt(10000)}, day.Add(time.Hour)},
+		{transfer{from: zeroAddress, to: treasury, value: big.NewInt(5000)}, day.Add(2 * time.Hour)},
+		// treasury releases tokens on the third day, alice burns some
+		{transfer{from: treasury, to: alice, value: big.NewInt(2000)}, day.AddDate(0, 0, 2)},
+		{transfer{from: alice, to: zeroAddress, value: big.NewInt(1000)}, day.AddDate(0, 0, 2).Add(time.Hour)},
+	}
+	for _, tr := range transfers {
+		history.add(tr.transfer, tr.time)
+	}
+	history.closeDays(history.currentDay.AddDate(0, 0, 1))
+
+	expected := []struct{ total, circulating float64 }{
+		{150, 100},
+		{150, 100},
+		{140, 110},
+	}
+	if len(history.supplies) != len(expected) {
+		t.Fatalf("expected %d days, got %d", len(expected), len(history.supplies))
+	}
+	for i, supply := range history.supplies {
+		if supply.Supply != expected[i].total || supply.CirculatingSupply != expected[i].circulating {
+			t.Errorf("day %d: supply %v, circulating %v", i, supply.Supply, supply.CirculatingSupply)
+		}
+		if end := day.AddDate(0, 0, i+1).Add(-time.Second); !supply.Time.Equal(end) {
+			t.Errorf("day %d: time %v", i, supply.Time)
+		}
+	}
+}
@@ -27,6 +27,10 @@ var (
 	tol = float64(0.1)
 )
 
+// DefaultGracePeriod is the time a block is kept open after its end, measured in trade time. It lets
+// trades from partitions of the trades topic which lag behind the others still enter their block.
+const DefaultGracePeriod = 30 * time.Second
+
 type TradesBlockService struct {
 	pair            string
 	shutdown        chan nothing
@@ -38,8 +42,16 @@ type TradesBlockService struct {
 	closed          bool
 	started         bool
 	BlockDuration   int64
-	currentBlock    *dia.TradesBlock
-	datastore       models.Datastore
+	// GracePeriod is the time by which the latest trade has to pass the end of a block before the
+	// block is finalised. Trades older than the end of the last finalised block are ignored.
+	GracePeriod time.Duration
+	// openBlocks are the blocks not yet finalised, ordered by BeginTime.
+	openBlocks []*dia.TradesBlock
+	// lastEndTime is the EndTime of the last finalised block.
+	lastEndTime time.Time
+	// watermark is the time of the latest trade processed.
+	watermark time.Time
+	datastore models.Datastore
 }
 
 func NewTradesBlockService(datastore models.Datastore, blockDuration int64) *TradesBlockService {
@@ -50,8 +62,8 @@ func NewTradesBlockService(datastore models.Datastore, blockDuration int64) *Tra
 		chanTradesBlock: make(chan *dia.TradesBlock),
 		error:           nil,
 		started:         false,
-		currentBlock:    nil,
 		BlockDuration:   blockDuration,
+		GracePeriod:     DefaultGracePeriod,
 		datastore:       datastore,
 	}
 	go s.mainLoop()
@@ -86,20 +98,53 @@ func (ps *TradesBlockService) Channel() chan *dia.TradesBlock {
 	return ps.chanTradesBlock
 }
 
-func (s *TradesBlockService) finaliseCurrentBlock() {
+func (s *TradesBlockService) finaliseBlock(block *dia.TradesBlock) {
 
-	sort.Slice(s.currentBlock.TradesBlockData.Trades, func(i, j int) bool {
-		return s.currentBlock.TradesBlockData.Trades[i].Time.Before(s.currentBlock.TradesBlockData.Trades[j].Time)
+	sort.Slice(block.TradesBlockData.Trades, func(i, j int) bool {
+		return block.TradesBlockData.Trades[i].Time.Before(block.TradesBlockData.Trades[j].Time)
 	})
 
-	hash, err := structhash.Hash(s.currentBlock.TradesBlockData, 1)
+	hash, err := structhash.Hash(block.TradesBlockData, 1)
 	if err != nil {
 		log.Printf("error on hash")
 		hash = "hashError"
 	}
-	s.currentBlock.BlockHash = hash
-	s.currentBlock.TradesBlockData.TradesNumber = len(s.currentBlock.TradesBlockData.Trades)
-	s.chanTradesBlock <- s.currentBlock
+	block.BlockHash = hash
+	block.TradesBlockData.TradesNumber = len(block.TradesBlockData.Trades)
+	s.chanTradesBlock <- block
+}
+
+// finaliseBlocks finalises the open blocks which ended more than GracePeriod before the watermark.
+func (s *TradesBlockService) finaliseBlocks() {
+	for len(s.openBlocks) > 0 && s.openBlocks[0].TradesBlockData.EndTime.Add(s.GracePeriod).Before(s.watermark) {
+		block := s.openBlocks[0]
+		s.openBlocks = s.openBlocks[1:]
+		s.finaliseBlock(block)
+		s.lastEndTime = block.TradesBlockData.EndTime
+		log.Info("finalised block beginTime:", block.TradesBlockData.BeginTime, " nb trades:", len(block.TradesBlockData.Trades))
+	}
+}
+
+// blockOf returns the open block the trade time @t belongs to and creates it if necessary.
+func (s *TradesBlockService) blockOf(t time.Time) *dia.TradesBlock {
+	beginTime := time.Unix((t.Unix()/s.BlockDuration)*s.BlockDuration, 0)
+	i := sort.Search(len(s.openBlocks), func(i int) bool { return !s.openBlocks[i].TradesBlockData.BeginTime.Before(beginTime) })
+	if i < len(s.openBlocks) && s.openBlocks[i].TradesBlockData.BeginTime.Equal(beginTime) {
+		return s.openBlocks[i]
+	}
+	b := &dia.TradesBlock{
+		TradesBlockData: dia.TradesBlockData{
+			Trades:    []dia.Trade{},
+			EndTime:   beginTime.Add(time.Duration(s.BlockDuration) * time.Second),
+			BeginTime: beginTime,
+		},
+	}
+	log.Info("created new block beginTime:", b.TradesBlockData.BeginTime)
+	s.openBlocks = append(s.openBlocks, nil)
+	copy(s.openBlocks[i+1:], s.openBlocks[i:])
+	s.openBlocks[i] = b
+	s.datastore.Flush()
+	return b
 }
 
 func (s *TradesBlockService) process(t dia.Trade) {
@@ -133,33 +178,18 @@ func (s *TradesBlockService) process(t dia.Trade) {
 		s.datastore.SaveTradeInflux(&t)
 	}
 
-	if s.currentBlock != nil &&
-		s.currentBlock.TradesBlockData.BeginTime.After(t.Time) {
-		log.Debugf("ignore trade should be in previous block %v", t)
+	if !s.lastEndTime.IsZero() && t.Time.Before(s.lastEndTime) {
+		log.Debugf("ignore trade should be in finalised block %v", t)
 		ignoreTrade = true
 	}
 
 	if !ignoreTrade {
-
-		if s.currentBlock == nil || s.currentBlock.TradesBlockData.EndTime.Before(t.Time) {
-			if s.currentBlock != nil {
-				s.finaliseCurrentBlock()
-			}
-
-			b := &dia.TradesBlock{
-				TradesBlockData: dia.TradesBlockData{
-					Trades:    []dia.Trade{},
-					EndTime:   time.Unix((t.Time.Unix()/s.BlockDuration)*s.BlockDuration+s.BlockDuration, 0),
-					BeginTime: time.Unix((t.Time.Unix()/s.BlockDuration)*s.BlockDuration, 0),
-				},
-			}
-			if s.currentBlock != nil {
-				log.Info("created new block beginTime:", b.TradesBlockData.BeginTime, "previous block nb trades:", len(s.currentBlock.TradesBlockData.Trades))
-			}
-			s.currentBlock = b
-			s.datastore.Flush()
+		block := s.blockOf(t.Time)
+		block.TradesBlockData.Trades = append(block.TradesBlockData.Trades, t)
+		if t.Time.After(s.watermark) {
+			s.watermark = t.Time
 		}
-		s.currentBlock.TradesBlockData.Trades = append(s.currentBlock.TradesBlockData.Trades, t)
+		s.finaliseBlocks()
 	} else {
 		log.Debugf("ignore trade  %v", t)
 	}
@@ -0,0 +1,55 @@
+package tradesBlockService
+
+import (
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+)
+
+// testDatastore stores nothing and quotes all base tokens at 1 USD.
+type testDatastore struct {
+	models.Datastore
+}
+
+func (ds testDatastore) GetPriceUSD(symbol string) (float64, error) { return 1, nil }
+func (ds testDatastore) SaveTradeInflux(t *dia.Trade) error         { return nil }
+func (ds testDatastore) Flush() error                               { return nil }
+
+func TestLaggingPartition(t *testing.T) {
+	s := &TradesBlockService{
+		chanTradesBlock: make(chan *dia.TradesBlock, 10),
+		BlockDuration:   120,
+		GracePeriod:     30 * time.Second,
+		datastore:       testDatastore{},
+	}
+	t0 := time.Unix(1600000080, 0) // block from 1600000080 to 1600000200
+	trade := func(seconds int64) dia.Trade {
+		return dia.Trade{Symbol: "BTC", Pair: "BTC-USD", Price: 1, Time: t0.Add(time.Duration(seconds) * time.Second)}
+	}
+
+	s.process(trade(10))
+	// a trade of the next block read from a partition which is ahead
+	s.process(trade(125))
+	// a trade of the first block from a lagging partition
+	s.process(trade(100))
+	if len(s.chanTradesBlock) != 0 {
+		t.Fatal("block finalised within the grace period")
+	}
+
+	s.process(trade(151))
+	if len(s.chanTradesBlock) != 1 {
+		t.Fatalf("%d blocks finalised, expected 1", len(s.chanTradesBlock))
+	}
+	block := <-s.chanTradesBlock
+	if block.TradesBlockData.TradesNumber != 2 || !block.TradesBlockData.BeginTime.Equal(t0) {
+		t.Errorf("block %+v", block.TradesBlockData)
+	}
+
+	// trades of a finalised block are ignored
+	s.process(trade(110))
+	if len(s.openBlocks) != 1 || len(s.openBlocks[0].TradesBlockData.Trades) != 2 {
+		t.Errorf("open blocks %+v", s.openBlocks)
+	}
+}
@@ -48,6 +48,9 @@ const (
 	STEXExchange      = "STEX"
 	Deribit           = "Deribit"
 	DfynNetwork       = "DFYN"
+	BitmexExchange    = "Bitmex"
+	BitflyerExchange  = "Bitflyer"
+	CoinflexExchange  = "Coinflex"
 )
 
 const (
@@ -21,6 +21,7 @@ const (
 	ETHEREUM                                = "Ethereum"
 	FLOW                                    = "Flow"
 	BINANCESMARTCHAIN                       = "BinanceSmartChain"
+	POLYGON                                 = "Polygon"
 )
 
 type VerificationMechanism string
@@ -57,11 +58,54 @@ type NFT struct {
 	CreationTime   time.Time
 	CreatorAddress string
 	URI            string
-	// @Attributes is a collection of attributes from on- and off-chain
-	// TO DO: Should we split up into two fields?
+	// @Attributes is a collection of attributes from on- and off-chain as found
+	// when the NFT was first seen. NFTMetadata keeps refreshed attributes apart.
 	Attributes NFTAttributes
 }
 
+// NFTMetadata holds the attributes of an NFT read from its contract (on-chain) separately
+// from those of the document its token URI points to (off-chain). Documents embedded in
+// data URIs are on-chain.
+type NFTMetadata struct {
+	NFT                NFT
+	TokenURI           string
+	OnchainAttributes  NFTAttributes
+	OnchainFetchTime   time.Time
+	OffchainAttributes NFTAttributes
+	OffchainFetchTime  time.Time
+	// ResolvedURL is the gateway url the off-chain document was fetched from.
+	ResolvedURL string
+	FetchError  string
+	// RarityScore is the sum of the scores of the NFT's traits, RarityRank its rank in the class.
+	RarityScore float64
+	RarityRank  int
+}
+
+// NFTTraitRarity is the rarity of a trait value within an NFT class. Score is the
+// number of NFTs in the class divided by the number of NFTs with the trait value.
+type NFTTraitRarity struct {
+	NFTClass   NFTClass
+	TraitType  string
+	Value      string
+	Count      int
+	Score      float64
+	UpdateTime time.Time
+}
+
+// NFTValuation is the estimated fair value of an NFT in USD at Time. The true value lies between
+// LowerBound and UpperBound with probability Confidence. Comparables is the number of sales the
+// estimate is based on, FloorPrice the floor of the NFT's class at Time.
+type NFTValuation struct {
+	NFT         NFT
+	Value       float64
+	LowerBound  float64
+	UpperBound  float64
+	Confidence  float64
+	FloorPrice  float64
+	Comparables int
+	Time        time.Time
+}
+
 // NFTAttributes can be stored as jasonb in postgres:
 // https://www.alexedwards.net/blog/using-postgresql-jsonb
 type NFTAttributes map[string]interface{}
@@ -92,8 +136,11 @@ func (n *NFT) UnmarshalBinary(data []byte) error {
 	return nil
 }
 
+// NFTTrade is the sale of @Quantity units of an NFT. Price and PriceUSD are per unit, such that
+// sales of several units of an ERC-1155 token are comparable to ERC721 sales.
 type NFTTrade struct {
 	NFT              NFT
+	Quantity         uint64
 	Price            *big.Int
 	PriceUSD         float64
 	FromAddress      string
@@ -185,6 +232,55 @@ func (no *NFTOffer) UnmarshalBinary(data []byte) error {
 	return nil
 }
 
+const (
+	NFTBidSide   = "bid"
+	NFTOfferSide = "offer"
+
+	NFTOrderCancelled = "cancelled"
+	NFTOrderFilled    = "filled"
+)
+
+// NFTOrderClose ends the bids or offers on an NFT which were placed on the marketplace Exchange
+// before it, either because they were withdrawn (Reason NFTOrderCancelled) or the NFT was sold
+// (NFTOrderFilled). If FromAddress is set, only orders placed by that address are closed.
+type NFTOrderClose struct {
+	NFT         NFT
+	Side        string
+	Reason      string
+	FromAddress string
+
+	BlockNumber   uint64
+	BlockPosition uint64
+	Timestamp     time.Time
+	TxHash        string
+	Exchange      string
+}
+
+// NFTOrderbook holds the bids and offers on the NFTs of a class which are active at Time.
+type NFTOrderbook struct {
+	NFTClass NFTClass
+	Bids     []NFTBid
+	Offers   []NFTOffer
+	Time     time.Time
+}
+
+// NFTBidAsk is the best active bid and offer on an NFT, or on any NFT of a class if TokenID is empty.
+// Prices are in units of CurrencySymbol and zero if there is no active order on the respective side.
+// Spread is BestAsk-BestBid if there are orders on both sides.
+type NFTBidAsk struct {
+	NFTClass       NFTClass
+	TokenID        string
+	BestBid        float64
+	BestBidTokenID string
+	BestAsk        float64
+	BestAskTokenID string
+	Spread         float64
+	CurrencySymbol string
+	ActiveBids     int
+	ActiveOffers   int
+	Time           time.Time
+}
+
 // BlockData stores information on a specific block in a given blockchain.
 type BlockData struct {
 	// Name of the blockchain, as found for instance in dia.ETHEREUM
@@ -276,11 +372,16 @@ type OptionOrderbookDatum struct {
 	BidSize         float64
 	StrikePrice     float64
 	ExpirationTime  time.Time
+	// OpenInterest is the number of open contracts, zero if not provided by the exchange.
+	OpenInterest float64
 }
 
 type OptionMeta struct {
 	InstrumentName string
 	BaseCurrency   string
+	// QuoteCurrency is the currency option prices are quoted in. Empty for options quoted in
+	// BaseCurrency, as on Deribit and OKEx.
+	QuoteCurrency  string `json:",omitempty"`
 	ExpirationTime time.Time
 	StrikePrice    float64
 	OptionType     OptionType
@@ -291,6 +392,110 @@ type OptionMetaIndex struct {
 	OptionOrderbookDatum
 }
 
+// OptionGreeks are the implied volatility and greeks of an option, derived from the mid price of its
+// order book. MarkPrice and UnderlyingPrice are in USD. Vega is per volatility point and Theta per day.
+type OptionGreeks struct {
+	InstrumentName    string
+	Underlying        string
+	OptionType        OptionType
+	StrikePrice       float64
+	ExpirationTime    time.Time
+	UnderlyingPrice   float64
+	MarkPrice         float64
+	ImpliedVolatility float64
+	Delta             float64
+	Gamma             float64
+	Vega              float64
+	Theta             float64
+	OpenInterest      float64
+	Time              time.Time
+}
+
+// OptionExpirySummary aggregates the open interest of all options on an underlying expiring at
+// the same time. PutCallRatio is the ratio of put to call open interest and MaxPain the strike
+// at which the options' holders lose the most at expiry.
+type OptionExpirySummary struct {
+	Underlying       string
+	ExpirationTime   time.Time
+	CallOpenInterest float64
+	PutOpenInterest  float64
+	PutCallRatio     float64
+	MaxPain          float64
+	Time             time.Time
+}
+
+// OptionStyle is the exercise style of an option.
+type OptionStyle string
+
+// OptionSettlementStatus is the settlement status of an on-chain option series.
+type OptionSettlementStatus string
+
+const (
+	// EuropeanOption can only be exercised at expiry.
+	EuropeanOption OptionStyle = "european"
+	// AmericanOption can be exercised at any time until expiry.
+	AmericanOption OptionStyle = "american"
+
+	// OptionActive series can be traded.
+	OptionActive OptionSettlementStatus = "active"
+	// OptionExpired series are past their expiry and await their settlement price.
+	OptionExpired OptionSettlementStatus = "expired"
+	// OptionSettled series are expired and can be redeemed for their payout.
+	OptionSettled OptionSettlementStatus = "settled"
+)
+
+// OptionAsset is a token referenced by an on-chain option.
+type OptionAsset struct {
+	Symbol   string
+	Address  string
+	Decimals uint8
+}
+
+// OnchainOption is an option series issued by a smart contract protocol such as Opyn or Premia.
+// InstrumentName is the name of the series in OptionMeta and order book data. StrikePrice is in
+// units of StrikeAsset, OpenInterest is the number of options written. Payout is the amount of
+// Collateral paid per option after settlement.
+type OnchainOption struct {
+	Protocol       string
+	InstrumentName string
+	Underlying     OptionAsset
+	StrikeAsset    OptionAsset
+	Collateral     OptionAsset
+	OptionType     OptionType
+	Style          OptionStyle
+	StrikePrice    float64
+	ExpirationTime time.Time
+	Status         OptionSettlementStatus
+	OpenInterest   float64
+	Exercised      float64
+	Payout         float64
+	LastUpdate     time.Time
+}
+
+// Kinds of OptionExercise.
+const (
+	OptionExerciseKind   = "exercise" // holder exercises an american option
+	OptionRedeemKind     = "redeem"   // holder redeems a settled option for its payout
+	OptionSettleKind     = "settle"   // writer settles a vault after expiry
+	OptionWithdrawalKind = "withdraw" // writer withdraws the remaining collateral after expiry
+)
+
+// OptionExercise is an exercise or settlement event of an on-chain option series. Amount is the
+// number of options and Payout the amount of collateral paid, zero if unknown. LogIndex is the
+// index of the event's log in its block.
+type OptionExercise struct {
+	Protocol       string
+	InstrumentName string
+	Kind           string
+	Account        string
+	Amount         float64
+	Payout         float64
+	TxHash         string
+	BlockNumber    uint64
+	LogIndex       uint
+	Time           time.Time
+}
+
 type OptionMetaForward struct {
 	GeneralizedInstrumentName string
 	StrikePrice               float64
@@ -299,6 +504,41 @@ type OptionMetaForward struct {
 	ExpirationTime            time.Time
 }
 
+// FuturesTrade is a trade on a futures market. Underlying is the symbol of the underlying asset.
+// Volume is the traded amount of the underlying, negative for sells. Perpetual futures have no Expiry.
+type FuturesTrade struct {
+	Exchange       string
+	Market         string
+	Underlying     string
+	Price          float64
+	Volume         float64
+	Time           time.Time
+	ForeignTradeID string
+	Perpetual      bool
+	Expiry         time.Time
+}
+
+// FundingRate is the funding rate of a perpetual futures market. Rate is the fraction paid by
+// long to short positions per Interval at FundingTime. Time is the observation time.
+type FundingRate struct {
+	Exchange    string
+	Market      string
+	Underlying  string
+	Rate        float64
+	Interval    time.Duration
+	FundingTime time.Time
+	Time        time.Time
+}
+
+// OpenInterest is the number of outstanding contracts of a futures market.
+type OpenInterest struct {
+	Exchange   string
+	Market     string
+	Underlying string
+	Value      float64
+	Time       time.Time
+}
+
 type CviDataPoint struct {
 	Timestamp time.Time
 	Value     float64
@@ -326,6 +566,53 @@ type DefiRate struct {
 	Protocol      string
 }
 
+// DefiMarketState is the state of a single market of a lending protocol.
+// Amounts are in USD, Utilisation, CollateralFactor and LiquidationThreshold are fractions.
+type DefiMarketState struct {
+	Timestamp            time.Time
+	Protocol             string
+	Asset                string
+	SuppliedUSD          float64
+	BorrowedUSD          float64
+	ReservesUSD          float64
+	Utilisation          float64
+	CollateralFactor     float64
+	LiquidationThreshold float64
+}
+
+// Pillar is a money market rate in percent with simple compounding over Days calendar days,
+// such as an overnight fixing or a compounded average. Source describes its origin.
+type Pillar struct {
+	Days   int
+	Rate   float64
+	Source string
+}
+
+// CurvePoint is the term structure at a single tenor.
+// ZeroRate is the continuously compounded zero rate in percent (ACT/365).
+// ForwardRate is the simple forward rate in percent in the day count convention of the curve,
+// for the period from the previous tenor (or the curve date) to the tenor.
+// Extrapolated is true for tenors beyond the longest pillar.
+type CurvePoint struct {
+	Tenor          string
+	Maturity       time.Time
+	Days           int
+	DiscountFactor float64
+	ZeroRate       float64
+	ForwardRate    float64
+	Extrapolated   bool
+}
+
+// TermStructure is the curve of a benchmark rate at Date bootstrapped from Pillars, as built by
+// the rate derivatives package.
+type TermStructure struct {
+	Symbol      string
+	Date        time.Time
+	DaysPerYear int
+	Pillars     []Pillar
+	Points      []CurvePoint
+}
+
 type TradesBlockData struct {
 	BeginTime    time.Time
 	EndTime      time.Time
@@ -448,8 +735,18 @@ func (e *FiltersBlock) MarshalBinary() ([]byte, error) {
 	return json.Marshal(e)
 }
 
-// UnmarshalBinary -
+// UnmarshalBinary decodes JSON as well as schema-versioned binary messages, see wire.go.
 func (e *FiltersBlock) UnmarshalBinary(data []byte) error {
+	return unmarshalVersioned(data, e)
+}
+
+// MarshalBinary -
+func (e *OptionOrderbookDatum) MarshalBinary() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// UnmarshalBinary -
+func (e *OptionOrderbookDatum) UnmarshalBinary(data []byte) error {
 	if err := json.Unmarshal(data, &e); err != nil {
 		return err
 	}
@@ -457,12 +754,12 @@ func (e *FiltersBlock) UnmarshalBinary(data []byte) error {
 }
 
 // MarshalBinary -
-func (e *Trade) MarshalBinary() ([]byte, error) {
+func (e *FuturesTrade) MarshalBinary() ([]byte, error) {
 	return json.Marshal(e)
 }
 
 // UnmarshalBinary -
-func (e *Trade) UnmarshalBinary(data []byte) error {
+func (e *FuturesTrade) UnmarshalBinary(data []byte) error {
 	if err := json.Unmarshal(data, &e); err != nil {
 		return err
 	}
@@ -470,18 +767,51 @@ func (e *Trade) UnmarshalBinary(data []byte) error {
 }
 
 // MarshalBinary -
-func (e *TradesBlock) MarshalBinary() ([]byte, error) {
+func (e *FundingRate) MarshalBinary() ([]byte, error) {
 	return json.Marshal(e)
 }
 
 // UnmarshalBinary -
-func (e *TradesBlock) UnmarshalBinary(data []byte) error {
+func (e *FundingRate) UnmarshalBinary(data []byte) error {
+	if err := json.Unmarshal(data, &e); err != nil {
+		return err
+	}
+	return nil
+}
+
+// MarshalBinary -
+func (e *OpenInterest) MarshalBinary() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// UnmarshalBinary -
+func (e *OpenInterest) UnmarshalBinary(data []byte) error {
 	if err := json.Unmarshal(data, &e); err != nil {
 		return err
 	}
 	return nil
 }
 
+// MarshalBinary -
+func (e *Trade) MarshalBinary() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// UnmarshalBinary decodes JSON as well as schema-versioned binary messages, see wire.go.
+func (e *Trade) UnmarshalBinary(data []byte) error {
+	return unmarshalVersioned(data, e)
+}
+
+// MarshalBinary -
+func (e *TradesBlock) MarshalBinary() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// UnmarshalBinary decodes JSON as well as schema-versioned binary messages, see wire.go.
+func (e *TradesBlock) UnmarshalBinary(data []byte) error {
+	return unmarshalVersioned(data, e)
+}
+
 // MarshalBinary -
 func (e *Supply) MarshalBinary() ([]byte, error) {
 	return json.Marshal(e)
@@ -521,6 +851,16 @@ func (e *ItinToken) UnmarshalBinary(data []byte) error {
 	return nil
 }
 
+// MarshalBinary -
+func (e *OnchainOption) MarshalBinary() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// UnmarshalBinary -
+func (e *OnchainOption) UnmarshalBinary(data []byte) error {
+	return json.Unmarshal(data, e)
+}
+
 func (e *OptionMeta) MarshalBinary() ([]byte, error) {
 	basicOptionMeta := struct {
 		InstrumentName string     `json:"instrumentname"`
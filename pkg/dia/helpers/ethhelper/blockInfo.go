@@ -6,6 +6,7 @@ import (
 
 	"github.com/diadata-org/diadata/pkg/dia"
 	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/fatih/structs"
 )
@@ -56,3 +57,17 @@ func GetBlockDataOnChain(blockNumber int64, client *ethclient.Client) (dia.Block
 	return blockdata, nil
 
 }
+
+// FilterRange calls @filter with the block range from @start to @end. As long as the node refuses
+// the query for returning too many results, the range is split in halves.
+func FilterRange(start uint64, end uint64, filter func(opts *bind.FilterOpts) error) error {
+	err := filter(&bind.FilterOpts{Start: start, End: &end})
+	if err != nil && err.Error() == "query returned more than 10000 results" && end > start {
+		mid := start + (end-start)/2
+		if err = FilterRange(start, mid, filter); err != nil {
+			return err
+		}
+		return FilterRange(mid+1, end, filter)
+	}
+	return err
+}
@@ -0,0 +1,107 @@
+package ethhelper
+
+import (
+	"errors"
+	"os"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// EVMChain holds the parameters of an Ethereum compatible blockchain.
+type EVMChain struct {
+	Blockchain     string
+	NativeSymbol   string
+	NativeDecimals int
+	// WrappedNative is the symbol of the ERC-20 token wrapping the native currency.
+	WrappedNative string
+	// BlockDelay is the number of blocks scrapers stay behind the head to avoid reorgs.
+	BlockDelay int
+	// NodeEnv is the environment variable holding the node url, DefaultNode is used if it is not set.
+	NodeEnv     string
+	DefaultNode string
+}
+
+// EVMChains are the supported Ethereum compatible chains.
+var EVMChains = map[string]EVMChain{
+	dia.ETHEREUM: {
+		Blockchain:     dia.ETHEREUM,
+		NativeSymbol:   "ETH",
+		NativeDecimals: 18,
+		WrappedNative:  "WETH",
+		BlockDelay:     8,
+		NodeEnv:        "ETHEREUM_NODE",
+		DefaultNode:    "https://eth-mainnet.alchemyapi.io/v2/v1bo6tRKiraJ71BVGKmCtWVedAzzNTd6",
+	},
+	dia.POLYGON: {
+		Blockchain:     dia.POLYGON,
+		NativeSymbol:   "MATIC",
+		NativeDecimals: 18,
+		WrappedNative:  "WMATIC",
+		BlockDelay:     64,
+		NodeEnv:        "POLYGON_NODE",
+		DefaultNode:    "https://polygon-rpc.com",
+	},
+	dia.BINANCESMARTCHAIN: {
+		Blockchain:     dia.BINANCESMARTCHAIN,
+		NativeSymbol:   "BNB",
+		NativeDecimals: 18,
+		WrappedNative:  "WBNB",
+		BlockDelay:     15,
+		NodeEnv:        "BSC_NODE",
+		DefaultNode:    "https://bsc-dataseed.binance.org",
+	},
+}
+
+// EVMClient is a connection to an Ethereum compatible chain. As it embeds the ethclient, it can be
+// used as backend of contract bindings.
+type EVMClient struct {
+	*ethclient.Client
+	chain EVMChain
+}
+
+// NewEVMClient dials a node of the Ethereum compatible @blockchain.
+func NewEVMClient(blockchain string) (*EVMClient, error) {
+	chain, ok := EVMChains[blockchain]
+	if !ok {
+		return nil, errors.New("unsupported blockchain: " + blockchain)
+	}
+	node := os.Getenv(chain.NodeEnv)
+	if node == "" {
+		node = chain.DefaultNode
+	}
+	connection, err := ethclient.Dial(node)
+	if err != nil {
+		return nil, err
+	}
+	return &EVMClient{Client: connection, chain: chain}, nil
+}
+
+// WrapEVMClient wraps an existing @connection to the Ethereum compatible @blockchain.
+func WrapEVMClient(connection *ethclient.Client, blockchain string) (*EVMClient, error) {
+	chain, ok := EVMChains[blockchain]
+	if !ok {
+		return nil, errors.New("unsupported blockchain: " + blockchain)
+	}
+	return &EVMClient{Client: connection, chain: chain}, nil
+}
+
+// NewEthereumClient wraps an existing connection to Ethereum.
+func NewEthereumClient(connection *ethclient.Client) *EVMClient {
+	return &EVMClient{Client: connection, chain: EVMChains[dia.ETHEREUM]}
+}
+
+// Chain returns the parameters of the client's chain.
+func (c *EVMClient) Chain() EVMChain {
+	return c.chain
+}
+
+// Blockchain returns the name of the chain as used in dia.NFTClass.
+func (c *EVMClient) Blockchain() string {
+	return c.chain.Blockchain
+}
+
+// NativeCurrency returns the symbol and decimals of the chain's native currency.
+func (c *EVMClient) NativeCurrency() (string, int) {
+	return c.chain.NativeSymbol, c.chain.NativeDecimals
+}
@@ -0,0 +1,87 @@
+package ethhelper
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+func TestEVMChains(t *testing.T) {
+	for blockchain, chain := range EVMChains {
+		if chain.Blockchain != blockchain {
+			t.Errorf("chain %s is registered as %s", chain.Blockchain, blockchain)
+		}
+		if chain.NativeSymbol == "" || chain.WrappedNative != "W"+chain.NativeSymbol || chain.NativeDecimals != 18 {
+			t.Errorf("native currency of %s: %+v", blockchain, chain)
+		}
+		if chain.NodeEnv == "" || chain.DefaultNode == "" || chain.BlockDelay <= 0 {
+			t.Errorf("node of %s: %+v", blockchain, chain)
+		}
+	}
+}
+
+func TestWrapEVMClient(t *testing.T) {
+	for _, blockchain := range []string{dia.ETHEREUM, dia.POLYGON, dia.BINANCESMARTCHAIN} {
+		client, err := WrapEVMClient(nil, blockchain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if client.Blockchain() != blockchain || client.Chain() != EVMChains[blockchain] {
+			t.Errorf("client of %s is on %s", blockchain, client.Blockchain())
+		}
+		if symbol, decimals := client.NativeCurrency(); symbol != EVMChains[blockchain].NativeSymbol || decimals != 18 {
+			t.Errorf("native currency of %s: %s %d", blockchain, symbol, decimals)
+		}
+	}
+	if _, err := WrapEVMClient(nil, dia.FLOW); err == nil {
+		t.Error("expected an error for a chain which is not Ethereum compatible")
+	}
+	if NewEthereumClient(nil).Blockchain() != dia.ETHEREUM {
+		t.Error("ethereum client is not on Ethereum")
+	}
+}
+
+func TestNewEVMClient(t *testing.T) {
+	if _, err := NewEVMClient(dia.FLOW); err == nil {
+		t.Error("expected an error for a chain which is not Ethereum compatible")
+	}
+
+	// the node is taken from the chain's environment variable
+	var calls int
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Method != "eth_blockNumber" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":` + string(request.ID) + `,"result":"0x2a"}`))
+	}))
+	defer node.Close()
+
+	nodeEnv := EVMChains[dia.POLYGON].NodeEnv
+	defer os.Setenv(nodeEnv, os.Getenv(nodeEnv))
+	os.Setenv(nodeEnv, node.URL)
+
+	client, err := NewEVMClient(dia.POLYGON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	blockNumber, err := client.BlockNumber(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blockNumber != 42 || calls != 1 || client.Blockchain() != dia.POLYGON {
+		t.Errorf("block %d from %d calls on %s", blockNumber, calls, client.Blockchain())
+	}
+}
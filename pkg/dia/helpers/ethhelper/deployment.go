@@ -0,0 +1,44 @@
+package ethhelper
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// CodeReader returns the code of a contract at a block, as ethclient.Client does.
+type CodeReader interface {
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+}
+
+// DeploymentBlock returns the first block up to @head in which @address has code. It performs a
+// binary search over the blocks and hence requires an archive node.
+func DeploymentBlock(ctx context.Context, client CodeReader, address common.Address, head uint64) (uint64, error) {
+	hasCode := func(blockNumber uint64) (bool, error) {
+		code, err := client.CodeAt(ctx, address, new(big.Int).SetUint64(blockNumber))
+		return len(code) > 0, err
+	}
+	ok, err := hasCode(head)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, errors.New("no contract at " + address.Hex())
+	}
+	low, high := uint64(0), head
+	for low < high {
+		mid := low + (high-low)/2
+		ok, err := hasCode(mid)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			high = mid
+		} else {
+			low = mid + 1
+		}
+	}
+	return low, nil
+}
@@ -0,0 +1,36 @@
+package ethhelper
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// testChain has code at the contract address from block deployed on.
+type testChain struct {
+	deployed uint64
+}
+
+func (c testChain) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	if blockNumber.Uint64() < c.deployed {
+		return nil, nil
+	}
+	return []byte{0x60, 0x80}, nil
+}
+
+func TestDeploymentBlock(t *testing.T) {
+	for _, deployed := range []uint64{0, 1, 12287507, 13000000} {
+		block, err := DeploymentBlock(context.Background(), testChain{deployed: deployed}, common.Address{}, 13000000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if block != deployed {
+			t.Errorf("deployment block %d, expected %d", block, deployed)
+		}
+	}
+	if _, err := DeploymentBlock(context.Background(), testChain{deployed: 13000001}, common.Address{}, 13000000); err == nil {
+		t.Error("expected an error for an address without code")
+	}
+}
@@ -5,8 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"net"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/diadata-org/diadata/pkg/dia"
@@ -26,6 +27,11 @@ type KafkaMessageWithAHash interface {
 	Hash() string
 }
 
+// KafkaMessageWithAVersion is a message which can be encoded in several schema versions, see dia.MarshalVersioned.
+type KafkaMessageWithAVersion interface {
+	MarshalVersion(version int) ([]byte, error)
+}
+
 const (
 	TopicIndexBlock      = 0
 	TopicFiltersBlock    = 1
@@ -34,13 +40,20 @@ const (
 	TopicSuppliesBlock   = 7
 	TopicIndexBlock2     = 8
 	TopicIndexBlockDaily = 11
+	TopicOptionOrderBook = 13
+	TopicFuturesTrades   = 14
 	retryDelay           = 2 * time.Second
-	TopicOptionOrderBook          = 13
-
+	defaultBroker        = "kafka0:9094"
+	// commitInterval is the interval at which group readers commit the offsets of consumed messages.
+	commitInterval = time.Second
 )
 
 type Config struct {
-	KafkaUrl []string
+	KafkaUrl          []string
+	ReplicationFactor int
+	// MessageVersion is the schema version in which versioned messages are written.
+	// Consumers decode all versions, so it should only be raised once all consumers are upgraded.
+	MessageVersion int
 }
 
 var KafkaConfig Config
@@ -50,110 +63,177 @@ func GetTopic(topic int) string {
 }
 
 func getTopic(topic int) string {
-	topicMap := map[int]string{
-		1: "filtersBlock",
-		2: "trades",
-		3: "tradesBlock",
-	}
-	result, ok := topicMap[topic]
+	result, ok := topics[topic]
 	if !ok {
-		log.Error("getTopic cant fine topic", topic)
+		log.Error("getTopic cant find topic ", topic)
 	}
-	return result
+	return result.Name
 }
 
+// init reads the brokers from the comma separated list in KAFKA_BROKERS. Further brokers
+// of the cluster are discovered by the client, so a single reachable broker suffices.
 func init() {
+	KafkaConfig.ReplicationFactor = 1
+	if rf, err := strconv.Atoi(os.Getenv("KAFKA_REPLICATION_FACTOR")); err == nil {
+		KafkaConfig.ReplicationFactor = rf
+	}
+	KafkaConfig.MessageVersion = dia.WireVersionJSON
+	if version, err := strconv.Atoi(os.Getenv("KAFKA_MESSAGE_VERSION")); err == nil {
+		KafkaConfig.MessageVersion = version
+	}
+	if brokers := os.Getenv("KAFKA_BROKERS"); brokers != "" {
+		SetBrokers(strings.Split(brokers, ","))
+	} else if os.Getenv("LOCALHOST_KAFKA") != "" {
+		log.Println("LOCALHOST_KAFKA is set, Adding localhost, probably runned outside of kafka")
+		SetBrokers([]string{"localhost:9094"})
+	} else {
+		SetBrokers([]string{defaultBroker})
+	}
+	log.Printf("brokers: %v", KafkaConfig.KafkaUrl)
+}
+
+// SetBrokers sets the addresses of the kafka brokers used by all readers and writers
+// created afterwards.
+func SetBrokers(brokers []string) {
 	KafkaConfig.KafkaUrl = []string{}
-	var kafkaCount = 0
-	for {
-		kafkaName := fmt.Sprintf("kafka%d", kafkaCount)
-		ip, err := net.LookupIP(kafkaName)
-		if err != nil {
-			log.Println("found", kafkaCount, "kafkas by name resolution")
-			break
-		} else {
-			log.Println(kafkaName, " -> ", ip)
-			kafkaName = fmt.Sprintf("kafka%d:9094", kafkaCount)
-			KafkaConfig.KafkaUrl = append(KafkaConfig.KafkaUrl, kafkaName)
-			kafkaCount++
+	for _, broker := range brokers {
+		if broker = strings.TrimSpace(broker); broker != "" {
+			KafkaConfig.KafkaUrl = append(KafkaConfig.KafkaUrl, broker)
 		}
 	}
-	if kafkaCount == 0 {
-		l := os.Getenv("LOCALHOST_KAFKA")
-		if l == "" {
-			log.Warning("could not find the kafka0 names, using kafka0")
-			KafkaConfig.KafkaUrl = []string{"kafka0:9094"}
-		} else {
-			log.Println("LOCALHOST_KAFKA is set, Adding localhost, probably runned outside of kafka")
-			KafkaConfig.KafkaUrl = []string{"localhost:9094"}
-		}
+}
+
+// MessageKey returns the partition key of @m. Trades and supplies are keyed by symbol and
+// option orders by instrument, such that all messages on an asset are consumed in order by
+// the same member of a consumer group. Messages with a hash are keyed by it, all others
+// are distributed round robin.
+func MessageKey(m KafkaMessage) []byte {
+	switch e := m.(type) {
+	case *dia.Trade:
+		return []byte(e.Symbol)
+	case *dia.Supply:
+		return []byte(e.Symbol)
+	case *dia.OptionOrderbookDatum:
+		return []byte(e.InstrumentName)
+	case *dia.FuturesTrade:
+		return []byte(e.Exchange + e.Market)
+	case KafkaMessageWithAHash:
+		return []byte(e.Hash())
 	}
-	log.Printf("brokers: %v", KafkaConfig.KafkaUrl)
+	return nil
 }
 
-// WithRetryOnError
+// ReadOffset returns the offset following the last message in partition 0 of @topic.
 func ReadOffset(topic int) (int64, error) {
-	var err error
+	return ReadPartitionOffset(topic, 0)
+}
+
+// ReadPartitionOffset returns the offset following the last message in @partition of @topic.
+func ReadPartitionOffset(topic int, partition int) (offset int64, err error) {
 	for _, ip := range KafkaConfig.KafkaUrl {
-		conn, err := kafka.DialLeader(context.Background(), "tcp", ip, getTopic(topic), 0)
+		var conn *kafka.Conn
+		conn, err = kafka.DialLeader(context.Background(), "tcp", ip, getTopic(topic), partition)
 		if err != nil {
 			log.Errorln("ReadOffset conn error: <", err, "> ", ip)
-		} else {
-			defer conn.Close()
-			offset, err := conn.ReadLastOffset()
-			if err != nil {
-				log.Errorln("ReadOffset ReadLastOffset error: <", err, "> ")
-			} else {
-				return offset, nil
-			}
+			continue
+		}
+		offset, err = conn.ReadLastOffset()
+		conn.Close()
+		if err != nil {
+			log.Errorln("ReadOffset ReadLastOffset error: <", err, "> ")
+			continue
 		}
+		return offset, nil
 	}
 	return 0, err
 }
 
 func ReadOffsetWithRetryOnError(topic int) (offset int64) {
 	for {
-		for {
-			for _, ip := range KafkaConfig.KafkaUrl {
-				conn, err := kafka.DialLeader(context.Background(), "tcp", ip, getTopic(topic), 0)
-				if err != nil {
-					log.Errorln("ReadOffsetWithRetryOnError conn error: <", err, "> ", ip, " topic:", topic)
-					time.Sleep(retryDelay)
-				} else {
-					defer conn.Close()
-					offset, err = conn.ReadLastOffset()
-					if err != nil {
-						log.Errorln("ReadOffsetWithRetryOnError ReadLastOffset error: <", err, "> ", ip, " topic:", topic)
-						time.Sleep(retryDelay)
-					} else {
-						return offset
-					}
-				}
-			}
+		offset, err := ReadOffset(topic)
+		if err == nil {
+			return offset
 		}
-		log.Println("ReadOffsetWithRetryOnError retrying...")
+		log.Println("ReadOffsetWithRetryOnError retrying on topic", topic)
+		time.Sleep(retryDelay)
 	}
 }
 
+// ensureTopic creates @topic with the number of partitions from the registry unless it exists,
+// such that it is not auto-created by the broker with its default number of partitions.
+func ensureTopic(topic int) {
+	if err := CreateTopics(topic); err != nil {
+		log.Errorf("error creating topic %s: %v", getTopic(topic), err)
+	}
+}
+
+// NewWriter returns an asynchronous writer which partitions messages by MessageKey.
 func NewWriter(topic int) *kafka.Writer {
+	ensureTopic(topic)
 	return kafka.NewWriter(kafka.WriterConfig{
 		Brokers:  KafkaConfig.KafkaUrl,
 		Topic:    getTopic(topic),
-		Balancer: &kafka.LeastBytes{},
+		Balancer: &kafka.Hash{},
 		Async:    true,
 	})
 }
 
+// NewSyncWriter returns a synchronous writer which partitions messages by MessageKey.
 func NewSyncWriter(topic int) *kafka.Writer {
+	ensureTopic(topic)
 	return kafka.NewWriter(kafka.WriterConfig{
 		Brokers:    KafkaConfig.KafkaUrl,
 		Topic:      getTopic(topic),
-		Balancer:   &kafka.LeastBytes{},
+		Balancer:   &kafka.Hash{},
 		Async:      false,
 		BatchBytes: 1e9, // 1GB
 	})
 }
 
+// NewGroupReader returns a reader which consumes all partitions of @topic assigned to it as member
+// of the consumer group @groupID. Offsets marked by ConsumeMessages are committed to kafka every
+// commitInterval, such that a restarted consumer continues where the group left off, apart from
+// the messages of the last interval which are consumed again. A group without committed offsets
+// starts at the end of the topic.
+func NewGroupReader(topic int, groupID string) *kafka.Reader {
+	ensureTopic(topic)
+	return kafka.NewReader(kafka.ReaderConfig{
+		Brokers:               KafkaConfig.KafkaUrl,
+		GroupID:               groupID,
+		Topic:                 getTopic(topic),
+		MinBytes:              0,
+		MaxBytes:              10e6, // 10MB
+		StartOffset:           kafka.LastOffset,
+		CommitInterval:        commitInterval,
+		WatchPartitionChanges: true,
+	})
+}
+
+// ConsumeMessages passes each message read by the group reader @r to @handle and marks its offset
+// for the next periodic commit once @handle returns. Messages failing to be handled are logged and
+// marked as well, so that a malformed message cannot block its partition. Returns when @ctx is done.
+func ConsumeMessages(ctx context.Context, r *kafka.Reader, handle func(m kafka.Message) error) error {
+	for {
+		m, err := r.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			log.Errorln("ConsumeMessages fetch error:", err)
+			time.Sleep(retryDelay)
+			continue
+		}
+		if err := handle(m); err != nil {
+			log.Errorf("ConsumeMessages: error handling message at partition %d offset %d: %v", m.Partition, m.Offset, err)
+		}
+		if err := r.CommitMessages(ctx, m); err != nil {
+			log.Errorln("ConsumeMessages commit error:", err)
+		}
+	}
+}
+
+// NewReader returns a reader of partition 0 of @topic with manually set offsets.
+// It is meant for single-partition topics such as blocks, use NewGroupReader otherwise.
 func NewReader(topic int) *kafka.Reader {
 	r := kafka.NewReader(kafka.ReaderConfig{
 		Brokers:   KafkaConfig.KafkaUrl,
@@ -165,11 +245,35 @@ func NewReader(topic int) *kafka.Reader {
 	return r
 }
 
+// NewTailReader returns a reader of @partition of @topic starting at the end of the partition.
+// It is meant for consumers which fan out live messages and keep no offsets, such as the streaming API.
+func NewTailReader(topic int, partition int) *kafka.Reader {
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   KafkaConfig.KafkaUrl,
+		Topic:     getTopic(topic),
+		Partition: partition,
+		MinBytes:  0,
+		MaxBytes:  10e6, // 10MB
+	})
+	err := r.SetOffset(kafka.LastOffset)
+	if err != nil {
+		log.Errorln("NewTailReader: error setting offset:", err)
+	}
+	return r
+}
+
+// WriteMessage writes @m keyed by MessageKey. Versioned messages are encoded in KafkaConfig.MessageVersion.
 func WriteMessage(w *kafka.Writer, m KafkaMessage) error {
-	key := []byte("helloKafka")
-	value, err := m.MarshalBinary()
+	key := MessageKey(m)
+	var value []byte
+	var err error
+	if vm, ok := m.(KafkaMessageWithAVersion); ok {
+		value, err = vm.MarshalVersion(KafkaConfig.MessageVersion)
+	} else {
+		value, err = m.MarshalBinary()
+	}
 	if err == nil && value != nil {
-		err := w.WriteMessages(context.Background(),
+		err = w.WriteMessages(context.Background(),
 			kafka.Message{
 				Key:   key,
 				Value: value,
@@ -252,18 +356,25 @@ func GetLastElement(topic int) (interface{}, error) {
 	}
 }
 
+// GetElements returns @nbElements messages of partition 0 of @topic starting at @offset.
 func GetElements(topic int, offset int64, nbElements int) ([]interface{}, error) {
+	return GetPartitionElements(topic, 0, offset, nbElements)
+}
+
+// GetPartitionElements returns @nbElements messages of @partition of @topic starting at @offset.
+func GetPartitionElements(topic int, partition int, offset int64, nbElements int) ([]interface{}, error) {
 
 	var result []interface{}
 
 	var maxOffset = offset + int64(nbElements)
 
-	conn, err := kafka.DialLeader(context.Background(), "tcp", KafkaConfig.KafkaUrl[0], getTopic(topic), 0)
+	conn, err := kafka.DialLeader(context.Background(), "tcp", KafkaConfig.KafkaUrl[0], getTopic(topic), partition)
 
 	if err != nil {
 		log.Errorln("kafka error:", err)
 		return nil, err
 	} else {
+		defer conn.Close()
 
 		newSeek, err := conn.Seek(int64(offset), kafka.SeekAbsolute)
 
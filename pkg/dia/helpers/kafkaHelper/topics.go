@@ -0,0 +1,117 @@
+package kafkaHelper
+
+import (
+	"errors"
+	"net"
+	"sort"
+	"strconv"
+
+	"github.com/segmentio/kafka-go"
+	log "github.com/sirupsen/logrus"
+)
+
+// Topic describes a kafka topic used by our services.
+type Topic struct {
+	Name string
+	// Partitions is the number of partitions the topic is created with. Topics whose messages
+	// must be consumed in order, such as blocks, have a single partition. On topics with
+	// several partitions, messages are distributed by their key, see MessageKey.
+	Partitions int
+}
+
+// topics is the registry of all topics. Topic IDs are kept stable as they are used in configs.
+var topics = map[int]Topic{
+	TopicIndexBlock:      {Name: "indexBlock", Partitions: 1},
+	TopicFiltersBlock:    {Name: "filtersBlock", Partitions: 1},
+	TopicTrades:          {Name: "trades", Partitions: 8},
+	TopicTradesBlock:     {Name: "tradesBlock", Partitions: 1},
+	TopicSuppliesBlock:   {Name: "suppliesBlock", Partitions: 4},
+	TopicIndexBlock2:     {Name: "indexBlock2", Partitions: 1},
+	TopicIndexBlockDaily: {Name: "indexBlockDaily", Partitions: 1},
+	TopicOptionOrderBook: {Name: "optionOrderBook", Partitions: 4},
+	TopicFuturesTrades:   {Name: "futuresTrades", Partitions: 4},
+}
+
+// Topics returns the IDs of all registered topics in ascending order.
+func Topics() []int {
+	var ids []int
+	for id := range topics {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// GetTopicConfig returns the registry entry of @topic.
+func GetTopicConfig(topic int) (Topic, bool) {
+	t, ok := topics[topic]
+	return t, ok
+}
+
+// ReadPartitionCount returns the number of partitions @topic has on the brokers, which can differ
+// from the registry for topics created before it.
+func ReadPartitionCount(topic int) (count int, err error) {
+	for _, ip := range KafkaConfig.KafkaUrl {
+		var conn *kafka.Conn
+		conn, err = kafka.Dial("tcp", ip)
+		if err != nil {
+			log.Errorln("ReadPartitionCount conn error: <", err, "> ", ip)
+			continue
+		}
+		var partitions []kafka.Partition
+		partitions, err = conn.ReadPartitions(getTopic(topic))
+		conn.Close()
+		if err != nil {
+			log.Errorln("ReadPartitionCount ReadPartitions error: <", err, "> ")
+			continue
+		}
+		return len(partitions), nil
+	}
+	if err == nil {
+		err = errors.New("no kafka brokers configured")
+	}
+	return 0, err
+}
+
+// CreateTopics creates @topicIDs, or all registered topics if none are given, with the
+// number of partitions from the registry. Existing topics are left untouched, so partitions
+// of topics created before the registry have to be added with the kafka tools.
+func CreateTopics(topicIDs ...int) error {
+	if len(topicIDs) == 0 {
+		topicIDs = Topics()
+	}
+	if len(KafkaConfig.KafkaUrl) == 0 {
+		return errors.New("no kafka brokers configured")
+	}
+	conn, err := kafka.Dial("tcp", KafkaConfig.KafkaUrl[0])
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Topics must be created on the controller.
+	controller, err := conn.Controller()
+	if err != nil {
+		return err
+	}
+	controllerConn, err := kafka.Dial("tcp", net.JoinHostPort(controller.Host, strconv.Itoa(controller.Port)))
+	if err != nil {
+		return err
+	}
+	defer controllerConn.Close()
+
+	var topicConfigs []kafka.TopicConfig
+	for _, id := range topicIDs {
+		topic, ok := topics[id]
+		if !ok {
+			log.Error("CreateTopics: unknown topic ", id)
+			continue
+		}
+		topicConfigs = append(topicConfigs, kafka.TopicConfig{
+			Topic:             topic.Name,
+			NumPartitions:     topic.Partitions,
+			ReplicationFactor: KafkaConfig.ReplicationFactor,
+		})
+	}
+	return controllerConn.CreateTopics(topicConfigs...)
+}
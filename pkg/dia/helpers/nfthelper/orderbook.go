@@ -0,0 +1,263 @@
+package nfthelper
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+// orderKey identifies the order slot of a token on a marketplace. The supported marketplaces keep at
+// most one bid and one offer per token, such that a new order replaces the previous one.
+type orderKey struct {
+	tokenID  string
+	side     string
+	exchange string
+}
+
+// OrderHistory replays the bids, offers and closes of an NFT class in order to find the orders
+// active at a given time.
+type OrderHistory struct {
+	bids   map[orderKey][]dia.NFTBid
+	offers map[orderKey][]dia.NFTOffer
+	closes map[orderKey][]dia.NFTOrderClose
+}
+
+// NewOrderHistory returns the order history made up of @bids, @offers and @closes.
+func NewOrderHistory(bids []dia.NFTBid, offers []dia.NFTOffer, closes []dia.NFTOrderClose) *OrderHistory {
+	h := &OrderHistory{
+		bids:   make(map[orderKey][]dia.NFTBid),
+		offers: make(map[orderKey][]dia.NFTOffer),
+		closes: make(map[orderKey][]dia.NFTOrderClose),
+	}
+	for _, bid := range bids {
+		key := orderKey{bid.NFT.TokenID, dia.NFTBidSide, bid.Exchange}
+		h.bids[key] = append(h.bids[key], bid)
+	}
+	for _, offer := range offers {
+		key := orderKey{offer.NFT.TokenID, dia.NFTOfferSide, offer.Exchange}
+		h.offers[key] = append(h.offers[key], offer)
+	}
+	for _, c := range closes {
+		key := orderKey{c.NFT.TokenID, c.Side, c.Exchange}
+		h.closes[key] = append(h.closes[key], c)
+	}
+	for _, b := range h.bids {
+		sort.Slice(b, func(i, j int) bool {
+			return before(b[i].BlockNumber, b[i].BlockPosition, b[j].BlockNumber, b[j].BlockPosition)
+		})
+	}
+	for _, o := range h.offers {
+		sort.Slice(o, func(i, j int) bool {
+			return before(o[i].BlockNumber, o[i].BlockPosition, o[j].BlockNumber, o[j].BlockPosition)
+		})
+	}
+	return h
+}
+
+func before(blockNumber, blockPosition, otherNumber, otherPosition uint64) bool {
+	return blockNumber < otherNumber || (blockNumber == otherNumber && blockPosition < otherPosition)
+}
+
+// Orderbook returns the bids and offers on @nftclass active at time @t. An order is active if it is
+// the latest order of its creator's side on the token, was not closed afterwards and, for offers with
+// a Duration, did not expire.
+func (h *OrderHistory) Orderbook(nftclass dia.NFTClass, t time.Time) dia.NFTOrderbook {
+	orderbook := dia.NFTOrderbook{NFTClass: nftclass, Time: t}
+	for key, bids := range h.bids {
+		i := sort.Search(len(bids), func(i int) bool { return bids[i].Timestamp.After(t) }) - 1
+		if i < 0 {
+			continue
+		}
+		bid := bids[i]
+		if h.closed(key, bid.FromAddress, bid.BlockNumber, bid.BlockPosition, t) {
+			continue
+		}
+		orderbook.Bids = append(orderbook.Bids, bid)
+	}
+	for key, offers := range h.offers {
+		i := sort.Search(len(offers), func(i int) bool { return offers[i].Timestamp.After(t) }) - 1
+		if i < 0 {
+			continue
+		}
+		offer := offers[i]
+		if offer.Duration > 0 && !t.Before(offer.Timestamp.Add(offer.Duration*time.Second)) {
+			continue
+		}
+		if h.closed(key, offer.FromAddress, offer.BlockNumber, offer.BlockPosition, t) {
+			continue
+		}
+		orderbook.Offers = append(orderbook.Offers, offer)
+	}
+	sortOrderbook(&orderbook)
+	return orderbook
+}
+
+func sortOrderbook(orderbook *dia.NFTOrderbook) {
+	sort.Slice(orderbook.Bids, func(i, j int) bool { return orderbook.Bids[i].NFT.TokenID < orderbook.Bids[j].NFT.TokenID })
+	sort.Slice(orderbook.Offers, func(i, j int) bool { return orderbook.Offers[i].NFT.TokenID < orderbook.Offers[j].NFT.TokenID })
+}
+
+// closed returns true if the order of @fromAddress in the slot @key at the given block position was
+// closed until time @t.
+func (h *OrderHistory) closed(key orderKey, fromAddress string, blockNumber, blockPosition uint64, t time.Time) bool {
+	for _, c := range h.closes[key] {
+		if !c.Timestamp.After(t) && closes(c, fromAddress, blockNumber, blockPosition) {
+			return true
+		}
+	}
+	return false
+}
+
+// closes returns true if @c closes the order of @fromAddress at the given block position.
+func closes(c dia.NFTOrderClose, fromAddress string, blockNumber, blockPosition uint64) bool {
+	if !before(blockNumber, blockPosition, c.BlockNumber, c.BlockPosition) {
+		return false
+	}
+	return c.FromAddress == "" || strings.EqualFold(c.FromAddress, fromAddress)
+}
+
+// BidPrice returns the value of @bid in units of its currency.
+func BidPrice(bid dia.NFTBid) float64 {
+	return scaleValue(bid.Value, bid.CurrencyDecimals)
+}
+
+// OfferPrice returns the price of @offer at time @t in units of its currency. The price of an auction
+// with EndValue moves linearly from StartValue to EndValue over its Duration.
+func OfferPrice(offer dia.NFTOffer, t time.Time) float64 {
+	start := scaleValue(offer.StartValue, offer.CurrencyDecimals)
+	if offer.EndValue == nil || offer.Duration <= 0 {
+		return start
+	}
+	end := scaleValue(offer.EndValue, offer.CurrencyDecimals)
+	elapsed := t.Sub(offer.Timestamp).Seconds() / (offer.Duration * time.Second).Seconds()
+	if elapsed <= 0 {
+		return start
+	}
+	if elapsed >= 1 {
+		return end
+	}
+	return start + (end-start)*elapsed
+}
+
+func scaleValue(value *big.Int, decimals int32) float64 {
+	if value == nil {
+		return 0
+	}
+	unit := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(value), unit).Float64()
+	return f
+}
+
+// BidAsk returns the best bid and offer in @orderbook on the token @tokenID, or on any token of the
+// class if @tokenID is empty.
+func BidAsk(orderbook dia.NFTOrderbook, tokenID string) dia.NFTBidAsk {
+	bidAsk := dia.NFTBidAsk{NFTClass: orderbook.NFTClass, TokenID: tokenID, Time: orderbook.Time}
+	for _, bid := range orderbook.Bids {
+		if tokenID != "" && bid.NFT.TokenID != tokenID {
+			continue
+		}
+		bidAsk.ActiveBids++
+		if price := BidPrice(bid); price > bidAsk.BestBid {
+			bidAsk.BestBid = price
+			bidAsk.BestBidTokenID = bid.NFT.TokenID
+			bidAsk.CurrencySymbol = bid.CurrencySymbol
+		}
+	}
+	for _, offer := range orderbook.Offers {
+		if tokenID != "" && offer.NFT.TokenID != tokenID {
+			continue
+		}
+		bidAsk.ActiveOffers++
+		if price := OfferPrice(offer, orderbook.Time); price > 0 && (bidAsk.BestAsk == 0 || price < bidAsk.BestAsk) {
+			bidAsk.BestAsk = price
+			bidAsk.BestAskTokenID = offer.NFT.TokenID
+			bidAsk.CurrencySymbol = offer.CurrencySymbol
+		}
+	}
+	if bidAsk.BestBid > 0 && bidAsk.BestAsk > 0 {
+		bidAsk.Spread = bidAsk.BestAsk - bidAsk.BestBid
+	}
+	return bidAsk
+}
+
+// orderEvent is a bid, offer or close in the order history.
+type orderEvent struct {
+	key           orderKey
+	timestamp     time.Time
+	blockNumber   uint64
+	blockPosition uint64
+	bid           *dia.NFTBid
+	offer         *dia.NFTOffer
+	close         *dia.NFTOrderClose
+}
+
+// events returns all bids, offers and closes of the history in the order they were recorded.
+func (h *OrderHistory) events() (events []orderEvent) {
+	for key, bids := range h.bids {
+		for i := range bids {
+			events = append(events, orderEvent{key: key, timestamp: bids[i].Timestamp, blockNumber: bids[i].BlockNumber, blockPosition: bids[i].BlockPosition, bid: &bids[i]})
+		}
+	}
+	for key, offers := range h.offers {
+		for i := range offers {
+			events = append(events, orderEvent{key: key, timestamp: offers[i].Timestamp, blockNumber: offers[i].BlockNumber, blockPosition: offers[i].BlockPosition, offer: &offers[i]})
+		}
+	}
+	for key, closes := range h.closes {
+		for i := range closes {
+			events = append(events, orderEvent{key: key, timestamp: closes[i].Timestamp, blockNumber: closes[i].BlockNumber, blockPosition: closes[i].BlockPosition, close: &closes[i]})
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].timestamp.Equal(events[j].timestamp) {
+			return events[i].timestamp.Before(events[j].timestamp)
+		}
+		return before(events[i].blockNumber, events[i].blockPosition, events[j].blockNumber, events[j].blockPosition)
+	})
+	return
+}
+
+// SpreadSeries returns the best bid and offer on any token of @nftclass every @interval from
+// @starttime to @endtime. The history is replayed once, keeping the active order of each slot.
+func (h *OrderHistory) SpreadSeries(nftclass dia.NFTClass, starttime time.Time, endtime time.Time, interval time.Duration) (series []dia.NFTBidAsk) {
+	events := h.events()
+	bids := make(map[orderKey]dia.NFTBid)
+	offers := make(map[orderKey]dia.NFTOffer)
+	var next int
+	for t := starttime; !t.After(endtime); t = t.Add(interval) {
+		for ; next < len(events) && !events[next].timestamp.After(t); next++ {
+			e := events[next]
+			switch {
+			case e.bid != nil:
+				bids[e.key] = *e.bid
+			case e.offer != nil:
+				offers[e.key] = *e.offer
+			case e.key.side == dia.NFTBidSide:
+				if bid, ok := bids[e.key]; ok && closes(*e.close, bid.FromAddress, bid.BlockNumber, bid.BlockPosition) {
+					delete(bids, e.key)
+				}
+			default:
+				if offer, ok := offers[e.key]; ok && closes(*e.close, offer.FromAddress, offer.BlockNumber, offer.BlockPosition) {
+					delete(offers, e.key)
+				}
+			}
+		}
+
+		orderbook := dia.NFTOrderbook{NFTClass: nftclass, Time: t}
+		for _, bid := range bids {
+			orderbook.Bids = append(orderbook.Bids, bid)
+		}
+		for _, offer := range offers {
+			if offer.Duration > 0 && !t.Before(offer.Timestamp.Add(offer.Duration*time.Second)) {
+				continue
+			}
+			orderbook.Offers = append(orderbook.Offers, offer)
+		}
+		sortOrderbook(&orderbook)
+		series = append(series, BidAsk(orderbook, ""))
+	}
+	return
+}
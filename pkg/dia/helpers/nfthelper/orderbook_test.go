@@ -0,0 +1,70 @@
+package nfthelper
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+func ether(value int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(value), big.NewInt(1e18))
+}
+
+func TestOrderbook(t *testing.T) {
+	t0 := time.Unix(1600000000, 0)
+	at := func(block uint64) time.Time { return t0.Add(time.Duration(block) * time.Minute) }
+	nft := func(id string) dia.NFT { return dia.NFT{TokenID: id} }
+
+	bids := []dia.NFTBid{
+		{NFT: nft("1"), Value: ether(10), FromAddress: "0xa", CurrencyDecimals: 18, BlockNumber: 1, Timestamp: at(1), Exchange: "market"},
+		{NFT: nft("1"), Value: ether(12), FromAddress: "0xb", CurrencyDecimals: 18, BlockNumber: 3, Timestamp: at(3), Exchange: "market"},
+		{NFT: nft("2"), Value: ether(20), FromAddress: "0xc", CurrencyDecimals: 18, BlockNumber: 2, Timestamp: at(2), Exchange: "market"},
+	}
+	offers := []dia.NFTOffer{
+		{NFT: nft("1"), StartValue: ether(15), CurrencyDecimals: 18, BlockNumber: 2, Timestamp: at(2), Exchange: "market"},
+		{NFT: nft("2"), StartValue: ether(40), EndValue: ether(20), Duration: 600, CurrencyDecimals: 18, BlockNumber: 4, Timestamp: at(4), Exchange: "market"},
+	}
+	closes := []dia.NFTOrderClose{
+		// withdrawal of another bidder's bid leaves the bid of 0xb open
+		{NFT: nft("1"), Side: dia.NFTBidSide, FromAddress: "0xa", BlockNumber: 5, Timestamp: at(5), Exchange: "market"},
+		{NFT: nft("2"), Side: dia.NFTBidSide, FromAddress: "0xc", BlockNumber: 6, Timestamp: at(6), Exchange: "market"},
+	}
+	h := NewOrderHistory(bids, offers, closes)
+
+	ob := h.Orderbook(dia.NFTClass{}, at(5))
+	if len(ob.Bids) != 2 || ob.Bids[0].FromAddress != "0xb" || len(ob.Offers) != 2 {
+		t.Errorf("orderbook at block 5: %+v", ob)
+	}
+	bidAsk := BidAsk(ob, "")
+	if bidAsk.BestBid != 20 || bidAsk.BestBidTokenID != "2" || bidAsk.BestAsk != 15 || bidAsk.Spread != -5 {
+		t.Errorf("collection bid ask %+v", bidAsk)
+	}
+	if price := OfferPrice(offers[1], at(9)); price != 30 {
+		t.Errorf("auction price %v", price)
+	}
+
+	// the bid on 2 is withdrawn and its auction expired after 10 minutes
+	ob = h.Orderbook(dia.NFTClass{}, at(14))
+	bidAsk = BidAsk(ob, "2")
+	if bidAsk.ActiveBids != 0 || bidAsk.ActiveOffers != 0 {
+		t.Errorf("token bid ask %+v", bidAsk)
+	}
+	bidAsk = BidAsk(ob, "")
+	if bidAsk.BestBid != 12 || bidAsk.BestAsk != 15 || bidAsk.Spread != 3 {
+		t.Errorf("collection bid ask %+v", bidAsk)
+	}
+
+	series := h.SpreadSeries(dia.NFTClass{}, at(0), at(14), 7*time.Minute)
+	if len(series) != 3 || series[0].ActiveBids != 0 || series[1].ActiveOffers != 2 || series[2].ActiveOffers != 1 {
+		t.Errorf("series %+v", series)
+	}
+	// the replayed series agrees with the orderbooks at every minute
+	series = h.SpreadSeries(dia.NFTClass{}, at(0), at(14), time.Minute)
+	for i, bidAsk := range series {
+		if expected := BidAsk(h.Orderbook(dia.NFTClass{}, at(uint64(i))), ""); bidAsk != expected {
+			t.Errorf("spread at block %d: %+v, expected %+v", i, bidAsk, expected)
+		}
+	}
+}
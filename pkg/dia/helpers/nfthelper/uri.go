@@ -0,0 +1,177 @@
+package nfthelper
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMaxSize = 256 * 1024
+	defaultTimeout = 30 * time.Second
+)
+
+var (
+	// DefaultIPFSGateways are public gateways serving /ipfs/<cid> paths.
+	DefaultIPFSGateways = []string{"https://ipfs.io", "https://cloudflare-ipfs.com", "https://dweb.link"}
+	// DefaultArweaveGateways are public gateways serving /<transaction id> paths.
+	DefaultArweaveGateways = []string{"https://arweave.net"}
+)
+
+// URIResolver fetches the JSON metadata documents token URIs point to. ipfs:// and ar:// URIs are
+// requested from the gateways in the given order until one of them succeeds. data: URIs are decoded
+// without any request.
+type URIResolver struct {
+	IPFSGateways    []string
+	ArweaveGateways []string
+	// MaxSize limits the size of a metadata document, Timeout the duration of each request.
+	MaxSize int
+	Timeout time.Duration
+	Client  *http.Client
+}
+
+// NewURIResolver returns a resolver with the given gateways, falling back to the defaults for empty lists.
+func NewURIResolver(ipfsGateways []string, arweaveGateways []string) *URIResolver {
+	if len(ipfsGateways) == 0 {
+		ipfsGateways = DefaultIPFSGateways
+	}
+	if len(arweaveGateways) == 0 {
+		arweaveGateways = DefaultArweaveGateways
+	}
+	return &URIResolver{
+		IPFSGateways:    ipfsGateways,
+		ArweaveGateways: arweaveGateways,
+		MaxSize:         defaultMaxSize,
+		Timeout:         defaultTimeout,
+		Client:          http.DefaultClient,
+	}
+}
+
+// IsDataURI returns true if @uri embeds its content, such that it is stored on-chain with the token.
+func IsDataURI(uri string) bool {
+	return strings.HasPrefix(strings.TrimSpace(uri), "data:")
+}
+
+// URLs returns the http urls @uri can be fetched from, in the order they should be tried.
+func (r *URIResolver) URLs(uri string) ([]string, error) {
+	uri = strings.TrimSpace(uri)
+	switch {
+	case strings.HasPrefix(uri, "ipfs://"):
+		// both ipfs://<cid>/<path> and the legacy ipfs://ipfs/<cid>/<path> are in use
+		path := strings.TrimPrefix(strings.TrimPrefix(uri, "ipfs://"), "ipfs/")
+		return gatewayURLs(r.IPFSGateways, "/ipfs/"+path), nil
+	case strings.HasPrefix(uri, "ar://"):
+		return gatewayURLs(r.ArweaveGateways, "/"+strings.TrimPrefix(uri, "ar://")), nil
+	case strings.HasPrefix(uri, "http://"), strings.HasPrefix(uri, "https://"):
+		return []string{uri}, nil
+	default:
+		return nil, fmt.Errorf("unsupported uri scheme: %.32s", uri)
+	}
+}
+
+func gatewayURLs(gateways []string, path string) []string {
+	urls := make([]string, len(gateways))
+	for i, gateway := range gateways {
+		urls[i] = strings.TrimSuffix(gateway, "/") + path
+	}
+	return urls
+}
+
+// Resolve returns the JSON document @uri points to and the url it was fetched from.
+// For data URIs the url is empty.
+func (r *URIResolver) Resolve(ctx context.Context, uri string) (map[string]interface{}, string, error) {
+	if IsDataURI(uri) {
+		data, err := DecodeDataURI(uri)
+		if err != nil {
+			return nil, "", err
+		}
+		document, err := r.decode(data)
+		return document, "", err
+	}
+
+	urls, err := r.URLs(uri)
+	if err != nil {
+		return nil, "", err
+	}
+	var errs []string
+	for _, u := range urls {
+		document, err := r.fetch(ctx, u)
+		if err == nil {
+			return document, u, nil
+		}
+		errs = append(errs, err.Error())
+	}
+	return nil, "", errors.New("unable to resolve " + uri + ": " + strings.Join(errs, "; "))
+}
+
+func (r *URIResolver) fetch(ctx context.Context, u string) (map[string]interface{}, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, errors.New(u + ": " + resp.Status)
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, int64(r.MaxSize)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > r.MaxSize {
+		return nil, fmt.Errorf("%s: document exceeds %d bytes", u, r.MaxSize)
+	}
+	return r.decode(data)
+}
+
+func (r *URIResolver) decode(data []byte) (map[string]interface{}, error) {
+	if len(data) > r.MaxSize {
+		return nil, fmt.Errorf("document exceeds %d bytes", r.MaxSize)
+	}
+	document := make(map[string]interface{})
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// DecodeDataURI returns the content of the data URI @uri as defined in RFC 2397.
+func DecodeDataURI(uri string) ([]byte, error) {
+	uri = strings.TrimSpace(uri)
+	if !strings.HasPrefix(uri, "data:") {
+		return nil, errors.New("not a data uri")
+	}
+	comma := strings.Index(uri, ",")
+	if comma < 0 {
+		return nil, errors.New("data uri without data")
+	}
+	header, data := uri[len("data:"):comma], uri[comma+1:]
+	if strings.HasSuffix(header, ";base64") {
+		decoded, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			// some contracts omit the padding
+			decoded, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(data, "="))
+		}
+		return decoded, err
+	}
+	decoded, err := url.PathUnescape(data)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(decoded), nil
+}
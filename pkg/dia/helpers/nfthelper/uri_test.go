@@ -0,0 +1,51 @@
+package nfthelper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResolveDataURI(t *testing.T) {
+	r := NewURIResolver(nil, nil)
+	for _, uri := range []string{
+		`data:application/json;base64,eyJuYW1lIjoiQmFnICMxIn0=`,
+		`data:application/json;base64,eyJuYW1lIjoiQmFnICMxIn0`,
+		`data:application/json,%7B%22name%22%3A%22Bag%20%231%22%7D`,
+	} {
+		document, u, err := r.Resolve(context.Background(), uri)
+		if err != nil || u != "" || document["name"] != "Bag #1" {
+			t.Errorf("%s: %v, %q, %v", uri, document, u, err)
+		}
+	}
+}
+
+func TestResolveGateways(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusGatewayTimeout)
+	}))
+	defer down.Close()
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(`{"path":"` + req.URL.Path + `"}`))
+	}))
+	defer up.Close()
+
+	r := NewURIResolver([]string{down.URL, up.URL + "/"}, []string{up.URL})
+	cases := map[string]string{
+		"ipfs://QmHash/1.json":      "/ipfs/QmHash/1.json",
+		"ipfs://ipfs/QmHash/1.json": "/ipfs/QmHash/1.json",
+		"ar://TxID":                 "/TxID",
+		up.URL + "/token/1":         "/token/1",
+	}
+	for uri, path := range cases {
+		document, u, err := r.Resolve(context.Background(), uri)
+		if err != nil || document["path"] != path || u != up.URL+path {
+			t.Errorf("%s: %v, %q, %v", uri, document, u, err)
+		}
+	}
+
+	if _, _, err := r.Resolve(context.Background(), "ftp://token"); err == nil {
+		t.Error("expected an error for an unsupported scheme")
+	}
+}
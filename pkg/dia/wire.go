@@ -0,0 +1,370 @@
+package dia
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Trades, TradesBlocks and FiltersBlocks are sent over kafka in one of the following
+// schema versions:
+//   - WireVersionJSON: plain JSON without header, as produced by MarshalBinary.
+//   - WireVersionProto1: header of wireMagic and the version byte, followed by the protobuf
+//     encoding specified in pkg/dia/proto/messages.proto.
+//
+// UnmarshalBinary of these types accepts all versions, so consumers must be upgraded before
+// producers switch to a new version. Decoders skip unknown protobuf fields, hence fields can
+// be added to a schema version without breaking consumers.
+const (
+	WireVersionJSON   = 0
+	WireVersionProto1 = 1
+	WireVersionLatest = WireVersionProto1
+
+	// wireMagic is the first byte of versioned messages. JSON never starts with it.
+	wireMagic byte = 0xD1
+)
+
+// VersionedMessage is a message which can be encoded in any wire version.
+type VersionedMessage interface {
+	MarshalBinary() ([]byte, error)
+	appendProto(b []byte) []byte
+	unmarshalProto(b []byte) error
+}
+
+// MarshalVersioned encodes @m in the schema @version.
+func MarshalVersioned(m VersionedMessage, version int) ([]byte, error) {
+	switch version {
+	case WireVersionJSON:
+		return m.MarshalBinary()
+	case WireVersionProto1:
+		return m.appendProto([]byte{wireMagic, WireVersionProto1}), nil
+	default:
+		return nil, fmt.Errorf("unsupported wire version %d", version)
+	}
+}
+
+// WireVersion returns the schema version of the encoded message @data.
+func WireVersion(data []byte) int {
+	if len(data) >= 2 && data[0] == wireMagic {
+		return int(data[1])
+	}
+	return WireVersionJSON
+}
+
+// unmarshalVersioned decodes @data in any supported schema version into @m.
+func unmarshalVersioned(data []byte, m VersionedMessage) error {
+	switch version := WireVersion(data); version {
+	case WireVersionJSON:
+		return json.Unmarshal(data, m)
+	case WireVersionProto1:
+		return m.unmarshalProto(data[2:])
+	default:
+		return fmt.Errorf("unsupported wire version %d", version)
+	}
+}
+
+// MarshalVersion encodes the trade in schema @version.
+func (e *Trade) MarshalVersion(version int) ([]byte, error) {
+	return MarshalVersioned(e, version)
+}
+
+// MarshalVersion encodes the trades block in schema @version.
+func (e *TradesBlock) MarshalVersion(version int) ([]byte, error) {
+	return MarshalVersioned(e, version)
+}
+
+// MarshalVersion encodes the filters block in schema @version.
+func (e *FiltersBlock) MarshalVersion(version int) ([]byte, error) {
+	return MarshalVersioned(e, version)
+}
+
+func (e *Trade) appendProto(b []byte) []byte {
+	b = appendString(b, 1, e.Symbol)
+	b = appendString(b, 2, e.Pair)
+	b = appendDouble(b, 3, e.Price)
+	b = appendDouble(b, 4, e.Volume)
+	b = appendTime(b, 5, e.Time)
+	b = appendString(b, 6, e.ForeignTradeID)
+	b = appendDouble(b, 7, e.EstimatedUSDPrice)
+	b = appendString(b, 8, e.Source)
+	return b
+}
+
+func (e *Trade) unmarshalProto(b []byte) error {
+	*e = Trade{}
+	return consumeFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1:
+			return consumeString(typ, b, &e.Symbol), nil
+		case 2:
+			return consumeString(typ, b, &e.Pair), nil
+		case 3:
+			return consumeDouble(typ, b, &e.Price), nil
+		case 4:
+			return consumeDouble(typ, b, &e.Volume), nil
+		case 5:
+			return consumeTime(typ, b, &e.Time)
+		case 6:
+			return consumeString(typ, b, &e.ForeignTradeID), nil
+		case 7:
+			return consumeDouble(typ, b, &e.EstimatedUSDPrice), nil
+		case 8:
+			return consumeString(typ, b, &e.Source), nil
+		}
+		return 0, nil
+	})
+}
+
+func (e *TradesBlock) appendProto(b []byte) []byte {
+	b = appendString(b, 1, e.BlockHash)
+	b = appendTime(b, 2, e.TradesBlockData.BeginTime)
+	b = appendTime(b, 3, e.TradesBlockData.EndTime)
+	b = appendInt(b, 4, int64(e.TradesBlockData.TradesNumber))
+	for i := range e.TradesBlockData.Trades {
+		b = appendMessage(b, 5, e.TradesBlockData.Trades[i].appendProto(nil))
+	}
+	return b
+}
+
+func (e *TradesBlock) unmarshalProto(b []byte) error {
+	*e = TradesBlock{}
+	return consumeFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1:
+			return consumeString(typ, b, &e.BlockHash), nil
+		case 2:
+			return consumeTime(typ, b, &e.TradesBlockData.BeginTime)
+		case 3:
+			return consumeTime(typ, b, &e.TradesBlockData.EndTime)
+		case 4:
+			var n int64
+			consumed := consumeInt(typ, b, &n)
+			e.TradesBlockData.TradesNumber = int(n)
+			return consumed, nil
+		case 5:
+			var raw []byte
+			consumed := consumeBytes(typ, b, &raw)
+			if consumed > 0 {
+				var t Trade
+				if err := t.unmarshalProto(raw); err != nil {
+					return 0, err
+				}
+				e.TradesBlockData.Trades = append(e.TradesBlockData.Trades, t)
+			}
+			return consumed, nil
+		}
+		return 0, nil
+	})
+}
+
+func (e *FilterPoint) appendProto(b []byte) []byte {
+	b = appendString(b, 1, e.Symbol)
+	b = appendDouble(b, 2, e.Value)
+	b = appendString(b, 3, e.Name)
+	b = appendTime(b, 4, e.Time)
+	return b
+}
+
+func (e *FilterPoint) unmarshalProto(b []byte) error {
+	*e = FilterPoint{}
+	return consumeFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1:
+			return consumeString(typ, b, &e.Symbol), nil
+		case 2:
+			return consumeDouble(typ, b, &e.Value), nil
+		case 3:
+			return consumeString(typ, b, &e.Name), nil
+		case 4:
+			return consumeTime(typ, b, &e.Time)
+		}
+		return 0, nil
+	})
+}
+
+func (e *FiltersBlock) appendProto(b []byte) []byte {
+	b = appendString(b, 1, e.BlockHash)
+	b = appendString(b, 2, e.FiltersBlockData.TradesBlockHash)
+	b = appendTime(b, 3, e.FiltersBlockData.BeginTime)
+	b = appendTime(b, 4, e.FiltersBlockData.EndTime)
+	for i := range e.FiltersBlockData.FilterPoints {
+		b = appendMessage(b, 5, e.FiltersBlockData.FilterPoints[i].appendProto(nil))
+	}
+	b = appendInt(b, 6, int64(e.FiltersBlockData.FiltersNumber))
+	return b
+}
+
+func (e *FiltersBlock) unmarshalProto(b []byte) error {
+	*e = FiltersBlock{}
+	return consumeFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1:
+			return consumeString(typ, b, &e.BlockHash), nil
+		case 2:
+			return consumeString(typ, b, &e.FiltersBlockData.TradesBlockHash), nil
+		case 3:
+			return consumeTime(typ, b, &e.FiltersBlockData.BeginTime)
+		case 4:
+			return consumeTime(typ, b, &e.FiltersBlockData.EndTime)
+		case 5:
+			var raw []byte
+			consumed := consumeBytes(typ, b, &raw)
+			if consumed > 0 {
+				var fp FilterPoint
+				if err := fp.unmarshalProto(raw); err != nil {
+					return 0, err
+				}
+				e.FiltersBlockData.FilterPoints = append(e.FiltersBlockData.FilterPoints, fp)
+			}
+			return consumed, nil
+		case 6:
+			var n int64
+			consumed := consumeInt(typ, b, &n)
+			e.FiltersBlockData.FiltersNumber = int(n)
+			return consumed, nil
+		}
+		return 0, nil
+	})
+}
+
+// -----------------------------------------------------------------------------
+// protobuf wire format helpers. Zero values are omitted as in proto3.
+// -----------------------------------------------------------------------------
+
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+func appendDouble(b []byte, num protowire.Number, v float64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, math.Float64bits(v))
+}
+
+func appendInt(b []byte, num protowire.Number, v int64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(v))
+}
+
+func appendMessage(b []byte, num protowire.Number, m []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, m)
+}
+
+// appendTime encodes @t as google.protobuf.Timestamp. The zero time is omitted.
+func appendTime(b []byte, num protowire.Number, t time.Time) []byte {
+	if t.IsZero() {
+		return b
+	}
+	var ts []byte
+	ts = appendInt(ts, 1, t.Unix())
+	ts = appendInt(ts, 2, int64(t.Nanosecond()))
+	return appendMessage(b, num, ts)
+}
+
+// consumeFields calls @field for each field in @b. @field returns the number of bytes consumed,
+// 0 for unknown fields which are skipped, or a negative protowire error code.
+func consumeFields(b []byte, field func(num protowire.Number, typ protowire.Type, b []byte) (int, error)) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		n, err := field(num, typ, b)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		if n > len(b) {
+			return errors.New("field exceeds message")
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+func consumeString(typ protowire.Type, b []byte, s *string) int {
+	if typ != protowire.BytesType {
+		return 0
+	}
+	v, n := protowire.ConsumeString(b)
+	if n > 0 {
+		*s = v
+	}
+	return n
+}
+
+func consumeBytes(typ protowire.Type, b []byte, v *[]byte) int {
+	if typ != protowire.BytesType {
+		return 0
+	}
+	raw, n := protowire.ConsumeBytes(b)
+	if n > 0 {
+		*v = raw
+	}
+	return n
+}
+
+func consumeDouble(typ protowire.Type, b []byte, f *float64) int {
+	if typ != protowire.Fixed64Type {
+		return 0
+	}
+	v, n := protowire.ConsumeFixed64(b)
+	if n > 0 {
+		*f = math.Float64frombits(v)
+	}
+	return n
+}
+
+func consumeInt(typ protowire.Type, b []byte, i *int64) int {
+	if typ != protowire.VarintType {
+		return 0
+	}
+	v, n := protowire.ConsumeVarint(b)
+	if n > 0 {
+		*i = int64(v)
+	}
+	return n
+}
+
+func consumeTime(typ protowire.Type, b []byte, t *time.Time) (int, error) {
+	var raw []byte
+	n := consumeBytes(typ, b, &raw)
+	if n <= 0 {
+		return n, nil
+	}
+	var seconds, nanos int64
+	err := consumeFields(raw, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1:
+			return consumeInt(typ, b, &seconds), nil
+		case 2:
+			return consumeInt(typ, b, &nanos), nil
+		}
+		return 0, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	*t = time.Unix(seconds, nanos).UTC()
+	return n, nil
+}
@@ -0,0 +1,157 @@
+package dia
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+var (
+	testTime  = time.Unix(1609459200, 500).UTC()
+	testTrade = Trade{
+		Symbol:            "BTC",
+		Pair:              "BTCUSDT",
+		Price:             50000.5,
+		Volume:            -0.25,
+		Time:              testTime,
+		ForeignTradeID:    "42",
+		EstimatedUSDPrice: 50010,
+		Source:            "Binance",
+	}
+	testTradesBlock = TradesBlock{
+		BlockHash: "tradesblockhash",
+		TradesBlockData: TradesBlockData{
+			BeginTime:    testTime,
+			EndTime:      testTime.Add(2 * time.Minute),
+			TradesNumber: 2,
+			Trades:       []Trade{testTrade, {Symbol: "ETH", Pair: "ETHBTC", Price: 0.03, Volume: 1, Time: testTime, Source: "Kraken"}},
+		},
+	}
+	testFiltersBlock = FiltersBlock{
+		BlockHash: "filtersblockhash",
+		FiltersBlockData: FiltersBlockData{
+			TradesBlockHash: "tradesblockhash",
+			BeginTime:       testTime,
+			EndTime:         testTime.Add(2 * time.Minute),
+			FilterPoints:    []FilterPoint{{Symbol: "BTC", Value: 50001, Name: "MA120", Time: testTime}},
+			FiltersNumber:   1,
+		},
+	}
+)
+
+// Encoding of testTrade in wire version 1. Changing it breaks consumers of existing messages.
+const goldenTradeV1 = "d1010a034254431207425443555344541900000000106ae84021000000000000d0bf2a090880ccb9ff0510f403320234323900000000406be840420742696e616e6365"
+
+func TestWireGolden(t *testing.T) {
+	b, err := MarshalVersioned(&testTrade, WireVersionProto1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(b) != goldenTradeV1 {
+		t.Errorf("encoding of wire version 1 changed: %x", b)
+	}
+}
+
+func TestWireRoundTrip(t *testing.T) {
+	for _, version := range []int{WireVersionJSON, WireVersionProto1} {
+		b, err := MarshalVersioned(&testTrade, version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if WireVersion(b) != version {
+			t.Errorf("version %d detected as %d", version, WireVersion(b))
+		}
+		var trade Trade
+		if err := trade.UnmarshalBinary(b); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(trade, testTrade) {
+			t.Errorf("version %d: trade %+v decoded as %+v", version, testTrade, trade)
+		}
+
+		b, err = MarshalVersioned(&testTradesBlock, version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var tradesBlock TradesBlock
+		if err := tradesBlock.UnmarshalBinary(b); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(tradesBlock, testTradesBlock) {
+			t.Errorf("version %d: trades block %+v decoded as %+v", version, testTradesBlock, tradesBlock)
+		}
+
+		b, err = MarshalVersioned(&testFiltersBlock, version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var filtersBlock FiltersBlock
+		if err := filtersBlock.UnmarshalBinary(b); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(filtersBlock, testFiltersBlock) {
+			t.Errorf("version %d: filters block %+v decoded as %+v", version, testFiltersBlock, filtersBlock)
+		}
+	}
+}
+
+// Messages written by producers before versioning are plain JSON.
+func TestWireLegacyJSON(t *testing.T) {
+	legacy := []byte(`{"Symbol":"BTC","Pair":"BTCUSDT","Price":50000.5,"Volume":-0.25,"Time":"2021-01-01T00:00:00.0000005Z","ForeignTradeID":"42","EstimatedUSDPrice":50010,"Source":"Binance"}`)
+	var trade Trade
+	if err := trade.UnmarshalBinary(legacy); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(trade, testTrade) {
+		t.Errorf("legacy trade decoded as %+v", trade)
+	}
+
+	b, err := MarshalVersioned(&testTradesBlock, WireVersionJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacyBlock, err := testTradesBlock.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, legacyBlock) {
+		t.Error("wire version 0 differs from MarshalBinary")
+	}
+}
+
+// Consumers must skip fields added by newer producers.
+func TestWireUnknownFields(t *testing.T) {
+	b, err := MarshalVersioned(&testTrade, WireVersionProto1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b = protowire.AppendTag(b, 99, protowire.BytesType)
+	b = protowire.AppendString(b, "added later")
+	b = protowire.AppendTag(b, 100, protowire.VarintType)
+	b = protowire.AppendVarint(b, 7)
+	var trade Trade
+	if err := trade.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(trade, testTrade) {
+		t.Errorf("trade with unknown fields decoded as %+v", trade)
+	}
+}
+
+func TestWireErrors(t *testing.T) {
+	var trade Trade
+	if err := trade.UnmarshalBinary([]byte{wireMagic, 99, 0x0a}); err == nil {
+		t.Error("expected error on unsupported version")
+	}
+	b, _ := MarshalVersioned(&testTrade, WireVersionProto1)
+	if err := trade.UnmarshalBinary(b[:len(b)-3]); err == nil {
+		t.Error("expected error on truncated message")
+	}
+	if _, err := MarshalVersioned(&testTrade, 99); err == nil {
+		t.Error("expected error on encoding in unsupported version")
+	}
+}
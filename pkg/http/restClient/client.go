@@ -0,0 +1,265 @@
+// Package restClient is a typed client for the public DIA REST API as served by cmd/http/restServer.
+// Responses are decoded into the same types the handlers in diaApi encode.
+package restClient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/http/restApi"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultMaxRetries = 3
+	defaultBackoff    = 500 * time.Millisecond
+	defaultTimeout    = 30 * time.Second
+
+	// Headers by which the API communicates the state of the caller's rate limit.
+	headerRateLimitRemaining = "X-RateLimit-Remaining"
+	headerRateLimitReset     = "X-RateLimit-Reset"
+	headerRetryAfter         = "Retry-After"
+	headerAPIKey             = "X-API-KEY"
+)
+
+// Client is a client for the public DIA REST API. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	apiKey     string
+	maxRetries int
+	backoff    time.Duration
+
+	// Rate limit state as reported by the API in the last response.
+	rateLimitLock sync.Mutex
+	blockedUntil  time.Time
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithBaseURL sets the base URL of the API. Defaults to dia.BaseUrl.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithHTTPClient sets the underlying http client.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithAPIKey sets the API key sent along with each request.
+func WithAPIKey(apiKey string) Option {
+	return func(c *Client) {
+		c.apiKey = apiKey
+	}
+}
+
+// WithRetries sets the maximal number of retries of a request and the initial backoff,
+// which is doubled on each retry.
+func WithRetries(maxRetries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.backoff = backoff
+	}
+}
+
+// NewClient returns a client for the API at dia.BaseUrl unless configured otherwise by @options.
+func NewClient(options ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(dia.BaseUrl, "/"),
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		maxRetries: defaultMaxRetries,
+		backoff:    defaultBackoff,
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// Error is returned for responses with a status code other than 200.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("dia api returned status %d: %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound returns true if @err is an API error with status 404.
+func IsNotFound(err error) bool {
+	apiErr, ok := err.(*Error)
+	return ok && apiErr.StatusCode == http.StatusNotFound
+}
+
+// get requests @path with query parameters @query and decodes the response into @result.
+func (c *Client) get(ctx context.Context, path string, query url.Values, result interface{}) error {
+	body, err := c.getBody(ctx, path, query)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, result)
+}
+
+// getBody requests @path with query parameters @query and returns the response body.
+// Requests failing with 429 or 5xx are retried with exponential backoff.
+func (c *Client) getBody(ctx context.Context, path string, query url.Values) ([]byte, error) {
+	requestURL := c.baseURL + path
+	if len(query) > 0 {
+		requestURL += "?" + query.Encode()
+	}
+
+	backoff := c.backoff
+	var err error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			if err = sleep(ctx, backoff); err != nil {
+				return nil, err
+			}
+			backoff *= 2
+		}
+		if err = c.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+
+		var body []byte
+		var retry bool
+		body, retry, err = c.do(ctx, requestURL)
+		if err == nil {
+			return body, nil
+		}
+		if !retry {
+			return nil, err
+		}
+		log.Warnf("request to %s failed, attempt %d of %d: %v", path, attempt+1, c.maxRetries+1, err)
+	}
+	return nil, err
+}
+
+// do executes a single GET request. The returned bool is true if the request may be retried.
+func (c *Client) do(ctx context.Context, requestURL string) ([]byte, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set(headerAPIKey, c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		// Network errors are retried unless the context is done.
+		return nil, ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+	c.updateRateLimit(resp)
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, true, err
+	}
+	if resp.StatusCode == http.StatusOK {
+		return body, false, nil
+	}
+
+	apiErr := &Error{StatusCode: resp.StatusCode, Message: string(body)}
+	var errorMessage restApi.APIError
+	if json.Unmarshal(body, &errorMessage) == nil && errorMessage.ErrorMessage != "" {
+		apiErr.Message = errorMessage.ErrorMessage
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+	return nil, retry, apiErr
+}
+
+// updateRateLimit blocks further requests if the API reports an exhausted rate limit,
+// either by Retry-After or by X-RateLimit-Remaining together with X-RateLimit-Reset (unix seconds).
+func (c *Client) updateRateLimit(resp *http.Response) {
+	var until time.Time
+	if retryAfter := resp.Header.Get(headerRetryAfter); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			until = time.Now().Add(time.Duration(seconds) * time.Second)
+		}
+	}
+	if resp.Header.Get(headerRateLimitRemaining) == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get(headerRateLimitReset), 10, 64); err == nil {
+			if resetTime := time.Unix(reset, 0); resetTime.After(until) {
+				until = resetTime
+			}
+		}
+	}
+	if until.IsZero() {
+		return
+	}
+	c.rateLimitLock.Lock()
+	if until.After(c.blockedUntil) {
+		c.blockedUntil = until
+	}
+	c.rateLimitLock.Unlock()
+}
+
+// waitForRateLimit waits until the rate limit reported by the API is reset.
+func (c *Client) waitForRateLimit(ctx context.Context) error {
+	c.rateLimitLock.Lock()
+	wait := time.Until(c.blockedUntil)
+	c.rateLimitLock.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+	log.Infof("rate limit of dia api exhausted, waiting %v", wait)
+	return sleep(ctx, wait)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// timeRange returns the query parameters @startKey and @endKey as unix seconds for non-zero times.
+func timeRange(startKey string, starttime time.Time, endKey string, endtime time.Time) url.Values {
+	query := url.Values{}
+	if !starttime.IsZero() {
+		query.Set(startKey, strconv.FormatInt(starttime.Unix(), 10))
+	}
+	if !endtime.IsZero() {
+		query.Set(endKey, strconv.FormatInt(endtime.Unix(), 10))
+	}
+	return query
+}
+
+// dateRange returns the query parameters dateInit and dateFinal in the format 2006-01-02.
+func dateRange(dateInit, dateFinal time.Time) url.Values {
+	return url.Values{
+		"dateInit":  []string{dateInit.Format("2006-01-02")},
+		"dateFinal": []string{dateFinal.Format("2006-01-02")},
+	}
+}
+
+func escape(segments ...string) string {
+	escaped := make([]string, len(segments))
+	for i, segment := range segments {
+		escaped[i] = url.PathEscape(segment)
+	}
+	return "/" + strings.Join(escaped, "/")
+}
@@ -0,0 +1,109 @@
+package restClient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestQuotationRetry(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path != "/v1/quotation/BTC" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"Symbol":"BTC","Name":"Bitcoin","Price":50000.5}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithRetries(2, time.Millisecond))
+	q, err := client.Quotation(context.Background(), "btc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 || q.Symbol != "BTC" || q.Price != 50000.5 {
+		t.Errorf("unexpected quotation %+v after %d calls", q, calls)
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"errorcode":404,"errormessage":"redis: nil"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithRetries(2, time.Millisecond))
+	_, err := client.Supply(context.Background(), "XYZ")
+	if !IsNotFound(err) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	if err.(*Error).Message != "redis: nil" || calls != 1 {
+		t.Errorf("unexpected error %v after %d calls", err, calls)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	var requestTimes []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestTimes = append(requestTimes, time.Now())
+		if len(requestTimes) == 1 {
+			w.Header().Set(headerRetryAfter, "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`["Binance","Kraken"]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithRetries(1, time.Millisecond))
+	exchanges, err := client.Exchanges(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exchanges) != 2 {
+		t.Errorf("unexpected exchanges %v", exchanges)
+	}
+	if wait := requestTimes[1].Sub(requestTimes[0]); wait < 900*time.Millisecond {
+		t.Errorf("retried after %v despite Retry-After", wait)
+	}
+
+	// An exhausted rate limit must not block a cancelled request.
+	client.blockedUntil = time.Now().Add(time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.Exchanges(ctx); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestCryptoDerivative(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/CryptoDerivatives/option/ETH-25JUN21-2000-C" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+	for _, body = range []string{"", `{"Name":"ETH-25JUN21-2000-C"}`} {
+		derivative, err := client.CryptoDerivative(context.Background(), "option", "ETH-25JUN21-2000-C")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(derivative) != body {
+			t.Errorf("derivative %q, want %q", derivative, body)
+		}
+	}
+}
@@ -0,0 +1,651 @@
+package restClient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+)
+
+// -----------------------------------------------------------------------------
+// QUOTATIONS AND TRADES
+// -----------------------------------------------------------------------------
+
+// Quotation returns the latest quotation of @symbol.
+func (c *Client) Quotation(ctx context.Context, symbol string) (*models.Quotation, error) {
+	var q models.Quotation
+	err := c.get(ctx, "/v1/quotation"+escape(strings.ToUpper(symbol)), nil, &q)
+	return &q, err
+}
+
+// LastTrades returns the latest trades of @symbol.
+func (c *Client) LastTrades(ctx context.Context, symbol string) ([]dia.Trade, error) {
+	var q []dia.Trade
+	err := c.get(ctx, "/v1/lastTrades"+escape(symbol), nil, &q)
+	return q, err
+}
+
+// LastPriceBefore returns the last price of @symbol on @exchange before @timestamp as computed by @filter.
+func (c *Client) LastPriceBefore(ctx context.Context, filter, exchange, symbol string, timestamp time.Time) (models.Price, error) {
+	var q models.Price
+	err := c.get(ctx, "/v1/lastPriceBefore"+escape(filter, exchange, symbol, strconv.FormatInt(timestamp.Unix(), 10)), nil, &q)
+	return q, err
+}
+
+// LastPriceBeforeAllExchanges returns the last price of @symbol before @timestamp as computed by @filter
+// over all exchanges.
+func (c *Client) LastPriceBeforeAllExchanges(ctx context.Context, filter, symbol string, timestamp time.Time) (models.Price, error) {
+	var q models.Price
+	err := c.get(ctx, "/v1/lastPriceBeforeAllExchanges"+escape(filter, symbol, strconv.FormatInt(timestamp.Unix(), 10)), nil, &q)
+	return q, err
+}
+
+// ChartPoints returns filter points of @symbol on @exchange in the given time range.
+// @scale is one of 5m 30m 1h 4h 1d 1w. Zero times are omitted and defaulted by the API.
+func (c *Client) ChartPoints(ctx context.Context, filter, exchange, symbol, scale string, starttime, endtime time.Time) (*models.Points, error) {
+	var q models.Points
+	query := timeRange("starttime", starttime, "endtime", endtime)
+	if scale != "" {
+		query.Set("scale", scale)
+	}
+	err := c.get(ctx, "/v1/chartPoints"+escape(filter, exchange, symbol), query, &q)
+	return &q, err
+}
+
+// ChartPointsAllExchanges returns filter points of @symbol over all exchanges in the given time range.
+func (c *Client) ChartPointsAllExchanges(ctx context.Context, filter, symbol, scale string, starttime, endtime time.Time) (*models.Points, error) {
+	var q models.Points
+	query := timeRange("starttime", starttime, "endtime", endtime)
+	if scale != "" {
+		query.Set("scale", scale)
+	}
+	err := c.get(ctx, "/v1/chartPointsAllExchanges"+escape(filter, symbol), query, &q)
+	return &q, err
+}
+
+// Candles returns OHLCV candles of @symbol on @exchange, or over all exchanges if @exchange is empty,
+// in @resolution (1m 5m 15m 1h 4h 1d). Zero times, @limit and @offset are omitted and defaulted by the API.
+func (c *Client) Candles(ctx context.Context, symbol, exchange, resolution string, starttime, endtime time.Time, limit, offset int) ([]models.Candle, error) {
+	var q []models.Candle
+	query := timeRange("starttime", starttime, "endtime", endtime)
+	if exchange != "" {
+		query.Set("exchange", exchange)
+	}
+	if resolution != "" {
+		query.Set("resolution", resolution)
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	if offset > 0 {
+		query.Set("offset", strconv.Itoa(offset))
+	}
+	err := c.get(ctx, "/v1/candles"+escape(symbol), query, &q)
+	return q, err
+}
+
+// Volume returns the trade volume of @symbol in the given time range.
+func (c *Client) Volume(ctx context.Context, symbol string, starttime, endtime time.Time) (float64, error) {
+	var q float64
+	err := c.get(ctx, "/v1/volume"+escape(symbol), timeRange("starttime", starttime, "endtime", endtime), &q)
+	return q, err
+}
+
+// Volume24 returns the trade volume on @exchange over the last 24 hours.
+func (c *Client) Volume24(ctx context.Context, exchange string) (float64, error) {
+	var q float64
+	err := c.get(ctx, "/v1/volume24"+escape(exchange), nil, &q)
+	return q, err
+}
+
+// -----------------------------------------------------------------------------
+// SYMBOLS AND SUPPLIES
+// -----------------------------------------------------------------------------
+
+// Supply returns the latest supply of @symbol.
+func (c *Client) Supply(ctx context.Context, symbol string) (*dia.Supply, error) {
+	var q dia.Supply
+	err := c.get(ctx, "/v1/supply"+escape(symbol), nil, &q)
+	return &q, err
+}
+
+// Supplies returns the supplies of @symbol in the given time range. The API returns
+// all supplies if one of the times is zero.
+func (c *Client) Supplies(ctx context.Context, symbol string, starttime, endtime time.Time) ([]dia.Supply, error) {
+	var q []dia.Supply
+	err := c.get(ctx, "/v1/supplies"+escape(symbol), timeRange("starttime", starttime, "endtime", endtime), &q)
+	return q, err
+}
+
+// DiaTotalSupply returns the total supply of the DIA token.
+func (c *Client) DiaTotalSupply(ctx context.Context) (float64, error) {
+	var q float64
+	err := c.get(ctx, "/v1/diaTotalSupply", nil, &q)
+	return q, err
+}
+
+// DiaCirculatingSupply returns the circulating supply of the DIA token.
+func (c *Client) DiaCirculatingSupply(ctx context.Context) (float64, error) {
+	var q float64
+	err := c.get(ctx, "/v1/diaCirculatingSupply", nil, &q)
+	return q, err
+}
+
+// SymbolDetails returns quotation, supply and exchange details of @symbol.
+func (c *Client) SymbolDetails(ctx context.Context, symbol string) (*models.SymbolDetails, error) {
+	var q models.SymbolDetails
+	err := c.get(ctx, "/v1/symbol"+escape(symbol), nil, &q)
+	return &q, err
+}
+
+// Symbols returns all symbols, restricted to those traded on @exchange if non-empty.
+func (c *Client) Symbols(ctx context.Context, exchange string) ([]string, error) {
+	var q dia.Symbols
+	query := url.Values{}
+	if exchange != "" {
+		query.Set("exchange", exchange)
+	}
+	err := c.get(ctx, "/v1/symbols", query, &q)
+	return q.Symbols, err
+}
+
+// Coins returns the coin toplist.
+func (c *Client) Coins(ctx context.Context) (*models.Coins, error) {
+	var q models.Coins
+	err := c.get(ctx, "/v1/coins", nil, &q)
+	return &q, err
+}
+
+// Pairs returns all pairs.
+func (c *Client) Pairs(ctx context.Context) ([]dia.Pair, error) {
+	var q models.Pairs
+	err := c.get(ctx, "/v1/pairs", nil, &q)
+	return q.Pairs, err
+}
+
+// Exchanges returns all exchanges.
+func (c *Client) Exchanges(ctx context.Context) ([]string, error) {
+	var q []string
+	err := c.get(ctx, "/v1/exchanges", nil, &q)
+	return q, err
+}
+
+// -----------------------------------------------------------------------------
+// INDICES
+// -----------------------------------------------------------------------------
+
+// CviIndex returns the crypto volatility index @symbol in the given time range.
+func (c *Client) CviIndex(ctx context.Context, symbol string, starttime, endtime time.Time) ([]dia.CviDataPoint, error) {
+	var q []dia.CviDataPoint
+	query := timeRange("starttime", starttime, "endtime", endtime)
+	if symbol != "" {
+		query.Set("symbol", symbol)
+	}
+	err := c.get(ctx, "/v1/cviIndex", query, &q)
+	return q, err
+}
+
+// CryptoIndex returns the values of the crypto index @symbol in the given time range.
+func (c *Client) CryptoIndex(ctx context.Context, symbol string, starttime, endtime time.Time) ([]models.CryptoIndex, error) {
+	var q []models.CryptoIndex
+	err := c.get(ctx, "/v1/index"+escape(symbol), timeRange("starttime", starttime, "endtime", endtime), &q)
+	return q, err
+}
+
+// CryptoIndexMintAmounts returns the mint amounts of the constituents of the crypto index @symbol.
+func (c *Client) CryptoIndexMintAmounts(ctx context.Context, symbol string) ([]models.CryptoIndexMintAmount, error) {
+	var q []models.CryptoIndexMintAmount
+	err := c.get(ctx, "/v1/cryptoIndexMintAmounts"+escape(symbol), nil, &q)
+	return q, err
+}
+
+// CryptoDerivative returns the crypto derivative @name of type @derivativeType. The API does not fix
+// the format of derivatives yet, hence the raw JSON response is returned, which is empty as long as
+// the endpoint does not serve any data.
+func (c *Client) CryptoDerivative(ctx context.Context, derivativeType, name string) (json.RawMessage, error) {
+	body, err := c.getBody(ctx, "/v1/CryptoDerivatives"+escape(derivativeType, name), nil)
+	if err != nil || len(bytes.TrimSpace(body)) == 0 {
+		return nil, err
+	}
+	return json.RawMessage(body), nil
+}
+
+// -----------------------------------------------------------------------------
+// DeFi LENDING AND FARMING
+// -----------------------------------------------------------------------------
+
+// LendingProtocols returns all DeFi lending protocols.
+func (c *Client) LendingProtocols(ctx context.Context) ([]dia.DefiProtocol, error) {
+	var q []dia.DefiProtocol
+	err := c.get(ctx, "/v1/defiLendingProtocols", nil, &q)
+	return q, err
+}
+
+// DefiRate returns the last lending and borrowing rate of @asset on @protocol before @timestamp.
+func (c *Client) DefiRate(ctx context.Context, protocol, asset string, timestamp time.Time) (dia.DefiRate, error) {
+	var q dia.DefiRate
+	err := c.get(ctx, "/v1/defiLendingRate"+escape(protocol, asset, strconv.FormatInt(timestamp.Unix(), 10)), nil, &q)
+	return q, err
+}
+
+// DefiRates returns the lending and borrowing rates of @asset on @protocol in the given time range.
+func (c *Client) DefiRates(ctx context.Context, protocol, asset string, starttime, endtime time.Time) ([]dia.DefiRate, error) {
+	var q []dia.DefiRate
+	err := c.get(ctx, "/v1/defiLendingRate"+escape(protocol, asset), timeRange("dateInit", starttime, "dateFinal", endtime), &q)
+	return q, err
+}
+
+// DefiState returns the last state of @protocol before @timestamp.
+func (c *Client) DefiState(ctx context.Context, protocol string, timestamp time.Time) (dia.DefiProtocolState, error) {
+	var q dia.DefiProtocolState
+	err := c.get(ctx, "/v1/defiLendingState"+escape(protocol, strconv.FormatInt(timestamp.Unix(), 10)), nil, &q)
+	return q, err
+}
+
+// DefiStates returns the states of @protocol in the given time range.
+func (c *Client) DefiStates(ctx context.Context, protocol string, starttime, endtime time.Time) ([]dia.DefiProtocolState, error) {
+	var q []dia.DefiProtocolState
+	err := c.get(ctx, "/v1/defiLendingState"+escape(protocol), timeRange("dateInit", starttime, "dateFinal", endtime), &q)
+	return q, err
+}
+
+// DefiMarketState returns the last state of the market @asset on @protocol before @timestamp.
+func (c *Client) DefiMarketState(ctx context.Context, protocol, asset string, timestamp time.Time) (dia.DefiMarketState, error) {
+	var q dia.DefiMarketState
+	err := c.get(ctx, "/v1/defiLendingMarket"+escape(protocol, asset, strconv.FormatInt(timestamp.Unix(), 10)), nil, &q)
+	return q, err
+}
+
+// DefiMarketStates returns the states of the market @asset on @protocol in the given time range.
+func (c *Client) DefiMarketStates(ctx context.Context, protocol, asset string, starttime, endtime time.Time) ([]dia.DefiMarketState, error) {
+	var q []dia.DefiMarketState
+	err := c.get(ctx, "/v1/defiLendingMarket"+escape(protocol, asset), timeRange("dateInit", starttime, "dateFinal", endtime), &q)
+	return q, err
+}
+
+// FarmingPools returns all farming pools.
+func (c *Client) FarmingPools(ctx context.Context) ([]models.FarmingPoolType, error) {
+	var q []models.FarmingPoolType
+	err := c.get(ctx, "/v1/FarmingPools", nil, &q)
+	return q, err
+}
+
+// FarmingPoolData returns the latest data of the pool @poolID on @protocol before @timestamp.
+func (c *Client) FarmingPoolData(ctx context.Context, protocol, poolID string, timestamp time.Time) (models.FarmingPool, error) {
+	var q models.FarmingPool
+	err := c.get(ctx, "/v1/FarmingPoolData"+escape(protocol, poolID, strconv.FormatInt(timestamp.Unix(), 10)), nil, &q)
+	return q, err
+}
+
+// FarmingPoolDataRange returns the data of the pool @poolID on @protocol in the given time range.
+func (c *Client) FarmingPoolDataRange(ctx context.Context, protocol, poolID string, starttime, endtime time.Time) ([]models.FarmingPool, error) {
+	var q []models.FarmingPool
+	err := c.get(ctx, "/v1/FarmingPoolData"+escape(protocol, poolID), timeRange("dateInit", starttime, "dateFinal", endtime), &q)
+	return q, err
+}
+
+// -----------------------------------------------------------------------------
+// FUTURES
+// -----------------------------------------------------------------------------
+
+// FundingRates returns the funding rates of the perpetual futures @market on @exchange in the given time range.
+func (c *Client) FundingRates(ctx context.Context, exchange, market string, starttime, endtime time.Time) ([]dia.FundingRate, error) {
+	var q []dia.FundingRate
+	err := c.get(ctx, "/v1/futures/fundingRates"+escape(exchange, market), timeRange("starttime", starttime, "endtime", endtime), &q)
+	return q, err
+}
+
+// FuturesBasis returns the basis of the futures @market on @exchange with respect to its underlying.
+func (c *Client) FuturesBasis(ctx context.Context, exchange, market string) (*models.FuturesBasis, error) {
+	var q models.FuturesBasis
+	err := c.get(ctx, "/v1/futures/basis"+escape(exchange, market), nil, &q)
+	return &q, err
+}
+
+// -----------------------------------------------------------------------------
+// OPTIONS
+// -----------------------------------------------------------------------------
+
+// OptionGreeks returns the implied volatility and greeks of the options on @underlying. A non-zero
+// @expiry restricts the result to options expiring on that day.
+func (c *Client) OptionGreeks(ctx context.Context, underlying string, expiry time.Time) ([]dia.OptionGreeks, error) {
+	var q []dia.OptionGreeks
+	path := "/v1/options/greeks" + escape(underlying)
+	if !expiry.IsZero() {
+		path += escape(expiry.Format("2006-01-02"))
+	}
+	err := c.get(ctx, path, nil, &q)
+	return q, err
+}
+
+// OptionExpirySummaries returns the put/call ratios and max pain per expiry of the options on
+// @underlying. A non-zero @expiry restricts the result to that day.
+func (c *Client) OptionExpirySummaries(ctx context.Context, underlying string, expiry time.Time) ([]dia.OptionExpirySummary, error) {
+	var q []dia.OptionExpirySummary
+	path := "/v1/options/expiries" + escape(underlying)
+	if !expiry.IsZero() {
+		path += escape(expiry.Format("2006-01-02"))
+	}
+	err := c.get(ctx, path, nil, &q)
+	return q, err
+}
+
+// LiveOnchainOptions returns the active on-chain option series with their latest quotes. A non-empty
+// @protocol restricts the result to that protocol.
+func (c *Client) LiveOnchainOptions(ctx context.Context, protocol string) ([]models.OnchainOptionSeries, error) {
+	var q []models.OnchainOptionSeries
+	query := url.Values{}
+	if protocol != "" {
+		query.Set("protocol", protocol)
+	}
+	err := c.get(ctx, "/v1/options/onchain", query, &q)
+	return q, err
+}
+
+// OptionExercises returns the exercise and settlement events of the on-chain option series
+// @instrument of @protocol in the given time range. Zero times use the API's defaults.
+func (c *Client) OptionExercises(ctx context.Context, protocol, instrument string, starttime, endtime time.Time) ([]dia.OptionExercise, error) {
+	var q []dia.OptionExercise
+	path := "/v1/options/onchain/exercises" + escape(protocol, instrument)
+	err := c.get(ctx, path, timeRange("starttime", starttime, "endtime", endtime), &q)
+	return q, err
+}
+
+// -----------------------------------------------------------------------------
+// INTEREST RATES
+// -----------------------------------------------------------------------------
+
+// InterestRates returns meta information on all interest rates.
+func (c *Client) InterestRates(ctx context.Context) ([]models.InterestRateMeta, error) {
+	var q []models.InterestRateMeta
+	err := c.get(ctx, "/v1/interestrates", nil, &q)
+	return q, err
+}
+
+// InterestRate returns the value of the interest rate @symbol on @date. A zero @date returns the latest value.
+func (c *Client) InterestRate(ctx context.Context, symbol string, date time.Time) (*models.InterestRate, error) {
+	var q models.InterestRate
+	path := "/v1/interestrate" + escape(symbol)
+	if !date.IsZero() {
+		path += escape(date.Format("2006-01-02"))
+	}
+	err := c.get(ctx, path, nil, &q)
+	return &q, err
+}
+
+// InterestRateRange returns the values of the interest rate @symbol in the given date range.
+func (c *Client) InterestRateRange(ctx context.Context, symbol string, dateInit, dateFinal time.Time) ([]*models.InterestRate, error) {
+	var q []*models.InterestRate
+	err := c.get(ctx, "/v1/interestrate"+escape(symbol), dateRange(dateInit, dateFinal), &q)
+	return q, err
+}
+
+// InterestRateReport returns the business days without fixing and the fixings on holidays of the
+// interest rate @symbol in the given date range.
+func (c *Client) InterestRateReport(ctx context.Context, symbol string, dateInit, dateFinal time.Time) (*models.InterestRateReport, error) {
+	var q models.InterestRateReport
+	err := c.get(ctx, "/v1/interestrateReport"+escape(symbol), dateRange(dateInit, dateFinal), &q)
+	return &q, err
+}
+
+// CompoundedRate returns the compounded index of @symbol on @date using the convention of @daysPerYear.
+// A zero @date returns the latest value.
+func (c *Client) CompoundedRate(ctx context.Context, symbol string, daysPerYear int, date time.Time) (*models.InterestRate, error) {
+	var q models.InterestRate
+	path := "/v1/compoundedRate" + escape(symbol, strconv.Itoa(daysPerYear))
+	if !date.IsZero() {
+		path += escape(date.Format("2006-01-02"))
+	}
+	err := c.get(ctx, path, nil, &q)
+	return &q, err
+}
+
+// CompoundedRateRange returns the compounded index of @symbol in the given date range.
+func (c *Client) CompoundedRateRange(ctx context.Context, symbol string, daysPerYear int, dateInit, dateFinal time.Time) ([]*models.InterestRate, error) {
+	var q []*models.InterestRate
+	err := c.get(ctx, "/v1/compoundedRate"+escape(symbol, strconv.Itoa(daysPerYear)), dateRange(dateInit, dateFinal), &q)
+	return q, err
+}
+
+// CompoundedAvg returns the average of @symbol compounded over @days calendar days before @date.
+func (c *Client) CompoundedAvg(ctx context.Context, symbol string, days, daysPerYear int, date time.Time) (*models.InterestRate, error) {
+	var q models.InterestRate
+	err := c.get(ctx, "/v1/compoundedAvg"+escape(symbol, strconv.Itoa(days), strconv.Itoa(daysPerYear), date.Format("2006-01-02")), nil, &q)
+	return &q, err
+}
+
+// CompoundedAvgRange returns the compounded averages of @symbol for each day in the given date range.
+func (c *Client) CompoundedAvgRange(ctx context.Context, symbol string, days, daysPerYear int, dateInit, dateFinal time.Time) ([]*models.InterestRate, error) {
+	var q []*models.InterestRate
+	err := c.get(ctx, "/v1/compoundedAvg"+escape(symbol, strconv.Itoa(days), strconv.Itoa(daysPerYear)), dateRange(dateInit, dateFinal), &q)
+	return q, err
+}
+
+// CompoundedAvgConvention returns the average of @symbol compounded over @days calendar days before @date
+// following @convention (plain, lookback, obsShift or lockout) with a lag of @lag business days.
+func (c *Client) CompoundedAvgConvention(ctx context.Context, symbol string, days, daysPerYear int, date time.Time, convention string, lag int) (*models.InterestRate, error) {
+	var q models.InterestRate
+	query := url.Values{"convention": []string{convention}, "lag": []string{strconv.Itoa(lag)}}
+	err := c.get(ctx, "/v1/compoundedAvg"+escape(symbol, strconv.Itoa(days), strconv.Itoa(daysPerYear), date.Format("2006-01-02")), query, &q)
+	return &q, err
+}
+
+// CompoundedAvgConventionRange returns the compounded averages of @symbol following @convention with a lag
+// of @lag business days for each business day in the given date range.
+func (c *Client) CompoundedAvgConventionRange(ctx context.Context, symbol string, days, daysPerYear int, dateInit, dateFinal time.Time, convention string, lag int) ([]*models.InterestRate, error) {
+	var q []*models.InterestRate
+	query := dateRange(dateInit, dateFinal)
+	query.Set("convention", convention)
+	query.Set("lag", strconv.Itoa(lag))
+	err := c.get(ctx, "/v1/compoundedAvg"+escape(symbol, strconv.Itoa(days), strconv.Itoa(daysPerYear)), query, &q)
+	return q, err
+}
+
+// CompoundedAvgDIA returns the compounded average of @symbol on @date using DIA's methodology,
+// which assigns a rate to each calendar day.
+func (c *Client) CompoundedAvgDIA(ctx context.Context, symbol string, days, daysPerYear int, date time.Time) ([]*models.InterestRate, error) {
+	var q []*models.InterestRate
+	err := c.get(ctx, "/v1/compoundedAvgDIA"+escape(symbol, strconv.Itoa(days), strconv.Itoa(daysPerYear), date.Format("2006-01-02")), nil, &q)
+	return q, err
+}
+
+// CompoundedAvgDIARange returns the compounded averages of @symbol using DIA's methodology in the given date range.
+func (c *Client) CompoundedAvgDIARange(ctx context.Context, symbol string, days, daysPerYear int, dateInit, dateFinal time.Time) ([]*models.InterestRate, error) {
+	var q []*models.InterestRate
+	err := c.get(ctx, "/v1/compoundedAvgDIA"+escape(symbol, strconv.Itoa(days), strconv.Itoa(daysPerYear)), dateRange(dateInit, dateFinal), &q)
+	return q, err
+}
+
+// RateCurve returns the term structure of the benchmark rate @symbol on @date with zero and forward
+// rates for the standard tenors.
+func (c *Client) RateCurve(ctx context.Context, symbol string, date time.Time) (*dia.TermStructure, error) {
+	var q dia.TermStructure
+	err := c.get(ctx, "/v1/rateCurve"+escape(symbol, date.Format("2006-01-02")), nil, &q)
+	return &q, err
+}
+
+// -----------------------------------------------------------------------------
+// FIAT, STOCKS, FOREIGN QUOTATIONS AND GOLD
+// -----------------------------------------------------------------------------
+
+// FiatQuotations returns the latest fiat exchange rates.
+func (c *Client) FiatQuotations(ctx context.Context) (*models.Change, error) {
+	var q models.Change
+	err := c.get(ctx, "/v1/fiatQuotations", nil, &q)
+	return &q, err
+}
+
+// StockSymbols returns all stocks along with their source.
+func (c *Client) StockSymbols(ctx context.Context) ([]models.SourcedStock, error) {
+	var q []models.SourcedStock
+	err := c.get(ctx, "/v1/stockSymbols", nil, &q)
+	return q, err
+}
+
+// StockQuotation returns the last quotation of @symbol from @source before @timestamp.
+func (c *Client) StockQuotation(ctx context.Context, source, symbol string, timestamp time.Time) (models.StockQuotation, error) {
+	var q models.StockQuotation
+	err := c.get(ctx, "/v1/stockQuotation"+escape(source, symbol, strconv.FormatInt(timestamp.Unix(), 10)), nil, &q)
+	return q, err
+}
+
+// StockQuotations returns the quotations of @symbol from @source in the given time range.
+func (c *Client) StockQuotations(ctx context.Context, source, symbol string, starttime, endtime time.Time) ([]models.StockQuotation, error) {
+	var q []models.StockQuotation
+	err := c.get(ctx, "/v1/stockQuotation"+escape(source, symbol), timeRange("dateInit", starttime, "dateFinal", endtime), &q)
+	return q, err
+}
+
+// ForeignQuotation returns the last quotation of @symbol from @source before @timestamp.
+// A zero @timestamp returns the latest quotation.
+func (c *Client) ForeignQuotation(ctx context.Context, source, symbol string, timestamp time.Time) (models.ForeignQuotation, error) {
+	var q models.ForeignQuotation
+	query := url.Values{}
+	if !timestamp.IsZero() {
+		query.Set("time", strconv.FormatInt(timestamp.Unix(), 10))
+	}
+	err := c.get(ctx, "/v1/foreignQuotation"+escape(source, symbol), query, &q)
+	return q, err
+}
+
+// ForeignSymbols returns all symbols quoted by @source.
+func (c *Client) ForeignSymbols(ctx context.Context, source string) ([]models.SymbolShort, error) {
+	var q []models.SymbolShort
+	err := c.get(ctx, "/v1/foreignSymbols"+escape(source), nil, &q)
+	return q, err
+}
+
+// DefiScore returns the last risk score of the DeFi @protocol with its indices before @timestamp.
+// A zero @timestamp returns the latest score.
+func (c *Client) DefiScore(ctx context.Context, protocol string, timestamp time.Time) (models.DefiScore, error) {
+	var q models.DefiScore
+	query := url.Values{}
+	if !timestamp.IsZero() {
+		query.Set("time", strconv.FormatInt(timestamp.Unix(), 10))
+	}
+	err := c.get(ctx, "/v1/defiScore"+escape(protocol), query, &q)
+	return q, err
+}
+
+// GoldPaxgOunces returns the price of gold per troy ounce as derived from PAXG.
+func (c *Client) GoldPaxgOunces(ctx context.Context) (*models.Quotation, error) {
+	var q models.Quotation
+	err := c.get(ctx, "/v1/goldPaxgOunces", nil, &q)
+	return &q, err
+}
+
+// GoldPaxgGrams returns the price of gold per gram as derived from PAXG.
+func (c *Client) GoldPaxgGrams(ctx context.Context) (*models.Quotation, error) {
+	var q models.Quotation
+	err := c.get(ctx, "/v1/goldPaxgGrams", nil, &q)
+	return &q, err
+}
+
+// -----------------------------------------------------------------------------
+// NFT
+// -----------------------------------------------------------------------------
+
+// NFTCategories returns all NFT categories.
+func (c *Client) NFTCategories(ctx context.Context) ([]string, error) {
+	var q []string
+	err := c.get(ctx, "/v1/NFTCategories", nil, &q)
+	return q, err
+}
+
+// AllNFTClasses returns all NFT classes on @blockchain.
+func (c *Client) AllNFTClasses(ctx context.Context, blockchain string) ([]dia.NFTClass, error) {
+	var q []dia.NFTClass
+	err := c.get(ctx, "/v1/AllNFTClasses"+escape(blockchain), nil, &q)
+	return q, err
+}
+
+// NFTClasses returns @limit NFT classes starting at @offset.
+func (c *Client) NFTClasses(ctx context.Context, limit, offset uint64) ([]dia.NFTClass, error) {
+	var q []dia.NFTClass
+	err := c.get(ctx, "/v1/NFTClasses"+escape(strconv.FormatUint(limit, 10), strconv.FormatUint(offset, 10)), nil, &q)
+	return q, err
+}
+
+// NFT returns the NFT with @id in the class @address on @blockchain.
+func (c *Client) NFT(ctx context.Context, blockchain, address, id string) (dia.NFT, error) {
+	var q dia.NFT
+	err := c.get(ctx, "/v1/NFT"+escape(blockchain, address, id), nil, &q)
+	return q, err
+}
+
+// NFTTrades returns all trades of the NFT with @id in the class @address on @blockchain.
+func (c *Client) NFTTrades(ctx context.Context, blockchain, address, id string) ([]dia.NFTTrade, error) {
+	var q []dia.NFTTrade
+	err := c.get(ctx, "/v1/NFTTrades"+escape(blockchain, address, id), nil, &q)
+	return q, err
+}
+
+// NFTPrice30Days returns the average price of the NFT class @address on @blockchain over the last 30 days.
+func (c *Client) NFTPrice30Days(ctx context.Context, blockchain, address string) (float64, error) {
+	var q float64
+	err := c.get(ctx, "/v1/NFTPrice30Days"+escape(blockchain, address), nil, &q)
+	return q, err
+}
+
+// NFTMetadata returns the metadata and rarity of the NFT with @id in the class @address on @blockchain.
+func (c *Client) NFTMetadata(ctx context.Context, blockchain, address, id string) (dia.NFTMetadata, error) {
+	var q dia.NFTMetadata
+	err := c.get(ctx, "/v1/NFTMetadata"+escape(blockchain, address, id), nil, &q)
+	return q, err
+}
+
+// NFTTraits returns the rarities of the trait values in the NFT class @address on @blockchain.
+func (c *Client) NFTTraits(ctx context.Context, blockchain, address string) ([]dia.NFTTraitRarity, error) {
+	var q []dia.NFTTraitRarity
+	err := c.get(ctx, "/v1/NFTTraits"+escape(blockchain, address), nil, &q)
+	return q, err
+}
+
+// NFTOrderbook returns the active bids and offers on the NFTs of the class @address on @blockchain.
+func (c *Client) NFTOrderbook(ctx context.Context, blockchain, address string) (dia.NFTOrderbook, error) {
+	var q dia.NFTOrderbook
+	err := c.get(ctx, "/v1/NFTOrderbook"+escape(blockchain, address), nil, &q)
+	return q, err
+}
+
+// NFTBidAsk returns the best active bid and offer on the NFT with @id in the class @address on @blockchain,
+// or on any NFT of the class if @id is empty.
+func (c *Client) NFTBidAsk(ctx context.Context, blockchain, address, id string) (dia.NFTBidAsk, error) {
+	var q dia.NFTBidAsk
+	path := "/v1/NFTBidAsk" + escape(blockchain, address)
+	if id != "" {
+		path = "/v1/NFTBidAsk" + escape(blockchain, address, id)
+	}
+	err := c.get(ctx, path, nil, &q)
+	return q, err
+}
+
+// NFTSpread returns the best bid and offer on the NFT class @address on @blockchain every @interval
+// between @starttime and @endtime. Zero values select the server defaults.
+func (c *Client) NFTSpread(ctx context.Context, blockchain, address string, starttime, endtime time.Time, interval time.Duration) ([]dia.NFTBidAsk, error) {
+	var q []dia.NFTBidAsk
+	query := timeRange("starttime", starttime, "endtime", endtime)
+	if interval > 0 {
+		query.Set("interval", strconv.FormatInt(int64(interval/time.Second), 10))
+	}
+	err := c.get(ctx, "/v1/NFTSpread"+escape(blockchain, address), query, &q)
+	return q, err
+}
+
+// NFTValuation returns the latest appraisal of the NFT with @id in the class @address on @blockchain
+// done at or before @t. A zero @t selects the latest appraisal.
+func (c *Client) NFTValuation(ctx context.Context, blockchain, address, id string, t time.Time) (dia.NFTValuation, error) {
+	var q dia.NFTValuation
+	query := url.Values{}
+	if !t.IsZero() {
+		query.Set("time", strconv.FormatInt(t.Unix(), 10))
+	}
+	err := c.get(ctx, "/v1/NFTValuation"+escape(blockchain, address, id), query, &q)
+	return q, err
+}
@@ -0,0 +1,112 @@
+package apiKeyApi
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/http/restApi"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// Env contains the store of API keys used by the admin endpoints.
+type Env struct {
+	RelDB models.RelDatastore
+}
+
+type createAPIKeyRequest struct {
+	Owner string `json:"owner" binding:"required"`
+	Tier  string `json:"tier" binding:"required"`
+}
+
+// CreatedAPIKey is returned on issuing a key. Key is only returned once.
+type CreatedAPIKey struct {
+	Key string
+	models.APIKey
+}
+
+// APIKeyUsage is the number of requests per route of a key in a month.
+type APIKeyUsage struct {
+	ID    string
+	Month string
+	Total int64
+	Usage map[string]int64
+}
+
+// PostAPIKey issues a new API key for the owner and tier given in the JSON body.
+func (env *Env) PostAPIKey(c *gin.Context) {
+	var req createAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		restApi.SendError(c, http.StatusBadRequest, err)
+		return
+	}
+	if _, ok := models.APIKeyTiers[req.Tier]; !ok || req.Tier == models.APIKeyTierAnonymous {
+		restApi.SendError(c, http.StatusBadRequest, errors.New("unknown tier "+req.Tier))
+		return
+	}
+	key, apiKey, err := env.RelDB.CreateAPIKey(req.Owner, req.Tier)
+	if err != nil {
+		log.Errorln("PostAPIKey:", err)
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
+	log.Infof("issued api key %s (%s) for %s in tier %s", apiKey.ID, apiKey.Prefix, apiKey.Owner, apiKey.Tier)
+	c.JSON(http.StatusOK, CreatedAPIKey{Key: key, APIKey: apiKey})
+}
+
+// GetAPIKeys returns all issued keys, restricted to an owner by the query parameter owner.
+func (env *Env) GetAPIKeys(c *gin.Context) {
+	apiKeys, err := env.RelDB.GetAPIKeys(c.Query("owner"))
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, apiKeys)
+}
+
+// DeleteAPIKey revokes the key with the given id.
+func (env *Env) DeleteAPIKey(c *gin.Context) {
+	id := c.Param("id")
+	err := env.RelDB.RevokeAPIKey(id)
+	if err == models.ErrAPIKeyNotFound {
+		restApi.SendError(c, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
+	log.Infof("revoked api key %s", id)
+	c.Status(http.StatusNoContent)
+}
+
+// GetAPIKeyUsage returns the requests per route of the key with the given id in the month
+// given by the query parameter month in the format 2006-01, by default the current month.
+func (env *Env) GetAPIKeyUsage(c *gin.Context) {
+	month := time.Now()
+	if monthQuery := c.Query("month"); monthQuery != "" {
+		var err error
+		month, err = time.Parse("2006-01", monthQuery)
+		if err != nil {
+			restApi.SendError(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+	id := c.Param("id")
+	usage, err := env.RelDB.GetAPIKeyUsage(id, month)
+	if err == models.ErrAPICountersUnavailable {
+		restApi.SendError(c, http.StatusServiceUnavailable, err)
+		return
+	}
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
+	result := APIKeyUsage{ID: id, Month: month.Format("2006-01"), Usage: usage}
+	for _, count := range usage {
+		result.Total += count
+	}
+	c.JSON(http.StatusOK, result)
+}
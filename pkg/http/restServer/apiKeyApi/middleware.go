@@ -0,0 +1,158 @@
+package apiKeyApi
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/http/restApi"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	HeaderAPIKey             = "X-API-KEY"
+	HeaderRateLimitLimit     = "X-RateLimit-Limit"
+	HeaderRateLimitRemaining = "X-RateLimit-Remaining"
+	HeaderRateLimitReset     = "X-RateLimit-Reset"
+	HeaderRetryAfter         = "Retry-After"
+	HeaderQuotaLimit         = "X-Quota-Limit"
+	HeaderQuotaRemaining     = "X-Quota-Remaining"
+
+	// queryAPIKey is the query parameter accepted instead of HeaderAPIKey.
+	queryAPIKey = "apikey"
+	// contextAPIKey is the gin context key of the models.APIKey of authenticated requests.
+	contextAPIKey = "apikey"
+
+	rateLimitWindow = time.Minute
+)
+
+var (
+	errInvalidAPIKey  = errors.New("invalid api key")
+	errRevokedAPIKey  = errors.New("api key has been revoked")
+	errRateLimit      = errors.New("rate limit exceeded")
+	errQuotaExhausted = errors.New("monthly quota exhausted")
+)
+
+// RateLimiter is a gin middleware enforcing the rate limits and quotas of API key tiers.
+type RateLimiter struct {
+	store     models.RelDatastore
+	anonymous models.APIKeyTier
+	now       func() time.Time
+}
+
+// NewRateLimiter returns a rate limiter keeping its counters in @store. Requests without API key
+// are limited to @anonymousRequestsPerMinute per client IP, or not at all if it is 0.
+func NewRateLimiter(store models.RelDatastore, anonymousRequestsPerMinute int64) *RateLimiter {
+	return &RateLimiter{
+		store:     store,
+		anonymous: models.APIKeyTier{Name: models.APIKeyTierAnonymous, RequestsPerMinute: anonymousRequestsPerMinute},
+		now:       time.Now,
+	}
+}
+
+// GetAPIKey returns the API key of a request which passed the middleware, if any.
+func GetAPIKey(c *gin.Context) (models.APIKey, bool) {
+	value, ok := c.Get(contextAPIKey)
+	if !ok {
+		return models.APIKey{}, false
+	}
+	apiKey, ok := value.(models.APIKey)
+	return apiKey, ok
+}
+
+// Handle authenticates requests by the API key in HeaderAPIKey or the query parameter apikey.
+// Requests without key are limited per client IP if configured, requests with invalid
+// or revoked keys are rejected. Requests of keys are counted per route for usage accounting.
+// If the counters are not available, requests are let through.
+func (rl *RateLimiter) Handle(c *gin.Context) {
+	key := c.GetHeader(HeaderAPIKey)
+	if key == "" {
+		key = c.Query(queryAPIKey)
+	}
+
+	clientID := "ip_" + c.ClientIP()
+	tier := rl.anonymous
+	if key != "" {
+		apiKey, err := rl.store.GetAPIKey(key)
+		if err != nil {
+			if err != models.ErrAPIKeyNotFound {
+				log.Errorln("RateLimiter: error getting api key:", err)
+				restApi.SendError(c, http.StatusInternalServerError, errors.New("error verifying api key"))
+			} else {
+				restApi.SendError(c, http.StatusUnauthorized, errInvalidAPIKey)
+			}
+			c.Abort()
+			return
+		}
+		if apiKey.Revoked() {
+			restApi.SendError(c, http.StatusUnauthorized, errRevokedAPIKey)
+			c.Abort()
+			return
+		}
+		var ok bool
+		if tier, ok = models.APIKeyTiers[apiKey.Tier]; !ok {
+			log.Errorf("RateLimiter: unknown tier %s of api key %s", apiKey.Tier, apiKey.ID)
+			restApi.SendError(c, http.StatusUnauthorized, errInvalidAPIKey)
+			c.Abort()
+			return
+		}
+		clientID = apiKey.ID
+		c.Set(contextAPIKey, apiKey)
+	}
+
+	now := rl.now()
+	if tier.RequestsPerMinute > 0 {
+		count, err := rl.store.IncrementRateLimitCounter(clientID, rateLimitWindow, now)
+		if err != nil {
+			if err != models.ErrAPICountersUnavailable {
+				log.Errorln("RateLimiter: error counting request:", err)
+			}
+		} else {
+			reset := now.Truncate(rateLimitWindow).Add(rateLimitWindow)
+			remaining := tier.RequestsPerMinute - count
+			if remaining < 0 {
+				remaining = 0
+			}
+			c.Header(HeaderRateLimitLimit, strconv.FormatInt(tier.RequestsPerMinute, 10))
+			c.Header(HeaderRateLimitRemaining, strconv.FormatInt(remaining, 10))
+			c.Header(HeaderRateLimitReset, strconv.FormatInt(reset.Unix(), 10))
+			if count > tier.RequestsPerMinute {
+				c.Header(HeaderRetryAfter, strconv.Itoa(int(reset.Sub(now).Seconds())+1))
+				restApi.SendError(c, http.StatusTooManyRequests, errRateLimit)
+				c.Abort()
+				return
+			}
+		}
+	}
+
+	if key != "" {
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+		count, err := rl.store.IncrementAPIKeyUsage(clientID, route, now)
+		if err != nil {
+			if err != models.ErrAPICountersUnavailable {
+				log.Errorln("RateLimiter: error accounting usage:", err)
+			}
+		} else if tier.MonthlyQuota > 0 {
+			remaining := tier.MonthlyQuota - count
+			if remaining < 0 {
+				remaining = 0
+			}
+			c.Header(HeaderQuotaLimit, strconv.FormatInt(tier.MonthlyQuota, 10))
+			c.Header(HeaderQuotaRemaining, strconv.FormatInt(remaining, 10))
+			if count > tier.MonthlyQuota {
+				// No Retry-After, as clients should not wait for the next month.
+				restApi.SendError(c, http.StatusForbidden, errQuotaExhausted)
+				c.Abort()
+				return
+			}
+		}
+	}
+
+	c.Next()
+}
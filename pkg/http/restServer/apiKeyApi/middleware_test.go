@@ -0,0 +1,132 @@
+package apiKeyApi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/gin-gonic/gin"
+)
+
+// fakeStore keeps api keys and counters in memory.
+type fakeStore struct {
+	models.RelDatastore
+	keys     map[string]models.APIKey
+	counters map[string]int64
+	usage    map[string]int64
+}
+
+func (s *fakeStore) GetAPIKey(key string) (models.APIKey, error) {
+	apiKey, ok := s.keys[key]
+	if !ok {
+		return apiKey, models.ErrAPIKeyNotFound
+	}
+	return apiKey, nil
+}
+
+func (s *fakeStore) IncrementRateLimitCounter(id string, window time.Duration, t time.Time) (int64, error) {
+	key := id + strconv.FormatInt(t.Truncate(window).Unix(), 10)
+	s.counters[key]++
+	return s.counters[key], nil
+}
+
+func (s *fakeStore) IncrementAPIKeyUsage(id string, route string, t time.Time) (int64, error) {
+	s.usage[id+route]++
+	s.counters[id+t.Format("200601")]++
+	return s.counters[id+t.Format("200601")], nil
+}
+
+func TestRateLimiter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	revokedAt := time.Now()
+	store := &fakeStore{
+		keys: map[string]models.APIKey{
+			"free":    {ID: "1", Tier: "free"},
+			"revoked": {ID: "2", Tier: "pro", RevokedAt: &revokedAt},
+		},
+		counters: map[string]int64{},
+		usage:    map[string]int64{},
+	}
+	now := time.Date(2021, 6, 1, 12, 0, 30, 0, time.UTC)
+	rl := NewRateLimiter(store, 30)
+	rl.now = func() time.Time { return now }
+
+	r := gin.New()
+	r.Use(rl.Handle)
+	r.GET("/v1/quotation/:symbol", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	request := func(key string, header bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/quotation/BTC", nil)
+		if header {
+			req.Header.Set(HeaderAPIKey, key)
+		} else if key != "" {
+			req.URL.RawQuery = "apikey=" + key
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := request("unknown", true); w.Code != http.StatusUnauthorized {
+		t.Errorf("unknown key: status %d", w.Code)
+	}
+	if w := request("revoked", true); w.Code != http.StatusUnauthorized {
+		t.Errorf("revoked key: status %d", w.Code)
+	}
+
+	// Anonymous requests are limited per minute.
+	for i := 1; i <= 30; i++ {
+		if w := request("", false); w.Code != http.StatusOK {
+			t.Fatalf("anonymous request %d: status %d", i, w.Code)
+		}
+	}
+	w := request("", false)
+	if w.Code != http.StatusTooManyRequests || w.Header().Get(HeaderRetryAfter) != "31" || w.Header().Get(HeaderRateLimitRemaining) != "0" {
+		t.Errorf("exceeding anonymous limit: status %d, headers %v", w.Code, w.Header())
+	}
+	if w.Header().Get(HeaderRateLimitReset) != strconv.FormatInt(now.Truncate(time.Minute).Add(time.Minute).Unix(), 10) {
+		t.Errorf("unexpected reset %s", w.Header().Get(HeaderRateLimitReset))
+	}
+
+	// Keys are not affected by the anonymous limit and their usage is accounted per route.
+	w = request("free", false)
+	if w.Code != http.StatusOK || w.Header().Get(HeaderRateLimitRemaining) != "59" || w.Header().Get(HeaderQuotaRemaining) != "99999" {
+		t.Errorf("free key: status %d, headers %v", w.Code, w.Header())
+	}
+	if store.usage["1/v1/quotation/:symbol"] != 1 {
+		t.Errorf("unexpected usage %v", store.usage)
+	}
+
+	// Exhausted quotas are rejected without Retry-After.
+	now = now.Add(time.Minute)
+	store.counters["1"+now.Format("200601")] = models.APIKeyTiers["free"].MonthlyQuota
+	w = request("free", true)
+	if w.Code != http.StatusForbidden || w.Header().Get(HeaderRetryAfter) != "" || w.Header().Get(HeaderQuotaRemaining) != "0" {
+		t.Errorf("exhausted quota: status %d, headers %v", w.Code, w.Header())
+	}
+}
+
+func TestRateLimiterAnonymousUnlimited(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := &fakeStore{counters: map[string]int64{}, usage: map[string]int64{}}
+	r := gin.New()
+	r.Use(NewRateLimiter(store, 0).Handle)
+	r.GET("/v1/quotation/:symbol", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	for i := 1; i <= 100; i++ {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/quotation/BTC", nil))
+		if w.Code != http.StatusOK || w.Header().Get(HeaderRateLimitLimit) != "" {
+			t.Fatalf("anonymous request %d: status %d, headers %v", i, w.Code, w.Header())
+		}
+	}
+	if len(store.counters) != 0 {
+		t.Errorf("unexpected counters %v", store.counters)
+	}
+}
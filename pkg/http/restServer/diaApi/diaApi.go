@@ -10,14 +10,17 @@ import (
 	"time"
 
 	"github.com/diadata-org/diadata/internal/pkg/indexCalculationService"
+	ratederivatives "github.com/diadata-org/diadata/internal/pkg/rateDerivatives"
 	"github.com/diadata-org/diadata/pkg/dia"
 	"github.com/diadata-org/diadata/pkg/dia/helpers"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/nfthelper"
 	"github.com/diadata-org/diadata/pkg/http/restApi"
 	models "github.com/diadata-org/diadata/pkg/model"
 	"github.com/diadata-org/diadata/pkg/utils"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis"
+	"github.com/jackc/pgx/v4"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -530,6 +533,62 @@ func (env *Env) GetChartPointsAllExchanges(c *gin.Context) {
 	}
 }
 
+const (
+	candlesDefaultLimit = 500
+	candlesMaxLimit     = 1000
+)
+
+// GetCandles returns OHLCV candles of a symbol, aggregated over all exchanges or on the exchange
+// given by the query parameter exchange. Further query parameters are resolution (1m, 5m, 15m, 1h,
+// 4h or 1d, default 1h), starttime and endtime as unix timestamps (default the last 24 hours),
+// limit (default 500, at most 1000) and offset for pagination.
+func (env *Env) GetCandles(c *gin.Context) {
+	symbol := c.Param("symbol")
+	exchange := c.Query("exchange")
+	resolution := c.DefaultQuery("resolution", "1h")
+	if _, ok := models.CandleResolutions[resolution]; !ok {
+		restApi.SendError(c, http.StatusBadRequest, errors.New("unsupported resolution "+resolution))
+		return
+	}
+
+	endtime := time.Now()
+	if endtimeStr := c.Query("endtime"); endtimeStr != "" {
+		endtimeInt, err := strconv.ParseInt(endtimeStr, 10, 64)
+		if err != nil {
+			restApi.SendError(c, http.StatusBadRequest, err)
+			return
+		}
+		endtime = time.Unix(endtimeInt, 0)
+	}
+	starttime := endtime.Add(-24 * time.Hour)
+	if starttimeStr := c.Query("starttime"); starttimeStr != "" {
+		starttimeInt, err := strconv.ParseInt(starttimeStr, 10, 64)
+		if err != nil {
+			restApi.SendError(c, http.StatusBadRequest, err)
+			return
+		}
+		starttime = time.Unix(starttimeInt, 0)
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(candlesDefaultLimit)))
+	if err != nil || limit <= 0 || limit > candlesMaxLimit {
+		restApi.SendError(c, http.StatusBadRequest, fmt.Errorf("limit must be between 1 and %d", candlesMaxLimit))
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		restApi.SendError(c, http.StatusBadRequest, errors.New("offset must be a non-negative integer"))
+		return
+	}
+
+	candles, err := env.DataStore.GetCandles(symbol, exchange, resolution, starttime, endtime, limit, offset)
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, candles)
+}
+
 // GetAllSymbols returns all symbols available in our (redis) database.
 // Optional query parameter exchange returns only symbols available on this exchange.
 func (env *Env) GetAllSymbols(c *gin.Context) {
@@ -624,6 +683,177 @@ func (env *Env) GetCryptoDerivative(c *gin.Context) {
 
 }
 
+// -----------------------------------------------------------------------------
+// FUTURES
+// -----------------------------------------------------------------------------
+
+// GetFundingRates returns the funding rates of the perpetual futures @market on @exchange.
+// Optional query parameters starttime and endtime are unix timestamps (default the last 7 days).
+func (env *Env) GetFundingRates(c *gin.Context) {
+	exchange := c.Param("exchange")
+	market := c.Param("market")
+
+	endtime := time.Now()
+	if endtimeStr := c.Query("endtime"); endtimeStr != "" {
+		endtimeInt, err := strconv.ParseInt(endtimeStr, 10, 64)
+		if err != nil {
+			restApi.SendError(c, http.StatusBadRequest, err)
+			return
+		}
+		endtime = time.Unix(endtimeInt, 0)
+	}
+	starttime := endtime.AddDate(0, 0, -7)
+	if starttimeStr := c.Query("starttime"); starttimeStr != "" {
+		starttimeInt, err := strconv.ParseInt(starttimeStr, 10, 64)
+		if err != nil {
+			restApi.SendError(c, http.StatusBadRequest, err)
+			return
+		}
+		starttime = time.Unix(starttimeInt, 0)
+	}
+
+	rates, err := env.DataStore.GetFundingRates(exchange, market, starttime, endtime)
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
+	if len(rates) == 0 {
+		restApi.SendError(c, http.StatusNotFound, errors.New("no funding rates for "+exchange+" "+market))
+		return
+	}
+	c.JSON(http.StatusOK, rates)
+}
+
+// GetFuturesBasis returns the basis of the futures @market on @exchange, i.e. the difference
+// of its last traded price and the price of its underlying.
+func (env *Env) GetFuturesBasis(c *gin.Context) {
+	exchange := c.Param("exchange")
+	market := c.Param("market")
+
+	basis, err := env.DataStore.GetFuturesBasis(exchange, market)
+	if err != nil {
+		if err == models.ErrFuturesMarketNotFound || err == redis.Nil {
+			restApi.SendError(c, http.StatusNotFound, err)
+		} else {
+			restApi.SendError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, basis)
+}
+
+// -----------------------------------------------------------------------------
+// OPTIONS
+// -----------------------------------------------------------------------------
+
+// optionExpiry returns the optional expiry path parameter in the format yyyy-mm-dd,
+// or the zero time if it is not given.
+func optionExpiry(c *gin.Context) (time.Time, error) {
+	if c.Param("expiry") == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse("2006-01-02", c.Param("expiry"))
+}
+
+// GetOptionGreeks returns the implied volatility and greeks of all options on an underlying.
+// The optional expiry yyyy-mm-dd restricts the result to options expiring on that day.
+func (env *Env) GetOptionGreeks(c *gin.Context) {
+	underlying := c.Param("underlying")
+	expiry, err := optionExpiry(c)
+	if err != nil {
+		restApi.SendError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	greeks, err := env.DataStore.GetOptionGreeks(underlying, expiry)
+	if err != nil {
+		if err == redis.Nil {
+			restApi.SendError(c, http.StatusNotFound, err)
+		} else {
+			restApi.SendError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, greeks)
+}
+
+// GetOptionExpirySummaries returns the open interest weighted put/call ratio and max pain per expiry
+// of the options on an underlying. The optional expiry yyyy-mm-dd restricts the result to that day.
+func (env *Env) GetOptionExpirySummaries(c *gin.Context) {
+	underlying := c.Param("underlying")
+	expiry, err := optionExpiry(c)
+	if err != nil {
+		restApi.SendError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	summaries, err := env.DataStore.GetOptionExpirySummaries(underlying, expiry)
+	if err != nil {
+		if err == redis.Nil {
+			restApi.SendError(c, http.StatusNotFound, err)
+		} else {
+			restApi.SendError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, summaries)
+}
+
+// GetLiveOnchainOptions returns the active on-chain option series with their latest quotes.
+// The optional query parameter protocol restricts the result to one protocol.
+func (env *Env) GetLiveOnchainOptions(c *gin.Context) {
+	protocols := []string{dia.Opyn, dia.Premia}
+	if protocol := c.Query("protocol"); protocol != "" {
+		protocols = []string{protocol}
+	}
+
+	series := []models.OnchainOptionSeries{}
+	for _, protocol := range protocols {
+		s, err := env.DataStore.GetLiveOnchainOptions(protocol)
+		if err != nil {
+			restApi.SendError(c, http.StatusInternalServerError, err)
+			return
+		}
+		series = append(series, s...)
+	}
+	c.JSON(http.StatusOK, series)
+}
+
+// GetOptionExercises returns the exercise and settlement events of an on-chain option series.
+// If no times are set the last 7 days are returned.
+func (env *Env) GetOptionExercises(c *gin.Context) {
+	protocol := c.Param("protocol")
+	instrument := c.Param("instrument")
+	starttimeStr := c.Query("starttime")
+	endtimeStr := c.Query("endtime")
+
+	endtime := time.Now()
+	if endtimeStr != "" {
+		endtimeInt, err := strconv.ParseInt(endtimeStr, 10, 64)
+		if err != nil {
+			restApi.SendError(c, http.StatusBadRequest, err)
+			return
+		}
+		endtime = time.Unix(endtimeInt, 0)
+	}
+	starttime := endtime.AddDate(0, 0, -7)
+	if starttimeStr != "" {
+		starttimeInt, err := strconv.ParseInt(starttimeStr, 10, 64)
+		if err != nil {
+			restApi.SendError(c, http.StatusBadRequest, err)
+			return
+		}
+		starttime = time.Unix(starttimeInt, 0)
+	}
+
+	exercises, err := env.DataStore.GetOptionExercises(protocol, instrument, starttime, endtime)
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, exercises)
+}
+
 // -----------------------------------------------------------------------------
 // DeFi LENDING RATES
 // -----------------------------------------------------------------------------
@@ -754,6 +984,60 @@ func (env *Env) GetDefiState(c *gin.Context) {
 	}
 }
 
+// GetDefiMarketState is the delegate method to fetch the state(s) of the market
+// @asset on the lending protocol @protocol, i.e. supplied, borrowed and reserve amounts in USD,
+// utilisation, collateral factor and liquidation threshold.
+// Last value is retrieved. Otional query parameters allow to obtain data in a time range.
+func (env *Env) GetDefiMarketState(c *gin.Context) {
+	protocol := c.Param("protocol")
+	asset := c.Param("asset")
+	date := c.Param("time")
+	// Add optional query parameters for requesting a range of values
+	dateInit := c.DefaultQuery("dateInit", "noRange")
+	dateFinal := c.Query("dateFinal")
+
+	if dateInit == "noRange" {
+		// Return most recent data point
+		endtime := time.Time{}
+		var err error
+		if date == "" {
+			endtime = time.Now()
+		} else {
+			// Convert unix time int/string to time
+			endtime, err = utils.StrToUnixtime(date)
+			if err != nil {
+				restApi.SendError(c, http.StatusNotFound, err)
+				return
+			}
+		}
+		starttime := endtime.AddDate(0, 0, -1)
+
+		q, err := env.DataStore.GetDefiMarketStateInflux(starttime, endtime, protocol, asset)
+		if err != nil || len(q) == 0 {
+			restApi.SendError(c, http.StatusNotFound, err)
+		} else {
+			c.JSON(http.StatusOK, q[len(q)-1])
+		}
+	} else {
+		starttime, err := utils.StrToUnixtime(dateInit)
+		if err != nil {
+			restApi.SendError(c, http.StatusNotFound, err)
+			return
+		}
+		endtime, err := utils.StrToUnixtime(dateFinal)
+		if err != nil {
+			restApi.SendError(c, http.StatusNotFound, err)
+			return
+		}
+		q, err := env.DataStore.GetDefiMarketStateInflux(starttime, endtime, protocol, asset)
+		if err != nil {
+			restApi.SendError(c, http.StatusNotFound, err)
+		} else {
+			c.JSON(http.StatusOK, q)
+		}
+	}
+}
+
 // -----------------------------------------------------------------------------
 // FARMING POOLS
 // -----------------------------------------------------------------------------
@@ -962,6 +1246,24 @@ func (env *Env) GetCompoundedAvg(c *gin.Context) {
 
 	rounding := 0
 
+	// Optional query parameters for compounding conventions
+	convention, ok, err := compoundingConvention(c)
+	if err != nil {
+		restApi.SendError(c, http.StatusBadRequest, err)
+		return
+	}
+	if ok {
+		q, err := env.compoundedAvgConvention(symbol, date, dateInitstring, dateFinalstring, calDays, daysPerYear, rounding, convention)
+		if err != nil {
+			restApi.SendError(c, http.StatusNotFound, err)
+		} else if dateInitstring == "noRange" {
+			c.JSON(http.StatusOK, q[0])
+		} else {
+			c.JSON(http.StatusOK, q)
+		}
+		return
+	}
+
 	if dateInitstring == "noRange" {
 
 		// Compute compunded rate and return if no error
@@ -1027,6 +1329,25 @@ func (env *Env) GetCompoundedAvgDIA(c *gin.Context) {
 
 	rounding := 0
 
+	// Optional query parameters for compounding conventions
+	convention, ok, err := compoundingConvention(c)
+	if err != nil {
+		restApi.SendError(c, http.StatusBadRequest, err)
+		return
+	}
+	if ok {
+		convention.Daily = true
+		q, err := env.compoundedAvgConvention(symbol, date, dateInitstring, dateFinalstring, calDays, daysPerYear, rounding, convention)
+		if err != nil {
+			restApi.SendError(c, http.StatusNotFound, err)
+		} else if dateInitstring == "noRange" {
+			c.JSON(http.StatusOK, q[0])
+		} else {
+			c.JSON(http.StatusOK, q)
+		}
+		return
+	}
+
 	if dateInitstring == "noRange" {
 
 		// In this method, there is a rate for every calendar day. Hence, the compounded rate
@@ -1072,6 +1393,104 @@ func (env *Env) GetCompoundedAvgDIA(c *gin.Context) {
 	fmt.Println("time elapsed in API call: ", tFinal.Sub(tInit))
 }
 
+// GetInterestRateReport is the delegate method to fetch a data quality report on the fixings of
+// the interest rate with symbol @symbol in the range given by the query parameters dateInit and dateFinal.
+// The range defaults to the last 30 days.
+func (env *Env) GetInterestRateReport(c *gin.Context) {
+	symbol := c.Param("symbol")
+	dateFinal := time.Now()
+	dateInit := dateFinal.AddDate(0, 0, -30)
+	var err error
+	if dateInitstring := c.Query("dateInit"); dateInitstring != "" {
+		dateInit, err = time.Parse("2006-01-02", dateInitstring)
+		if err != nil {
+			restApi.SendError(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+	if dateFinalstring := c.Query("dateFinal"); dateFinalstring != "" {
+		dateFinal, err = time.Parse("2006-01-02", dateFinalstring)
+		if err != nil {
+			restApi.SendError(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	q, err := env.DataStore.GetInterestRateReport(symbol, dateInit, dateFinal)
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, q)
+}
+
+// GetRateCurve is the delegate method to fetch the term structure of the benchmark rate @symbol
+// at the date @date, given as yyyy-mm-dd.
+func (env *Env) GetRateCurve(c *gin.Context) {
+	symbol := c.Param("symbol")
+	date, err := time.Parse("2006-01-02", c.Param("date"))
+	if err != nil {
+		restApi.SendError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	q, err := env.DataStore.GetRateCurve(symbol, date)
+	if err != nil {
+		if err == redis.Nil {
+			restApi.SendError(c, http.StatusNotFound, err)
+		} else {
+			restApi.SendError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, q)
+}
+
+// compoundingConvention returns the compounding convention given by the optional query parameters
+// convention and lag. The boolean is false if neither is given.
+func compoundingConvention(c *gin.Context) (ratederivatives.Convention, bool, error) {
+	name := c.Query("convention")
+	lagstring := c.Query("lag")
+	if name == "" && lagstring == "" {
+		return ratederivatives.Convention{}, false, nil
+	}
+	lag := 0
+	if lagstring != "" {
+		var err error
+		lag, err = strconv.Atoi(lagstring)
+		if err != nil {
+			return ratederivatives.Convention{}, false, err
+		}
+	}
+	convention, err := ratederivatives.ParseConvention(name, lag)
+	return convention, true, err
+}
+
+// compoundedAvgConvention returns the compounded averages following @convention on @date or,
+// for range queries, in the range from @dateInitstring to @dateFinalstring.
+func (env *Env) compoundedAvgConvention(symbol string, date time.Time, dateInitstring, dateFinalstring string, calDays, daysPerYear int, rounding int, convention ratederivatives.Convention) ([]*models.InterestRate, error) {
+	dateInit, dateFinal := date, date.AddDate(0, 0, 1)
+	if dateInitstring != "noRange" {
+		var err error
+		dateInit, err = time.Parse("2006-01-02", dateInitstring)
+		if err != nil {
+			return nil, err
+		}
+		dateFinal, err = time.Parse("2006-01-02", dateFinalstring)
+		if err != nil {
+			return nil, err
+		}
+	}
+	q, err := env.DataStore.GetCompoundedAvgConventionRange(symbol, dateInit, dateFinal, calDays, daysPerYear, rounding, convention)
+	if err != nil {
+		return nil, err
+	}
+	if len(q) == 0 {
+		return nil, errors.New("no rate information for holidays or weekends")
+	}
+	return q, nil
+}
+
 // GetRates is the delegate method for fetching all rate types
 // present in the (redis) database.
 func (env *Env) GetRates(c *gin.Context) {
@@ -1108,11 +1527,7 @@ func (env *Env) GetFiatQuotations(c *gin.Context) {
 // -----------------------------------------------------------------------------
 
 func (env *Env) GetStockSymbols(c *gin.Context) {
-	type sourcedStock struct {
-		Stock  models.Stock
-		Source string
-	}
-	var srcStocks []sourcedStock
+	var srcStocks []models.SourcedStock
 	stocks, err := env.DataStore.GetStockSymbols()
 	log.Info("stocks: ", stocks)
 
@@ -1124,7 +1539,7 @@ func (env *Env) GetStockSymbols(c *gin.Context) {
 		}
 	} else {
 		for stock, source := range stocks {
-			srcStocks = append(srcStocks, sourcedStock{
+			srcStocks = append(srcStocks, models.SourcedStock{
 				Stock:  stock,
 				Source: source,
 			})
@@ -1224,6 +1639,31 @@ func (env *Env) GetForeignQuotation(c *gin.Context) {
 	}
 }
 
+// GetDefiScore returns the latest risk score of a DeFi protocol together with its indices, or the
+// latest one before the unix timestamp given in the query parameter time.
+func (env *Env) GetDefiScore(c *gin.Context) {
+	protocol := c.Param("protocol")
+	timestamp := time.Now()
+	if timeStr := c.Query("time"); timeStr != "" {
+		timeInt, err := strconv.ParseInt(timeStr, 10, 64)
+		if err != nil {
+			restApi.SendError(c, http.StatusBadRequest, err)
+			return
+		}
+		timestamp = time.Unix(timeInt, 0)
+	}
+	q, err := env.DataStore.GetDefiScoreInflux(protocol, timestamp)
+	if err != nil {
+		if errors.Is(err, models.ErrDefiScoreNotFound) {
+			restApi.SendError(c, http.StatusNotFound, err)
+		} else {
+			restApi.SendError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, q)
+}
+
 // GetForeignSymbols returns all symbols available for quotation from @source, along with their ITIN
 func (env *Env) GetForeignSymbols(c *gin.Context) {
 	source := c.Param("source")
@@ -1471,6 +1911,175 @@ func (env *Env) GetNFTTrades(c *gin.Context) {
 	c.JSON(http.StatusOK, q)
 }
 
+// GetNFTMetadata returns the on- and off-chain metadata and the rarity of an NFT.
+func (env *Env) GetNFTMetadata(c *gin.Context) {
+	blockchain := c.Param("blockchain")
+	address := common.HexToAddress(c.Param("address")).Hex()
+	id := c.Param("id")
+
+	q, err := env.RelDB.GetNFTMetadata(address, blockchain, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			restApi.SendError(c, http.StatusNotFound, err)
+		} else {
+			restApi.SendError(c, http.StatusInternalServerError, nil)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, q)
+}
+
+// GetNFTTraits returns the rarities of all trait values in an nft class, rarest first.
+func (env *Env) GetNFTTraits(c *gin.Context) {
+	blockchain := c.Param("blockchain")
+	address := common.HexToAddress(c.Param("address")).Hex()
+
+	nftClass, err := env.RelDB.GetNFTClass(address, blockchain)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			restApi.SendError(c, http.StatusNotFound, err)
+		} else {
+			restApi.SendError(c, http.StatusInternalServerError, nil)
+		}
+		return
+	}
+	q, err := env.RelDB.GetNFTTraitRarities(nftClass)
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, nil)
+		return
+	}
+	c.JSON(http.StatusOK, q)
+}
+
+// maxNFTSpreadPoints limits the length of the bid-ask spread series of an nft class.
+const maxNFTSpreadPoints = 1000
+
+// nftOrderHistory loads the bids, offers and closes of the nft class given by the path parameters
+// which determine its orderbook from @starttime to @endtime. It sends an error and returns false if
+// the class cannot be loaded.
+func (env *Env) nftOrderHistory(c *gin.Context, starttime time.Time, endtime time.Time) (*nfthelper.OrderHistory, dia.NFTClass, bool) {
+	blockchain := c.Param("blockchain")
+	address := common.HexToAddress(c.Param("address")).Hex()
+
+	nftClass, err := env.RelDB.GetNFTClass(address, blockchain)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			restApi.SendError(c, http.StatusNotFound, err)
+		} else {
+			restApi.SendError(c, http.StatusInternalServerError, nil)
+		}
+		return nil, nftClass, false
+	}
+	bids, err := env.RelDB.GetNFTBids(nftClass, starttime, endtime)
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, nil)
+		return nil, nftClass, false
+	}
+	offers, err := env.RelDB.GetNFTOffers(nftClass, starttime, endtime)
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, nil)
+		return nil, nftClass, false
+	}
+	closes, err := env.RelDB.GetNFTOrderCloses(nftClass, starttime, endtime)
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, nil)
+		return nil, nftClass, false
+	}
+	return nfthelper.NewOrderHistory(bids, offers, closes), nftClass, true
+}
+
+// GetNFTOrderbook returns the currently active bids and offers on all nfts of a class.
+func (env *Env) GetNFTOrderbook(c *gin.Context) {
+	now := time.Now()
+	history, nftClass, ok := env.nftOrderHistory(c, now, now)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, history.Orderbook(nftClass, now))
+}
+
+// GetNFTBidAsk returns the best active bid and offer on an nft, or on any nft of the class
+// if the id is not given.
+func (env *Env) GetNFTBidAsk(c *gin.Context) {
+	now := time.Now()
+	history, nftClass, ok := env.nftOrderHistory(c, now, now)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, nfthelper.BidAsk(history.Orderbook(nftClass, now), c.Param("id")))
+}
+
+// GetNFTSpread returns the best bid and offer on any nft of a class at regular intervals.
+// Per default, the last 30 days are returned in daily steps.
+func (env *Env) GetNFTSpread(c *gin.Context) {
+	endtime := time.Now()
+	if endtimeStr := c.Query("endtime"); endtimeStr != "" {
+		endtimeInt, err := strconv.ParseInt(endtimeStr, 10, 64)
+		if err != nil {
+			restApi.SendError(c, http.StatusBadRequest, err)
+			return
+		}
+		endtime = time.Unix(endtimeInt, 0)
+	}
+	starttime := endtime.AddDate(0, 0, -30)
+	if starttimeStr := c.Query("starttime"); starttimeStr != "" {
+		starttimeInt, err := strconv.ParseInt(starttimeStr, 10, 64)
+		if err != nil {
+			restApi.SendError(c, http.StatusBadRequest, err)
+			return
+		}
+		starttime = time.Unix(starttimeInt, 0)
+	}
+	interval := 24 * time.Hour
+	if intervalStr := c.Query("interval"); intervalStr != "" {
+		intervalInt, err := strconv.ParseInt(intervalStr, 10, 64)
+		if err != nil || intervalInt <= 0 {
+			restApi.SendError(c, http.StatusBadRequest, errors.New("interval must be a positive number of seconds"))
+			return
+		}
+		interval = time.Duration(intervalInt) * time.Second
+	}
+	if endtime.Before(starttime) || endtime.Sub(starttime)/interval >= maxNFTSpreadPoints {
+		restApi.SendError(c, http.StatusBadRequest, fmt.Errorf("time range must be positive and span at most %d intervals", maxNFTSpreadPoints))
+		return
+	}
+
+	history, nftClass, ok := env.nftOrderHistory(c, starttime, endtime)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, history.SpreadSeries(nftClass, starttime, endtime, interval))
+}
+
+// GetNFTValuation returns the latest appraisal of an nft in USD, or the latest one done at or before
+// the unix timestamp given in the query parameter time.
+func (env *Env) GetNFTValuation(c *gin.Context) {
+	blockchain := c.Param("blockchain")
+	address := common.HexToAddress(c.Param("address")).Hex()
+	id := c.Param("id")
+
+	t := time.Now()
+	if timeStr := c.Query("time"); timeStr != "" {
+		timeInt, err := strconv.ParseInt(timeStr, 10, 64)
+		if err != nil {
+			restApi.SendError(c, http.StatusBadRequest, err)
+			return
+		}
+		t = time.Unix(timeInt, 0)
+	}
+
+	q, err := env.RelDB.GetNFTValuation(address, blockchain, id, t)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			restApi.SendError(c, http.StatusNotFound, err)
+		} else {
+			restApi.SendError(c, http.StatusInternalServerError, nil)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, q)
+}
+
 // GetNFTPrice30Days returns the average price of the whole nft class over the last 30 days.
 func (env *Env) GetNFTPrice30Days(c *gin.Context) {
 	blockchain := c.Param("blockchain")
@@ -0,0 +1,47 @@
+package graphqlApi
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultMaxCost is the default cost limit of a single query. Resolvers charge the cost of their
+// field before querying the datastores: 1 for single values, and for time series 1 plus one per
+// 100 expected data points. Queries exceeding the limit fail on the fields resolved after the limit
+// was reached.
+const DefaultMaxCost = 200
+
+type costKey struct{}
+
+// costBudget is the remaining cost of a query. Fields are resolved in parallel.
+type costBudget struct {
+	mu        sync.Mutex
+	maxCost   int
+	remaining int
+}
+
+func withCostBudget(ctx context.Context, maxCost int) context.Context {
+	return context.WithValue(ctx, costKey{}, &costBudget{maxCost: maxCost, remaining: maxCost})
+}
+
+// charge subtracts @cost from the budget of the query in @ctx.
+func charge(ctx context.Context, cost int) error {
+	budget, ok := ctx.Value(costKey{}).(*costBudget)
+	if !ok {
+		return nil
+	}
+	budget.mu.Lock()
+	defer budget.mu.Unlock()
+	if cost > budget.remaining {
+		return fmt.Errorf("query exceeds cost limit of %d", budget.maxCost)
+	}
+	budget.remaining -= cost
+	return nil
+}
+
+// rangeCost returns the cost of a time series from @starttime to @endtime with one point per @interval.
+func rangeCost(starttime time.Time, endtime time.Time, interval time.Duration) int {
+	return 1 + int(endtime.Sub(starttime)/interval)/100
+}
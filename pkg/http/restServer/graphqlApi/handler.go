@@ -0,0 +1,67 @@
+package graphqlApi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/diadata-org/diadata/pkg/http/restApi"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/gin-gonic/gin"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+const (
+	maxDepth       = 10
+	maxParallelism = 10
+)
+
+// Handler serves GraphQL queries on Schema.
+type Handler struct {
+	schema  *graphql.Schema
+	maxCost int
+}
+
+type request struct {
+	Query         string                 `json:"query" form:"query"`
+	OperationName string                 `json:"operationName" form:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// NewHandler returns a handler resolving queries from @datastore and @relDB with a cost limit
+// of @maxCost per query.
+func NewHandler(datastore models.Datastore, relDB models.RelDatastore, maxCost int) (*Handler, error) {
+	schema, err := graphql.ParseSchema(Schema, &Resolver{DataStore: datastore, RelDB: relDB},
+		graphql.UseFieldResolvers(),
+		graphql.MaxDepth(maxDepth),
+		graphql.MaxParallelism(maxParallelism),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{schema: schema, maxCost: maxCost}, nil
+}
+
+// Serve executes the query given in the JSON body of POST requests, or in the query
+// parameters query, operationName and variables (JSON) of GET requests.
+func (h *Handler) Serve(c *gin.Context) {
+	var req request
+	if c.Request.Method == http.MethodPost {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			restApi.SendError(c, http.StatusBadRequest, err)
+			return
+		}
+	} else {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if variables := c.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				restApi.SendError(c, http.StatusBadRequest, err)
+				return
+			}
+		}
+	}
+
+	ctx := withCostBudget(c.Request.Context(), h.maxCost)
+	response := h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,87 @@
+package graphqlApi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/gin-gonic/gin"
+)
+
+type fakeDatastore struct {
+	models.Datastore
+	candleArgs []interface{}
+}
+
+func (ds *fakeDatastore) GetQuotation(symbol string) (*models.Quotation, error) {
+	return &models.Quotation{Symbol: symbol, Name: "Bitcoin", Price: 50000, Source: "diadata.org", Time: time.Unix(1622505600, 0)}, nil
+}
+
+func (ds *fakeDatastore) GetCandles(symbol string, exchange string, resolution string, starttime time.Time, endtime time.Time, limit int, offset int) ([]models.Candle, error) {
+	ds.candleArgs = []interface{}{symbol, exchange, resolution, starttime.Unix(), endtime.Unix(), limit, offset}
+	return []models.Candle{{Symbol: symbol, Resolution: resolution, Time: starttime, Open: 1, Close: 2, Trades: 10}}, nil
+}
+
+func serve(t *testing.T, ds models.Datastore, maxCost int, query string) map[string]interface{} {
+	handler, err := NewHandler(ds, nil, maxCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/graphql", handler.Serve)
+	body, _ := json.Marshal(map[string]interface{}{"query": query})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body))))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestQuery(t *testing.T) {
+	ds := &fakeDatastore{}
+	result := serve(t, ds, DefaultMaxCost, `{
+		quotation(symbol: "BTC") { price time }
+		candles(symbol: "BTC", resolution: "1d", starttime: "2021-06-01T00:00:00Z", endtime: "2021-06-08T00:00:00Z", limit: 7) { time open close trades }
+	}`)
+	if result["errors"] != nil {
+		t.Fatalf("unexpected errors %v", result["errors"])
+	}
+	data := result["data"].(map[string]interface{})
+	quotation := data["quotation"].(map[string]interface{})
+	if len(quotation) != 2 || quotation["price"] != 50000.0 || quotation["time"] != "2021-06-01T00:00:00Z" {
+		t.Errorf("unexpected quotation %v", quotation)
+	}
+	candles := data["candles"].([]interface{})
+	if len(candles) != 1 || candles[0].(map[string]interface{})["trades"] != 10.0 {
+		t.Errorf("unexpected candles %v", candles)
+	}
+	expectedArgs := []interface{}{"BTC", "", "1d", int64(1622505600), int64(1623110400), 7, 0}
+	for i := range expectedArgs {
+		if ds.candleArgs[i] != expectedArgs[i] {
+			t.Errorf("unexpected candles arguments %v", ds.candleArgs)
+			break
+		}
+	}
+}
+
+func TestCostLimit(t *testing.T) {
+	// Each candles field costs 1 + 1000/100 = 11.
+	result := serve(t, &fakeDatastore{}, 20, `{
+		a: candles(symbol: "BTC", limit: 1000) { close }
+		b: candles(symbol: "ETH", limit: 1000) { close }
+	}`)
+	errs, ok := result["errors"].([]interface{})
+	if !ok || len(errs) != 1 || !strings.Contains(errs[0].(map[string]interface{})["message"].(string), "cost limit") {
+		t.Errorf("expected cost limit error, got %v", result["errors"])
+	}
+}
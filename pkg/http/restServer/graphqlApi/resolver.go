@@ -0,0 +1,406 @@
+package graphqlApi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+const (
+	defaultTimeRange = 24 * time.Hour
+	defaultCandles   = 500
+	maxCandles       = 1000
+)
+
+// scales are the intervals of the aggregated filter measurements queried by GetFilterPoints.
+var scales = map[string]time.Duration{
+	"5m":  5 * time.Minute,
+	"30m": 30 * time.Minute,
+	"1h":  time.Hour,
+	"4h":  4 * time.Hour,
+	"1d":  24 * time.Hour,
+	"1w":  7 * 24 * time.Hour,
+}
+
+// Resolver resolves the queries of Schema from the datastores.
+type Resolver struct {
+	DataStore models.Datastore
+	RelDB     models.RelDatastore
+}
+
+type quotation struct {
+	Symbol             string
+	Name               string
+	Price              float64
+	PriceYesterday     *float64
+	VolumeYesterdayUSD *float64
+	Source             string
+	Time               graphql.Time
+}
+
+type supply struct {
+	Symbol            string
+	Name              string
+	Supply            float64
+	CirculatingSupply float64
+	Source            string
+	Time              graphql.Time
+}
+
+type filterPoint struct {
+	Symbol   string
+	Exchange string
+	Filter   string
+	Value    float64
+	Time     graphql.Time
+}
+
+type candle struct {
+	Symbol     string
+	Exchange   string
+	Resolution string
+	Time       graphql.Time
+	Open       float64
+	High       float64
+	Low        float64
+	Close      float64
+	Volume     float64
+	VolumeUSD  float64
+	Trades     float64
+}
+
+type nftTrade struct {
+	Blockchain     string
+	Address        string
+	TokenID        string
+	Quantity       float64
+	Price          string
+	PriceUSD       float64
+	FromAddress    string
+	ToAddress      string
+	CurrencySymbol string
+	BlockNumber    float64
+	Timestamp      graphql.Time
+	TxHash         string
+	Exchange       string
+}
+
+type farmingPool struct {
+	ProtocolName string
+	PoolID       string
+	Rate         float64
+	Balance      float64
+	BlockNumber  float64
+	TimeStamp    graphql.Time
+	InputAsset   []string
+	OutputAsset  []string
+}
+
+type symbolArgs struct {
+	Symbol string
+}
+
+type timeRangeArgs struct {
+	Starttime *graphql.Time
+	Endtime   *graphql.Time
+}
+
+// timeRange returns the time range of @args, by default the defaultTimeRange before now
+// or before the given endtime.
+func (args timeRangeArgs) timeRange() (starttime time.Time, endtime time.Time, err error) {
+	endtime = time.Now()
+	if args.Endtime != nil {
+		endtime = args.Endtime.Time
+	}
+	starttime = endtime.Add(-defaultTimeRange)
+	if args.Starttime != nil {
+		starttime = args.Starttime.Time
+	}
+	if starttime.After(endtime) {
+		err = errors.New("starttime after endtime")
+	}
+	return
+}
+
+func toSupply(s dia.Supply) supply {
+	return supply{
+		Symbol:            s.Symbol,
+		Name:              s.Name,
+		Supply:            s.Supply,
+		CirculatingSupply: s.CirculatingSupply,
+		Source:            s.Source,
+		Time:              graphql.Time{Time: s.Time},
+	}
+}
+
+// Quotation resolves the latest quotation of a symbol.
+func (r *Resolver) Quotation(ctx context.Context, args symbolArgs) (*quotation, error) {
+	if err := charge(ctx, 1); err != nil {
+		return nil, err
+	}
+	q, err := r.DataStore.GetQuotation(args.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	return &quotation{
+		Symbol:             q.Symbol,
+		Name:               q.Name,
+		Price:              q.Price,
+		PriceYesterday:     q.PriceYesterday,
+		VolumeYesterdayUSD: q.VolumeYesterdayUSD,
+		Source:             q.Source,
+		Time:               graphql.Time{Time: q.Time},
+	}, nil
+}
+
+// Supply resolves the latest supply of a symbol.
+func (r *Resolver) Supply(ctx context.Context, args symbolArgs) (*supply, error) {
+	if err := charge(ctx, 1); err != nil {
+		return nil, err
+	}
+	s, err := r.DataStore.GetLatestSupply(args.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	result := toSupply(*s)
+	return &result, nil
+}
+
+// Supplies resolves the supplies of a symbol in a time range.
+func (r *Resolver) Supplies(ctx context.Context, args struct {
+	Symbol string
+	timeRangeArgs
+}) ([]supply, error) {
+	starttime, endtime, err := args.timeRange()
+	if err != nil {
+		return nil, err
+	}
+	if err := charge(ctx, rangeCost(starttime, endtime, 24*time.Hour)); err != nil {
+		return nil, err
+	}
+	supplies, err := r.DataStore.GetSupply(args.Symbol, starttime, endtime)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]supply, 0, len(supplies))
+	for _, s := range supplies {
+		result = append(result, toSupply(s))
+	}
+	return result, nil
+}
+
+// FilterPoints resolves the values of a filter in a time range, optionally restricted
+// to an exchange and aggregated in a scale.
+func (r *Resolver) FilterPoints(ctx context.Context, args struct {
+	Filter   string
+	Symbol   string
+	Exchange *string
+	Scale    *string
+	timeRangeArgs
+}) ([]filterPoint, error) {
+	starttime, endtime, err := args.timeRange()
+	if err != nil {
+		return nil, err
+	}
+	interval := time.Duration(dia.BlockSizeSeconds) * time.Second
+	scale := ""
+	if args.Scale != nil && *args.Scale != "" {
+		var ok bool
+		scale = *args.Scale
+		if interval, ok = scales[scale]; !ok {
+			return nil, errors.New("unsupported scale " + scale)
+		}
+	}
+	exchange := ""
+	if args.Exchange != nil {
+		exchange = *args.Exchange
+	}
+	if err := charge(ctx, rangeCost(starttime, endtime, interval)); err != nil {
+		return nil, err
+	}
+
+	points, err := r.DataStore.GetFilterPoints(args.Filter, exchange, args.Symbol, scale, starttime, endtime)
+	if err != nil {
+		return nil, err
+	}
+	return parseFilterPoints(points)
+}
+
+// parseFilterPoints converts the rows returned by GetFilterPoints.
+func parseFilterPoints(points *models.Points) ([]filterPoint, error) {
+	result := []filterPoint{}
+	for _, res := range points.DataPoints {
+		for _, series := range res.Series {
+			columns := make(map[string]int)
+			for i, column := range series.Columns {
+				columns[column] = i
+			}
+			for _, row := range series.Values {
+				var fp filterPoint
+				t, err := time.Parse(time.RFC3339, row[columns["time"]].(string))
+				if err != nil {
+					return nil, err
+				}
+				fp.Time = graphql.Time{Time: t}
+				if value, ok := row[columns["value"]].(json.Number); ok {
+					if fp.Value, err = value.Float64(); err != nil {
+						return nil, err
+					}
+				}
+				fp.Symbol, _ = row[columns["symbol"]].(string)
+				fp.Exchange, _ = row[columns["exchange"]].(string)
+				fp.Filter, _ = row[columns["filter"]].(string)
+				result = append(result, fp)
+			}
+		}
+	}
+	return result, nil
+}
+
+// Candles resolves the OHLCV candles of a symbol in a time range.
+func (r *Resolver) Candles(ctx context.Context, args struct {
+	Symbol     string
+	Exchange   *string
+	Resolution *string
+	Limit      *int32
+	Offset     *int32
+	timeRangeArgs
+}) ([]candle, error) {
+	starttime, endtime, err := args.timeRange()
+	if err != nil {
+		return nil, err
+	}
+	exchange := ""
+	if args.Exchange != nil {
+		exchange = *args.Exchange
+	}
+	resolution := "1h"
+	if args.Resolution != nil {
+		resolution = *args.Resolution
+	}
+	if _, ok := models.CandleResolutions[resolution]; !ok {
+		return nil, errors.New("unsupported resolution " + resolution)
+	}
+	limit, offset := defaultCandles, 0
+	if args.Limit != nil {
+		limit = int(*args.Limit)
+	}
+	if args.Offset != nil {
+		offset = int(*args.Offset)
+	}
+	if limit <= 0 || limit > maxCandles || offset < 0 {
+		return nil, errors.New("limit must be between 1 and 1000 and offset non-negative")
+	}
+	if err := charge(ctx, 1+limit/100); err != nil {
+		return nil, err
+	}
+
+	candles, err := r.DataStore.GetCandles(args.Symbol, exchange, resolution, starttime, endtime, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]candle, 0, len(candles))
+	for _, c := range candles {
+		result = append(result, candle{
+			Symbol:     c.Symbol,
+			Exchange:   c.Exchange,
+			Resolution: c.Resolution,
+			Time:       graphql.Time{Time: c.Time},
+			Open:       c.Open,
+			High:       c.High,
+			Low:        c.Low,
+			Close:      c.Close,
+			Volume:     c.Volume,
+			VolumeUSD:  c.VolumeUSD,
+			Trades:     float64(c.Trades),
+		})
+	}
+	return result, nil
+}
+
+// NFTTrades resolves all trades of an NFT.
+func (r *Resolver) NFTTrades(ctx context.Context, args struct {
+	Blockchain string
+	Address    string
+	TokenID    string
+}) ([]nftTrade, error) {
+	if err := charge(ctx, 10); err != nil {
+		return nil, err
+	}
+	nft, err := r.RelDB.GetNFT(args.Address, args.Blockchain, args.TokenID)
+	if err != nil {
+		return nil, err
+	}
+	trades, err := r.RelDB.GetNFTTrades(nft)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]nftTrade, 0, len(trades))
+	for _, t := range trades {
+		trade := nftTrade{
+			Blockchain:     args.Blockchain,
+			Address:        args.Address,
+			TokenID:        args.TokenID,
+			Quantity:       float64(t.Quantity),
+			PriceUSD:       t.PriceUSD,
+			FromAddress:    t.FromAddress,
+			ToAddress:      t.ToAddress,
+			CurrencySymbol: t.CurrencySymbol,
+			BlockNumber:    float64(t.BlockNumber),
+			Timestamp:      graphql.Time{Time: t.Timestamp},
+			TxHash:         t.TxHash,
+			Exchange:       t.Exchange,
+		}
+		if t.Price != nil {
+			trade.Price = t.Price.String()
+		}
+		result = append(result, trade)
+	}
+	return result, nil
+}
+
+// FarmingPools resolves all farming pools.
+func (r *Resolver) FarmingPools(ctx context.Context) ([]models.FarmingPoolType, error) {
+	if err := charge(ctx, 5); err != nil {
+		return nil, err
+	}
+	return r.DataStore.GetFarmingPools()
+}
+
+// FarmingPoolData resolves the states of a farming pool in a time range.
+func (r *Resolver) FarmingPoolData(ctx context.Context, args struct {
+	Protocol string
+	PoolID   string
+	timeRangeArgs
+}) ([]farmingPool, error) {
+	starttime, endtime, err := args.timeRange()
+	if err != nil {
+		return nil, err
+	}
+	if err := charge(ctx, rangeCost(starttime, endtime, 10*time.Minute)); err != nil {
+		return nil, err
+	}
+	pools, err := r.DataStore.GetFarmingPoolData(starttime, endtime, args.Protocol, args.PoolID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]farmingPool, 0, len(pools))
+	for _, p := range pools {
+		result = append(result, farmingPool{
+			ProtocolName: p.ProtocolName,
+			PoolID:       p.PoolID,
+			Rate:         p.Rate,
+			Balance:      p.Balance,
+			BlockNumber:  float64(p.BlockNumber),
+			TimeStamp:    graphql.Time{Time: p.TimeStamp},
+			InputAsset:   p.InputAsset,
+			OutputAsset:  p.OutputAsset,
+		})
+	}
+	return result, nil
+}
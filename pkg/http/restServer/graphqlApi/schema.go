@@ -0,0 +1,96 @@
+package graphqlApi
+
+// Schema is the GraphQL schema served by the REST server. Time arguments and fields are
+// RFC 3339 strings. Fields with a time range default to the last 24 hours.
+const Schema = `
+schema {
+	query: Query
+}
+
+scalar Time
+
+type Query {
+	quotation(symbol: String!): Quotation
+	supply(symbol: String!): Supply
+	supplies(symbol: String!, starttime: Time, endtime: Time): [Supply!]!
+	filterPoints(filter: String!, symbol: String!, exchange: String, scale: String, starttime: Time, endtime: Time): [FilterPoint!]!
+	candles(symbol: String!, exchange: String, resolution: String, starttime: Time, endtime: Time, limit: Int, offset: Int): [Candle!]!
+	nftTrades(blockchain: String!, address: String!, tokenID: String!): [NFTTrade!]!
+	farmingPools: [FarmingPoolType!]!
+	farmingPoolData(protocol: String!, poolID: String!, starttime: Time, endtime: Time): [FarmingPool!]!
+}
+
+type Quotation {
+	symbol: String!
+	name: String!
+	price: Float!
+	priceYesterday: Float
+	volumeYesterdayUSD: Float
+	source: String!
+	time: Time!
+}
+
+type Supply {
+	symbol: String!
+	name: String!
+	supply: Float!
+	circulatingSupply: Float!
+	source: String!
+	time: Time!
+}
+
+type FilterPoint {
+	symbol: String!
+	exchange: String!
+	filter: String!
+	value: Float!
+	time: Time!
+}
+
+type Candle {
+	symbol: String!
+	exchange: String!
+	resolution: String!
+	time: Time!
+	open: Float!
+	high: Float!
+	low: Float!
+	close: Float!
+	volume: Float!
+	volumeUSD: Float!
+	trades: Float!
+}
+
+type NFTTrade {
+	blockchain: String!
+	address: String!
+	tokenID: String!
+	quantity: Float!
+	price: String!
+	priceUSD: Float!
+	fromAddress: String!
+	toAddress: String!
+	currencySymbol: String!
+	blockNumber: Float!
+	timestamp: Time!
+	txHash: String!
+	exchange: String!
+}
+
+type FarmingPoolType {
+	protocolName: String!
+	poolID: String!
+	inputAsset: [String!]!
+}
+
+type FarmingPool {
+	protocolName: String!
+	poolID: String!
+	rate: Float!
+	balance: Float!
+	blockNumber: Float!
+	timeStamp: Time!
+	inputAsset: [String!]!
+	outputAsset: [String!]!
+}
+`
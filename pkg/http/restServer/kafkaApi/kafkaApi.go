@@ -1,12 +1,16 @@
 package kafkaApi
 
 import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
 	"github.com/diadata-org/diadata/pkg/dia/helpers/kafkaHelper"
 	"github.com/gin-gonic/gin"
 	"github.com/segmentio/kafka-go"
 	log "github.com/sirupsen/logrus"
-	"net/http"
-	"strconv"
 )
 
 var (
@@ -20,7 +24,10 @@ var (
 // @hello
 // returns some kafka messages
 type resultApi struct {
-	Offset   int64         `json:"offset"`
+	// Offset is the first offset read in partition 0.
+	Offset int64 `json:"offset"`
+	// Offsets are the first offsets read in each non-empty partition.
+	Offsets  map[int]int64 `json:"offsets"`
 	Messages []interface{} `json:"messages"`
 }
 
@@ -68,42 +75,58 @@ func Process(c *gin.Context, topic int) {
 // @Failure 404 {object} restApi.APIError "Can not find ID"
 // @Router /testapi/get-string-by-int/{some_id} [get]
 
+// Get returns up to @elements messages from each partition of the topic, starting at @offset in
+// each partition, or the latest message of each partition if @offset is negative. Messages of
+// several partitions are merged in the order of their timestamps.
 func (s *RestApi) Get(offset int64, elements int) (map[string]interface{}, error) {
 
 	if (elements == 0) || (elements > 100) {
 		elements = 100
 	}
 
-	result := &resultApi{}
-
-	maxOffset, err := kafkaHelper.ReadOffset(s.topic)
-
+	partitions, err := kafkaHelper.ReadPartitionCount(s.topic)
 	if err != nil {
 		return nil, err
 	}
 
-	maxOffset--
-
-	if offset > maxOffset {
-		offset = maxOffset
-	}
-	if offset < 0 {
-		offset = maxOffset
-	}
-	result.Offset = offset
-
-	nbElements := int(maxOffset - offset + 1)
-	if nbElements > elements {
-		nbElements = elements
+	result := &resultApi{Offset: -1, Offsets: make(map[int]int64)}
+	var messages []interface{}
+	for partition := 0; partition < partitions; partition++ {
+		maxOffset, err := kafkaHelper.ReadPartitionOffset(s.topic, partition)
+		if err != nil {
+			return nil, err
+		}
+		maxOffset--
+		if maxOffset < 0 {
+			// empty partition
+			continue
+		}
+
+		partitionOffset := offset
+		if partitionOffset > maxOffset || partitionOffset < 0 {
+			partitionOffset = maxOffset
+		}
+		result.Offsets[partition] = partitionOffset
+		if partition == 0 {
+			result.Offset = partitionOffset
+		}
+
+		nbElements := int(maxOffset - partitionOffset + 1)
+		if nbElements > elements {
+			nbElements = elements
+		}
+		log.Printf("Get: partition %v maxOffset %v offset:%v nbElements:%v ", partition, maxOffset, partitionOffset, nbElements)
+
+		element, err := kafkaHelper.GetPartitionElements(s.topic, partition, partitionOffset, nbElements)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, element...)
 	}
-	log.Printf("Get: maxOffset %v offset:%v nbElements:%v ", maxOffset, offset, nbElements)
-
-	element, err := kafkaHelper.GetElements(s.topic, offset, nbElements)
-
-	if err != nil {
-		return nil, err
+	if partitions > 1 {
+		sort.SliceStable(messages, func(i, j int) bool { return messageTime(messages[i]).Before(messageTime(messages[j])) })
 	}
-	result.Messages = append(result.Messages, element)
+	result.Messages = append(result.Messages, messages)
 
 	r := map[string]interface{}{
 		"Result": result,
@@ -113,6 +136,14 @@ func (s *RestApi) Get(offset int64, elements int) (map[string]interface{}, error
 
 }
 
+// messageTime returns the time of trades, used to merge the messages of several partitions.
+func messageTime(message interface{}) time.Time {
+	if trade, ok := message.(dia.Trade); ok {
+		return trade.Time
+	}
+	return time.Time{}
+}
+
 func NewRestApi(topic int) *RestApi {
 	s := &RestApi{
 		topic: topic,
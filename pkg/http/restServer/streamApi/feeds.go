@@ -0,0 +1,120 @@
+package streamApi
+
+import (
+	"context"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/kafkaHelper"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/segmentio/kafka-go"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// indexPollInterval is the interval in which index levels of subscribed indices are looked up.
+	// Index levels are computed by the index calculation service and stored in influx, not kafka.
+	indexPollInterval = time.Minute
+	retryDelay        = 5 * time.Second
+)
+
+// Run feeds the hub from all partitions of the trades topic, the filters blocks and the index levels
+// in @datastore until @ctx is done.
+func (h *Hub) Run(ctx context.Context, datastore models.Datastore) {
+	partitions, err := kafkaHelper.ReadPartitionCount(kafkaHelper.TopicTrades)
+	if err != nil {
+		topic, _ := kafkaHelper.GetTopicConfig(kafkaHelper.TopicTrades)
+		partitions = topic.Partitions
+		log.Errorf("streamApi: error reading partitions of trades topic, assuming %d: %v", partitions, err)
+	}
+	for partition := 0; partition < partitions; partition++ {
+		go h.consume(ctx, kafkaHelper.TopicTrades, partition, h.handleTrade)
+	}
+	go h.consume(ctx, kafkaHelper.TopicFiltersBlock, 0, h.handleFiltersBlock)
+	if datastore != nil {
+		go h.pollIndices(ctx, datastore)
+	}
+}
+
+// consume passes all new messages of @partition of @topic to @handle.
+func (h *Hub) consume(ctx context.Context, topic int, partition int, handle func(m kafka.Message) error) {
+	r := kafkaHelper.NewTailReader(topic, partition)
+	defer r.Close()
+	for {
+		m, err := r.ReadMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Errorf("streamApi: error reading topic %d partition %d: %v", topic, partition, err)
+			time.Sleep(retryDelay)
+			continue
+		}
+		if err := handle(m); err != nil {
+			log.Errorf("streamApi: error handling message of topic %d at offset %d: %v", topic, m.Offset, err)
+		}
+	}
+}
+
+func (h *Hub) handleTrade(m kafka.Message) error {
+	var trade dia.Trade
+	if err := trade.UnmarshalBinary(m.Value); err != nil {
+		return err
+	}
+	h.Publish(channelName(ChannelTrades, trade.Symbol, ""), trade)
+	h.Publish(channelName(ChannelTrades, trade.Symbol, trade.Source), trade)
+	return nil
+}
+
+// handleFiltersBlock publishes the filter points of a block. Points of the price filter
+// are published as quotations as well, as they are stored as such by the quotation service.
+func (h *Hub) handleFiltersBlock(m kafka.Message) error {
+	var block dia.FiltersBlock
+	if err := block.UnmarshalBinary(m.Value); err != nil {
+		return err
+	}
+	for _, fp := range block.FiltersBlockData.FilterPoints {
+		h.Publish(channelName(ChannelFilters, fp.Symbol, ""), fp)
+		if fp.Name != dia.FilterKing {
+			continue
+		}
+		channel := channelName(ChannelQuotations, fp.Symbol, "")
+		if h.HasSubscribers(channel) {
+			h.Publish(channel, models.Quotation{
+				Symbol: fp.Symbol,
+				Name:   helpers.NameForSymbol(fp.Symbol),
+				Price:  fp.Value,
+				Source: dia.Diadata,
+				Time:   fp.Time,
+			})
+		}
+	}
+	return nil
+}
+
+// pollIndices publishes new levels of subscribed indices.
+func (h *Hub) pollIndices(ctx context.Context, datastore models.Datastore) {
+	lastUpdate := make(map[string]time.Time)
+	ticker := time.NewTicker(indexPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			endtime := time.Now()
+			for _, symbol := range h.SubscribedSymbols(ChannelIndex) {
+				indices, err := datastore.GetCryptoIndex(endtime.Add(-24*time.Hour), endtime, symbol)
+				if err != nil || len(indices) == 0 {
+					continue
+				}
+				if !indices[0].CalculationTime.After(lastUpdate[symbol]) {
+					continue
+				}
+				lastUpdate[symbol] = indices[0].CalculationTime
+				h.Publish(channelName(ChannelIndex, symbol, ""), indices[0])
+			}
+		}
+	}
+}
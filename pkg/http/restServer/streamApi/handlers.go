@@ -0,0 +1,180 @@
+package streamApi
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/http/restApi"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	writeWait  = 10 * time.Second
+	pongWait   = 60 * time.Second
+	pingPeriod = pongWait * 9 / 10
+	// maxRequestSize is the maximum size of subscription requests sent by clients.
+	maxRequestSize = 1024
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+	// Connections are authenticated by JWT, hence requests from all origins are accepted.
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// request is sent by websocket clients in order to change their subscriptions.
+type request struct {
+	Action  string `json:"action"`
+	Channel string `json:"channel"`
+}
+
+// response acknowledges a request or reports an error to websocket clients.
+type response struct {
+	Event   string `json:"event"`
+	Channel string `json:"channel,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// subscribeQuery subscribes @s to the comma separated channels in the query parameter channels.
+func (h *Hub) subscribeQuery(c *gin.Context, s *Subscriber) error {
+	for _, channel := range strings.Split(c.Query("channels"), ",") {
+		if strings.TrimSpace(channel) == "" {
+			continue
+		}
+		if err := h.Subscribe(s, channel); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ServeWebSocket upgrades the request to a websocket connection. Channels can be given in the
+// query parameter channels and changed by sending {"action":"subscribe|unsubscribe","channel":"..."}.
+// Updates are sent as {"channel":"...","data":{...}}.
+func (h *Hub) ServeWebSocket(c *gin.Context) {
+	s := h.NewSubscriber()
+	defer s.Close()
+	if err := h.subscribeQuery(c, s); err != nil {
+		restApi.SendError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Errorln("ServeWebSocket: upgrade error:", err)
+		return
+	}
+	defer conn.Close()
+
+	responses := make(chan response, 16)
+	go h.readRequests(conn, s, responses)
+
+	ping := time.NewTicker(pingPeriod)
+	defer ping.Stop()
+	for {
+		var err error
+		select {
+		case msg := <-s.C:
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			err = conn.WriteJSON(msg)
+		case resp := <-responses:
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			err = conn.WriteJSON(resp)
+		case <-ping.C:
+			err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait))
+		case <-s.Done():
+			closeMessage := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+			if s.Err() != nil {
+				closeMessage = websocket.FormatCloseMessage(websocket.ClosePolicyViolation, s.Err().Error())
+			}
+			conn.WriteControl(websocket.CloseMessage, closeMessage, time.Now().Add(writeWait))
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// readRequests handles subscription requests of the client until the connection is closed.
+func (h *Hub) readRequests(conn *websocket.Conn, s *Subscriber, responses chan<- response) {
+	defer s.Close()
+	conn.SetReadLimit(maxRequestSize)
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	respond := func(resp response) {
+		select {
+		case responses <- resp:
+		case <-s.Done():
+		}
+	}
+	for {
+		var req request
+		if err := conn.ReadJSON(&req); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				log.Warnln("ServeWebSocket: read error:", err)
+			}
+			return
+		}
+		var err error
+		switch req.Action {
+		case "subscribe":
+			err = h.Subscribe(s, req.Channel)
+		case "unsubscribe":
+			err = h.Unsubscribe(s, req.Channel)
+		default:
+			respond(response{Event: "error", Message: "unknown action, expected subscribe or unsubscribe"})
+			continue
+		}
+		if err != nil {
+			respond(response{Event: "error", Channel: req.Channel, Message: err.Error()})
+			continue
+		}
+		channel, _ := ParseChannel(req.Channel)
+		respond(response{Event: req.Action + "d", Channel: channel})
+	}
+}
+
+// ServeSSE streams updates of the comma separated channels in the query parameter channels
+// as server-sent events named by their channel.
+func (h *Hub) ServeSSE(c *gin.Context) {
+	s := h.NewSubscriber()
+	defer s.Close()
+	if err := h.subscribeQuery(c, s); err != nil {
+		restApi.SendError(c, http.StatusBadRequest, err)
+		return
+	}
+	if len(s.channels) == 0 {
+		restApi.SendError(c, http.StatusBadRequest, errInvalidChannel)
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	heartbeat := time.NewTicker(pingPeriod)
+	defer heartbeat.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case msg := <-s.C:
+			c.SSEvent(msg.Channel, msg.Data)
+			return true
+		case <-heartbeat.C:
+			c.SSEvent("heartbeat", time.Now().Unix())
+			return true
+		case <-s.Done():
+			if s.Err() != nil {
+				c.SSEvent("error", s.Err().Error())
+			}
+			return false
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
@@ -0,0 +1,231 @@
+package streamApi
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Channel kinds clients can subscribe to. Channels are named <kind>:<symbol>, trades can
+// additionally be restricted to an exchange by trades:<symbol>:<exchange>.
+const (
+	ChannelTrades     = "trades"
+	ChannelFilters    = "filters"
+	ChannelQuotations = "quotation"
+	ChannelIndex      = "index"
+)
+
+const (
+	// defaultBufferSize is the number of messages buffered per connection.
+	defaultBufferSize = 256
+	// defaultMaxDropped is the number of consecutive messages dropped for a connection
+	// before it is considered too slow and disconnected.
+	defaultMaxDropped = 512
+)
+
+var (
+	errInvalidChannel = errors.New("invalid channel, expected <trades|filters|quotation|index>:<symbol>[:<exchange>]")
+	errSlowConsumer   = errors.New("connection closed as it could not keep up with the stream")
+)
+
+// Message is a single update sent to subscribers of Channel.
+type Message struct {
+	Channel string      `json:"channel"`
+	Data    interface{} `json:"data"`
+}
+
+// Hub distributes published messages to the subscribers of their channel.
+type Hub struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[*Subscriber]struct{}
+	bufferSize  int
+	maxDropped  int
+}
+
+// Subscriber is a single connection receiving messages on C.
+type Subscriber struct {
+	hub      *Hub
+	C        chan Message
+	channels map[string]struct{}
+	// dropped counts consecutive messages which could not be buffered.
+	dropped   int32
+	done      chan struct{}
+	closeOnce sync.Once
+	err       error
+}
+
+// NewHub returns a hub buffering @bufferSize messages per subscriber which disconnects
+// subscribers after @maxDropped consecutively dropped messages. Non-positive values
+// fall back to the defaults.
+func NewHub(bufferSize int, maxDropped int) *Hub {
+	if bufferSize <= 0 {
+		bufferSize = defaultBufferSize
+	}
+	if maxDropped <= 0 {
+		maxDropped = defaultMaxDropped
+	}
+	return &Hub{
+		subscribers: make(map[string]map[*Subscriber]struct{}),
+		bufferSize:  bufferSize,
+		maxDropped:  maxDropped,
+	}
+}
+
+// ParseChannel validates @channel and returns it in normalized form, i.e. with upper case
+// symbol. Exchange names are case sensitive and kept as they are.
+func ParseChannel(channel string) (string, error) {
+	parts := strings.Split(strings.TrimSpace(channel), ":")
+	if len(parts) < 2 || parts[1] == "" {
+		return "", errInvalidChannel
+	}
+	kind := strings.ToLower(parts[0])
+	switch kind {
+	case ChannelTrades:
+		if len(parts) > 3 || (len(parts) == 3 && parts[2] == "") {
+			return "", errInvalidChannel
+		}
+	case ChannelFilters, ChannelQuotations, ChannelIndex:
+		if len(parts) != 2 {
+			return "", errInvalidChannel
+		}
+	default:
+		return "", errInvalidChannel
+	}
+	parts[0] = kind
+	parts[1] = strings.ToUpper(parts[1])
+	return strings.Join(parts, ":"), nil
+}
+
+// channelName returns the channel of @kind for @symbol and optionally @exchange.
+func channelName(kind string, symbol string, exchange string) string {
+	if exchange != "" {
+		return kind + ":" + strings.ToUpper(symbol) + ":" + exchange
+	}
+	return kind + ":" + strings.ToUpper(symbol)
+}
+
+// NewSubscriber returns a subscriber without any subscriptions.
+func (h *Hub) NewSubscriber() *Subscriber {
+	return &Subscriber{
+		hub:      h,
+		C:        make(chan Message, h.bufferSize),
+		channels: make(map[string]struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Subscribe adds @channel to the subscriptions of @s.
+func (h *Hub) Subscribe(s *Subscriber, channel string) error {
+	channel, err := ParseChannel(channel)
+	if err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subscribers[channel]; !ok {
+		h.subscribers[channel] = make(map[*Subscriber]struct{})
+	}
+	h.subscribers[channel][s] = struct{}{}
+	s.channels[channel] = struct{}{}
+	return nil
+}
+
+// Unsubscribe removes @channel from the subscriptions of @s.
+func (h *Hub) Unsubscribe(s *Subscriber, channel string) error {
+	channel, err := ParseChannel(channel)
+	if err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.unsubscribe(s, channel)
+	return nil
+}
+
+func (h *Hub) unsubscribe(s *Subscriber, channel string) {
+	delete(s.channels, channel)
+	if subs, ok := h.subscribers[channel]; ok {
+		delete(subs, s)
+		if len(subs) == 0 {
+			delete(h.subscribers, channel)
+		}
+	}
+}
+
+// HasSubscribers returns true if anybody is subscribed to @channel.
+func (h *Hub) HasSubscribers(channel string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subscribers[channel]) > 0
+}
+
+// SubscribedSymbols returns all symbols with subscribers on channels of @kind.
+func (h *Hub) SubscribedSymbols(kind string) []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	var symbols []string
+	seen := make(map[string]bool)
+	for channel := range h.subscribers {
+		parts := strings.Split(channel, ":")
+		if parts[0] == kind && !seen[parts[1]] {
+			seen[parts[1]] = true
+			symbols = append(symbols, parts[1])
+		}
+	}
+	return symbols
+}
+
+// Publish sends @data to all subscribers of @channel without blocking. Messages are dropped
+// for subscribers whose buffer is full, and subscribers dropping too many messages in a row
+// are closed.
+func (h *Hub) Publish(channel string, data interface{}) {
+	var slow []*Subscriber
+	h.mu.RLock()
+	for s := range h.subscribers[channel] {
+		select {
+		case s.C <- Message{Channel: channel, Data: data}:
+			atomic.StoreInt32(&s.dropped, 0)
+		default:
+			if atomic.AddInt32(&s.dropped, 1) == int32(h.maxDropped) {
+				slow = append(slow, s)
+			}
+		}
+	}
+	h.mu.RUnlock()
+	for _, s := range slow {
+		s.closeWithError(errSlowConsumer)
+	}
+}
+
+// Done is closed once the subscriber is closed, either by Close or by the hub.
+func (s *Subscriber) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns the reason the hub closed the subscriber, if any.
+func (s *Subscriber) Err() error {
+	select {
+	case <-s.done:
+		return s.err
+	default:
+		return nil
+	}
+}
+
+// Close removes all subscriptions of @s.
+func (s *Subscriber) Close() {
+	s.closeWithError(nil)
+}
+
+func (s *Subscriber) closeWithError(err error) {
+	s.closeOnce.Do(func() {
+		s.hub.mu.Lock()
+		for channel := range s.channels {
+			s.hub.unsubscribe(s, channel)
+		}
+		s.hub.mu.Unlock()
+		s.err = err
+		close(s.done)
+	})
+}
@@ -0,0 +1,69 @@
+package streamApi
+
+import (
+	"testing"
+)
+
+func TestParseChannel(t *testing.T) {
+	valid := map[string]string{
+		"trades:btc":         "trades:BTC",
+		"Trades:BTC:Binance": "trades:BTC:Binance",
+		"filters:eth":        "filters:ETH",
+		"quotation:ETH":      "quotation:ETH",
+		" index:scifi ":      "index:SCIFI",
+	}
+	for channel, expected := range valid {
+		parsed, err := ParseChannel(channel)
+		if err != nil || parsed != expected {
+			t.Errorf("%q parsed as %q, %v", channel, parsed, err)
+		}
+	}
+	for _, channel := range []string{"", "trades", "trades:", "trades:BTC:", "filters:BTC:Binance", "orders:BTC"} {
+		if _, err := ParseChannel(channel); err == nil {
+			t.Errorf("expected error on %q", channel)
+		}
+	}
+}
+
+func TestPublish(t *testing.T) {
+	hub := NewHub(2, 3)
+	all := hub.NewSubscriber()
+	binance := hub.NewSubscriber()
+	if err := hub.Subscribe(all, "trades:btc"); err != nil {
+		t.Fatal(err)
+	}
+	if err := hub.Subscribe(binance, "trades:BTC:Binance"); err != nil {
+		t.Fatal(err)
+	}
+
+	hub.Publish(channelName(ChannelTrades, "btc", ""), 1)
+	hub.Publish(channelName(ChannelTrades, "btc", "Binance"), 1)
+	hub.Publish(channelName(ChannelTrades, "btc", "Kraken"), 2)
+	if len(all.C) != 1 || len(binance.C) != 1 {
+		t.Fatalf("unexpected number of messages %d, %d", len(all.C), len(binance.C))
+	}
+	if msg := <-binance.C; msg.Channel != "trades:BTC:Binance" || msg.Data != 1 {
+		t.Errorf("unexpected message %+v", msg)
+	}
+
+	// all has one buffered message, fill its buffer and drop 3 messages in a row.
+	for i := 0; i < 4; i++ {
+		hub.Publish("trades:BTC", i)
+	}
+	select {
+	case <-all.Done():
+	default:
+		t.Fatal("slow subscriber not closed")
+	}
+	if all.Err() != errSlowConsumer {
+		t.Errorf("unexpected error %v", all.Err())
+	}
+	if hub.HasSubscribers("trades:BTC") || !hub.HasSubscribers("trades:BTC:Binance") {
+		t.Error("unexpected subscriptions after closing slow subscriber")
+	}
+
+	binance.Close()
+	if len(hub.SubscribedSymbols(ChannelTrades)) != 0 {
+		t.Error("subscriptions left after close")
+	}
+}
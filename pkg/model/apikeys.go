@@ -0,0 +1,250 @@
+package models
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	apiKeyPrefix = "dia_"
+	// apiKeyCacheTTL bounds the time a revoked key can still be used by other API instances.
+	apiKeyCacheTTL = 5 * time.Minute
+	// apiUsageTTL is the time usage statistics of a month are kept in redis.
+	apiUsageTTL = 400 * 24 * time.Hour
+	// apiQuotaTTL is the time monthly quota counters are kept in redis.
+	apiQuotaTTL = 35 * 24 * time.Hour
+	// apiMonthFormat is the format of months in usage keys.
+	apiMonthFormat = "200601"
+)
+
+var (
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	// ErrAPICountersUnavailable is returned if the counters can not be kept for lack of a redis client.
+	ErrAPICountersUnavailable = errors.New("api key counters unavailable")
+)
+
+// APIKeyTier defines the limits of an API key. Zero limits are unlimited.
+type APIKeyTier struct {
+	Name              string
+	RequestsPerMinute int64
+	MonthlyQuota      int64
+}
+
+// APIKeyTierAnonymous applies to requests without API key. Its rate limit per client IP is
+// configured by the API server and off by default, so it is not among APIKeyTiers.
+const APIKeyTierAnonymous = "anonymous"
+
+// APIKeyTiers are the available tiers of API keys.
+var APIKeyTiers = map[string]APIKeyTier{
+	"free":       {Name: "free", RequestsPerMinute: 60, MonthlyQuota: 100000},
+	"pro":        {Name: "pro", RequestsPerMinute: 600, MonthlyQuota: 5000000},
+	"enterprise": {Name: "enterprise", RequestsPerMinute: 6000},
+}
+
+// APIKey is the record of an issued API key. The key itself is only known to its owner,
+// only its hash is stored.
+type APIKey struct {
+	ID        string
+	Prefix    string
+	Owner     string
+	Tier      string
+	CreatedAt time.Time
+	RevokedAt *time.Time `json:",omitempty"`
+}
+
+// Revoked returns true if the key has been revoked.
+func (k APIKey) Revoked() bool {
+	return k.RevokedAt != nil
+}
+
+func hashAPIKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+func getKeyAPIKey(keyHash string) string {
+	return "dia_apikey_" + keyHash
+}
+
+func getKeyAPIRateLimit(id string, windowStart time.Time) string {
+	return "dia_apikey_window_" + id + "_" + strconv.FormatInt(windowStart.Unix(), 10)
+}
+
+func getKeyAPIQuota(id string, t time.Time) string {
+	return "dia_apikey_quota_" + id + "_" + t.UTC().Format(apiMonthFormat)
+}
+
+func getKeyAPIUsage(id string, t time.Time) string {
+	return "dia_apikey_usage_" + id + "_" + t.UTC().Format(apiMonthFormat)
+}
+
+// CreateAPIKey issues a new API key for @owner in @tier. The returned key is not stored and
+// can not be recovered later.
+func (rdb *RelDB) CreateAPIKey(owner string, tier string) (key string, apiKey APIKey, err error) {
+	if _, ok := APIKeyTiers[tier]; !ok || tier == APIKeyTierAnonymous {
+		err = fmt.Errorf("unknown tier %s", tier)
+		return
+	}
+	random := make([]byte, 24)
+	if _, err = rand.Read(random); err != nil {
+		return
+	}
+	key = apiKeyPrefix + hex.EncodeToString(random)
+	apiKey = APIKey{
+		Prefix: key[:len(apiKeyPrefix)+8],
+		Owner:  owner,
+		Tier:   tier,
+	}
+	query := fmt.Sprintf("insert into %s (key_hash,key_prefix,owner,tier) values ($1,$2,$3,$4) returning apikey_id,created_at", apikeyTable)
+	err = rdb.postgresClient.QueryRow(context.Background(), query, hashAPIKey(key), apiKey.Prefix, owner, tier).Scan(&apiKey.ID, &apiKey.CreatedAt)
+	return
+}
+
+// GetAPIKey returns the record of @key. Records are cached in redis for apiKeyCacheTTL.
+func (rdb *RelDB) GetAPIKey(key string) (apiKey APIKey, err error) {
+	keyHash := hashAPIKey(key)
+	if rdb.redisClient != nil {
+		cached, errCache := rdb.redisClient.Get(getKeyAPIKey(keyHash)).Bytes()
+		if errCache == nil && json.Unmarshal(cached, &apiKey) == nil {
+			return
+		}
+	}
+
+	query := fmt.Sprintf("select apikey_id,key_prefix,owner,tier,created_at,revoked_at from %s where key_hash=$1", apikeyTable)
+	err = rdb.postgresClient.QueryRow(context.Background(), query, keyHash).Scan(
+		&apiKey.ID,
+		&apiKey.Prefix,
+		&apiKey.Owner,
+		&apiKey.Tier,
+		&apiKey.CreatedAt,
+		&apiKey.RevokedAt,
+	)
+	if err == pgx.ErrNoRows {
+		err = ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return
+	}
+
+	if rdb.redisClient != nil {
+		if cached, errMarshal := json.Marshal(apiKey); errMarshal == nil {
+			rdb.redisClient.Set(getKeyAPIKey(keyHash), cached, apiKeyCacheTTL)
+		}
+	}
+	return
+}
+
+// GetAPIKeys returns the records of all keys of @owner, or of all keys if @owner is empty.
+func (rdb *RelDB) GetAPIKeys(owner string) (apiKeys []APIKey, err error) {
+	var rows pgx.Rows
+	query := fmt.Sprintf("select apikey_id,key_prefix,owner,tier,created_at,revoked_at from %s", apikeyTable)
+	if owner != "" {
+		rows, err = rdb.postgresClient.Query(context.Background(), query+" where owner=$1 order by created_at", owner)
+	} else {
+		rows, err = rdb.postgresClient.Query(context.Background(), query+" order by created_at")
+	}
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var apiKey APIKey
+		err = rows.Scan(
+			&apiKey.ID,
+			&apiKey.Prefix,
+			&apiKey.Owner,
+			&apiKey.Tier,
+			&apiKey.CreatedAt,
+			&apiKey.RevokedAt,
+		)
+		if err != nil {
+			return
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+	err = rows.Err()
+	return
+}
+
+// RevokeAPIKey revokes the key with @id. Cached records of the key expire after apiKeyCacheTTL.
+func (rdb *RelDB) RevokeAPIKey(id string) error {
+	query := fmt.Sprintf("update %s set revoked_at=now() where apikey_id=$1 and revoked_at is null returning key_hash", apikeyTable)
+	var keyHash string
+	err := rdb.postgresClient.QueryRow(context.Background(), query, id).Scan(&keyHash)
+	if err == pgx.ErrNoRows {
+		return ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if rdb.redisClient != nil {
+		return rdb.redisClient.Del(getKeyAPIKey(keyHash)).Err()
+	}
+	return nil
+}
+
+// IncrementRateLimitCounter counts a request of client @id at time @t and returns the number of
+// its requests in the current fixed @window.
+func (rdb *RelDB) IncrementRateLimitCounter(id string, window time.Duration, t time.Time) (int64, error) {
+	if rdb.redisClient == nil {
+		return 0, ErrAPICountersUnavailable
+	}
+	key := getKeyAPIRateLimit(id, t.Truncate(window))
+	pipe := rdb.redisClient.TxPipeline()
+	count := pipe.Incr(key)
+	pipe.Expire(key, 2*window)
+	if _, err := pipe.Exec(); err != nil {
+		return 0, err
+	}
+	return count.Val(), nil
+}
+
+// IncrementAPIKeyUsage counts a request of the key with @id to @route at time @t and returns
+// the number of its requests in the month of @t.
+func (rdb *RelDB) IncrementAPIKeyUsage(id string, route string, t time.Time) (int64, error) {
+	if rdb.redisClient == nil {
+		return 0, ErrAPICountersUnavailable
+	}
+	quotaKey := getKeyAPIQuota(id, t)
+	usageKey := getKeyAPIUsage(id, t)
+	pipe := rdb.redisClient.TxPipeline()
+	count := pipe.Incr(quotaKey)
+	pipe.Expire(quotaKey, apiQuotaTTL)
+	pipe.HIncrBy(usageKey, route, 1)
+	pipe.Expire(usageKey, apiUsageTTL)
+	if _, err := pipe.Exec(); err != nil {
+		return 0, err
+	}
+	return count.Val(), nil
+}
+
+// GetAPIKeyUsage returns the number of requests per route of the key with @id in the month of @t.
+func (rdb *RelDB) GetAPIKeyUsage(id string, t time.Time) (map[string]int64, error) {
+	usage := make(map[string]int64)
+	if rdb.redisClient == nil {
+		return usage, ErrAPICountersUnavailable
+	}
+	values, err := rdb.redisClient.HGetAll(getKeyAPIUsage(id, t)).Result()
+	if err != nil && err != redis.Nil {
+		return usage, err
+	}
+	for route, value := range values {
+		count, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return usage, err
+		}
+		usage[route] = count
+	}
+	return usage, nil
+}
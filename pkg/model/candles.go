@@ -0,0 +1,143 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	clientInfluxdb "github.com/influxdata/influxdb1-client/v2"
+	log "github.com/sirupsen/logrus"
+)
+
+const influxDbCandlesTable = "candles"
+
+// CandleResolutions are the supported resolutions of candles, indexed by their name.
+var CandleResolutions = map[string]time.Duration{
+	"1m":  time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"1h":  time.Hour,
+	"4h":  4 * time.Hour,
+	"1d":  24 * time.Hour,
+}
+
+// Candle is an OHLCV bar of the trades of Symbol on Exchange in the interval of length Resolution
+// starting at Time. Exchange is empty for candles aggregated over all exchanges. Prices are in USD,
+// Volume is in units of Symbol.
+type Candle struct {
+	Symbol     string
+	Exchange   string
+	Resolution string
+	Time       time.Time
+	Open       float64
+	High       float64
+	Low        float64
+	Close      float64
+	Volume     float64
+	VolumeUSD  float64
+	Trades     int64
+	// openTime and closeTime are the times of the trades setting Open and Close. They are not
+	// stored, candles loaded from the database keep their open and take later trades as close.
+	openTime  time.Time
+	closeTime time.Time
+}
+
+// Add updates the candle with a trade at @price in USD and @volume at time @t.
+func (c *Candle) Add(price float64, volume float64, t time.Time) {
+	if volume < 0 {
+		volume = -volume
+	}
+	if c.Trades == 0 {
+		c.Open = price
+		c.High = price
+		c.Low = price
+		c.openTime = t
+	} else if t.Before(c.openTime) {
+		c.Open = price
+		c.openTime = t
+	}
+	if price > c.High {
+		c.High = price
+	}
+	if price < c.Low {
+		c.Low = price
+	}
+	if !t.Before(c.closeTime) {
+		c.Close = price
+		c.closeTime = t
+	}
+	c.Volume += volume
+	c.VolumeUSD += volume * price
+	c.Trades++
+}
+
+// SaveCandleInflux stores @candle. A stored candle with same symbol, exchange, resolution
+// and time is overwritten.
+func (db *DB) SaveCandleInflux(candle Candle) error {
+	tags := map[string]string{
+		"symbol":     candle.Symbol,
+		"exchange":   candle.Exchange,
+		"resolution": candle.Resolution,
+	}
+	fields := map[string]interface{}{
+		"open":      candle.Open,
+		"high":      candle.High,
+		"low":       candle.Low,
+		"close":     candle.Close,
+		"volume":    candle.Volume,
+		"volumeUSD": candle.VolumeUSD,
+		"trades":    candle.Trades,
+	}
+	pt, err := clientInfluxdb.NewPoint(influxDbCandlesTable, tags, fields, candle.Time)
+	if err != nil {
+		log.Errorln("SaveCandleInflux:", err)
+	} else {
+		db.addPoint(pt)
+	}
+	return err
+}
+
+// GetCandles returns at most @limit candles of @symbol on @exchange in @resolution starting in
+// [@starttime, @endtime], skipping the first @offset candles. Candles are sorted by time ascending.
+// Set @exchange empty for candles aggregated over all exchanges.
+func (db *DB) GetCandles(symbol string, exchange string, resolution string, starttime time.Time, endtime time.Time, limit int, offset int) ([]Candle, error) {
+	candles := []Candle{}
+	q := fmt.Sprintf("SELECT open,high,low,close,volume,volumeUSD,trades FROM %s WHERE symbol='%s' AND exchange='%s' AND resolution='%s' AND time>=%d AND time<=%d ORDER BY time ASC LIMIT %d OFFSET %d",
+		influxDbCandlesTable, symbol, exchange, resolution, starttime.UnixNano(), endtime.UnixNano(), limit, offset)
+	res, err := queryInfluxDB(db.influxClient, q)
+	if err != nil {
+		return candles, err
+	}
+	if len(res) == 0 || len(res[0].Series) == 0 {
+		return candles, nil
+	}
+	for _, row := range res[0].Series[0].Values {
+		candle := Candle{
+			Symbol:     symbol,
+			Exchange:   exchange,
+			Resolution: resolution,
+		}
+		candle.Time, err = time.Parse(time.RFC3339, row[0].(string))
+		if err != nil {
+			return candles, err
+		}
+		values := []*float64{&candle.Open, &candle.High, &candle.Low, &candle.Close, &candle.Volume, &candle.VolumeUSD}
+		for i, value := range values {
+			if row[i+1] == nil {
+				continue
+			}
+			*value, err = row[i+1].(json.Number).Float64()
+			if err != nil {
+				return candles, err
+			}
+		}
+		if row[7] != nil {
+			candle.Trades, err = row[7].(json.Number).Int64()
+			if err != nil {
+				return candles, err
+			}
+		}
+		candles = append(candles, candle)
+	}
+	return candles, nil
+}
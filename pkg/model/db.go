@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"time"
 
+	ratederivatives "github.com/diadata-org/diadata/internal/pkg/rateDerivatives"
 	"github.com/diadata-org/diadata/pkg/dia"
 	"github.com/go-redis/redis"
 	clientInfluxdb "github.com/influxdata/influxdb1-client/v2"
@@ -47,6 +48,14 @@ type Datastore interface {
 	GetAllTrades(t time.Time, maxTrades int) ([]dia.Trade, error)
 	Flush() error
 	GetFilterPoints(filter string, exchange string, symbol string, scale string, starttime time.Time, endtime time.Time) (*Points, error)
+	SaveCandleInflux(candle Candle) error
+	GetCandles(symbol string, exchange string, resolution string, starttime time.Time, endtime time.Time, limit int, offset int) ([]Candle, error)
+	SaveFuturesTradeInflux(t dia.FuturesTrade) error
+	SaveFundingRateInflux(f dia.FundingRate) error
+	SaveOpenInterestInflux(o dia.OpenInterest) error
+	GetFundingRates(exchange string, market string, starttime time.Time, endtime time.Time) ([]dia.FundingRate, error)
+	GetLastFuturesTrade(exchange string, market string, endtime time.Time) (*dia.FuturesTrade, error)
+	GetFuturesBasis(exchange string, market string) (*FuturesBasis, error)
 	SetFilter(filterName string, symbol string, exchange string, value float64, t time.Time) error
 	GetLastPriceBefore(symbol string, filter string, exchange string, timestamp time.Time) (Price, error)
 	SetAvailablePairsForExchange(exchange string, pairs []dia.Pair) error
@@ -62,8 +71,19 @@ type Datastore interface {
 	GetExchanges() []string
 	SetOptionMeta(optionMeta *dia.OptionMeta) error
 	GetOptionMeta(baseCurrency string) ([]dia.OptionMeta, error)
+	GetOptionOrderbookDataInflux(t dia.OptionMeta) (dia.OptionOrderbookDatum, error)
+	SetOptionGreeks(underlying string, greeks []dia.OptionGreeks) error
+	GetOptionGreeks(underlying string, expiry time.Time) ([]dia.OptionGreeks, error)
+	SetOptionExpirySummaries(underlying string, summaries []dia.OptionExpirySummary) error
+	GetOptionExpirySummaries(underlying string, expiry time.Time) ([]dia.OptionExpirySummary, error)
+	SetOnchainOption(option *dia.OnchainOption) error
+	GetOnchainOptions(protocol string) ([]dia.OnchainOption, error)
+	GetLiveOnchainOptions(protocol string) ([]OnchainOptionSeries, error)
+	SaveOptionExerciseInflux(e dia.OptionExercise) error
+	GetOptionExercises(protocol string, instrumentName string, starttime time.Time, endtime time.Time) ([]dia.OptionExercise, error)
 	SaveCVIInflux(float64, time.Time) error
 	GetCVIInflux(time.Time, time.Time, string) ([]dia.CviDataPoint, error)
+	SaveSupplyInflux(*dia.Supply) error
 	GetSupplyInflux(string, time.Time, time.Time) ([]dia.Supply, error)
 	GetVolumeInflux(string, time.Time, time.Time) (float64, error)
 	// Get24Volume(symbol string, exchange string) (float64, error)
@@ -80,6 +100,9 @@ type Datastore interface {
 	GetCompoundedAvg(symbol string, date time.Time, calDays, daysPerYear int, rounding int) (*InterestRate, error)
 	GetCompoundedAvgRange(symbol string, dateInit, dateFinal time.Time, calDays, daysPerYear int, rounding int) ([]*InterestRate, error)
 	GetCompoundedAvgDIARange(symbol string, dateInit, dateFinal time.Time, calDays, daysPerYear int, rounding int) ([]*InterestRate, error)
+	GetCompoundedAvgConventionRange(symbol string, dateInit, dateFinal time.Time, calDays, daysPerYear int, rounding int, convention ratederivatives.Convention) ([]*InterestRate, error)
+	GetRateCurve(symbol string, date time.Time) (*dia.TermStructure, error)
+	GetInterestRateReport(symbol string, dateInit, dateFinal time.Time) (*InterestRateReport, error)
 
 	// Pool  methods
 	SetFarmingPool(pr *FarmingPool) error
@@ -100,12 +123,17 @@ type Datastore interface {
 
 	GetDefiStateInflux(time.Time, time.Time, string) ([]dia.DefiProtocolState, error)
 	SetDefiStateInflux(state *dia.DefiProtocolState) error
+	GetDefiMarketStateInflux(time.Time, time.Time, string, string) ([]dia.DefiMarketState, error)
+	GetDefiMarketStatesLatest(protocol string) ([]dia.DefiMarketState, error)
+	SetDefiMarketStateInflux(state *dia.DefiMarketState) error
 
 	// Foreign quotation methods
 	SaveForeignQuotationInflux(fq ForeignQuotation) error
 	GetForeignQuotationInflux(symbol, source string, timestamp time.Time) (ForeignQuotation, error)
 	GetForeignPriceYesterday(symbol, source string) (float64, error)
 	GetForeignSymbolsInflux(source string) (symbols []SymbolShort, err error)
+	SaveDefiScoreInflux(score DefiScore) error
+	GetDefiScoreInflux(protocol string, timestamp time.Time) (DefiScore, error)
 
 	// Gold token methods
 	GetPaxgQuotationOunces() (*Quotation, error)
@@ -157,6 +185,7 @@ const (
 	influxDbSupplyTableOld               = "supply"
 	influxDbDefiRateTable                = "defiRate"
 	influxDbDefiStateTable               = "defiState"
+	influxDbDefiMarketTable              = "defiMarket"
 	influxDbPoolTable                    = "defiPools"
 	influxDbCryptoIndexTable             = "cryptoindex"
 	influxDbCryptoIndexConstituentsTable = "cryptoindexconstituents"
@@ -585,6 +614,9 @@ func (db *DB) SaveOptionOrderbookDatumInflux(t dia.OptionOrderbookDatum) error {
 		"askSize":  t.AskSize,
 		"bidSize":  t.BidSize,
 	}
+	if t.OpenInterest > 0 {
+		fields["openInterest"] = t.OpenInterest
+	}
 	pt, err := clientInfluxdb.NewPoint(influxDbOptionsTable, tags, fields, t.ObservationTime)
 	if err != nil {
 		log.Errorln("NewOptionInflux:", err)
@@ -602,7 +634,7 @@ func (db *DB) SaveOptionOrderbookDatumInflux(t dia.OptionOrderbookDatum) error {
 
 func (db *DB) GetOptionOrderbookDataInflux(t dia.OptionMeta) (dia.OptionOrderbookDatum, error) {
 	retval := dia.OptionOrderbookDatum{}
-	q := fmt.Sprintf("SELECT LAST(askPrice), bidPrice, askSize, bidSize, observationTime FROM %s WHERE instrumentName ='%s'", influxDbOptionsTable, t.InstrumentName)
+	q := fmt.Sprintf("SELECT LAST(askPrice), bidPrice, askSize, bidSize, openInterest FROM %s WHERE instrumentName ='%s'", influxDbOptionsTable, t.InstrumentName)
 	res, err := queryInfluxDB(db.influxClient, q)
 
 	if err != nil {
@@ -630,6 +662,12 @@ func (db *DB) GetOptionOrderbookDataInflux(t dia.OptionMeta) (dia.OptionOrderboo
 		if err != nil {
 			return retval, err
 		}
+		if res[0].Series[0].Values[0][5] != nil {
+			retval.OpenInterest, err = res[0].Series[0].Values[0][5].(json.Number).Float64()
+			if err != nil {
+				return retval, err
+			}
+		}
 		return retval, nil
 	}
 	return retval, nil
@@ -897,6 +935,132 @@ func (db *DB) GetDefiStateInflux(starttime time.Time, endtime time.Time, protoco
 	return
 }
 
+func (db *DB) SetDefiMarketStateInflux(state *dia.DefiMarketState) error {
+	fields := map[string]interface{}{
+		"suppliedUSD":          state.SuppliedUSD,
+		"borrowedUSD":          state.BorrowedUSD,
+		"reservesUSD":          state.ReservesUSD,
+		"utilisation":          state.Utilisation,
+		"collateralFactor":     state.CollateralFactor,
+		"liquidationThreshold": state.LiquidationThreshold,
+	}
+	tags := map[string]string{
+		"asset":    state.Asset,
+		"protocol": state.Protocol,
+	}
+	pt, err := clientInfluxdb.NewPoint(influxDbDefiMarketTable, tags, fields, state.Timestamp)
+	if err != nil {
+		log.Errorln("SetDefiMarketStateInflux:", err)
+	} else {
+		db.addPoint(pt)
+	}
+
+	err = db.WriteBatchInflux()
+	if err != nil {
+		log.Errorln("SetDefiMarketStateInflux", err)
+	}
+
+	return err
+}
+
+// GetDefiMarketStateInflux returns the states of the market @asset on the lending protocol @protocol in the given time range.
+func (db *DB) GetDefiMarketStateInflux(starttime time.Time, endtime time.Time, protocol string, asset string) (retval []dia.DefiMarketState, err error) {
+	influxQuery := "SELECT borrowedUSD,collateralFactor,liquidationThreshold,reservesUSD,suppliedUSD,utilisation FROM %s WHERE time > %d and time < %d and protocol = '%s' and asset = '%s'"
+	q := fmt.Sprintf(influxQuery, influxDbDefiMarketTable, starttime.UnixNano(), endtime.UnixNano(), protocol, asset)
+	res, err := queryInfluxDB(db.influxClient, q)
+	if err != nil {
+		return
+	}
+	if len(res) > 0 && len(res[0].Series) > 0 {
+		for i := 0; i < len(res[0].Series[0].Values); i++ {
+			marketState := dia.DefiMarketState{
+				Protocol: protocol,
+				Asset:    asset,
+			}
+			marketState.Timestamp, err = time.Parse(time.RFC3339, res[0].Series[0].Values[i][0].(string))
+			if err != nil {
+				return
+			}
+			marketState.BorrowedUSD, err = res[0].Series[0].Values[i][1].(json.Number).Float64()
+			if err != nil {
+				return
+			}
+			marketState.CollateralFactor, err = res[0].Series[0].Values[i][2].(json.Number).Float64()
+			if err != nil {
+				return
+			}
+			marketState.LiquidationThreshold, err = res[0].Series[0].Values[i][3].(json.Number).Float64()
+			if err != nil {
+				return
+			}
+			marketState.ReservesUSD, err = res[0].Series[0].Values[i][4].(json.Number).Float64()
+			if err != nil {
+				return
+			}
+			marketState.SuppliedUSD, err = res[0].Series[0].Values[i][5].(json.Number).Float64()
+			if err != nil {
+				return
+			}
+			marketState.Utilisation, err = res[0].Series[0].Values[i][6].(json.Number).Float64()
+			if err != nil {
+				return
+			}
+			retval = append(retval, marketState)
+		}
+	} else {
+		err = errors.New("Error parsing Defi market state from Database")
+		return
+	}
+	return
+}
+
+// GetDefiMarketStatesLatest returns the latest state of each market on the lending protocol @protocol within the last 24h.
+func (db *DB) GetDefiMarketStatesLatest(protocol string) (retval []dia.DefiMarketState, err error) {
+	influxQuery := "SELECT last(borrowedUSD),last(collateralFactor),last(liquidationThreshold),last(reservesUSD),last(suppliedUSD),last(utilisation) FROM %s WHERE time > now()-1d and protocol = '%s' GROUP BY asset"
+	q := fmt.Sprintf(influxQuery, influxDbDefiMarketTable, protocol)
+	res, err := queryInfluxDB(db.influxClient, q)
+	if err != nil {
+		return
+	}
+	if len(res) == 0 || len(res[0].Series) == 0 {
+		err = errors.New("no market states for " + protocol)
+		return
+	}
+	for _, series := range res[0].Series {
+		if len(series.Values) == 0 {
+			continue
+		}
+		vals := series.Values[0]
+		marketState := dia.DefiMarketState{
+			Protocol: protocol,
+			Asset:    series.Tags["asset"],
+		}
+		marketState.Timestamp, err = time.Parse(time.RFC3339, vals[0].(string))
+		if err != nil {
+			return
+		}
+		fields := []*float64{
+			&marketState.BorrowedUSD,
+			&marketState.CollateralFactor,
+			&marketState.LiquidationThreshold,
+			&marketState.ReservesUSD,
+			&marketState.SuppliedUSD,
+			&marketState.Utilisation,
+		}
+		for i, field := range fields {
+			if vals[i+1] == nil {
+				continue
+			}
+			*field, err = vals[i+1].(json.Number).Float64()
+			if err != nil {
+				return
+			}
+		}
+		retval = append(retval, marketState)
+	}
+	return
+}
+
 func (db *DB) SaveSupplyInflux(supply *dia.Supply) error {
 	fields := map[string]interface{}{
 		"supply":            supply.Supply,
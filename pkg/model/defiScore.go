@@ -0,0 +1,85 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	clientInfluxdb "github.com/influxdata/influxdb1-client/v2"
+	log "github.com/sirupsen/logrus"
+)
+
+const influxDbDefiScoreTable = "defiScore"
+
+// ErrDefiScoreNotFound is returned if a protocol has no score.
+var ErrDefiScoreNotFound = errors.New("no defi score found")
+
+// SaveDefiScoreInflux stores the score of a DeFi protocol together with its indices.
+func (db *DB) SaveDefiScoreInflux(score DefiScore) error {
+	fields := map[string]interface{}{
+		"score":              score.Score,
+		"liquidityIndex":     score.LiquidityIndex,
+		"collateralIndex":    score.CollateralIndex,
+		"contractAgeIndex":   score.ContractAgeIndex,
+		"concentrationIndex": score.ConcentrationIndex,
+		"adminIndex":         score.AdminIndex,
+		"oracleIndex":        score.OracleIndex,
+	}
+	tags := map[string]string{
+		"protocol": score.Protocol,
+		"symbol":   score.Symbol,
+	}
+	pt, err := clientInfluxdb.NewPoint(influxDbDefiScoreTable, tags, fields, score.Time)
+	if err != nil {
+		log.Errorln("NewDefiScoreInflux:", err)
+	} else {
+		db.addPoint(pt)
+	}
+	err = db.WriteBatchInflux()
+	if err != nil {
+		log.Errorln("Write influx batch: ", err)
+	}
+	return err
+}
+
+// GetDefiScoreInflux returns the last score of @protocol before @timestamp.
+func (db *DB) GetDefiScoreInflux(protocol string, timestamp time.Time) (DefiScore, error) {
+	retval := DefiScore{Protocol: protocol}
+	q := fmt.Sprintf("SELECT score,liquidityIndex,collateralIndex,contractAgeIndex,concentrationIndex,adminIndex,oracleIndex,\"symbol\" FROM %s WHERE protocol='%s' and time<%d order by time desc limit 1",
+		influxDbDefiScoreTable, protocol, timestamp.UnixNano())
+	res, err := queryInfluxDB(db.influxClient, q)
+	if err != nil {
+		return retval, err
+	}
+	if len(res) == 0 || len(res[0].Series) == 0 || len(res[0].Series[0].Values) == 0 {
+		return retval, ErrDefiScoreNotFound
+	}
+	vals := res[0].Series[0].Values[0]
+	retval.Time, err = time.Parse(time.RFC3339, vals[0].(string))
+	if err != nil {
+		return retval, err
+	}
+	fields := []*float64{
+		&retval.Score,
+		&retval.LiquidityIndex,
+		&retval.CollateralIndex,
+		&retval.ContractAgeIndex,
+		&retval.ConcentrationIndex,
+		&retval.AdminIndex,
+		&retval.OracleIndex,
+	}
+	for i, field := range fields {
+		if vals[i+1] == nil {
+			continue
+		}
+		*field, err = vals[i+1].(json.Number).Float64()
+		if err != nil {
+			return retval, err
+		}
+	}
+	if symbol, ok := vals[len(fields)+1].(string); ok {
+		retval.Symbol = symbol
+	}
+	return retval, nil
+}
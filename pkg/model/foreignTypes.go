@@ -16,12 +16,19 @@ type ForeignQuotation struct {
 	ITIN               string
 }
 
+// DefiScore is the risk score of a DeFi protocol. Score ranges from 0 (high risk) to 100 (low risk),
+// all indices range from 0 to 1 with higher values indicating lower risk.
 type DefiScore struct {
-	Protocol        string
-	Symbol          string
-	Score           float64
-	LiquidityIndex  float64
-	CollateralIndex float64
+	Protocol           string
+	Symbol             string
+	Score              float64
+	LiquidityIndex     float64
+	CollateralIndex    float64
+	ContractAgeIndex   float64
+	ConcentrationIndex float64
+	AdminIndex         float64
+	OracleIndex        float64
+	Time               time.Time
 }
 
 // MarshalBinary -
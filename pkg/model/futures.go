@@ -0,0 +1,233 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	clientInfluxdb "github.com/influxdata/influxdb1-client/v2"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	influxDbFuturesTradesTable = "futuresTrades"
+	influxDbFundingRatesTable  = "fundingRates"
+	influxDbOpenInterestTable  = "openInterest"
+)
+
+// ErrFuturesMarketNotFound is returned if there are no trades on a futures market.
+var ErrFuturesMarketNotFound = errors.New("futures market not found")
+
+// FuturesBasis is the difference of the last traded price of a futures market and the price of its
+// underlying. BasisPercent is relative to the underlying's price. For dated futures, AnnualisedBasis
+// is BasisPercent scaled to a year with the remaining days until expiry.
+type FuturesBasis struct {
+	Exchange        string
+	Market          string
+	Underlying      string
+	FuturesPrice    float64
+	FuturesTime     time.Time
+	SpotPrice       float64
+	SpotTime        time.Time
+	Basis           float64
+	BasisPercent    float64
+	AnnualisedBasis float64
+	Perpetual       bool
+	Expiry          time.Time
+}
+
+// SaveFuturesTradeInflux stores the futures trade @t in influx.
+func (db *DB) SaveFuturesTradeInflux(t dia.FuturesTrade) error {
+	tags := map[string]string{
+		"exchange":   t.Exchange,
+		"market":     t.Market,
+		"underlying": t.Underlying,
+		"perpetual":  strconv.FormatBool(t.Perpetual),
+	}
+	fields := map[string]interface{}{
+		"price":          t.Price,
+		"volume":         t.Volume,
+		"foreignTradeID": t.ForeignTradeID,
+	}
+	if !t.Perpetual {
+		fields["expiry"] = t.Expiry.Unix()
+	}
+	pt, err := clientInfluxdb.NewPoint(influxDbFuturesTradesTable, tags, fields, t.Time)
+	if err != nil {
+		log.Errorln("SaveFuturesTradeInflux:", err)
+	} else {
+		db.addPoint(pt)
+	}
+	return err
+}
+
+// SaveFundingRateInflux stores the funding rate @f in influx.
+func (db *DB) SaveFundingRateInflux(f dia.FundingRate) error {
+	tags := map[string]string{
+		"exchange":   f.Exchange,
+		"market":     f.Market,
+		"underlying": f.Underlying,
+	}
+	fields := map[string]interface{}{
+		"rate":        f.Rate,
+		"interval":    int64(f.Interval.Seconds()),
+		"fundingTime": f.FundingTime.Unix(),
+	}
+	pt, err := clientInfluxdb.NewPoint(influxDbFundingRatesTable, tags, fields, f.Time)
+	if err != nil {
+		log.Errorln("SaveFundingRateInflux:", err)
+	} else {
+		db.addPoint(pt)
+	}
+	return err
+}
+
+// SaveOpenInterestInflux stores the open interest @o in influx.
+func (db *DB) SaveOpenInterestInflux(o dia.OpenInterest) error {
+	tags := map[string]string{
+		"exchange":   o.Exchange,
+		"market":     o.Market,
+		"underlying": o.Underlying,
+	}
+	fields := map[string]interface{}{
+		"value": o.Value,
+	}
+	pt, err := clientInfluxdb.NewPoint(influxDbOpenInterestTable, tags, fields, o.Time)
+	if err != nil {
+		log.Errorln("SaveOpenInterestInflux:", err)
+	} else {
+		db.addPoint(pt)
+	}
+	return err
+}
+
+// GetFundingRates returns the funding rates of the perpetual futures @market on @exchange
+// observed in [@starttime, @endtime], sorted by time ascending.
+func (db *DB) GetFundingRates(exchange string, market string, starttime time.Time, endtime time.Time) ([]dia.FundingRate, error) {
+	rates := []dia.FundingRate{}
+	q := fmt.Sprintf("SELECT rate,interval,fundingTime,underlying FROM %s WHERE exchange='%s' AND market='%s' AND time>=%d AND time<=%d ORDER BY time ASC",
+		influxDbFundingRatesTable, exchange, market, starttime.UnixNano(), endtime.UnixNano())
+	res, err := queryInfluxDB(db.influxClient, q)
+	if err != nil {
+		return rates, err
+	}
+	if len(res) == 0 || len(res[0].Series) == 0 {
+		return rates, nil
+	}
+	for _, row := range res[0].Series[0].Values {
+		rate := dia.FundingRate{
+			Exchange: exchange,
+			Market:   market,
+		}
+		rate.Time, err = time.Parse(time.RFC3339, row[0].(string))
+		if err != nil {
+			return rates, err
+		}
+		rate.Rate, err = row[1].(json.Number).Float64()
+		if err != nil {
+			return rates, err
+		}
+		interval, err := row[2].(json.Number).Int64()
+		if err != nil {
+			return rates, err
+		}
+		rate.Interval = time.Duration(interval) * time.Second
+		fundingTime, err := row[3].(json.Number).Int64()
+		if err != nil {
+			return rates, err
+		}
+		rate.FundingTime = time.Unix(fundingTime, 0)
+		if row[4] != nil {
+			rate.Underlying = row[4].(string)
+		}
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
+
+// GetLastFuturesTrade returns the latest trade on the futures @market on @exchange before @endtime,
+// or ErrFuturesMarketNotFound if there is none.
+func (db *DB) GetLastFuturesTrade(exchange string, market string, endtime time.Time) (*dia.FuturesTrade, error) {
+	q := fmt.Sprintf("SELECT price,volume,foreignTradeID,expiry,underlying,perpetual FROM %s WHERE exchange='%s' AND market='%s' AND time<=%d ORDER BY time DESC LIMIT 1",
+		influxDbFuturesTradesTable, exchange, market, endtime.UnixNano())
+	res, err := queryInfluxDB(db.influxClient, q)
+	if err != nil {
+		return nil, err
+	}
+	if len(res) == 0 || len(res[0].Series) == 0 || len(res[0].Series[0].Values) == 0 {
+		return nil, ErrFuturesMarketNotFound
+	}
+	row := res[0].Series[0].Values[0]
+	trade := dia.FuturesTrade{
+		Exchange: exchange,
+		Market:   market,
+	}
+	trade.Time, err = time.Parse(time.RFC3339, row[0].(string))
+	if err != nil {
+		return nil, err
+	}
+	trade.Price, err = row[1].(json.Number).Float64()
+	if err != nil {
+		return nil, err
+	}
+	trade.Volume, err = row[2].(json.Number).Float64()
+	if err != nil {
+		return nil, err
+	}
+	if row[3] != nil {
+		trade.ForeignTradeID = row[3].(string)
+	}
+	if row[4] != nil {
+		expiry, err := row[4].(json.Number).Int64()
+		if err != nil {
+			return nil, err
+		}
+		trade.Expiry = time.Unix(expiry, 0)
+	}
+	if row[5] != nil {
+		trade.Underlying = row[5].(string)
+	}
+	if row[6] != nil {
+		trade.Perpetual = row[6].(string) == "true"
+	}
+	return &trade, nil
+}
+
+// GetFuturesBasis returns the basis of the futures @market on @exchange, computed from its last trade
+// and the latest quotation of its underlying. As quotations are in USD, the basis is only meaningful
+// for markets quoted in USD.
+func (db *DB) GetFuturesBasis(exchange string, market string) (*FuturesBasis, error) {
+	trade, err := db.GetLastFuturesTrade(exchange, market, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	quotation, err := db.GetQuotation(trade.Underlying)
+	if err != nil {
+		return nil, err
+	}
+	if quotation.Price == 0 {
+		return nil, errors.New("no price for " + trade.Underlying)
+	}
+	basis := &FuturesBasis{
+		Exchange:     exchange,
+		Market:       market,
+		Underlying:   trade.Underlying,
+		FuturesPrice: trade.Price,
+		FuturesTime:  trade.Time,
+		SpotPrice:    quotation.Price,
+		SpotTime:     quotation.Time,
+		Basis:        trade.Price - quotation.Price,
+		BasisPercent: 100 * (trade.Price/quotation.Price - 1),
+		Perpetual:    trade.Perpetual,
+	}
+	if !trade.Perpetual {
+		basis.Expiry = trade.Expiry
+		if days := trade.Expiry.Sub(time.Now()).Hours() / 24; days > 0 {
+			basis.AnnualisedBasis = basis.BasisPercent * 365 / days
+		}
+	}
+	return basis, nil
+}
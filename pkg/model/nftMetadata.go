@@ -0,0 +1,202 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/jackc/pgx/v4"
+)
+
+const nftMetadataVars = "m.token_uri,coalesce(m.onchain_attributes,'{}'::jsonb),m.onchain_fetch_time,coalesce(m.offchain_attributes,'{}'::jsonb),m.offchain_fetch_time,coalesce(m.resolved_url,''),coalesce(m.fetch_error,''),coalesce(m.rarity_score,0),coalesce(m.rarity_rank,0)"
+
+// SetNFTMetadata stores the on- and off-chain attributes in @metadata, replacing previously stored
+// attributes of the NFT. Rarity scores are kept.
+func (rdb *RelDB) SetNFTMetadata(metadata dia.NFTMetadata) error {
+	nftID, err := rdb.GetNFTID(metadata.NFT.NFTClass.Address, metadata.NFT.NFTClass.Blockchain, metadata.NFT.TokenID)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`insert into %s (nft_id,token_uri,onchain_attributes,onchain_fetch_time,offchain_attributes,offchain_fetch_time,resolved_url,fetch_error)
+	values ($1,$2,$3,$4,$5,$6,$7,$8)
+	on conflict (nft_id) do update set token_uri=excluded.token_uri,onchain_attributes=excluded.onchain_attributes,onchain_fetch_time=excluded.onchain_fetch_time,
+	offchain_attributes=coalesce(excluded.offchain_attributes,%s.offchain_attributes),offchain_fetch_time=coalesce(excluded.offchain_fetch_time,%s.offchain_fetch_time),
+	resolved_url=excluded.resolved_url,fetch_error=excluded.fetch_error`, nftmetadataTable, nftmetadataTable, nftmetadataTable)
+	// without off-chain document the previous one is kept
+	var offchainAttributes, offchainFetchTime interface{}
+	if metadata.OffchainAttributes != nil {
+		offchainAttributes = metadata.OffchainAttributes
+		offchainFetchTime = metadata.OffchainFetchTime
+	}
+	_, err = rdb.postgresClient.Exec(
+		context.Background(),
+		query,
+		nftID,
+		metadata.TokenURI,
+		metadata.OnchainAttributes,
+		metadata.OnchainFetchTime,
+		offchainAttributes,
+		offchainFetchTime,
+		metadata.ResolvedURL,
+		metadata.FetchError,
+	)
+	return err
+}
+
+// GetNFTMetadata returns the metadata of the NFT with @tokenID in the class @address on @blockchain.
+func (rdb *RelDB) GetNFTMetadata(address string, blockchain string, tokenID string) (dia.NFTMetadata, error) {
+	nft, err := rdb.GetNFT(address, blockchain, tokenID)
+	if err != nil {
+		return dia.NFTMetadata{}, err
+	}
+	query := fmt.Sprintf("select %s from %s m inner join %s n on(n.nft_id=m.nft_id) inner join %s c on(c.nftclass_id=n.nftclass_id) where c.address=$1 and c.blockchain=$2 and n.token_id=$3",
+		nftMetadataVars, nftmetadataTable, nftTable, nftclassTable)
+	metadata, err := scanNFTMetadata(rdb.postgresClient.QueryRow(context.Background(), query, nft.NFTClass.Address, blockchain, tokenID))
+	metadata.NFT = nft
+	return metadata, err
+}
+
+// GetNFTClassMetadata returns the metadata of all NFTs in @nftclass. Only the token ids of the NFTs are set.
+func (rdb *RelDB) GetNFTClassMetadata(nftclass dia.NFTClass) (metadata []dia.NFTMetadata, err error) {
+	query := fmt.Sprintf("select n.token_id,%s from %s m inner join %s n on(n.nft_id=m.nft_id) inner join %s c on(c.nftclass_id=n.nftclass_id) where c.address=$1 and c.blockchain=$2",
+		nftMetadataVars, nftmetadataTable, nftTable, nftclassTable)
+	rows, err := rdb.postgresClient.Query(context.Background(), query, nftclass.Address, nftclass.Blockchain)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tokenID string
+		m, err := scanNFTMetadata(rows, &tokenID)
+		if err != nil {
+			return []dia.NFTMetadata{}, err
+		}
+		m.NFT = dia.NFT{NFTClass: nftclass, TokenID: tokenID}
+		metadata = append(metadata, m)
+	}
+	return metadata, rows.Err()
+}
+
+// scanNFTMetadata scans a row of @dest followed by nftMetadataVars.
+func scanNFTMetadata(row pgx.Row, dest ...interface{}) (metadata dia.NFTMetadata, err error) {
+	var (
+		tokenURI                            sql.NullString
+		onchainFetchTime, offchainFetchTime *time.Time
+	)
+	dest = append(dest,
+		&tokenURI,
+		&metadata.OnchainAttributes,
+		&onchainFetchTime,
+		&metadata.OffchainAttributes,
+		&offchainFetchTime,
+		&metadata.ResolvedURL,
+		&metadata.FetchError,
+		&metadata.RarityScore,
+		&metadata.RarityRank,
+	)
+	err = row.Scan(dest...)
+	if err != nil {
+		return
+	}
+	metadata.TokenURI = tokenURI.String
+	if onchainFetchTime != nil {
+		metadata.OnchainFetchTime = *onchainFetchTime
+	}
+	if offchainFetchTime != nil {
+		metadata.OffchainFetchTime = *offchainFetchTime
+	}
+	return
+}
+
+// GetNFTsForMetadataRefresh returns up to @limit NFTs whose metadata was not fetched since @before,
+// starting with NFTs without metadata.
+func (rdb *RelDB) GetNFTsForMetadataRefresh(before time.Time, limit int) (nfts []dia.NFT, err error) {
+	query := fmt.Sprintf(`select c.address,c.symbol,c.name,c.blockchain,c.contract_type,n.token_id,coalesce(n.uri,''),coalesce(n.attributes,'{}'::jsonb) from %s n
+	inner join %s c on(c.nftclass_id=n.nftclass_id) left join %s m on(m.nft_id=n.nft_id)
+	where m.onchain_fetch_time is null or m.onchain_fetch_time<$1 order by m.onchain_fetch_time asc nulls first limit $2`, nftTable, nftclassTable, nftmetadataTable)
+	rows, err := rdb.postgresClient.Query(context.Background(), query, before, limit)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var nft dia.NFT
+		err = rows.Scan(
+			&nft.NFTClass.Address,
+			&nft.NFTClass.Symbol,
+			&nft.NFTClass.Name,
+			&nft.NFTClass.Blockchain,
+			&nft.NFTClass.ContractType,
+			&nft.TokenID,
+			&nft.URI,
+			&nft.Attributes,
+		)
+		if err != nil {
+			return []dia.NFT{}, err
+		}
+		nfts = append(nfts, nft)
+	}
+	return nfts, rows.Err()
+}
+
+// SetNFTRarityScore stores the rarity @score and @rank of @nft within its class.
+func (rdb *RelDB) SetNFTRarityScore(nft dia.NFT, score float64, rank int) error {
+	nftID, err := rdb.GetNFTID(nft.NFTClass.Address, nft.NFTClass.Blockchain, nft.TokenID)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf("update %s set rarity_score=$1,rarity_rank=$2 where nft_id=$3", nftmetadataTable)
+	_, err = rdb.postgresClient.Exec(context.Background(), query, score, rank, nftID)
+	return err
+}
+
+// SetNFTTraitRarities replaces the trait rarities of the class of @traits.
+func (rdb *RelDB) SetNFTTraitRarities(nftclass dia.NFTClass, traits []dia.NFTTraitRarity) error {
+	nftclassID, err := rdb.GetNFTClassID(nftclass.Address, nftclass.Blockchain)
+	if err != nil {
+		return err
+	}
+	tx, err := rdb.postgresClient.Begin(context.Background())
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(context.Background())
+
+	_, err = tx.Exec(context.Background(), fmt.Sprintf("delete from %s where nftclass_id=$1", nfttraitTable), nftclassID)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf("insert into %s (nftclass_id,trait_type,trait_value,token_count,rarity_score,update_time) values ($1,$2,$3,$4,$5,$6)", nfttraitTable)
+	for _, trait := range traits {
+		_, err = tx.Exec(context.Background(), query, nftclassID, trait.TraitType, trait.Value, trait.Count, trait.Score, trait.UpdateTime)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(context.Background())
+}
+
+// GetNFTTraitRarities returns the trait rarities of @nftclass, rarest first.
+func (rdb *RelDB) GetNFTTraitRarities(nftclass dia.NFTClass) (traits []dia.NFTTraitRarity, err error) {
+	query := fmt.Sprintf("select t.trait_type,t.trait_value,t.token_count,t.rarity_score,t.update_time from %s t inner join %s c on(c.nftclass_id=t.nftclass_id) where c.address=$1 and c.blockchain=$2 order by t.rarity_score desc",
+		nfttraitTable, nftclassTable)
+	rows, err := rdb.postgresClient.Query(context.Background(), query, nftclass.Address, nftclass.Blockchain)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		trait := dia.NFTTraitRarity{NFTClass: nftclass}
+		err = rows.Scan(&trait.TraitType, &trait.Value, &trait.Count, &trait.Score, &trait.UpdateTime)
+		if err != nil {
+			return []dia.NFTTraitRarity{}, err
+		}
+		traits = append(traits, trait)
+	}
+	return traits, rows.Err()
+}
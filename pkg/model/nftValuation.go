@@ -0,0 +1,57 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+// SetNFTValuation stores @valuation in the valuation history of its NFT.
+func (rdb *RelDB) SetNFTValuation(valuation dia.NFTValuation) error {
+	nftID, err := rdb.GetNFTID(valuation.NFT.NFTClass.Address, valuation.NFT.NFTClass.Blockchain, valuation.NFT.TokenID)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`insert into %s (nft_id,value_usd,lower_bound,upper_bound,confidence,floor_price,comparables,valuation_time)
+	values ($1,$2,$3,$4,$5,$6,$7,$8) on conflict (nft_id,valuation_time) do nothing`, nftvaluationTable)
+	_, err = rdb.postgresClient.Exec(
+		context.Background(),
+		query,
+		nftID,
+		valuation.Value,
+		valuation.LowerBound,
+		valuation.UpperBound,
+		valuation.Confidence,
+		valuation.FloorPrice,
+		valuation.Comparables,
+		valuation.Time,
+	)
+	return err
+}
+
+// GetNFTValuation returns the latest valuation of the NFT with @tokenID in the class @address on
+// @blockchain done at or before @t.
+func (rdb *RelDB) GetNFTValuation(address string, blockchain string, tokenID string, t time.Time) (dia.NFTValuation, error) {
+	valuation := dia.NFTValuation{}
+	nft, err := rdb.GetNFT(address, blockchain, tokenID)
+	if err != nil {
+		return valuation, err
+	}
+	valuation.NFT = nft
+	query := fmt.Sprintf(`select v.value_usd,v.lower_bound,v.upper_bound,v.confidence,v.floor_price,v.comparables,v.valuation_time
+	from %s v inner join %s n on(n.nft_id=v.nft_id) inner join %s c on(c.nftclass_id=n.nftclass_id)
+	where c.address=$1 and c.blockchain=$2 and n.token_id=$3 and v.valuation_time<=$4 order by v.valuation_time desc limit 1`,
+		nftvaluationTable, nftTable, nftclassTable)
+	err = rdb.postgresClient.QueryRow(context.Background(), query, nft.NFTClass.Address, blockchain, tokenID, t).Scan(
+		&valuation.Value,
+		&valuation.LowerBound,
+		&valuation.UpperBound,
+		&valuation.Confidence,
+		&valuation.FloorPrice,
+		&valuation.Comparables,
+		&valuation.Time,
+	)
+	return valuation, err
+}
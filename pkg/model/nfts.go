@@ -189,6 +189,17 @@ func (rdb *RelDB) GetNFT(address string, blockchain string, tokenID string) (dia
 	return nft, err
 }
 
+// GetNumNFTs returns the number of NFTs of @nftclass in the nft table.
+func (rdb *RelDB) GetNumNFTs(nftclass dia.NFTClass) (n int, err error) {
+	nftclassID, err := rdb.GetNFTClassID(nftclass.Address, nftclass.Blockchain)
+	if err != nil {
+		return
+	}
+	query := fmt.Sprintf("select count(*) from %s where nftclass_id=$1", nftTable)
+	err = rdb.postgresClient.QueryRow(context.Background(), query, nftclassID).Scan(&n)
+	return
+}
+
 func (rdb *RelDB) GetNFTID(address string, blockchain string, tokenID string) (ID string, err error) {
 	nftclassID, err := rdb.GetNFTClassID(address, blockchain)
 	if err != nil {
@@ -214,7 +225,7 @@ func (rdb *RelDB) GetLastBlockheightTopshot(upperBound time.Time) (uint64, error
 	return currentBlock, nil
 }
 
-// SetNFTTTrade stores @trade.
+// SetNFTTTrade stores @trade. Trades without quantity are stored as trades of a single unit.
 func (rdb *RelDB) SetNFTTrade(trade dia.NFTTrade) error {
 	nftclassID, err := rdb.GetNFTClassID(trade.NFT.NFTClass.Address, trade.NFT.NFTClass.Blockchain)
 	if err != nil {
@@ -225,15 +236,31 @@ func (rdb *RelDB) SetNFTTrade(trade dia.NFTTrade) error {
 		return err
 	}
 	price := trade.Price.String()
-	tradeVars := "nftclass_id,nft_id,price,price_usd,transfer_from,transfer_to,currency_symbol,currency_address,currency_decimals,block_number,trade_time,tx_hash,marketplace"
-	query := fmt.Sprintf("insert into %s (%s) values ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)", nfttradeTable, tradeVars)
-	_, err = rdb.postgresClient.Exec(context.Background(), query, nftclassID, nftID, price, trade.PriceUSD, trade.FromAddress, trade.ToAddress, trade.CurrencySymbol, trade.CurrencyAddress, trade.CurrencyDecimals, trade.BlockNumber, trade.Timestamp, trade.TxHash, trade.Exchange)
+	quantity := trade.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+	tradeVars := "nftclass_id,nft_id,quantity,price,price_usd,transfer_from,transfer_to,currency_symbol,currency_address,currency_decimals,block_number,trade_time,tx_hash,marketplace"
+	query := fmt.Sprintf("insert into %s (%s) values ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14)", nfttradeTable, tradeVars)
+	_, err = rdb.postgresClient.Exec(context.Background(), query, nftclassID, nftID, quantity, price, trade.PriceUSD, trade.FromAddress, trade.ToAddress, trade.CurrencySymbol, trade.CurrencyAddress, trade.CurrencyDecimals, trade.BlockNumber, trade.Timestamp, trade.TxHash, trade.Exchange)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// DeleteNFTTrade removes the trade of @trade.NFT in the transaction @trade.TxHash, such as a trade
+// in a block which was removed from the chain.
+func (rdb *RelDB) DeleteNFTTrade(trade dia.NFTTrade) error {
+	nftID, err := rdb.GetNFTID(trade.NFT.NFTClass.Address, trade.NFT.NFTClass.Blockchain, trade.NFT.TokenID)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf("delete from %s where nft_id=$1 and tx_hash=$2", nfttradeTable)
+	_, err = rdb.postgresClient.Exec(context.Background(), query, nftID, trade.TxHash)
+	return err
+}
+
 func (rdb *RelDB) GetLastBlockNFTTradeScraper(nftclass dia.NFTClass) (blocknumber uint64, err error) {
 	query := fmt.Sprintf("select block_number from %s where nftclass_id=(select nftclass_id from %s where address='%s' and blockchain='%s') order by block_number desc limit 1;", nfttradeTable, nftclassTable, nftclass.Address, nftclass.Blockchain)
 	err = rdb.postgresClient.QueryRow(context.Background(), query).Scan(&blocknumber)
@@ -247,7 +274,7 @@ func (rdb *RelDB) GetLastBlockNFTTradeScraper(nftclass dia.NFTClass) (blocknumbe
 func (rdb *RelDB) GetNFTTrades(nft dia.NFT) (trades []dia.NFTTrade, err error) {
 	var rows pgx.Rows
 	nftID, err := rdb.GetNFTID(nft.NFTClass.Address, nft.NFTClass.Blockchain, nft.TokenID)
-	tradeVars := "price,price_usd,transfer_from,transfer_to,currency_symbol,currency_address,currency_decimals,block_number,trade_time,tx_hash,marketplace"
+	tradeVars := "coalesce(quantity,1),price,price_usd,transfer_from,transfer_to,currency_symbol,currency_address,currency_decimals,block_number,trade_time,tx_hash,marketplace"
 	query := fmt.Sprintf("select %s from %s where nft_id='%s' order by trade_time desc", tradeVars, nfttradeTable, nftID)
 	rows, err = rdb.postgresClient.Query(context.Background(), query)
 	if err != nil {
@@ -259,6 +286,7 @@ func (rdb *RelDB) GetNFTTrades(nft dia.NFT) (trades []dia.NFTTrade, err error) {
 		var trade dia.NFTTrade
 		var price string
 		err := rows.Scan(
+			&trade.Quantity,
 			&price,
 			&trade.PriceUSD,
 			&trade.FromAddress,
@@ -285,10 +313,56 @@ func (rdb *RelDB) GetNFTTrades(nft dia.NFT) (trades []dia.NFTTrade, err error) {
 	return
 }
 
-// GetNFTPrice30Days returns the average price of all NFTs in @nftclass over the last 30 days.
+// GetNFTPrice30Days returns the average USD price per unit of all NFTs in @nftclass over the last 30 days,
+// weighted by the quantities traded.
 func (rdb *RelDB) GetNFTPrice30Days(nftclass dia.NFTClass) (float64, error) {
-	// TO DO
-	return 0, nil
+	var avgPrice float64
+	query := fmt.Sprintf("select coalesce(sum(price_usd*coalesce(quantity,1))/nullif(sum(coalesce(quantity,1)),0),0) from %s where nftclass_id=(select nftclass_id from %s where address=$1 and blockchain=$2) and trade_time>$3", nfttradeTable, nftclassTable)
+	err := rdb.postgresClient.QueryRow(context.Background(), query, nftclass.Address, nftclass.Blockchain, time.Now().AddDate(0, 0, -30)).Scan(&avgPrice)
+	return avgPrice, err
+}
+
+// GetNFTClassTrades returns all trades done on nfts of @nftclass in the time range (@starttime, @endtime],
+// ordered by time. Only the token ids of the nfts are set.
+func (rdb *RelDB) GetNFTClassTrades(nftclass dia.NFTClass, starttime time.Time, endtime time.Time) (trades []dia.NFTTrade, err error) {
+	tradeVars := "n.token_id,coalesce(t.quantity,1),t.price,coalesce(t.price_usd,0),t.transfer_from,t.transfer_to,t.currency_symbol,t.currency_address,t.currency_decimals,t.block_number,t.trade_time,t.tx_hash,t.marketplace"
+	query := fmt.Sprintf("select %s from %s t inner join %s n on(n.nft_id=t.nft_id) inner join %s c on(c.nftclass_id=t.nftclass_id) where c.address=$1 and c.blockchain=$2 and t.trade_time>$3 and t.trade_time<=$4 order by t.trade_time asc",
+		tradeVars, nfttradeTable, nftTable, nftclassTable)
+	rows, err := rdb.postgresClient.Query(context.Background(), query, nftclass.Address, nftclass.Blockchain, starttime, endtime)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		trade := dia.NFTTrade{NFT: dia.NFT{NFTClass: nftclass}}
+		var price string
+		err = rows.Scan(
+			&trade.NFT.TokenID,
+			&trade.Quantity,
+			&price,
+			&trade.PriceUSD,
+			&trade.FromAddress,
+			&trade.ToAddress,
+			&trade.CurrencySymbol,
+			&trade.CurrencyAddress,
+			&trade.CurrencyDecimals,
+			&trade.BlockNumber,
+			&trade.Timestamp,
+			&trade.TxHash,
+			&trade.Exchange,
+		)
+		if err != nil {
+			return []dia.NFTTrade{}, err
+		}
+		n, ok := new(big.Int).SetString(price, 10)
+		if !ok {
+			return []dia.NFTTrade{}, fmt.Errorf("invalid trade price %s", price)
+		}
+		trade.Price = n
+		trades = append(trades, trade)
+	}
+	return trades, rows.Err()
 }
 
 // SetNFTBid stores @bid.
@@ -471,3 +545,150 @@ func (rdb *RelDB) GetLastNFTOffer(address string, blockchain string, tokenID str
 	offer.EndValue = n
 	return
 }
+
+// SetNFTOrderClose stores the closing of bids or offers on an NFT.
+func (rdb *RelDB) SetNFTOrderClose(orderClose dia.NFTOrderClose) error {
+	nftID, err := rdb.GetNFTID(orderClose.NFT.NFTClass.Address, orderClose.NFT.NFTClass.Blockchain, orderClose.NFT.TokenID)
+	if err != nil {
+		return err
+	}
+	closeVars := "nft_id,side,reason,from_address,blocknumber,blockposition,close_time,tx_hash,marketplace"
+	query := fmt.Sprintf("insert into %s (%s) values ($1,$2,$3,$4,$5,$6,$7,$8,$9)", nftordercloseTable, closeVars)
+	_, err = rdb.postgresClient.Exec(
+		context.Background(),
+		query,
+		nftID,
+		orderClose.Side,
+		orderClose.Reason,
+		orderClose.FromAddress,
+		orderClose.BlockNumber,
+		orderClose.BlockPosition,
+		orderClose.Timestamp,
+		orderClose.TxHash,
+		orderClose.Exchange,
+	)
+	return err
+}
+
+// nftOrderWindowQuery returns the query for the rows of @table on NFTs of a class that were recorded
+// in @timeColumn after $3 until $4, together with the latest row recorded until $3 for each distinct
+// value of @slotColumns. The class is given by address $1 and blockchain $2, the rows are aliased
+// as x and joined with the nft table aliased as n.
+func nftOrderWindowQuery(table string, timeColumn string, slotColumns string, returnVars string) string {
+	classFilter := fmt.Sprintf("x.nft_id in (select n.nft_id from %s n inner join %s c on(c.nftclass_id=n.nftclass_id) where c.address=$1 and c.blockchain=$2)", nftTable, nftclassTable)
+	latest := fmt.Sprintf("select distinct on (%s) x.* from %s x where %s and x.%s<=$3 order by %s,x.%s desc,x.blocknumber desc,x.blockposition desc",
+		slotColumns, table, classFilter, timeColumn, slotColumns, timeColumn)
+	window := fmt.Sprintf("select x.* from %s x where %s and x.%s>$3 and x.%s<=$4", table, classFilter, timeColumn, timeColumn)
+	return fmt.Sprintf("select %s from ((%s) union all (%s)) x inner join %s n on(n.nft_id=x.nft_id)", returnVars, latest, window, nftTable)
+}
+
+// GetNFTBids returns the bids on NFTs in @nftclass placed after @starttime until @endtime, together
+// with the latest bid per token and marketplace placed until @starttime. These are all bids needed
+// to find the active ones at any time from @starttime to @endtime.
+func (rdb *RelDB) GetNFTBids(nftclass dia.NFTClass, starttime time.Time, endtime time.Time) (bids []dia.NFTBid, err error) {
+	returnVars := "n.token_id,x.bid_value,x.from_address,x.currency_symbol,x.currency_address,x.currency_decimals,x.blocknumber,x.blockposition,x.bid_time,coalesce(x.tx_hash,''),x.marketplace"
+	query := nftOrderWindowQuery(nftbidTable, "bid_time", "x.nft_id,x.marketplace", returnVars)
+	rows, err := rdb.postgresClient.Query(context.Background(), query, nftclass.Address, nftclass.Blockchain, starttime, endtime)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		bid := dia.NFTBid{NFT: dia.NFT{NFTClass: nftclass}}
+		var value string
+		err = rows.Scan(
+			&bid.NFT.TokenID,
+			&value,
+			&bid.FromAddress,
+			&bid.CurrencySymbol,
+			&bid.CurrencyAddress,
+			&bid.CurrencyDecimals,
+			&bid.BlockNumber,
+			&bid.BlockPosition,
+			&bid.Timestamp,
+			&bid.TxHash,
+			&bid.Exchange,
+		)
+		if err != nil {
+			return []dia.NFTBid{}, err
+		}
+		bid.Value, _ = new(big.Int).SetString(value, 10)
+		bids = append(bids, bid)
+	}
+	return bids, rows.Err()
+}
+
+// GetNFTOffers returns the offers on NFTs in @nftclass placed after @starttime until @endtime,
+// together with the latest offer per token and marketplace placed until @starttime.
+func (rdb *RelDB) GetNFTOffers(nftclass dia.NFTClass, starttime time.Time, endtime time.Time) (offers []dia.NFTOffer, err error) {
+	returnVars := "n.token_id,x.start_value,coalesce(x.end_value,''),coalesce(x.duration,0),x.from_address,x.auction_type,x.currency_symbol,x.currency_address,x.currency_decimals,x.blocknumber,x.blockposition,x.offer_time,coalesce(x.tx_hash,''),x.marketplace"
+	query := nftOrderWindowQuery(nftofferTable, "offer_time", "x.nft_id,x.marketplace", returnVars)
+	rows, err := rdb.postgresClient.Query(context.Background(), query, nftclass.Address, nftclass.Blockchain, starttime, endtime)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		offer := dia.NFTOffer{NFT: dia.NFT{NFTClass: nftclass}}
+		var startValue, endValue string
+		err = rows.Scan(
+			&offer.NFT.TokenID,
+			&startValue,
+			&endValue,
+			&offer.Duration,
+			&offer.FromAddress,
+			&offer.AuctionType,
+			&offer.CurrencySymbol,
+			&offer.CurrencyAddress,
+			&offer.CurrencyDecimals,
+			&offer.BlockNumber,
+			&offer.BlockPosition,
+			&offer.Timestamp,
+			&offer.TxHash,
+			&offer.Exchange,
+		)
+		if err != nil {
+			return []dia.NFTOffer{}, err
+		}
+		offer.StartValue, _ = new(big.Int).SetString(startValue, 10)
+		// offers without end value are stored as "<nil>"
+		offer.EndValue, _ = new(big.Int).SetString(endValue, 10)
+		offers = append(offers, offer)
+	}
+	return offers, rows.Err()
+}
+
+// GetNFTOrderCloses returns the closings of bids and offers on NFTs in @nftclass after @starttime
+// until @endtime, together with the latest closing per order slot and closing address until
+// @starttime, which is the only one that can close an order placed until @starttime.
+func (rdb *RelDB) GetNFTOrderCloses(nftclass dia.NFTClass, starttime time.Time, endtime time.Time) (closes []dia.NFTOrderClose, err error) {
+	returnVars := "n.token_id,x.side,x.reason,coalesce(x.from_address,''),x.blocknumber,x.blockposition,x.close_time,coalesce(x.tx_hash,''),x.marketplace"
+	query := nftOrderWindowQuery(nftordercloseTable, "close_time", "x.nft_id,x.side,x.marketplace,x.from_address", returnVars)
+	rows, err := rdb.postgresClient.Query(context.Background(), query, nftclass.Address, nftclass.Blockchain, starttime, endtime)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		orderClose := dia.NFTOrderClose{NFT: dia.NFT{NFTClass: nftclass}}
+		err = rows.Scan(
+			&orderClose.NFT.TokenID,
+			&orderClose.Side,
+			&orderClose.Reason,
+			&orderClose.FromAddress,
+			&orderClose.BlockNumber,
+			&orderClose.BlockPosition,
+			&orderClose.Timestamp,
+			&orderClose.TxHash,
+			&orderClose.Exchange,
+		)
+		if err != nil {
+			return []dia.NFTOrderClose{}, err
+		}
+		closes = append(closes, orderClose)
+	}
+	return closes, rows.Err()
+}
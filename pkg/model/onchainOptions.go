@@ -0,0 +1,191 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	clientInfluxdb "github.com/influxdata/influxdb1-client/v2"
+	log "github.com/sirupsen/logrus"
+)
+
+const influxDbOptionExercisesTable = "optionExercises"
+
+// OnchainOptionSeries is an on-chain option series together with the latest datum of its order
+// book. Quote is nil if the series has not been quoted yet.
+type OnchainOptionSeries struct {
+	Option dia.OnchainOption
+	Quote  *dia.OptionOrderbookDatum
+}
+
+func getKeyOnchainOptions(protocol string) string {
+	return "dia_onchainOptions_" + protocol
+}
+
+// SetOnchainOption stores the on-chain option series @option, replacing a previous version.
+func (db *DB) SetOnchainOption(option *dia.OnchainOption) error {
+	if db.redisClient == nil {
+		return errors.New("Datastore has no redis client.")
+	}
+	key := getKeyOnchainOptions(option.Protocol)
+	err := db.redisClient.HSet(key, option.InstrumentName, option).Err()
+	if err != nil {
+		log.Errorf("Error: %v on SetOnchainOption %v\n", err, option.InstrumentName)
+	}
+	return err
+}
+
+// GetOnchainOptions returns all option series of @protocol, including expired ones.
+func (db *DB) GetOnchainOptions(protocol string) ([]dia.OnchainOption, error) {
+	options := []dia.OnchainOption{}
+	if db.redisClient == nil {
+		return options, errors.New("Datastore has no redis client.")
+	}
+	values, err := db.redisClient.HVals(getKeyOnchainOptions(protocol)).Result()
+	if err != nil {
+		return options, err
+	}
+	for _, value := range values {
+		var option dia.OnchainOption
+		err = option.UnmarshalBinary([]byte(value))
+		if err != nil {
+			return options, err
+		}
+		options = append(options, option)
+	}
+	return options, nil
+}
+
+// GetLiveOnchainOptions returns the active option series of @protocol with their latest quotes,
+// sorted by expiry and strike.
+func (db *DB) GetLiveOnchainOptions(protocol string) ([]OnchainOptionSeries, error) {
+	options, err := db.GetOnchainOptions(protocol)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	series := []OnchainOptionSeries{}
+	for _, option := range options {
+		if option.Status != dia.OptionActive || !option.ExpirationTime.After(now) {
+			continue
+		}
+		s := OnchainOptionSeries{Option: option}
+		quote, err := db.GetOptionOrderbookDataInflux(dia.OptionMeta{InstrumentName: option.InstrumentName})
+		if err != nil {
+			log.Errorln("GetLiveOnchainOptions: quote of", option.InstrumentName, ":", err)
+		} else if !quote.ObservationTime.IsZero() {
+			s.Quote = &quote
+		}
+		series = append(series, s)
+	}
+	sort.Slice(series, func(i, j int) bool {
+		a, b := series[i].Option, series[j].Option
+		if !a.ExpirationTime.Equal(b.ExpirationTime) {
+			return a.ExpirationTime.Before(b.ExpirationTime)
+		}
+		if a.StrikePrice != b.StrikePrice {
+			return a.StrikePrice < b.StrikePrice
+		}
+		return a.OptionType < b.OptionType
+	})
+	return series, nil
+}
+
+// SaveOptionExerciseInflux stores the exercise or settlement event @e in influx. Events of the same kind
+// in a block would share their timestamp and tags, so the timestamp is offset by the log index in
+// nanoseconds. Storing an event again overwrites it. As events are rare, and the shared batch would
+// truncate the offset to seconds, the event is written right away in a batch of its own.
+func (db *DB) SaveOptionExerciseInflux(e dia.OptionExercise) error {
+	tags := map[string]string{
+		"protocol":       e.Protocol,
+		"instrumentName": e.InstrumentName,
+		"kind":           e.Kind,
+	}
+	fields := map[string]interface{}{
+		"account":     e.Account,
+		"amount":      e.Amount,
+		"payout":      e.Payout,
+		"txHash":      e.TxHash,
+		"blockNumber": int64(e.BlockNumber),
+		"logIndex":    int64(e.LogIndex),
+	}
+	pt, err := clientInfluxdb.NewPoint(influxDbOptionExercisesTable, tags, fields, e.Time.Add(time.Duration(e.LogIndex)))
+	if err != nil {
+		log.Errorln("SaveOptionExerciseInflux:", err)
+		return err
+	}
+	bp, err := clientInfluxdb.NewBatchPoints(clientInfluxdb.BatchPointsConfig{
+		Database:  influxDbName,
+		Precision: "ns",
+	})
+	if err != nil {
+		log.Errorln("SaveOptionExerciseInflux:", err)
+		return err
+	}
+	bp.AddPoint(pt)
+	err = db.influxClient.Write(bp)
+	if err != nil {
+		log.Errorln("SaveOptionExerciseInflux", err)
+	}
+	return err
+}
+
+// GetOptionExercises returns the exercise and settlement events of the option series @instrumentName
+// of @protocol in [@starttime, @endtime], sorted by time ascending.
+func (db *DB) GetOptionExercises(protocol string, instrumentName string, starttime time.Time, endtime time.Time) ([]dia.OptionExercise, error) {
+	exercises := []dia.OptionExercise{}
+	q := fmt.Sprintf("SELECT kind,account,amount,payout,txHash,blockNumber,logIndex FROM %s WHERE protocol='%s' AND instrumentName='%s' AND time>=%d AND time<=%d ORDER BY time ASC",
+		influxDbOptionExercisesTable, protocol, instrumentName, starttime.UnixNano(), endtime.UnixNano())
+	res, err := queryInfluxDB(db.influxClient, q)
+	if err != nil {
+		return exercises, err
+	}
+	if len(res) == 0 || len(res[0].Series) == 0 {
+		return exercises, nil
+	}
+	for _, row := range res[0].Series[0].Values {
+		e := dia.OptionExercise{
+			Protocol:       protocol,
+			InstrumentName: instrumentName,
+		}
+		e.Time, err = time.Parse(time.RFC3339, row[0].(string))
+		if err != nil {
+			return exercises, err
+		}
+		if row[1] != nil {
+			e.Kind = row[1].(string)
+		}
+		if row[2] != nil {
+			e.Account = row[2].(string)
+		}
+		e.Amount, err = row[3].(json.Number).Float64()
+		if err != nil {
+			return exercises, err
+		}
+		e.Payout, err = row[4].(json.Number).Float64()
+		if err != nil {
+			return exercises, err
+		}
+		if row[5] != nil {
+			e.TxHash = row[5].(string)
+		}
+		blockNumber, err := row[6].(json.Number).Int64()
+		if err != nil {
+			return exercises, err
+		}
+		e.BlockNumber = uint64(blockNumber)
+		if row[7] != nil {
+			logIndex, err := row[7].(json.Number).Int64()
+			if err != nil {
+				return exercises, err
+			}
+			e.LogIndex = uint(logIndex)
+			e.Time = e.Time.Add(-time.Duration(logIndex))
+		}
+		exercises = append(exercises, e)
+	}
+	return exercises, nil
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	influxModels "github.com/influxdata/influxdb1-client/models"
+	clientInfluxdb "github.com/influxdata/influxdb1-client/v2"
+)
+
+// testInflux keeps the written points at the precision of their batch, and answers all queries
+// with the option exercises sorted by time.
+type testInflux struct {
+	clientInfluxdb.Client
+	points map[testInfluxKey]*clientInfluxdb.Point
+}
+
+type testInfluxKey struct {
+	kind string
+	time time.Time
+}
+
+func (c *testInflux) Write(bp clientInfluxdb.BatchPoints) error {
+	precision, err := time.ParseDuration("1" + bp.Precision())
+	if err != nil {
+		precision = time.Nanosecond
+	}
+	for _, pt := range bp.Points() {
+		// influx overwrites points of the same series and timestamp
+		c.points[testInfluxKey{pt.Tags()["kind"], pt.Time().Truncate(precision)}] = pt
+	}
+	return nil
+}
+
+func (c *testInflux) Query(q clientInfluxdb.Query) (*clientInfluxdb.Response, error) {
+	var keys []testInfluxKey
+	for key := range c.points {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].time.Before(keys[j].time) })
+	var values [][]interface{}
+	for _, key := range keys {
+		pt := c.points[key]
+		fields, _ := pt.Fields()
+		number := func(name string) json.Number {
+			v, _ := json.Marshal(fields[name])
+			return json.Number(v)
+		}
+		values = append(values, []interface{}{
+			key.time.Format(time.RFC3339Nano), key.kind, fields["account"], number("amount"), number("payout"),
+			fields["txHash"], number("blockNumber"), number("logIndex"),
+		})
+	}
+	result := clientInfluxdb.Result{}
+	if len(values) > 0 {
+		result.Series = append(result.Series, influxModels.Row{Name: influxDbOptionExercisesTable, Values: values})
+	}
+	return &clientInfluxdb.Response{Results: []clientInfluxdb.Result{result}}, nil
+}
+
+func TestOptionExercisesRoundTrip(t *testing.T) {
+	db := &DB{influxClient: &testInflux{points: map[testInfluxKey]*clientInfluxdb.Point{}}}
+	blockTime := time.Unix(1617000000, 0).UTC()
+	exercises := []dia.OptionExercise{
+		{Kind: dia.OptionRedeemKind, Account: "0x1", Amount: 2, Payout: 150.5, TxHash: "0xa", BlockNumber: 12000000, LogIndex: 3},
+		// the same kind in the same block
+		{Kind: dia.OptionRedeemKind, Account: "0x2", Amount: 0.5, Payout: 37.625, TxHash: "0xb", BlockNumber: 12000000, LogIndex: 17},
+		{Kind: dia.OptionSettleKind, Account: "0x3", Payout: 10, TxHash: "0xc", BlockNumber: 12000000, LogIndex: 0},
+	}
+	for i := range exercises {
+		exercises[i].Protocol = dia.Opyn
+		exercises[i].InstrumentName = "0x5f1A1Bd0dfc79ABC0Ac2B5cC9db3C86Ea3f6e80D"
+		exercises[i].Time = blockTime
+		if err := db.SaveOptionExerciseInflux(exercises[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// storing an event again overwrites it
+	if err := db.SaveOptionExerciseInflux(exercises[1]); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := db.GetOptionExercises(dia.Opyn, exercises[0].InstrumentName, blockTime, blockTime.Add(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []dia.OptionExercise{exercises[2], exercises[0], exercises[1]}
+	if len(got) != len(want) {
+		t.Fatalf("%d exercises, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if !got[i].Time.Equal(blockTime) {
+			t.Errorf("exercise %d at %v, want the block time %v", i, got[i].Time, blockTime)
+		}
+		got[i].Time = want[i].Time
+		if got[i] != want[i] {
+			t.Errorf("exercise %d: %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	log "github.com/sirupsen/logrus"
+)
+
+// timeOutOptionAnalytics is the lifetime of option analytics in redis, such that stale values
+// expire if the option analytics service stops.
+const timeOutOptionAnalytics = time.Hour
+
+func getKeyOptionGreeks(underlying string) string {
+	return "dia_optionGreeks_" + underlying
+}
+
+func getKeyOptionExpirySummaries(underlying string) string {
+	return "dia_optionExpirySummaries_" + underlying
+}
+
+// sameDay returns true if @a and @b are on the same day in UTC.
+func sameDay(a time.Time, b time.Time) bool {
+	ya, ma, da := a.UTC().Date()
+	yb, mb, db := b.UTC().Date()
+	return ya == yb && ma == mb && da == db
+}
+
+// SetOptionGreeks replaces the greeks of all options on @underlying.
+func (db *DB) SetOptionGreeks(underlying string, greeks []dia.OptionGreeks) error {
+	if db.redisClient == nil {
+		return errors.New("Datastore has no redis client.")
+	}
+	data, err := json.Marshal(greeks)
+	if err != nil {
+		return err
+	}
+	key := getKeyOptionGreeks(underlying)
+	err = db.redisClient.Set(key, data, timeOutOptionAnalytics).Err()
+	if err != nil {
+		log.Errorf("Error: %v on SetOptionGreeks %v\n", err, key)
+	}
+	return err
+}
+
+// GetOptionGreeks returns the greeks of the options on @underlying. If @expiry is not zero, only
+// options expiring on the same day are returned.
+func (db *DB) GetOptionGreeks(underlying string, expiry time.Time) ([]dia.OptionGreeks, error) {
+	greeks := []dia.OptionGreeks{}
+	if db.redisClient == nil {
+		return greeks, errors.New("Datastore has no redis client.")
+	}
+	data, err := db.redisClient.Get(getKeyOptionGreeks(underlying)).Bytes()
+	if err != nil {
+		return greeks, err
+	}
+	var all []dia.OptionGreeks
+	err = json.Unmarshal(data, &all)
+	if err != nil {
+		return greeks, err
+	}
+	for _, g := range all {
+		if expiry.IsZero() || sameDay(g.ExpirationTime, expiry) {
+			greeks = append(greeks, g)
+		}
+	}
+	return greeks, nil
+}
+
+// SetOptionExpirySummaries replaces the expiry summaries of the options on @underlying.
+func (db *DB) SetOptionExpirySummaries(underlying string, summaries []dia.OptionExpirySummary) error {
+	if db.redisClient == nil {
+		return errors.New("Datastore has no redis client.")
+	}
+	data, err := json.Marshal(summaries)
+	if err != nil {
+		return err
+	}
+	key := getKeyOptionExpirySummaries(underlying)
+	err = db.redisClient.Set(key, data, timeOutOptionAnalytics).Err()
+	if err != nil {
+		log.Errorf("Error: %v on SetOptionExpirySummaries %v\n", err, key)
+	}
+	return err
+}
+
+// GetOptionExpirySummaries returns the put/call ratios and max pain per expiry of the options on
+// @underlying. If @expiry is not zero, only the summary of options expiring on the same day is returned.
+func (db *DB) GetOptionExpirySummaries(underlying string, expiry time.Time) ([]dia.OptionExpirySummary, error) {
+	summaries := []dia.OptionExpirySummary{}
+	if db.redisClient == nil {
+		return summaries, errors.New("Datastore has no redis client.")
+	}
+	data, err := db.redisClient.Get(getKeyOptionExpirySummaries(underlying)).Bytes()
+	if err != nil {
+		return summaries, err
+	}
+	var all []dia.OptionExpirySummary
+	err = json.Unmarshal(data, &all)
+	if err != nil {
+		return summaries, err
+	}
+	for _, s := range all {
+		if expiry.IsZero() || sameDay(s.ExpirationTime, expiry) {
+			summaries = append(summaries, s)
+		}
+	}
+	return summaries, nil
+}
@@ -11,6 +11,7 @@ import (
 
 	ratederivatives "github.com/diadata-org/diadata/internal/pkg/rateDerivatives"
 	ratedevs "github.com/diadata-org/diadata/internal/pkg/rateDerivatives"
+	"github.com/diadata-org/diadata/pkg/dia"
 	"github.com/diadata-org/diadata/pkg/utils"
 	"github.com/go-redis/redis"
 	log "github.com/sirupsen/logrus"
@@ -208,8 +209,8 @@ func (db *DB) GetFirstDate(symbol string) (time.Time, error) {
 // Risk-free rates methods
 // ---------------------------------------------------------------------------------------
 
-// GetCompoundedRate returns the compounded rate for the period @dateInit to @date. It computes the rate for all
-// days for which an entry is present in the database. All other days are assumed to be holidays (or weekends).
+// GetCompoundedRate returns the compounded rate for the period @dateInit to @date. It compounds the rate on all
+// business days of the calendar of @symbol. Missing fixings are replaced by the preceding fixing.
 func (db *DB) GetCompoundedRate(symbol string, dateInit, date time.Time, daysPerYear int, rounding int) (*InterestRate, error) {
 
 	// Get first publication date for the rate with @symbol in order to check feasibility of dateInit
@@ -223,6 +224,10 @@ func (db *DB) GetCompoundedRate(symbol string, dateInit, date time.Time, daysPer
 		return &InterestRate{}, err
 	}
 
+	cal, err := ratedevs.GetCalendar(symbol)
+	if err != nil {
+		return &InterestRate{}, err
+	}
 	ratesAPI, err := db.GetInterestRateRange(symbol, dateInit.Format("2006-01-02"), date.Format("2006-01-02"))
 	if err != nil {
 		return &InterestRate{}, err
@@ -231,13 +236,15 @@ func (db *DB) GetCompoundedRate(symbol string, dateInit, date time.Time, daysPer
 		err = errors.New("no rate information for this period")
 		return &InterestRate{}, err
 	}
-
-	// Determine holidays through missing database entries
-	existDates := []time.Time{}
-	for _, entry := range ratesAPI {
-		existDates = append(existDates, (*entry).EffectiveDate)
+	ratesAPI, err = db.completeFixings(symbol, ratesAPI, cal, dateInit, date)
+	if err != nil {
+		return &InterestRate{}, err
+	}
+	if len(ratesAPI) == 0 {
+		err = errors.New("no business days in this period")
+		return &InterestRate{}, err
 	}
-	holidays := utils.GetHolidays(existDates, dateInit, date)
+	holidays := cal.Holidays(dateInit, date)
 
 	// Sort ratesApi (type []*InterestRates) in increasing order according to date
 	// and remove the data for the final date, as only past values are compounded.
@@ -316,12 +323,19 @@ func (db *DB) GetCompoundedIndexRange(symbol string, dateInit, dateFinal time.Ti
 		return (ratesAPI[i].EffectiveDate).Before(ratesAPI[j].EffectiveDate)
 	})
 
-	// Determine holidays through missing database entries
-	existDates := []time.Time{}
-	for _, entry := range ratesAPI {
-		existDates = append(existDates, (*entry).EffectiveDate)
+	cal, err := ratedevs.GetCalendar(symbol)
+	if err != nil {
+		return []*InterestRate{}, err
+	}
+	ratesAPI, err = db.completeFixings(symbol, ratesAPI, cal, dateInit, dateFinal)
+	if err != nil {
+		return []*InterestRate{}, err
 	}
-	holidays := utils.GetHolidays(existDates, firstPublication, dateFinal)
+	if len(ratesAPI) == 0 {
+		err = errors.New("no business days in this period")
+		return []*InterestRate{}, err
+	}
+	holidays := cal.Holidays(firstPublication, dateFinal)
 
 	// Consider previous business day if @dateFinal is holiday or weekend
 	for utils.ContainsDay(holidays, dateFinal) || !utils.CheckWeekDay(dateFinal) {
@@ -457,14 +471,22 @@ func (db *DB) GetCompoundedAvgRange(symbol string, dateInit, dateFinal time.Time
 		return []*InterestRate{}, err
 	}
 
+	cal, err := ratedevs.GetCalendar(symbol)
+	if err != nil {
+		return []*InterestRate{}, err
+	}
+	ratesAPI, err = db.completeFixings(symbol, ratesAPI, cal, dateStart, dateFinal)
+	if err != nil {
+		return []*InterestRate{}, err
+	}
+	if len(ratesAPI) == 0 {
+		err = errors.New("no business days in this period")
+		return []*InterestRate{}, err
+	}
+
 	// Check, whether first day is a holiday or weekend. If so, prepend rate of
 	// preceding business day (outside the considered time range!).
-	// Determine holidays through missing database entries
-	existDates := []time.Time{}
-	for _, entry := range ratesAPI {
-		existDates = append(existDates, (*entry).EffectiveDate)
-	}
-	holidays := utils.GetHolidays(existDates, dateStart, dateFinal)
+	holidays := cal.Holidays(dateStart, dateFinal)
 	if utils.ContainsDay(holidays, dateStart) || !utils.CheckWeekDay(dateStart) {
 		firstRate, err := db.GetInterestRate(symbol, dateStart.Format("2006-01-02"))
 		if err != nil {
@@ -599,14 +621,22 @@ func (db *DB) GetCompoundedAvgDIARange(symbol string, dateInit, dateFinal time.T
 		return []*InterestRate{}, err
 	}
 
+	cal, err := ratedevs.GetCalendar(symbol)
+	if err != nil {
+		return []*InterestRate{}, err
+	}
+	ratesAPI, err = db.completeFixings(symbol, ratesAPI, cal, dateStart, dateFinal)
+	if err != nil {
+		return []*InterestRate{}, err
+	}
+	if len(ratesAPI) == 0 {
+		err = errors.New("no business days in this period")
+		return []*InterestRate{}, err
+	}
+
 	// Check, whether first day is a holiday or weekend. If so, prepend rate of
 	// preceding business day (outside the considered time range!).
-	// Determine holidays through missing database entries
-	existDates := []time.Time{}
-	for _, entry := range ratesAPI {
-		existDates = append(existDates, (*entry).EffectiveDate)
-	}
-	holidays := utils.GetHolidays(existDates, dateStart, dateFinal)
+	holidays := cal.Holidays(dateStart, dateFinal)
 	if utils.ContainsDay(holidays, dateStart) || !utils.CheckWeekDay(dateStart) {
 		firstRate, err := db.GetInterestRate(symbol, dateStart.Format("2006-01-02"))
 		if err != nil {
@@ -670,10 +700,162 @@ func (db *DB) GetCompoundedAvgDIARange(symbol string, dateInit, dateFinal time.T
 	return values, nil
 }
 
+// ---------------------------------------------------------------------------------------------
+// Computation of compounded averages following ISDA and ARRC conventions
+// ---------------------------------------------------------------------------------------------
+
+// GetCompoundedAvgConventionRange returns the compounded averages of @symbol over rolling @calDays calendar days
+// ending on each business day from @dateInit to @dateFinal (excluded). Lookback, observation shift and lockout are
+// set by @convention. For daily compounding averages are returned for each calendar day.
+func (db *DB) GetCompoundedAvgConventionRange(symbol string, dateInit, dateFinal time.Time, calDays, daysPerYear int, rounding int, convention ratedevs.Convention) (values []*InterestRate, err error) {
+	cal, err := ratedevs.GetCalendar(symbol)
+	if err != nil {
+		return []*InterestRate{}, err
+	}
+
+	// Fixings are needed from the first observation date, i.e. up to @convention.Lag business days
+	// before the first interest period.
+	dateStart := cal.AddBusinessDays(dateInit.AddDate(0, 0, -calDays), -convention.Lag-1)
+	firstPublication, err := db.GetFirstDate(symbol)
+	if err != nil {
+		return []*InterestRate{}, err
+	}
+	if utils.AfterDay(firstPublication, dateStart) {
+		log.Error("dateStart cannot be earlier than first publication date.")
+		err = errors.New("dateStart cannot be earlier than first publication date")
+		return []*InterestRate{}, err
+	}
+	ratesAPI, err := db.GetInterestRateRange(symbol, dateStart.Format("2006-01-02"), dateFinal.Format("2006-01-02"))
+	if err != nil {
+		return []*InterestRate{}, err
+	}
+	if len(ratesAPI) == 0 {
+		err = errors.New("no rate information for this period")
+		return []*InterestRate{}, err
+	}
+	source := ratesAPI[0].Source
+	ratesAPI, err = db.completeFixings(symbol, ratesAPI, cal, dateStart, dateFinal)
+	if err != nil {
+		return []*InterestRate{}, err
+	}
+	fixings := make(map[string]float64)
+	for _, rate := range ratesAPI {
+		fixings[rate.EffectiveDate.Format("2006-01-02")] = rate.Value
+	}
+	fixing := func(date time.Time) (float64, error) {
+		if value, ok := fixings[date.Format("2006-01-02")]; ok {
+			return value, nil
+		}
+		return 0, errors.New("no fixing of " + symbol + " on " + date.Format("2006-01-02"))
+	}
+
+	name := symbol + strconv.Itoa(calDays) + "_compounded_by_DIA"
+	if convention.Name != ratedevs.ConventionPlain {
+		name += "_" + convention.Name + strconv.Itoa(convention.Lag)
+	}
+	for date := dateInit; utils.AfterDay(dateFinal, date); date = date.AddDate(0, 0, 1) {
+		if !convention.Daily && !cal.IsBusinessDay(date) {
+			continue
+		}
+		value, err := ratedevs.CompoundedAverage(fixing, cal, date.AddDate(0, 0, -calDays), date, daysPerYear, convention)
+		if err != nil {
+			return []*InterestRate{}, err
+		}
+		if rounding != 0 {
+			value = math.Round(value*math.Pow(10, float64(rounding))) / math.Pow(10, float64(rounding))
+		}
+		values = append(values, &InterestRate{
+			Symbol:        name,
+			Value:         value,
+			EffectiveDate: date,
+			Source:        source,
+		})
+	}
+	return values, nil
+}
+
 // ---------------------------------------------------------------------------------------
 // Auxiliary functions
 // ---------------------------------------------------------------------------------------
 
+// completeFixings returns a fixing of @symbol for each business day of @cal in the period from
+// @dateInit to @dateFinal, sorted by effective date. A missing fixing is replaced by the preceding
+// fixing, fixings on weekends and holidays are dropped. @fixings are the stored fixings in the period.
+func (db *DB) completeFixings(symbol string, fixings []*InterestRate, cal *ratedevs.Calendar, dateInit, dateFinal time.Time) ([]*InterestRate, error) {
+	stored := make(map[string]*InterestRate)
+	for _, fixing := range fixings {
+		stored[fixing.EffectiveDate.Format("2006-01-02")] = fixing
+	}
+
+	completed := []*InterestRate{}
+	for _, day := range cal.BusinessDays(dateInit, dateFinal) {
+		if fixing, ok := stored[day.Format("2006-01-02")]; ok {
+			completed = append(completed, fixing)
+			continue
+		}
+		var previous *InterestRate
+		if len(completed) > 0 {
+			previous = completed[len(completed)-1]
+		} else {
+			var err error
+			previous, err = db.GetInterestRate(symbol, day.Format("2006-01-02"))
+			if err != nil {
+				return []*InterestRate{}, err
+			}
+		}
+		log.Warnf("missing fixing of %s on business day %s, using fixing of %s", symbol, day.Format("2006-01-02"), previous.EffectiveDate.Format("2006-01-02"))
+		completed = append(completed, &InterestRate{
+			Symbol:          previous.Symbol,
+			Value:           previous.Value,
+			PublicationTime: previous.PublicationTime,
+			EffectiveDate:   day,
+			Source:          previous.Source,
+		})
+	}
+	return completed, nil
+}
+
+// GetInterestRateReport returns the business days in the period from @dateInit to @dateFinal on which
+// no fixing of @symbol is stored and the stored fixings on weekends and holidays of its calendar.
+func (db *DB) GetInterestRateReport(symbol string, dateInit, dateFinal time.Time) (*InterestRateReport, error) {
+	cal, err := ratedevs.GetCalendar(symbol)
+	if err != nil {
+		return &InterestRateReport{}, err
+	}
+	if utils.AfterDay(dateInit, dateFinal) {
+		return &InterestRateReport{}, errors.New("dateInit cannot be after dateFinal")
+	}
+	fixings, err := db.GetInterestRateRange(symbol, dateInit.Format("2006-01-02"), dateFinal.Format("2006-01-02"))
+	if err != nil {
+		return &InterestRateReport{}, err
+	}
+
+	report := &InterestRateReport{
+		Symbol:            symbol,
+		Calendar:          cal.Name,
+		DateInit:          dateInit,
+		DateFinal:         dateFinal,
+		Fixings:           len(fixings),
+		MissingFixings:    []time.Time{},
+		FixingsOnHolidays: []time.Time{},
+	}
+	stored := make(map[string]bool)
+	for _, fixing := range fixings {
+		stored[fixing.EffectiveDate.Format("2006-01-02")] = true
+		if !cal.IsBusinessDay(fixing.EffectiveDate) {
+			report.FixingsOnHolidays = append(report.FixingsOnHolidays, fixing.EffectiveDate)
+		}
+	}
+	businessDays := cal.BusinessDays(dateInit, dateFinal)
+	report.BusinessDays = len(businessDays)
+	for _, day := range businessDays {
+		if !stored[day.Format("2006-01-02")] {
+			report.MissingFixings = append(report.MissingFixings, day)
+		}
+	}
+	return report, nil
+}
+
 // ExistInterestRate returns true if a database entry with given date stamp exists,
 // and false otherwise.
 // @date should be a substring of a string formatted as "yyyy-mm-dd hh:mm:ss".
@@ -695,6 +877,9 @@ func (db *DB) matchKeyInterestRate(symbol, date string) (string, error) {
 	// Determine all database entries with given date
 	pattern := "*" + symbol + "_" + exDate + "*"
 	strSlice := db.redisClient.Keys(pattern).Val()
+	if len(strSlice) == 0 {
+		return "", errors.New("no database entry for " + symbol + " before " + date)
+	}
 
 	var strSliceFormatted []string
 	layout := "2006-01-02 15:04:05"
@@ -722,3 +907,60 @@ func (db *DB) findLastDay(symbol, date string) (string, error) {
 	err := errors.New("No database entry found in the last " + strconv.FormatInt(int64(maxDays), 10) + "days.")
 	return "", err
 }
+
+// ---------------------------------------------------------------------------------------
+// Term structure of risk-free rates
+// ---------------------------------------------------------------------------------------
+
+// rateCurveDaysPerYear is the day count convention of the benchmarks a term structure is available for.
+// SAFR is the level of the SOFR Index rather than a rate, so it is not a benchmark for a curve.
+var rateCurveDaysPerYear = map[string]int{
+	"SOFR":  360,
+	"ESTER": 360,
+	"SONIA": 365,
+}
+
+// rateCurveTerms are the terms in calendar days of the compounded averages used as pillars.
+var rateCurveTerms = []int{30, 90, 180}
+
+// GetRateCurve returns the term structure of the benchmark @symbol at @date with zero and forward
+// rates for the standard tenors. Pillars are the overnight fixing and the compounded averages over
+// 30, 90 and 180 days. Published term averages (such as SOFR30) are preferred over averages
+// compounded by DIA.
+func (db *DB) GetRateCurve(symbol string, date time.Time) (*dia.TermStructure, error) {
+	daysPerYear, ok := rateCurveDaysPerYear[symbol]
+	if !ok {
+		return nil, errors.New("no term structure available for " + symbol)
+	}
+	allRates := db.GetRates()
+	if !utils.Contains(&allRates, symbol) {
+		return nil, redis.Nil
+	}
+
+	overnight, err := db.GetInterestRate(symbol, date.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	pillars := []dia.Pillar{{Days: 1, Rate: overnight.Value, Source: overnight.Symbol}}
+
+	for _, calDays := range rateCurveTerms {
+		published := symbol + strconv.Itoa(calDays)
+		if utils.Contains(&allRates, published) {
+			avg, err := db.GetInterestRate(published, date.Format("2006-01-02"))
+			if err == nil {
+				pillars = append(pillars, dia.Pillar{Days: calDays, Rate: avg.Value, Source: avg.Symbol})
+				continue
+			}
+			log.Warnf("GetRateCurve: published average %s not available at %v: %v", published, date, err)
+		}
+		avg, err := db.GetCompoundedAvg(symbol, date, calDays, daysPerYear, 0)
+		if err != nil {
+			// Not enough history for this term. The curve is extrapolated from shorter pillars.
+			log.Warnf("GetRateCurve: compounded average of %s over %d days not available at %v: %v", symbol, calDays, date, err)
+			continue
+		}
+		pillars = append(pillars, dia.Pillar{Days: calDays, Rate: avg.Value, Source: avg.Symbol})
+	}
+
+	return ratederivatives.BuildTermStructure(symbol, date, pillars, daysPerYear, ratederivatives.StandardTenors)
+}
@@ -30,11 +30,27 @@ type RelDatastore interface {
 	SetNFT(nft dia.NFT) error
 	GetNFT(address string, blockchain string, tokenID string) (dia.NFT, error)
 	GetNFTID(address string, blockchain string, tokenID string) (string, error)
+	GetNumNFTs(nftclass dia.NFTClass) (int, error)
+
+	// NFT metadata methods
+	SetNFTMetadata(metadata dia.NFTMetadata) error
+	GetNFTMetadata(address string, blockchain string, tokenID string) (dia.NFTMetadata, error)
+	GetNFTClassMetadata(nftclass dia.NFTClass) ([]dia.NFTMetadata, error)
+	GetNFTsForMetadataRefresh(before time.Time, limit int) ([]dia.NFT, error)
+	SetNFTRarityScore(nft dia.NFT, score float64, rank int) error
+	SetNFTTraitRarities(nftclass dia.NFTClass, traits []dia.NFTTraitRarity) error
+	GetNFTTraitRarities(nftclass dia.NFTClass) ([]dia.NFTTraitRarity, error)
+
+	// NFT valuation methods
+	SetNFTValuation(valuation dia.NFTValuation) error
+	GetNFTValuation(address string, blockchain string, tokenID string, t time.Time) (dia.NFTValuation, error)
 
 	// NFT trading and bidding methods
 	SetNFTTrade(trade dia.NFTTrade) error
+	DeleteNFTTrade(trade dia.NFTTrade) error
 	GetNFTTrades(nft dia.NFT) ([]dia.NFTTrade, error)
 	GetNFTPrice30Days(nftclass dia.NFTClass) (float64, error)
+	GetNFTClassTrades(nftclass dia.NFTClass, starttime time.Time, endtime time.Time) ([]dia.NFTTrade, error)
 	GetLastBlockheightTopshot(upperBound time.Time) (uint64, error)
 	GetLastBlockNFTTradeScraper(nftclass dia.NFTClass) (uint64, error)
 	SetNFTBid(bid dia.NFTBid) error
@@ -42,6 +58,10 @@ type RelDatastore interface {
 	GetLastBlockNFTBid(nftclass dia.NFTClass) (uint64, error)
 	SetNFTOffer(offer dia.NFTOffer) error
 	GetLastNFTOffer(address string, blockchain string, tokenID string, blockNumber uint64, blockPosition uint) (offer dia.NFTOffer, err error)
+	SetNFTOrderClose(orderClose dia.NFTOrderClose) error
+	GetNFTBids(nftclass dia.NFTClass, starttime time.Time, endtime time.Time) ([]dia.NFTBid, error)
+	GetNFTOffers(nftclass dia.NFTClass, starttime time.Time, endtime time.Time) ([]dia.NFTOffer, error)
+	GetNFTOrderCloses(nftclass dia.NFTClass, starttime time.Time, endtime time.Time) ([]dia.NFTOrderClose, error)
 
 	// General methods
 	GetKeys(table string) ([]string, error)
@@ -56,20 +76,34 @@ type RelDatastore interface {
 	SetBlockData(dia.BlockData) error
 	GetBlockData(blockchain string, blocknumber int64) (dia.BlockData, error)
 	GetLastBlockBlockscraper(blockchain string) (int64, error)
+
+	// API keys and usage
+	CreateAPIKey(owner string, tier string) (string, APIKey, error)
+	GetAPIKey(key string) (APIKey, error)
+	GetAPIKeys(owner string) ([]APIKey, error)
+	RevokeAPIKey(id string) error
+	IncrementRateLimitCounter(id string, window time.Duration, t time.Time) (int64, error)
+	IncrementAPIKeyUsage(id string, route string, t time.Time) (int64, error)
+	GetAPIKeyUsage(id string, t time.Time) (map[string]int64, error)
 }
 
 const (
 	postgresKey = "postgres_credentials.txt"
 
-	blockchainTable  = "blockchain"
-	blockdataTable   = "blockdata"
-	nftcategoryTable = "nftcategory"
-	nftclassTable    = "nftclass"
-	nftTable         = "nft"
-	nfttradeTable    = "nfttrade"
-	nftbidTable      = "nftbid"
-	nftofferTable    = "nftoffer"
-	scrapersTable    = "scrapers"
+	apikeyTable        = "apikey"
+	blockchainTable    = "blockchain"
+	blockdataTable     = "blockdata"
+	nftcategoryTable   = "nftcategory"
+	nftclassTable      = "nftclass"
+	nftTable           = "nft"
+	nfttradeTable      = "nfttrade"
+	nftmetadataTable   = "nftmetadata"
+	nfttraitTable      = "nfttrait"
+	nftvaluationTable  = "nftvaluation"
+	nftbidTable        = "nftbid"
+	nftofferTable      = "nftoffer"
+	nftordercloseTable = "nftorderclose"
+	scrapersTable      = "scrapers"
 
 	// time format for blockchain genesis dates
 	timeFormatBlockchain = "2006-01-02"
@@ -56,6 +56,12 @@ type Stock struct {
 	ISIN   string
 }
 
+// SourcedStock is a stock along with the source of its quotations.
+type SourcedStock struct {
+	Stock  Stock
+	Source string
+}
+
 type Price struct {
 	Symbol string
 	Name   string
@@ -78,6 +84,19 @@ type InterestRateMeta struct {
 	Issuer    string
 }
 
+// InterestRateReport compares the stored fixings of a rate with the business days of its calendar.
+// MissingFixings are business days without fixing, FixingsOnHolidays are fixings on weekends or holidays.
+type InterestRateReport struct {
+	Symbol            string
+	Calendar          string
+	DateInit          time.Time
+	DateFinal         time.Time
+	BusinessDays      int
+	Fixings           int
+	MissingFixings    []time.Time
+	FixingsOnHolidays []time.Time
+}
+
 type CurrencyChange struct {
 	Symbol        string
 	Rate          float64